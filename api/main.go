@@ -25,7 +25,7 @@ import (
 
 	"github.com/joho/godotenv"
 
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 
 	health "github.com/featureform/health"
 	help "github.com/featureform/helpers"
@@ -43,6 +43,9 @@ type ApiServer struct {
 	listener   net.Listener
 	metadata   MetadataServer
 	online     OnlineServer
+	// authorizer is nil when access control is off.
+	authorizer  helpers.Authorizer
+	credentials credentials.TransportCredentials
 }
 
 type MetadataServer struct {
@@ -1017,6 +1020,51 @@ func (serv *MetadataServer) CreateModel(ctx context.Context, modelRequest *pb.Mo
 	return serv.meta.CreateModel(ctx, modelRequest)
 }
 
+func (serv *MetadataServer) DeleteFeatureVariant(ctx context.Context, deleteRequest *pb.DeleteRequest) (*pb.Empty, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.FeatureVariant, deleteRequest.NameVariant.Name, deleteRequest.NameVariant.Variant)
+	logger.Infow("Deleting Feature Variant", "cascade", deleteRequest.Cascade, "archive", deleteRequest.Archive)
+	deleteRequest.RequestId = requestID
+
+	return serv.meta.DeleteFeatureVariant(ctx, deleteRequest)
+}
+
+func (serv *MetadataServer) DeleteLabelVariant(ctx context.Context, deleteRequest *pb.DeleteRequest) (*pb.Empty, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.LabelVariant, deleteRequest.NameVariant.Name, deleteRequest.NameVariant.Variant)
+	logger.Infow("Deleting Label Variant", "cascade", deleteRequest.Cascade, "archive", deleteRequest.Archive)
+	deleteRequest.RequestId = requestID
+
+	return serv.meta.DeleteLabelVariant(ctx, deleteRequest)
+}
+
+func (serv *MetadataServer) DeleteSourceVariant(ctx context.Context, deleteRequest *pb.DeleteRequest) (*pb.Empty, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.SourceVariant, deleteRequest.NameVariant.Name, deleteRequest.NameVariant.Variant)
+	logger.Infow("Deleting Source Variant", "cascade", deleteRequest.Cascade, "archive", deleteRequest.Archive)
+	deleteRequest.RequestId = requestID
+
+	return serv.meta.DeleteSourceVariant(ctx, deleteRequest)
+}
+
+func (serv *MetadataServer) DeleteTrainingSetVariant(ctx context.Context, deleteRequest *pb.DeleteRequest) (*pb.Empty, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.TrainingSetVariant, deleteRequest.NameVariant.Name, deleteRequest.NameVariant.Variant)
+	logger.Infow("Deleting Training Set Variant", "cascade", deleteRequest.Cascade, "archive", deleteRequest.Archive)
+	deleteRequest.RequestId = requestID
+
+	return serv.meta.DeleteTrainingSetVariant(ctx, deleteRequest)
+}
+
+func (serv *MetadataServer) DeleteModel(ctx context.Context, deleteRequest *pb.DeleteRequest) (*pb.Empty, error) {
+	requestID, ctx, logger := serv.Logger.InitializeRequestID(ctx)
+	logger = logger.WithResource(logging.Model, deleteRequest.NameVariant.Name, logging.NoVariant)
+	logger.Infow("Deleting Model", "cascade", deleteRequest.Cascade, "archive", deleteRequest.Archive)
+	deleteRequest.RequestId = requestID
+
+	return serv.meta.DeleteModel(ctx, deleteRequest)
+}
+
 func (serv *OnlineServer) FeatureServe(ctx context.Context, req *srv.FeatureServeRequest) (*srv.FeatureRow, error) {
 	_, ctx, logger := serv.Logger.InitializeRequestID(ctx)
 	logger.Infow("Serving Features", "request", req.String())
@@ -1074,6 +1122,56 @@ func (serv *OnlineServer) TrainingData(req *srv.TrainingDataRequest, stream srv.
 	}
 }
 
+func (serv *OnlineServer) HistoricalFeatures(req *srv.HistoricalFeaturesRequest, stream srv.Feature_HistoricalFeaturesServer) error {
+	_, ctx, logger := serv.Logger.InitializeRequestID(context.Background())
+	logger.Infow("Serving Historical Features", "features", req.Features)
+	client, err := serv.client.HistoricalFeatures(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to get Historical Features client", "error", err)
+		return err
+	}
+	for {
+		row, err := client.Recv()
+		if err != nil {
+			if err == io.EOF {
+				logger.Debugw("End of stream reached. Stream request completed")
+				return nil
+			}
+			logger.Errorw("Failed to receive row from client", "row", row, "error", err)
+			return err
+		}
+		if err := stream.Send(row); err != nil {
+			logger.Errorw("Failed to write to stream", "error", err)
+			return err
+		}
+	}
+}
+
+func (serv *OnlineServer) TrainingDataArrow(req *srv.TrainingDataArrowRequest, stream srv.Feature_TrainingDataArrowServer) error {
+	_, ctx, logger := serv.Logger.InitializeRequestID(context.Background())
+	logger.Infow("Serving Arrow Training Data", "id", req.Id.String())
+	client, err := serv.client.TrainingDataArrow(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to get Arrow Training Data client", "error", err)
+		return err
+	}
+	for {
+		batch, err := client.Recv()
+		if err != nil {
+			if err == io.EOF {
+				logger.Debugw("End of stream reached. Stream request completed")
+				return nil
+			}
+			logger.Errorw("Failed to receive batch from client", "error", err)
+			return err
+		}
+		if err := stream.Send(batch); err != nil {
+			logger.Errorw("Failed to write to stream", "error", err)
+			return err
+		}
+	}
+}
+
 func (serv *OnlineServer) TrainTestSplit(stream srv.Feature_TrainTestSplitServer) error {
 	_, ctx, logger := serv.Logger.InitializeRequestID(context.Background())
 	logger.Infow("Starting Training Test Split Stream")
@@ -1187,11 +1285,13 @@ func (serv *ApiServer) Serve() error {
 		logger.Errorw("Failed to listen", "error", err)
 		return fferr.NewInternalError(err)
 	}
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		// grpc.WithUnaryInterceptor(fferr.UnaryClientInterceptor()),
-		// grpc.WithStreamInterceptor(fferr.StreamClientInterceptor()),
+	opts, err := helpers.ClientDialOptionsFromEnv()
+	if err != nil {
+		logger.Errorw("Failed to configure client credentials", "error", err)
+		return err
 	}
+	// opts = append(opts, grpc.WithUnaryInterceptor(fferr.UnaryClientInterceptor()))
+	// opts = append(opts, grpc.WithStreamInterceptor(fferr.StreamClientInterceptor()))
 	metaConn, err := grpc.Dial(serv.metadata.address, opts...)
 	if err != nil {
 		logger.Errorw("Failed to dial metadata server", "error", err)
@@ -1212,6 +1312,18 @@ func (serv *ApiServer) Serve() error {
 	serv.online.client = srv.NewFeatureClient(servConn)
 	serv.metadata.health = health.NewHealth(client)
 	logger.Infof("Created metadata client successfully.")
+	rbac, err := metadata.RBACConfigFromEnv()
+	if err != nil {
+		logger.Errorw("Failed to configure access control", "error", err)
+		return err
+	}
+	if rbac != nil {
+		serv.authorizer = metadata.NewRBACAuthorizer(client, *rbac)
+	}
+	if serv.credentials, err = helpers.ServerTLSFromEnv(); err != nil {
+		logger.Errorw("Failed to load TLS credentials", "error", err)
+		return err
+	}
 	return serv.ServeOnListener(lis)
 }
 
@@ -1247,13 +1359,21 @@ func (serv *ApiServer) ServeOnListener(lis net.Listener) error {
 	kasp := keepalive.ServerParameters{
 		Timeout: time.Duration(timeout) * time.Minute, // time after which the connection is closed if no activity
 	}
+	unary := []grpc.UnaryServerInterceptor{grpc_logrus.UnaryServerInterceptor(logrusEntry, lorgusOpts...)}
+	var stream []grpc.StreamServerInterceptor
+	if serv.authorizer != nil {
+		unary = append(unary, helpers.UnaryServerErrorInterceptor, helpers.UnaryServerAuthInterceptor(serv.authorizer))
+		stream = append(stream, helpers.StreamServerErrorInterceptor, helpers.StreamServerAuthInterceptor(serv.authorizer))
+	}
 	opt := []grpc.ServerOption{
-		grpc_middleware.WithUnaryServerChain(
-			grpc_logrus.UnaryServerInterceptor(logrusEntry, lorgusOpts...),
-		),
+		grpc_middleware.WithUnaryServerChain(unary...),
+		grpc_middleware.WithStreamServerChain(stream...),
 		grpc.KeepaliveEnforcementPolicy(kaep),
 		grpc.KeepaliveParams(kasp),
 	}
+	if serv.credentials != nil {
+		opt = append(opt, grpc.Creds(serv.credentials))
+	}
 	grpcServer := grpc.NewServer(opt...)
 	reflection.Register(grpcServer)
 	pb.RegisterApiServer(grpcServer, &serv.metadata)
@@ -2,9 +2,9 @@ package backup
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/featureform/filestore"
@@ -27,6 +27,34 @@ type BackupManager struct {
 	ETCDClient Client
 	Provider   Provider
 	Logger     *zap.SugaredLogger
+	// EncryptionKey encrypts snapshots with AES-GCM when set, and is needed to restore them. It must be 16,
+	// 24 or 32 bytes long.
+	EncryptionKey []byte
+	// Retention prunes old snapshots after each save when set.
+	Retention *RetentionPolicy
+}
+
+// RestoreOptions narrows down what a restore does.
+type RestoreOptions struct {
+	// Prefixes limits the restore to keys starting with any of them, e.g. "FEATURE_VARIANT__transactions__"
+	// for every variant of one feature. Only matching keys are deleted from etcd. Everything is restored
+	// if it's empty.
+	Prefixes []string
+	// DryRun only diffs the snapshot against etcd, leaving etcd untouched.
+	DryRun bool
+	// AllowUnencrypted restores snapshots that aren't encrypted even though an EncryptionKey is set, e.g.
+	// ones taken before encryption was turned on.
+	AllowUnencrypted bool
+}
+
+// RestoreDiff lists the keys a restore changes.
+type RestoreDiff struct {
+	// Added are in the snapshot but not in etcd.
+	Added []string
+	// Changed have a different value in the snapshot than in etcd.
+	Changed []string
+	// Removed are in etcd but not in the snapshot, and are deleted by the restore.
+	Removed []string
 }
 
 func (b *BackupManager) Save() error {
@@ -49,82 +77,67 @@ func (b *BackupManager) SaveTo(filename string) error {
 	if err != nil {
 		return fmt.Errorf("cannot upload snapshot to filestore: %v", err)
 	}
+	if b.Retention != nil {
+		if _, err := b.Prune(); err != nil {
+			return fmt.Errorf("could not prune snapshots: %v", err)
+		}
+	}
 	return nil
 }
 
 func (b *BackupManager) Restore(filenamePrefix string) error {
+	_, err := b.RestoreWithOptions(filenamePrefix, RestoreOptions{})
+	return err
+}
+
+func (b *BackupManager) RestoreWithOptions(filenamePrefix string, opts RestoreOptions) (RestoreDiff, error) {
 	b.Logger.Info("Starting Restore")
 	err := b.Provider.Init()
 	if err != nil {
-		return fmt.Errorf("could not initialize provider: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not initialize provider: %v", err)
 	}
 
 	b.Logger.Infof("Getting Latest Backup With Prefix `%s`", filenamePrefix)
 	filename, err := b.Provider.LatestBackupName(filenamePrefix)
 	if err != nil {
-		return fmt.Errorf("could not get latest backup: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not get latest backup: %v", err)
 	}
 
 	b.Logger.Infof("Restoring with file: %s", filename.ToURI())
-	err = b.RestoreFrom(filename)
+	diff, err := b.RestoreFromWithOptions(filename, opts)
 	if err != nil {
-		return fmt.Errorf("could not restore file %s: %v", filename.ToURI(), err)
+		return RestoreDiff{}, fmt.Errorf("could not restore file %s: %v", filename.ToURI(), err)
 	}
-	return nil
+	return diff, nil
 }
 
 func (b *BackupManager) RestoreFrom(source filestore.Filepath) error {
+	_, err := b.RestoreFromWithOptions(source, RestoreOptions{})
+	return err
+}
+
+func (b *BackupManager) RestoreFromWithOptions(source filestore.Filepath, opts RestoreOptions) (RestoreDiff, error) {
 	err := b.Provider.Init()
 	if err != nil {
-		return fmt.Errorf("could not initialize provider: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not initialize provider: %v", err)
 	}
 	b.Logger.Info("Downloading Restore File")
 	destination := &filestore.LocalFilepath{}
 	if err := destination.SetKey(SnapshotFilename); err != nil {
-		return fmt.Errorf("cannot set destination key: %v", err)
+		return RestoreDiff{}, fmt.Errorf("cannot set destination key: %v", err)
 	}
 
 	err = b.Provider.Download(source, destination)
 	if err != nil {
-		return fmt.Errorf("could not download snapshot file %s: %v", source.ToURI(), err)
+		return RestoreDiff{}, fmt.Errorf("could not download snapshot file %s: %v", source.ToURI(), err)
 	}
 
 	b.Logger.Info("Loading Snapshot")
-	err = b.loadSnapshot(SnapshotFilename)
-	if err != nil {
-		return fmt.Errorf("could not load snapshot: %v", err)
-	}
-	return nil
-}
-
-type backupRow struct {
-	Key   []byte
-	Value []byte
-}
-
-type backup []backupRow
-
-func (f backup) writeTo(filename string) error {
-	file, err := json.Marshal(f)
+	diff, err := b.loadSnapshot(SnapshotFilename, opts)
 	if err != nil {
-		return fmt.Errorf("could not marshal snapshot: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not load snapshot: %v", err)
 	}
-	if err = ioutil.WriteFile(filename, file, 0644); err != nil {
-		return fmt.Errorf("could not write snapshot to file: %v", err)
-	}
-	return nil
-}
-
-func (f *backup) readFrom(filename string) error {
-	file, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("could not read file %s: %v", filename, err)
-	}
-	err = json.Unmarshal(file, &f)
-	if err != nil {
-		return fmt.Errorf("could not unmarshal file %s: %v", filename, err)
-	}
-	return nil
+	return diff, nil
 }
 
 func (b *BackupManager) takeSnapshot(filename string) error {
@@ -133,7 +146,7 @@ func (b *BackupManager) takeSnapshot(filename string) error {
 		return fmt.Errorf("could get snapshot values: %v", err)
 	}
 
-	err = b.convertEtcdToBackup(resp.Kvs).writeTo(filename)
+	err = b.convertEtcdToBackup(resp.Kvs).writeTo(filename, b.EncryptionKey)
 	if err != nil {
 		return err
 	}
@@ -151,25 +164,96 @@ func (b *BackupManager) convertEtcdToBackup(resp []*mvccpb.KeyValue) backup {
 	return values
 }
 
-func (b *BackupManager) loadSnapshot(filename string) error {
+func (b *BackupManager) loadSnapshot(filename string, opts RestoreOptions) (RestoreDiff, error) {
 	backupData := backup{}
 	b.Logger.Info("Reading From Snapshot File")
-	err := backupData.readFrom(filename)
+	manifest, err := backupData.readFrom(filename, b.EncryptionKey, opts.AllowUnencrypted)
 	if err != nil {
-		return fmt.Errorf("could not read from file %s: %v", filename, err)
+		return RestoreDiff{}, fmt.Errorf("could not read from file %s: %v", filename, err)
 	}
+	b.Logger.Infow("Read Snapshot", "schema_version", manifest.SchemaVersion, "created_at", manifest.CreatedAt, "keys", manifest.Keys)
+	backupData = backupData.filter(opts.Prefixes)
+
+	b.Logger.Info("Comparing Snapshot to ETCD")
+	live, err := b.getEtcd(opts.Prefixes)
+	if err != nil {
+		return RestoreDiff{}, fmt.Errorf("could not read ETCD: %v", err)
+	}
+	diff := diffSnapshot(backupData, live)
+	if opts.DryRun {
+		return diff, nil
+	}
+
 	b.Logger.Info("Clearing ETCD")
-	err = b.clearEtcd()
+	err = b.clearEtcd(opts.Prefixes)
 	if err != nil {
-		return fmt.Errorf("could not clear ETCD: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not clear ETCD: %v", err)
 	}
 	b.Logger.Info("Writing Snapshot to ETCD")
 	if err := b.writeToEtcd(backupData); err != nil {
 		b.Logger.Error("BACKUP FAILED: Backup was unable to complete. Partial snapshot has been restored")
-		return fmt.Errorf("could not write to ETCD: %v", err)
+		return RestoreDiff{}, fmt.Errorf("could not write to ETCD: %v", err)
 	}
 
-	return nil
+	return diff, nil
+}
+
+// filter returns the rows whose keys start with any of prefixes, or every row if there are none.
+func (f backup) filter(prefixes []string) backup {
+	if len(prefixes) == 0 {
+		return f
+	}
+	filtered := make(backup, 0)
+	for _, row := range f {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(string(row.Key), prefix) {
+				filtered = append(filtered, row)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// getEtcd returns the live keys starting with any of prefixes, or every key if there are none.
+func (b *BackupManager) getEtcd(prefixes []string) (backup, error) {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	live := make(backup, 0)
+	for _, prefix := range prefixes {
+		resp, err := b.ETCDClient.Get(context.Background(), prefix, clientv3.WithPrefix())
+		if err != nil {
+			return nil, err
+		}
+		live = append(live, b.convertEtcdToBackup(resp.Kvs)...)
+	}
+	return live.filter(prefixes), nil
+}
+
+func diffSnapshot(snapshot, live backup) RestoreDiff {
+	liveValues := make(map[string]string, len(live))
+	for _, row := range live {
+		liveValues[string(row.Key)] = string(row.Value)
+	}
+	diff := RestoreDiff{Added: []string{}, Changed: []string{}, Removed: []string{}}
+	for _, row := range snapshot {
+		key := string(row.Key)
+		value, has := liveValues[key]
+		if !has {
+			diff.Added = append(diff.Added, key)
+		} else if value != string(row.Value) {
+			diff.Changed = append(diff.Changed, key)
+		}
+		delete(liveValues, key)
+	}
+	for key := range liveValues {
+		diff.Removed = append(diff.Removed, key)
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff
 }
 
 func (b *BackupManager) writeToEtcd(data backup) error {
@@ -182,17 +266,33 @@ func (b *BackupManager) writeToEtcd(data backup) error {
 	return nil
 }
 
-func (b *BackupManager) clearEtcd() error {
-	_, err := b.ETCDClient.Delete(context.Background(), "", clientv3.WithPrefix())
-	if err != nil {
-		return fmt.Errorf("delete: %v", err)
+func (b *BackupManager) clearEtcd(prefixes []string) error {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	for _, prefix := range prefixes {
+		_, err := b.ETCDClient.Delete(context.Background(), prefix, clientv3.WithPrefix())
+		if err != nil {
+			return fmt.Errorf("delete %s: %v", prefix, err)
+		}
 	}
 	return nil
 }
 
 func GenerateSnapshotName(currentTime time.Time) string {
 	prefix := SnapshotPrefix
-	formattedTime := currentTime.Format("2006-01-02_15:04:05")
+	formattedTime := currentTime.UTC().Format("2006-01-02_15:04:05")
 
 	return fmt.Sprintf("%s__%s.db", prefix, formattedTime)
 }
+
+// ParseSnapshotTime returns the time a snapshot named by GenerateSnapshotName was taken. Names are in UTC.
+// Any directories before the name are ignored.
+func ParseSnapshotTime(name string) (time.Time, error) {
+	name = name[strings.LastIndex(name, "/")+1:]
+	formattedTime := strings.TrimSuffix(strings.TrimPrefix(name, SnapshotPrefix+"__"), ".db")
+	if formattedTime == name {
+		return time.Time{}, fmt.Errorf("%s is not a snapshot name", name)
+	}
+	return time.Parse("2006-01-02_15:04:05", formattedTime)
+}
@@ -3,15 +3,17 @@ package backup
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
 )
 
 func TestGenerateSnapshotName(t *testing.T) {
@@ -24,6 +26,17 @@ func TestGenerateSnapshotName(t *testing.T) {
 	}
 }
 
+func TestGenerateSnapshotNameIsUTC(t *testing.T) {
+	taken := time.Date(2020, 11, 12, 10, 5, 1, 0, time.FixedZone("UTC-5", -5*60*60))
+	parsed, err := ParseSnapshotTime(GenerateSnapshotName(taken))
+	if err != nil {
+		t.Fatalf("Failed to parse snapshot name: %v", err)
+	}
+	if !parsed.Equal(taken) {
+		t.Fatalf("Expected snapshot time %s, got %s", taken, parsed)
+	}
+}
+
 func TestBackup_Save(t *testing.T) {
 	emptyClient := myClient{}
 
@@ -113,7 +126,7 @@ func TestBackup_takeSnapshot(t *testing.T) {
 				Provider:   &Local{Path: "file://./"},
 			},
 			args{
-				"Test.json",
+				filepath.Join(t.TempDir(), "Test.json"),
 			},
 			false},
 	}
@@ -127,13 +140,8 @@ func TestBackup_takeSnapshot(t *testing.T) {
 				t.Errorf("takeSnapshot() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
-		file, err := ioutil.ReadFile(tt.args.filename)
-		if err != nil {
-			return
-		}
-		bFile := []backupRow{}
-		err = json.Unmarshal(file, &bFile)
-		if err != nil {
+		bFile := backup{}
+		if _, err := bFile.readFrom(tt.args.filename, nil, false); err != nil {
 			t.Fatalf(err.Error())
 		}
 		for i, row := range bFile {
@@ -143,3 +151,109 @@ func TestBackup_takeSnapshot(t *testing.T) {
 		}
 	}
 }
+
+// memoryClient is an in-memory etcd that treats every Get and Delete as a prefix operation, as
+// BackupManager only makes those.
+type memoryClient map[string]string
+
+func (c memoryClient) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	kvs := make([]*mvccpb.KeyValue, 0)
+	for k, v := range c {
+		if strings.HasPrefix(k, key) {
+			kvs = append(kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	resp := clientv3.GetResponse(pb.RangeResponse{Kvs: kvs})
+	return &resp, nil
+}
+
+func (c memoryClient) Put(ctx context.Context, key string, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	c[key] = val
+	return nil, nil
+}
+
+func (c memoryClient) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	for k := range c {
+		if strings.HasPrefix(k, key) {
+			delete(c, k)
+		}
+	}
+	return nil, nil
+}
+
+func TestBackup_loadSnapshot(t *testing.T) {
+	snapshot := memoryClient{
+		"FEATURE_VARIANT__amount__v1": "amount v1",
+		"FEATURE_VARIANT__amount__v2": "amount v2",
+		"FEATURE_VARIANT__count__v1":  "count v1",
+	}
+	filename := filepath.Join(t.TempDir(), "snapshot.db")
+	key := []byte("0123456789abcdef")
+	if err := (&BackupManager{ETCDClient: snapshot, EncryptionKey: key}).takeSnapshot(filename); err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	live := func() memoryClient {
+		return memoryClient{
+			"FEATURE_VARIANT__amount__v1": "amount v1 changed",
+			"FEATURE_VARIANT__amount__v3": "amount v3",
+			"FEATURE_VARIANT__count__v2":  "count v2",
+		}
+	}
+	tests := []struct {
+		name     string
+		opts     RestoreOptions
+		diff     RestoreDiff
+		expected memoryClient
+	}{
+		{
+			"Dry Run",
+			RestoreOptions{DryRun: true},
+			RestoreDiff{
+				Added:   []string{"FEATURE_VARIANT__amount__v2", "FEATURE_VARIANT__count__v1"},
+				Changed: []string{"FEATURE_VARIANT__amount__v1"},
+				Removed: []string{"FEATURE_VARIANT__amount__v3", "FEATURE_VARIANT__count__v2"},
+			},
+			live(),
+		},
+		{
+			"Full Restore",
+			RestoreOptions{},
+			RestoreDiff{
+				Added:   []string{"FEATURE_VARIANT__amount__v2", "FEATURE_VARIANT__count__v1"},
+				Changed: []string{"FEATURE_VARIANT__amount__v1"},
+				Removed: []string{"FEATURE_VARIANT__amount__v3", "FEATURE_VARIANT__count__v2"},
+			},
+			snapshot,
+		},
+		{
+			"Prefix Restore",
+			RestoreOptions{Prefixes: []string{"FEATURE_VARIANT__amount__"}},
+			RestoreDiff{
+				Added:   []string{"FEATURE_VARIANT__amount__v2"},
+				Changed: []string{"FEATURE_VARIANT__amount__v1"},
+				Removed: []string{"FEATURE_VARIANT__amount__v3"},
+			},
+			memoryClient{
+				"FEATURE_VARIANT__amount__v1": "amount v1",
+				"FEATURE_VARIANT__amount__v2": "amount v2",
+				"FEATURE_VARIANT__count__v2":  "count v2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etcd := live()
+			b := &BackupManager{ETCDClient: etcd, EncryptionKey: key, Logger: zap.NewNop().Sugar()}
+			diff, err := b.loadSnapshot(filename, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to load snapshot: %v", err)
+			}
+			if !reflect.DeepEqual(diff, tt.diff) {
+				t.Fatalf("Expected diff %+v, got %+v", tt.diff, diff)
+			}
+			if !reflect.DeepEqual(etcd, tt.expected) {
+				t.Fatalf("Expected etcd to be %v, got %v", tt.expected, etcd)
+			}
+		})
+	}
+}
@@ -22,6 +22,17 @@ type Provider interface {
 	Upload(src, dest string) error
 	Download(src, dest filestore.Filepath) error
 	LatestBackupName(filenamePrefix string) (filestore.Filepath, error)
+	// ListBackups returns every snapshot whose name starts with filenamePrefix.
+	ListBackups(filenamePrefix string) ([]filestore.Filepath, error)
+	Delete(path filestore.Filepath) error
+}
+
+func listBackups(store provider.FileStore, filenamePrefix string) ([]filestore.Filepath, error) {
+	dirPath, err := store.CreateFilePath(filenamePrefix, true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create dir path: %v", err)
+	}
+	return store.List(dirPath, filestore.DB)
 }
 
 type Azure struct {
@@ -76,6 +87,14 @@ func (az *Azure) LatestBackupName(dir string) (filestore.Filepath, error) {
 	return az.store.NewestFileOfType(dirPath, filestore.DB)
 }
 
+func (az *Azure) ListBackups(filenamePrefix string) ([]filestore.Filepath, error) {
+	return listBackups(az.store, filenamePrefix)
+}
+
+func (az *Azure) Delete(path filestore.Filepath) error {
+	return az.store.Delete(path)
+}
+
 type S3 struct {
 	AWSAccessKeyId string
 	AWSSecretKey   string
@@ -133,6 +152,14 @@ func (s3 *S3) LatestBackupName(dir string) (filestore.Filepath, error) {
 	return s3.store.NewestFileOfType(dirPath, filestore.DB)
 }
 
+func (s3 *S3) ListBackups(filenamePrefix string) ([]filestore.Filepath, error) {
+	return listBackups(s3.store, filenamePrefix)
+}
+
+func (s3 *S3) Delete(path filestore.Filepath) error {
+	return s3.store.Delete(path)
+}
+
 type Local struct {
 	Path  string
 	store provider.FileStore
@@ -179,6 +206,14 @@ func (fs *Local) LatestBackupName(dir string) (filestore.Filepath, error) {
 	return fs.store.NewestFileOfType(dirPath, filestore.DB)
 }
 
+func (fs *Local) ListBackups(filenamePrefix string) ([]filestore.Filepath, error) {
+	return listBackups(fs.store, filenamePrefix)
+}
+
+func (fs *Local) Delete(path filestore.Filepath) error {
+	return fs.store.Delete(path)
+}
+
 type GCS struct {
 	BucketName             string
 	BucketPath             string
@@ -265,3 +300,11 @@ func (g *GCS) LatestBackupName(dir string) (filestore.Filepath, error) {
 	}
 	return g.store.NewestFileOfType(dirPath, filestore.DB)
 }
+
+func (g *GCS) ListBackups(filenamePrefix string) ([]filestore.Filepath, error) {
+	return listBackups(g.store, filenamePrefix)
+}
+
+func (g *GCS) Delete(path filestore.Filepath) error {
+	return g.store.Delete(path)
+}
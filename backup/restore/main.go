@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/featureform/backup"
@@ -76,13 +78,30 @@ func main() {
 		Provider:   backupProvider,
 		Logger:     logger.SugaredLogger,
 	}
+	if encodedKey := help.GetEnv("BACKUP_ENCRYPTION_KEY", ""); encodedKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			panic(fmt.Errorf("BACKUP_ENCRYPTION_KEY must be base64 encoded: %v", err))
+		}
+		backupExecutor.EncryptionKey = key
+	}
 
 	if snapshotName == "" {
 		snapshotName = backup.SnapshotPrefix
 	}
 
-	err = backupExecutor.Restore(snapshotName)
+	opts := backup.RestoreOptions{
+		DryRun:           help.GetEnvBool("RESTORE_DRY_RUN", false),
+		AllowUnencrypted: help.GetEnvBool("RESTORE_ALLOW_UNENCRYPTED", false),
+	}
+	for _, prefix := range strings.Split(help.GetEnv("RESTORE_PREFIXES", ""), ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			opts.Prefixes = append(opts.Prefixes, prefix)
+		}
+	}
+	diff, err := backupExecutor.RestoreWithOptions(snapshotName, opts)
 	if err != nil {
 		panic(err)
 	}
+	logger.Infow("Restore diff", "dry_run", opts.DryRun, "added", diff.Added, "changed", diff.Changed, "removed", diff.Removed)
 }
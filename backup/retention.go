@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/featureform/filestore"
+)
+
+// RetentionPolicy decides which snapshots to keep. A snapshot is pruned if it isn't one of the KeepLast
+// newest or if it's older than MaxAge; a zero value turns either rule off. The newest snapshot is never
+// pruned.
+type RetentionPolicy struct {
+	KeepLast int
+	MaxAge   time.Duration
+}
+
+type datedSnapshot struct {
+	path  filestore.Filepath
+	taken time.Time
+}
+
+// expired returns the snapshots the policy prunes at now. Files that aren't named like snapshots are kept.
+func (policy RetentionPolicy) expired(paths []filestore.Filepath, now time.Time) []filestore.Filepath {
+	snapshots := make([]datedSnapshot, 0, len(paths))
+	for _, path := range paths {
+		taken, err := ParseSnapshotTime(path.Key())
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, datedSnapshot{path, taken})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].taken.After(snapshots[j].taken) })
+	expired := make([]filestore.Filepath, 0)
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		tooMany := policy.KeepLast > 0 && i >= policy.KeepLast
+		tooOld := policy.MaxAge > 0 && now.Sub(snapshot.taken) > policy.MaxAge
+		if tooMany || tooOld {
+			expired = append(expired, snapshot.path)
+		}
+	}
+	return expired
+}
+
+// Prune deletes the snapshots that the retention policy no longer keeps and returns them.
+func (b *BackupManager) Prune() ([]filestore.Filepath, error) {
+	if b.Retention == nil {
+		return nil, nil
+	}
+	if err := b.Provider.Init(); err != nil {
+		return nil, fmt.Errorf("could not initialize provider: %v", err)
+	}
+	paths, err := b.Provider.ListBackups(SnapshotPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not list snapshots: %v", err)
+	}
+	expired := b.Retention.expired(paths, time.Now())
+	for _, path := range expired {
+		if err := b.Provider.Delete(path); err != nil {
+			return nil, fmt.Errorf("could not delete snapshot %s: %v", path.ToURI(), err)
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/featureform/filestore"
+)
+
+func snapshotPaths(t *testing.T, names ...string) []filestore.Filepath {
+	paths := make([]filestore.Filepath, len(names))
+	for i, name := range names {
+		path := &filestore.LocalFilepath{}
+		if err := path.SetKey(name); err != nil {
+			t.Fatalf("Failed to set key: %v", err)
+		}
+		paths[i] = path
+	}
+	return paths
+}
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	names := []string{
+		GenerateSnapshotName(now.Add(-24 * time.Hour)),
+		GenerateSnapshotName(now.Add(-72 * time.Hour)),
+		GenerateSnapshotName(now.Add(-1 * time.Hour)),
+		GenerateSnapshotName(now.Add(-48 * time.Hour)),
+		"unrelated.db",
+	}
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		expected []string
+	}{
+		{"Keep Everything", RetentionPolicy{}, []string{}},
+		{"Keep Last Two", RetentionPolicy{KeepLast: 2}, []string{names[3], names[1]}},
+		{"Max Age", RetentionPolicy{MaxAge: 36 * time.Hour}, []string{names[3], names[1]}},
+		{"Both", RetentionPolicy{KeepLast: 3, MaxAge: 60 * time.Hour}, []string{names[1]}},
+		{"Newest Always Kept", RetentionPolicy{MaxAge: time.Minute}, []string{names[0], names[3], names[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := tt.policy.expired(snapshotPaths(t, names...), now)
+			keys := make([]string, len(expired))
+			for i, path := range expired {
+				keys[i] = path.Key()
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Fatalf("Expected %v to expire, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := GenerateSnapshotName(now.Add(-48 * time.Hour))
+	recent := GenerateSnapshotName(now.Add(-time.Hour))
+	for _, name := range []string{old, recent} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to write snapshot: %v", err)
+		}
+	}
+	b := &BackupManager{
+		Provider:  &Local{Path: "file://" + dir + "/"},
+		Retention: &RetentionPolicy{MaxAge: 24 * time.Hour},
+	}
+	pruned, err := b.Prune()
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if len(pruned) != 1 {
+		t.Fatalf("Expected one snapshot to be pruned, got %v", pruned)
+	}
+	if _, err := os.Stat(filepath.Join(dir, old)); !os.IsNotExist(err) {
+		t.Fatalf("Expected %s to be deleted", old)
+	}
+	if _, err := os.Stat(filepath.Join(dir, recent)); err != nil {
+		t.Fatalf("Expected %s to be kept: %v", recent, err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"time"
@@ -75,6 +76,21 @@ func main() {
 		Provider:   backupProvider,
 		Logger:     logger.SugaredLogger,
 	}
+	if encodedKey := help.GetEnv("BACKUP_ENCRYPTION_KEY", ""); encodedKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			panic(fmt.Errorf("BACKUP_ENCRYPTION_KEY must be base64 encoded: %v", err))
+		}
+		backupExecutor.EncryptionKey = key
+	}
+	keepLast := help.GetEnvInt("BACKUP_KEEP_LAST", 0)
+	maxAge, err := time.ParseDuration(help.GetEnv("BACKUP_MAX_AGE", "0s"))
+	if err != nil {
+		panic(fmt.Errorf("BACKUP_MAX_AGE must be a duration like 720h: %v", err))
+	}
+	if keepLast > 0 || maxAge > 0 {
+		backupExecutor.Retention = &backup.RetentionPolicy{KeepLast: keepLast, MaxAge: maxAge}
+	}
 
 	err = backupExecutor.Save()
 	if err != nil {
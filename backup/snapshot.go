@@ -0,0 +1,238 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// SnapshotSchemaVersion is the version of the snapshot format written by this package. Version 0 is the
+// original format, a plain JSON list of rows with no manifest, and version 1 checksummed the rows rather
+// than the payload and didn't authenticate the manifest. Both can still be restored.
+const SnapshotSchemaVersion = 2
+
+const (
+	gzipCompression  = "gzip"
+	aesGCMEncryption = "aes-gcm"
+)
+
+// Manifest describes a snapshot and is stored unencrypted alongside it, so snapshots can be inspected
+// without their key.
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Keys          int       `json:"keys"`
+	Compression   string    `json:"compression"`
+	Encryption    string    `json:"encryption,omitempty"`
+	// Checksum is the hex encoded SHA-256 of the snapshot's payload, after it's compressed and encrypted.
+	Checksum string `json:"checksum"`
+}
+
+// additionalData is the part of the manifest that encryption authenticates, so it can't be changed without
+// the key. The checksum is left out, as it's computed from the encrypted payload.
+func (manifest Manifest) additionalData() ([]byte, error) {
+	manifest.Checksum = ""
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal manifest: %v", err)
+	}
+	return data, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type snapshotFile struct {
+	Manifest Manifest `json:"manifest"`
+	Payload  []byte   `json:"payload"`
+}
+
+type backupRow struct {
+	Key   []byte
+	Value []byte
+}
+
+type backup []backupRow
+
+// writeTo compresses the rows and, if key is set, encrypts them with AES-GCM.
+func (f backup) writeTo(filename string, key []byte) error {
+	rows, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("could not marshal snapshot: %v", err)
+	}
+	manifest := Manifest{
+		SchemaVersion: SnapshotSchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+		Keys:          len(f),
+		Compression:   gzipCompression,
+	}
+	payload, err := compress(rows)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		manifest.Encryption = aesGCMEncryption
+		additionalData, err := manifest.additionalData()
+		if err != nil {
+			return err
+		}
+		if payload, err = encrypt(payload, key, additionalData); err != nil {
+			return err
+		}
+	}
+	manifest.Checksum = checksum(payload)
+	file, err := json.Marshal(snapshotFile{Manifest: manifest, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("could not marshal snapshot: %v", err)
+	}
+	if err = ioutil.WriteFile(filename, file, 0644); err != nil {
+		return fmt.Errorf("could not write snapshot to file: %v", err)
+	}
+	return nil
+}
+
+// readFrom reads a snapshot of any schema version, checking it against its manifest's checksum. If key is
+// set, snapshots that aren't encrypted are rejected unless allowUnencrypted is, so a snapshot can't be
+// swapped for a forged plaintext one.
+func (f *backup) readFrom(filename string, key []byte, allowUnencrypted bool) (Manifest, error) {
+	file, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("could not read file %s: %v", filename, err)
+	}
+	rejectUnencrypted := key != nil && !allowUnencrypted
+	if trimmed := bytes.TrimSpace(file); len(trimmed) > 0 && trimmed[0] == '[' {
+		if rejectUnencrypted {
+			return Manifest{}, fmt.Errorf("snapshot %s is not encrypted but an encryption key is set", filename)
+		}
+		if err := json.Unmarshal(file, f); err != nil {
+			return Manifest{}, fmt.Errorf("could not unmarshal file %s: %v", filename, err)
+		}
+		return Manifest{Keys: len(*f)}, nil
+	}
+	snapshot := snapshotFile{}
+	if err := json.Unmarshal(file, &snapshot); err != nil {
+		return Manifest{}, fmt.Errorf("could not unmarshal file %s: %v", filename, err)
+	}
+	manifest := snapshot.Manifest
+	if manifest.SchemaVersion > SnapshotSchemaVersion {
+		return manifest, fmt.Errorf("snapshot %s has schema version %d, newer than the supported %d", filename, manifest.SchemaVersion, SnapshotSchemaVersion)
+	}
+	payload := snapshot.Payload
+	// Version 1 snapshots checksum their rows, which is checked once they're decompressed.
+	if manifest.SchemaVersion >= 2 && checksum(payload) != manifest.Checksum {
+		return manifest, fmt.Errorf("snapshot %s does not match its checksum", filename)
+	}
+	switch manifest.Encryption {
+	case "":
+		if rejectUnencrypted {
+			return manifest, fmt.Errorf("snapshot %s is not encrypted but an encryption key is set", filename)
+		}
+	case aesGCMEncryption:
+		if key == nil {
+			return manifest, fmt.Errorf("snapshot %s is encrypted but no key was given", filename)
+		}
+		var additionalData []byte
+		if manifest.SchemaVersion >= 2 {
+			if additionalData, err = manifest.additionalData(); err != nil {
+				return manifest, err
+			}
+		}
+		if payload, err = decrypt(payload, key, additionalData); err != nil {
+			return manifest, err
+		}
+	default:
+		return manifest, fmt.Errorf("snapshot %s has unsupported encryption %s", filename, manifest.Encryption)
+	}
+	if manifest.Compression != gzipCompression {
+		return manifest, fmt.Errorf("snapshot %s has unsupported compression %s", filename, manifest.Compression)
+	}
+	rows, err := decompress(payload)
+	if err != nil {
+		return manifest, err
+	}
+	if manifest.SchemaVersion < 2 && checksum(rows) != manifest.Checksum {
+		return manifest, fmt.Errorf("snapshot %s does not match its checksum", filename)
+	}
+	if err := json.Unmarshal(rows, f); err != nil {
+		return manifest, fmt.Errorf("could not unmarshal rows of %s: %v", filename, err)
+	}
+	return manifest, nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("could not compress snapshot: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("could not compress snapshot: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress snapshot: %v", err)
+	}
+	defer reader.Close()
+	rows, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress snapshot: %v", err)
+	}
+	return rows, nil
+}
+
+// encrypt seals data with AES-GCM, prefixing it with the random nonce used, and authenticates additionalData
+// along with it. The key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func encrypt(data, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %v", err)
+	}
+	return gcm.Seal(nonce, nonce, data, additionalData), nil
+}
+
+func decrypt(data, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted snapshot is too short")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt snapshot, the key may be wrong or the manifest changed: %v", err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %v", err)
+	}
+	return gcm, nil
+}
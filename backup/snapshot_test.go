@@ -0,0 +1,148 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	rows := backup{{Key: []byte("key1"), Value: []byte("value1")}, {Key: []byte("key2"), Value: []byte("value2")}}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name       string
+		writeKey   []byte
+		readKey    []byte
+		encryption string
+		wantErr    bool
+	}{
+		{"Compressed", nil, nil, "", false},
+		{"Encrypted", key, key, aesGCMEncryption, false},
+		{"Missing Key", key, nil, aesGCMEncryption, true},
+		{"Wrong Key", key, []byte("fedcba9876543210fedcba9876543210"), aesGCMEncryption, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "snapshot.db")
+			if err := rows.writeTo(filename, tt.writeKey); err != nil {
+				t.Fatalf("Failed to write snapshot: %v", err)
+			}
+			read := backup{}
+			manifest, err := read.readFrom(filename, tt.readKey, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if manifest.SchemaVersion != SnapshotSchemaVersion || manifest.Keys != len(rows) || manifest.Encryption != tt.encryption {
+				t.Fatalf("Unexpected manifest %+v", manifest)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(read, rows) {
+				t.Fatalf("Expected %v, got %v", rows, read)
+			}
+		})
+	}
+}
+
+func TestSnapshotChecksumMismatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := (backup{{Key: []byte("key"), Value: []byte("value")}}).writeTo(filename, nil); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	rewriteSnapshot(t, filename, func(snapshot *snapshotFile) { snapshot.Manifest.Checksum = "0000" })
+	if _, err := (&backup{}).readFrom(filename, nil, false); err == nil {
+		t.Fatalf("Expected a snapshot that doesn't match its checksum to fail")
+	}
+}
+
+// rewriteSnapshot applies change to the snapshot file at filename.
+func rewriteSnapshot(t *testing.T, filename string, change func(*snapshotFile)) {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	snapshot := snapshotFile{}
+	if err := json.Unmarshal(file, &snapshot); err != nil {
+		t.Fatalf("Failed to parse snapshot: %v", err)
+	}
+	change(&snapshot)
+	if file, err = json.Marshal(snapshot); err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	if err := os.WriteFile(filename, file, 0644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+}
+
+func TestSnapshotTampering(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name   string
+		change func(*snapshotFile)
+	}{
+		{"Manifest", func(snapshot *snapshotFile) { snapshot.Manifest.Keys++ }},
+		{"Payload", func(snapshot *snapshotFile) { snapshot.Payload[len(snapshot.Payload)-1] ^= 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "snapshot.db")
+			if err := (backup{{Key: []byte("key"), Value: []byte("value")}}).writeTo(filename, key); err != nil {
+				t.Fatalf("Failed to write snapshot: %v", err)
+			}
+			rewriteSnapshot(t, filename, tt.change)
+			if _, err := (&backup{}).readFrom(filename, key, false); err == nil {
+				t.Fatalf("Expected a snapshot with a changed %s to fail", tt.name)
+			}
+		})
+	}
+}
+
+// writeLegacySnapshot writes writeValues as a snapshot from before manifests were added: a bare JSON list
+// of rows.
+func writeLegacySnapshot(t *testing.T) string {
+	rows := make([]struct{ Key, Value []byte }, len(writeValues))
+	for i, kv := range writeValues {
+		rows[i].Key, rows[i].Value = kv.Key, kv.Value
+	}
+	contents, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatalf("Failed to encode legacy snapshot: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "legacy.json")
+	if err := os.WriteFile(filename, contents, 0644); err != nil {
+		t.Fatalf("Failed to write legacy snapshot: %v", err)
+	}
+	return filename
+}
+
+func TestSnapshotUnencryptedWithKey(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	filename := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := (backup{{Key: []byte("key"), Value: []byte("value")}}).writeTo(filename, nil); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	for _, name := range []string{filename, writeLegacySnapshot(t)} {
+		if _, err := (&backup{}).readFrom(name, key, false); err == nil {
+			t.Fatalf("Expected unencrypted snapshot %s to be rejected when a key is set", name)
+		}
+		if _, err := (&backup{}).readFrom(name, key, true); err != nil {
+			t.Fatalf("Expected unencrypted snapshot %s to be read once allowed: %v", name, err)
+		}
+	}
+}
+
+func TestSnapshotLegacyFormat(t *testing.T) {
+	read := backup{}
+	manifest, err := read.readFrom(writeLegacySnapshot(t), nil, false)
+	if err != nil {
+		t.Fatalf("Failed to read legacy snapshot: %v", err)
+	}
+	if manifest.SchemaVersion != 0 || len(read) != len(writeValues) {
+		t.Fatalf("Expected %d rows of schema version 0, got %d rows of %+v", len(writeValues), len(read), manifest)
+	}
+	for i, row := range read {
+		if string(row.Key) != string(writeValues[i].Key) || string(row.Value) != string(writeValues[i].Value) {
+			t.Fatalf("Expected (%s:%s), got (%s:%s)", writeValues[i].Key, writeValues[i].Value, row.Key, row.Value)
+		}
+	}
+}
@@ -60,3 +60,81 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Environment that services calling the gRPC servers authenticate with when access control is enabled
+*/}}
+{{- define "featureform.authClientEnv" -}}
+{{- if .Values.auth.enabled }}
+- name: FEATUREFORM_AUTH_TOKEN
+  valueFrom:
+    secretKeyRef:
+      name: {{ .Values.auth.secretName }}
+      key: service-token
+{{- if .Values.auth.tlsSecretName }}
+- name: FEATUREFORM_TLS_CA
+  value: /etc/featureform/tls/ca.crt
+{{- else }}
+- name: FEATUREFORM_AUTH_TOKEN_INSECURE
+  value: {{ .Values.auth.allowInsecureTokens | quote }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Environment that the gRPC servers check callers with when access control is enabled
+*/}}
+{{- define "featureform.authServerEnv" -}}
+{{- if .Values.auth.enabled }}
+- name: FEATUREFORM_RBAC_ENABLED
+  value: "true"
+- name: FEATUREFORM_AUTH_TOKENS_FILE
+  value: /etc/featureform/auth/tokens.json
+- name: FEATUREFORM_ADMINS
+  value: {{ prepend .Values.auth.admins .Values.auth.serviceUser | join "," | quote }}
+- name: FEATUREFORM_DEFAULT_ROLE
+  value: {{ .Values.auth.defaultRole | quote }}
+{{- if .Values.auth.tlsSecretName }}
+- name: FEATUREFORM_TLS_CERT
+  value: /etc/featureform/tls/tls.crt
+- name: FEATUREFORM_TLS_KEY
+  value: /etc/featureform/tls/tls.key
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Mounts for the token and TLS files. Only the gRPC servers, passed as "server", get the tokens of every user.
+*/}}
+{{- define "featureform.authVolumeMounts" -}}
+{{- if .Values.auth.enabled }}
+{{- if .server }}
+- name: featureform-auth
+  mountPath: /etc/featureform/auth
+  readOnly: true
+{{- end }}
+{{- if .Values.auth.tlsSecretName }}
+- name: featureform-tls
+  mountPath: /etc/featureform/tls
+  readOnly: true
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{- define "featureform.authVolumes" -}}
+{{- if .Values.auth.enabled }}
+{{- if .server }}
+- name: featureform-auth
+  secret:
+    secretName: {{ .Values.auth.secretName }}
+    items:
+      - key: tokens.json
+        path: tokens.json
+{{- end }}
+{{- if .Values.auth.tlsSecretName }}
+- name: featureform-tls
+  secret:
+    secretName: {{ .Values.auth.tlsSecretName }}
+{{- end }}
+{{- end }}
+{{- end }}
@@ -92,6 +92,7 @@ class ResourceStatus(str, Enum):
         PENDING (str): The state indicating that the resource is in the process of being prepared, but is not yet ready.
         READY (str): The state indicating that the resource has been successfully prepared and is now ready for use.
         FAILED (str): The state indicating that an error occurred during the creation or preparation of the resource.
+        WARNING (str): The state indicating that the resource is ready for use, but monitoring detected that its values have drifted.
     """
 
     NO_STATUS = "NO_STATUS"
@@ -99,6 +100,7 @@ class ResourceStatus(str, Enum):
     PENDING = "PENDING"
     READY = "READY"
     FAILED = "FAILED"
+    WARNING = "WARNING"
 
     @staticmethod
     def from_proto(proto):
@@ -103,7 +103,7 @@ def get_feature_variant_info(stub, name, variant):
                 ("PROVIDER:", x.provider),
                 ("STATUS: ", x.status.Status._enum_type.values[x.status.status].name),
             ]
-            if status == "FAILED":
+            if status in ("FAILED", "WARNING"):
                 rows.append(("ERROR: ", x.status.error_message))
             format_rows(rows)
             format_tags_and_properties(x.tags, x.properties)
@@ -7,7 +7,7 @@ import os
 import warnings
 from abc import ABC
 from collections.abc import Iterable
-from datetime import timedelta
+from datetime import datetime, timedelta
 from typing import Callable, Dict, List, Optional, Tuple, Union
 
 import dill
@@ -4075,6 +4075,9 @@ class Registrar:
         schedule: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        start_time: Optional[datetime] = None,
+        end_time: Optional[datetime] = None,
+        entity_filter: Optional[Tuple[Union[NameVariant, SourceRegistrar], str]] = None,
     ):
         """Register a training set.
 
@@ -4098,6 +4101,9 @@ class Registrar:
             schedule (str): Kubernetes CronJob schedule string ("* * * * *")
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
+            start_time (datetime): Only include label rows at or after this time
+            end_time (datetime): Only include label rows before this time
+            entity_filter (Tuple[Union[NameVariant, SourceRegistrar], str]): A source and one of its columns; only entities found in that column are included
 
         Returns:
             resource (ResourceRegistrar): resource
@@ -4108,6 +4114,15 @@ class Registrar:
             owner = self.must_get_default_owner()
         if variant == "":
             variant = self.__run
+        if start_time is not None and end_time is not None and end_time <= start_time:
+            raise ValueError("Training set end_time must be after start_time")
+        if entity_filter is not None:
+            filter_source, entity_column = entity_filter
+            if hasattr(filter_source, "name_variant"):
+                filter_source = filter_source.name_variant()
+            if filter_source[1] == "":
+                filter_source = (filter_source[0], self.__run)
+            entity_filter = (filter_source, entity_column)
 
         if not isinstance(features, (list, MultiFeatureColumnResource)):
             raise ValueError(
@@ -4157,6 +4172,9 @@ class Registrar:
             feature_lags=feature_lags,
             tags=tags,
             properties=properties,
+            start_time=start_time,
+            end_time=end_time,
+            entity_filter=entity_filter,
         )
         self.__resources.append(resource)
         return resource
@@ -13,7 +13,9 @@ from typing import List, Tuple, Union, Optional
 import dill
 import grpc
 from dataclasses import field
+from datetime import datetime
 from google.protobuf.duration_pb2 import Duration
+from google.protobuf.timestamp_pb2 import Timestamp
 from google.rpc import error_details_pb2
 
 from . import feature_flag
@@ -1278,7 +1280,7 @@ class SourceVariant(ResourceVariant):
         return isinstance(self.definition, Transformation)
 
     def is_ready(self):
-        return self.status == ResourceStatus.READY.value
+        return self.status in (ResourceStatus.READY.value, ResourceStatus.WARNING.value)
 
 
 @typechecked
@@ -1484,7 +1486,7 @@ class FeatureVariant(ResourceVariant):
         return ResourceStatus(self.status)
 
     def is_ready(self):
-        return self.status == ResourceStatus.READY.value
+        return self.status in (ResourceStatus.READY.value, ResourceStatus.WARNING.value)
 
 
 @typechecked
@@ -1574,7 +1576,7 @@ class OnDemandFeatureVariant(ResourceVariant):
         return ResourceStatus(self.status)
 
     def is_ready(self):
-        return self.status == ResourceStatus.READY.value
+        return self.status in (ResourceStatus.READY.value, ResourceStatus.WARNING.value)
 
 
 @typechecked
@@ -1677,7 +1679,7 @@ class LabelVariant(ResourceVariant):
         return ResourceStatus(self.status)
 
     def is_ready(self):
-        return self.status == ResourceStatus.READY.value
+        return self.status in (ResourceStatus.READY.value, ResourceStatus.WARNING.value)
 
 
 @typechecked
@@ -1782,6 +1784,14 @@ class TrainingSet:
         }
 
 
+def _optional_timestamp(dt: Optional[datetime]) -> Optional[Timestamp]:
+    if dt is None:
+        return None
+    ts = Timestamp()
+    ts.FromDatetime(dt)
+    return ts
+
+
 @typechecked
 @dataclass
 class TrainingSetVariant(ResourceVariant):
@@ -1801,6 +1811,10 @@ class TrainingSetVariant(ResourceVariant):
     status: str = "NO_STATUS"
     error: Optional[str] = None
     server_status: Optional[ServerStatus] = None
+    start_time: Optional[datetime] = None
+    end_time: Optional[datetime] = None
+    # A ((source name, source variant), entity column) pair.
+    entity_filter: Optional[tuple] = None
 
     def update_schedule(self, schedule) -> None:
         self.schedule_obj = Schedule(
@@ -1855,6 +1869,18 @@ class TrainingSetVariant(ResourceVariant):
             properties={k: v for k, v in ts.properties.property.items()},
             error=ts.status.error_message,
             server_status=ServerStatus.from_proto(ts.status),
+            start_time=(
+                ts.start_time.ToDatetime() if ts.HasField("start_time") else None
+            ),
+            end_time=ts.end_time.ToDatetime() if ts.HasField("end_time") else None,
+            entity_filter=(
+                (
+                    (ts.entity_filter.source.name, ts.entity_filter.source.variant),
+                    ts.entity_filter.entity_column,
+                )
+                if ts.HasField("entity_filter")
+                else None
+            ),
         )
 
     def _create(self, stub) -> Optional[str]:
@@ -1877,6 +1903,14 @@ class TrainingSetVariant(ResourceVariant):
         if hasattr(self.label, "name_variant"):
             self.label = self.label.name_variant()
 
+        entity_filter = None
+        if self.entity_filter is not None:
+            (source_name, source_variant), entity_column = self.entity_filter
+            entity_filter = pb.TrainingSetEntityFilter(
+                source=pb.NameVariant(name=source_name, variant=source_variant),
+                entity_column=entity_column,
+            )
+
         serialized = pb.TrainingSetVariantRequest(
             training_set_variant=pb.TrainingSetVariant(
                 created=None,
@@ -1893,6 +1927,9 @@ class TrainingSetVariant(ResourceVariant):
                 tags=pb.Tags(tag=self.tags),
                 properties=Properties(self.properties).serialized,
                 status=pb.ResourceStatus(status=pb.ResourceStatus.NO_STATUS),
+                start_time=_optional_timestamp(self.start_time),
+                end_time=_optional_timestamp(self.end_time),
+                entity_filter=entity_filter,
             ),
             request_id="",
         )
@@ -1904,7 +1941,7 @@ class TrainingSetVariant(ResourceVariant):
         return ResourceStatus(self.status)
 
     def is_ready(self):
-        return self.status == ResourceStatus.READY.value
+        return self.status in (ResourceStatus.READY.value, ResourceStatus.WARNING.value)
 
 
 @typechecked
@@ -7,19 +7,22 @@ import random
 import types
 import warnings
 from collections.abc import Iterator
+from datetime import datetime
 from typing import List, Optional, Union
 
 import dill
+import grpc
 import math
 import numpy as np
 import pandas as pd
+import pyarrow as pa
 
 from featureform.proto import serving_pb2, serving_pb2_grpc
 from . import GrpcClient, Model, TrainingSetVariant
 from .enums import FileFormat, ResourceType
 from .register import FeatureColumnResource
 from .tls import insecure_channel, secure_channel
-from .train_test_split import TrainTestSplit
+from .train_test_split import SPLIT_STRATEGIES, TrainTestSplit
 from .version import check_up_to_date
 
 
@@ -298,6 +301,7 @@ class TrainingSetStream(Iterator):
             req.model.name = model if isinstance(model, str) else model.name
         self.name = name
         self.version = version
+        self.model = model
         self._stub = stub
         self._req = req
         self._iter = stub.TrainingData(req)
@@ -312,6 +316,49 @@ class TrainingSetStream(Iterator):
         self._iter = self._stub.TrainingData(self._req)
 
 
+class ArrowTrainingSetStream:
+    """Streams a training set as pyarrow RecordBatches from the TrainingDataArrow endpoint.
+
+    The schema is read when the stream is created. Each column is named like the columns of
+    TrainingDataColumns, with the label last.
+    """
+
+    def __init__(
+        self, stub, name, version, model: Union[str, Model] = None, batch_size=0
+    ):
+        req = serving_pb2.TrainingDataArrowRequest(batch_size=batch_size)
+        req.id.name = name
+        req.id.version = version
+        if model is not None:
+            req.model.name = model if isinstance(model, str) else model.name
+        self._responses = iter(stub.TrainingDataArrow(req))
+        first = next(self._responses)
+        self.schema = pa.ipc.open_stream(first.schema).schema
+
+    def __iter__(self):
+        for resp in self._responses:
+            with pa.ipc.open_stream(resp.record_batch) as reader:
+                yield from reader
+
+    def table(self) -> pa.Table:
+        return pa.Table.from_batches(list(self), schema=self.schema)
+
+
+def _torch_dataset(torch, stub, name, version, model, batch_size):
+    class TrainingSetTorchDataset(torch.utils.data.IterableDataset):
+        def __iter__(self):
+            stream = ArrowTrainingSetStream(stub, name, version, model, batch_size)
+            for batch in stream:
+                columns = [
+                    batch.column(i).to_numpy(zero_copy_only=False)
+                    for i in range(batch.num_columns)
+                ]
+                features = np.column_stack(columns[:-1])
+                yield torch.from_numpy(features), torch.from_numpy(columns[-1])
+
+    return TrainingSetTorchDataset()
+
+
 class LocalStream:
     def __init__(self, datalist, include_label_timestamp):
         self._datalist = datalist
@@ -429,12 +476,17 @@ class Dataset:
         shuffle: bool = True,
         random_state: Optional[int] = None,
         batch_size: int = 1,
+        strategy: str = "random",
+        validation_size: float = 0,
+        test_cutoff: Optional[datetime] = None,
+        validation_cutoff: Optional[datetime] = None,
     ):
         """
-        (This functionality is currently only available for Clickhouse).
+        (This functionality is currently only available for Clickhouse and the local memory store).
 
         Splits an existing training set into training and testing iterators. The split is processed on the underlying
-        provider and calculated at serving time.
+        provider and calculated at serving time. If a validation set is requested, with validation_size or with a
+        validation_cutoff for time splits, a validation iterator is returned between the training and testing ones.
 
         **Examples**:
 
@@ -497,9 +549,17 @@ class Dataset:
                 randomly on every call. If >0, the value will be used a seed to create random shuffle that can be repeated
                 if subsequent calls use the same seed.
             batch_size (int): The size of the batch to return from the iterator. Must be greater than 0.
+            strategy (str): How rows are assigned to splits. One of "random", "time", "stratified" (keeps the label
+                distribution in every split) or "entity_hash" (keeps all of an entity's rows in the same split).
+            validation_size (float): The ratio of the training set to hold out for validation. test_size and
+                validation_size together must not exceed 1. Unused for time splits.
+            test_cutoff (Optional[datetime]): For time splits, rows at or after this time are used for testing.
+            validation_cutoff (Optional[datetime]): For time splits, rows between this time and the test_cutoff are
+                used for validation. Must be before the test_cutoff.
 
         Returns:
             train (Iterator): An iterator for training values.
+            validation (Iterator): An iterator for validation values, only returned if a validation set is requested.
             test (Iterator): An iterator for testing values.
         """
         if batch_size < 1:
@@ -508,14 +568,27 @@ class Dataset:
         if random_state is not None and random_state < 0:
             raise ValueError("random_state must be 0 or greater")
 
+        if strategy not in SPLIT_STRATEGIES:
+            raise ValueError(
+                f"strategy must be one of {', '.join(SPLIT_STRATEGIES)}, got {strategy}"
+            )
+
+        if strategy == "time":
+            if test_cutoff is None:
+                raise ValueError("test_cutoff is required for time splits")
+            if validation_cutoff is not None and validation_cutoff >= test_cutoff:
+                raise ValueError("validation_cutoff must be before test_cutoff")
+
         test_size, train_size = self.validate_test_size(test_size, train_size)
+        if not 0 <= validation_size <= 1 - test_size:
+            raise ValueError("validation_size must be between 0 and 1 - test_size")
 
         name = self._stream.name
         variant = self._stream.version
         stub = self._stream._stub
         model = self._stream.model if hasattr(self._stream, "model") else None
 
-        train, test = TrainTestSplit(
+        return TrainTestSplit(
             stub=stub,
             name=name,
             version=variant,
@@ -525,10 +598,12 @@ class Dataset:
             shuffle=shuffle,
             random_state=(0 if random_state is None else random_state),
             batch_size=batch_size,
+            strategy=strategy,
+            validation_size=validation_size,
+            test_cutoff=test_cutoff,
+            validation_cutoff=validation_cutoff,
         ).split()
 
-        return train, test
-
     @staticmethod
     def validate_test_size(test_size, train_size):
         # Validate ranges
@@ -589,19 +664,77 @@ class Dataset:
             )
             return self._dataframe
         else:
-            name = self._stream.name
-            variant = self._stream.version
-            stub = self._stream._stub
-            id = serving_pb2.TrainingDataID(name=name, version=variant)
-            req = serving_pb2.TrainingDataRequest(id=id)
-            cols = stub.TrainingDataColumns(req)
-            data = [r.to_dict(cols.features, cols.label) for r in self._stream]
-            self._dataframe = pd.DataFrame(
-                data=data, columns=[*cols.features, cols.label]
+            try:
+                table = self._arrow_stream().table()
+            except grpc.RpcError as e:
+                # Servers without the Arrow endpoint stream the training set row by row.
+                if e.code() != grpc.StatusCode.UNIMPLEMENTED:
+                    raise
+                self._dataframe = self._dataframe_from_rows()
+                return self._dataframe
+            self._dataframe = table.to_pandas()
+            self._dataframe.rename(
+                columns={table.column_names[-1]: "label"}, inplace=True
             )
-            self._dataframe.rename(columns={cols.label: "label"}, inplace=True)
             return self._dataframe
 
+    def _arrow_stream(self, batch_size=0):
+        return ArrowTrainingSetStream(
+            self._stream._stub,
+            self._stream.name,
+            self._stream.version,
+            getattr(self._stream, "model", None),
+            batch_size,
+        )
+
+    def _dataframe_from_rows(self):
+        name = self._stream.name
+        variant = self._stream.version
+        stub = self._stream._stub
+        id = serving_pb2.TrainingDataID(name=name, version=variant)
+        req = serving_pb2.TrainingDataRequest(id=id)
+        cols = stub.TrainingDataColumns(req)
+        data = [r.to_dict(cols.features, cols.label) for r in self._stream]
+        df = pd.DataFrame(data=data, columns=[*cols.features, cols.label])
+        df.rename(columns={cols.label: "label"}, inplace=True)
+        return df
+
+    def pytorch(self, batch_size=1024):
+        """Returns the training set as a PyTorch IterableDataset. Each item is a batch of up to batch_size rows,
+        as a tuple of a 2D features tensor and a 1D label tensor. Batches are built from Arrow record batches,
+        so numeric columns are converted without parsing each row. Features must be numeric.
+
+        **Examples**:
+        ``` py
+            client = Client()
+            dataset = client.training_set("fraud_training", "v1").pytorch(batch_size=256)
+            for features, label in torch.utils.data.DataLoader(dataset, batch_size=None):
+                # Train model
+        ```
+
+        Args:
+            batch_size (int): The maximum number of rows in each batch.
+
+        Returns:
+            dataset (torch.utils.data.IterableDataset): The training set as batches of tensors.
+        """
+        if batch_size <= 0:
+            raise ValueError("batch_size must be 1 or greater")
+        try:
+            import torch
+        except ImportError:
+            raise ImportError(
+                "Dataset.pytorch() requires PyTorch; install it with `pip install torch`"
+            )
+        return _torch_dataset(
+            torch,
+            self._stream._stub,
+            self._stream.name,
+            self._stream.version,
+            getattr(self._stream, "model", None),
+            batch_size,
+        )
+
     def _sanitize_location(self, location: str) -> str:
         # Returns the location directory rather than a single file if it is part-file.
         # Also converts s3:// to s3a:// if necessary.
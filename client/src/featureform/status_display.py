@@ -41,6 +41,7 @@ class DisplayStatus:
     def is_finished(self) -> bool:
         return (
             self.status == "READY"
+            or self.status == "WARNING"
             or self.status == "FAILED"
             or (
                 self.resource_type is Provider
@@ -79,6 +80,7 @@ class StatusDisplayer:
         "PENDING": "yellow",
         "NO_STATUS": "white",
         "FAILED": "red",
+        "WARNING": "dark_orange",
     }
 
     def __init__(
@@ -1,5 +1,6 @@
 from collections import deque
-from typing import Any, List, Tuple, Union
+from datetime import datetime
+from typing import Any, List, Optional, Tuple, Union
 
 import grpc
 import numpy as np
@@ -9,6 +10,14 @@ from featureform import Model
 from featureform.proto import serving_pb2
 
 
+SPLIT_STRATEGIES = {
+    "random": serving_pb2.SplitStrategy.SPLIT_RANDOM,
+    "time": serving_pb2.SplitStrategy.SPLIT_TIME,
+    "stratified": serving_pb2.SplitStrategy.SPLIT_STRATIFIED,
+    "entity_hash": serving_pb2.SplitStrategy.SPLIT_ENTITY_HASH,
+}
+
+
 @dataclass
 class TrainTestSplitDetails:
     name: str
@@ -18,6 +27,16 @@ class TrainTestSplitDetails:
     shuffle: bool
     random_state: int
     batch_size: int = 1
+    strategy: str = "random"
+    validation_size: float = 0
+    test_cutoff: Optional[datetime] = None
+    validation_cutoff: Optional[datetime] = None
+
+    @property
+    def has_validation(self) -> bool:
+        if self.strategy == "time":
+            return self.validation_cutoff is not None
+        return self.validation_size > 0
 
     def to_proto(
         self, request_type: serving_pb2.RequestType
@@ -34,6 +53,12 @@ class TrainTestSplitDetails:
         req.random_state = self.random_state
         req.request_type = request_type
         req.batch_size = self.batch_size
+        req.strategy = SPLIT_STRATEGIES[self.strategy]
+        req.validation_size = self.validation_size
+        if self.test_cutoff is not None:
+            req.test_cutoff.FromDatetime(self.test_cutoff)
+        if self.validation_cutoff is not None:
+            req.validation_cutoff.FromDatetime(self.validation_cutoff)
         return req
 
 
@@ -136,6 +161,12 @@ class TrainTestSplitIterator:
             split_stream, batch_size, serving_pb2.RequestType.TEST
         )
 
+    @staticmethod
+    def validation_iter(split_stream, batch_size):
+        return TrainTestSplitIterator(
+            split_stream, batch_size, serving_pb2.RequestType.VALIDATION
+        )
+
     def __iter__(self):
         return self
 
@@ -174,7 +205,8 @@ class TrainTestSplitIterator:
 
 class TrainTestSplit:
     """
-    Returns two iterators, one for the training set and one for the test set, in that order
+    Returns two iterators, one for the training set and one for the test set, in that order.
+    If the split has a validation set, its iterator is returned between them.
     """
 
     def __init__(
@@ -188,6 +220,10 @@ class TrainTestSplit:
         random_state: int,
         batch_size: int,
         model: Union[str, Model] = None,
+        strategy: str = "random",
+        validation_size: float = 0,
+        test_cutoff: Optional[datetime] = None,
+        validation_cutoff: Optional[datetime] = None,
     ):
         self.batch_size = batch_size
         self.train_iter = None
@@ -201,12 +237,20 @@ class TrainTestSplit:
             shuffle=shuffle,
             random_state=random_state,
             batch_size=batch_size,
+            strategy=strategy,
+            validation_size=validation_size,
+            test_cutoff=test_cutoff,
+            validation_cutoff=validation_cutoff,
         )
 
         self._split_stream = _SplitStream(stub, self.train_test_split_details).start()
 
     def split(self):
-        return (
-            TrainTestSplitIterator.train_iter(self._split_stream, self.batch_size),
-            TrainTestSplitIterator.test_iter(self._split_stream, self.batch_size),
-        )
+        train = TrainTestSplitIterator.train_iter(self._split_stream, self.batch_size)
+        test = TrainTestSplitIterator.test_iter(self._split_stream, self.batch_size)
+        if self.train_test_split_details.has_validation:
+            validation = TrainTestSplitIterator.validation_iter(
+                self._split_stream, self.batch_size
+            )
+            return train, validation, test
+        return train, test
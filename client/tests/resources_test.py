@@ -2,6 +2,7 @@
 # License, v. 2.0. If a copy of the MPL was not distributed with this
 # file, You can obtain one at https://mozilla.org/MPL/2.0/.
 import os.path
+from datetime import datetime
 import sys
 
 sys.path.insert(0, "client/src/")
@@ -1008,3 +1009,54 @@ def test_add_all_resources_with_schedule(all_resources_strange_order, redis_conf
             name="primary", variant="abc", resource_type=7, schedule_string="* * * * *"
         ),
     ]
+
+
+class CreateTrainingSetStub:
+    def __init__(self):
+        self.created = None
+
+    def GetEquivalent(self, req):
+        return pb.ResourceVariant()
+
+    def CreateTrainingSetVariant(self, req):
+        self.created = req.training_set_variant
+
+
+def test_training_set_filters_serialized():
+    start = datetime(2024, 1, 1)
+    end = datetime(2024, 2, 1)
+    stub = CreateTrainingSetStub()
+    TrainingSetVariant(
+        created=None,
+        name="training-set",
+        variant="v1",
+        description="desc",
+        owner="featureform",
+        label=("label", "var"),
+        features=[("f1", "var")],
+        start_time=start,
+        end_time=end,
+        entity_filter=(("customers", "v1"), "customer_id"),
+    )._create(stub)
+    assert stub.created.start_time.ToDatetime() == start
+    assert stub.created.end_time.ToDatetime() == end
+    assert stub.created.entity_filter == pb.TrainingSetEntityFilter(
+        source=pb.NameVariant(name="customers", variant="v1"),
+        entity_column="customer_id",
+    )
+
+
+def test_training_set_without_filters_serialized():
+    stub = CreateTrainingSetStub()
+    TrainingSetVariant(
+        created=None,
+        name="training-set",
+        variant="v1",
+        description="desc",
+        owner="featureform",
+        label=("label", "var"),
+        features=[("f1", "var")],
+    )._create(stub)
+    assert not stub.created.HasField("start_time")
+    assert not stub.created.HasField("end_time")
+    assert not stub.created.HasField("entity_filter")
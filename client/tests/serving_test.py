@@ -10,12 +10,14 @@ from unittest import mock
 
 import numpy as np
 import pandas as pd
+import pyarrow as pa
 import pytest
 
 sys.path.insert(0, "client/src/")
 from featureform import ResourceClient, ServingClient
 import serving_cases as cases
 import featureform as ff
+from featureform.proto import serving_pb2
 from featureform.serving import check_feature_type, Row, Dataset
 
 
@@ -123,6 +125,48 @@ def retry_delete():
             time.sleep(1)
 
 
+def _arrow_ipc(schema, *batches):
+    sink = pa.BufferOutputStream()
+    with pa.ipc.new_stream(sink, schema) as writer:
+        for batch in batches:
+            writer.write_batch(batch)
+    return sink.getvalue().to_pybytes()
+
+
+class ArrowStub:
+    def __init__(self):
+        self.requests = []
+        schema = pa.schema(
+            [("feature__avg__v1", pa.float64()), ("label__fraud__v1", pa.bool_())]
+        )
+        batches = [
+            pa.record_batch([pa.array([1.0, 2.0]), pa.array([True, False])], schema),
+            pa.record_batch([pa.array([3.0]), pa.array([True])], schema),
+        ]
+        self.responses = [serving_pb2.ArrowBatch(schema=_arrow_ipc(schema))] + [
+            serving_pb2.ArrowBatch(
+                record_batch=_arrow_ipc(schema, batch), num_rows=batch.num_rows
+            )
+            for batch in batches
+        ]
+
+    def TrainingDataArrow(self, req):
+        self.requests.append(req)
+        return iter(self.responses)
+
+
+def test_dataframe_from_arrow():
+    stub = ArrowStub()
+    stream = mock.Mock(_stub=stub, version="v1", model="fraud_model")
+    stream.name = "fraud_training"
+    df = Dataset(stream).dataframe()
+    assert list(df.columns) == ["feature__avg__v1", "label"]
+    assert df["feature__avg__v1"].tolist() == [1.0, 2.0, 3.0]
+    assert df["label"].tolist() == [True, False, True]
+    assert stub.requests[0].id.name == "fraud_training"
+    assert stub.requests[0].model.name == "fraud_model"
+
+
 @pytest.mark.parametrize(
     "location, expected_location",
     [
@@ -1,10 +1,12 @@
 import os
+from datetime import datetime, timezone
 
 import numpy as np
 import pytest
 
 from featureform.proto import serving_pb2
 from featureform.serving import Dataset
+from featureform.train_test_split import TrainTestSplitDetails
 
 real_path = os.path.realpath(__file__)
 dir_path = os.path.dirname(real_path)
@@ -21,6 +23,8 @@ def response(
         label_value = "train"
     elif req_type == serving_pb2.RequestType.TEST:
         label_value = "test"
+    elif req_type == serving_pb2.RequestType.VALIDATION:
+        label_value = "validation"
 
     row = serving_pb2.TrainingDataRow(
         features=[
@@ -43,11 +47,14 @@ class MockGrpcStub:
         self.num_rows = num_rows
         self.train_rows = 0
         self.test_rows = 0
+        self.validation_rows = 0
         self.batch_size = batch_size
+        self.requests = []
 
     def TrainTestSplit(self, iterator) -> serving_pb2.BatchTrainTestSplitResponse:
         rows_to_return = self.batch_size
         for value in iterator:
+            self.requests.append(value)
             iterator_done = False
             if value.request_type == serving_pb2.RequestType.TRAINING:
                 self.train_rows += self.batch_size
@@ -61,6 +68,14 @@ class MockGrpcStub:
                 if self.test_rows > self.num_rows:
                     rows_to_return = self.num_rows - (self.test_rows - self.batch_size)
                     iterator_done = True
+            elif value.request_type == serving_pb2.RequestType.VALIDATION:
+                self.validation_rows += self.batch_size
+
+                if self.validation_rows > self.num_rows:
+                    rows_to_return = self.num_rows - (
+                        self.validation_rows - self.batch_size
+                    )
+                    iterator_done = True
             yield response(value.request_type, iterator_done, rows_to_return)
 
 
@@ -120,6 +135,19 @@ class MockStream:
             },
             True,
         ),
+        ({"test_size": 0.5, "validation_size": 0.2}, False),
+        ({"test_size": 0.5, "validation_size": 0.6}, True),
+        ({"test_size": 0.5, "strategy": "unknown"}, True),
+        ({"strategy": "time"}, True),
+        (
+            {
+                "strategy": "time",
+                "test_cutoff": datetime(2024, 1, 1),
+                "validation_cutoff": datetime(2024, 2, 1),
+            },
+            True,
+        ),
+        ({"strategy": "time", "test_cutoff": datetime(2024, 1, 1)}, False),
     ],
 )
 def test_initialization(kwargs, should_fail):
@@ -240,3 +268,45 @@ def test_train_test_partial_batch():
         )
         assert np.array_equal(label, np.array(["test", "test", "test"]))
         i += 1
+
+
+def test_train_validation_test_split():
+    train_test_stream = MockStream("name", "variant", 1)
+    dataset = Dataset(train_test_stream)
+    train, validation, test = dataset.train_test_split(
+        test_size=0.25, validation_size=0.25, strategy="stratified"
+    )
+
+    for name, iterator in [
+        ("train", train),
+        ("validation", validation),
+        ("test", test),
+    ]:
+        for features, label in iterator:
+            assert np.array_equal(features, np.array([["f1", 1, False]], dtype="O"))
+            assert np.array_equal(label, np.array([name]))
+
+    request = train_test_stream._stub.requests[0]
+    assert request.strategy == serving_pb2.SplitStrategy.SPLIT_STRATIFIED
+    assert request.validation_size == 0.25
+
+
+def test_time_split_details_to_proto():
+    test_cutoff = datetime(2024, 2, 1, tzinfo=timezone.utc)
+    validation_cutoff = datetime(2024, 1, 1, tzinfo=timezone.utc)
+    details = TrainTestSplitDetails(
+        name="name",
+        version="variant",
+        model=None,
+        test_size=0,
+        shuffle=False,
+        random_state=0,
+        strategy="time",
+        test_cutoff=test_cutoff,
+        validation_cutoff=validation_cutoff,
+    )
+    assert details.has_validation
+    req = details.to_proto(serving_pb2.RequestType.INITIALIZE)
+    assert req.strategy == serving_pb2.SplitStrategy.SPLIT_TIME
+    assert req.test_cutoff.ToDatetime(tzinfo=timezone.utc) == test_cutoff
+    assert req.validation_cutoff.ToDatetime(tzinfo=timezone.utc) == validation_cutoff
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	db "github.com/jackc/pgx/v4"
@@ -18,12 +19,15 @@ import (
 	"github.com/featureform/fferr"
 	"github.com/featureform/filestore"
 	"github.com/featureform/kubernetes"
+	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	vt "github.com/featureform/provider/types"
 	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
 	"github.com/featureform/types"
 )
 
@@ -90,6 +94,43 @@ type Coordinator struct {
 	KVClient   *clientv3.KV
 	Spawner    JobSpawner
 	Timeout    int
+	Monitoring FeatureMonitoring
+	// MetadataAddress is where source and transformation jobs check and record their schemas. They're
+	// skipped if it isn't set.
+	MetadataAddress string
+	// Scheduler runs scheduled jobs that the spawner's runners can't schedule themselves, and jobs that
+	// depend on other scheduled jobs. The coordinator should be its Executor. Each coordinator job is run
+	// as a task run on it too.
+	Scheduler *scheduling.Scheduler
+	// JobRetries is how often, and how far apart, a failed coordinator job is attempted.
+	JobRetries scheduling.RetryPolicy
+	// JobTimeout is how long each attempt at a coordinator job can take. Zero means there's no limit.
+	JobTimeout time.Duration
+	// jobFunctions replaces the functions that run coordinator jobs for each resource type, for tests.
+	jobFunctions map[metadata.ResourceType]jobFunction
+	// attemptErrors holds the error of the last attempt at each coordinator job's run.
+	attemptErrors sync.Map
+}
+
+// jobFunction runs the coordinator job that creates a resource.
+type jobFunction func(metadata.ResourceID, string) error
+
+// runKey identifies a task run.
+type runKey struct {
+	taskID scheduling.TaskID
+	runID  scheduling.TaskRunID
+}
+
+// FeatureMonitoring configures the monitoring job that computes value statistics for each feature after
+// it's materialized, and flags the feature with a WARNING status when its values drift.
+type FeatureMonitoring struct {
+	Enabled bool
+	// DriftMetric is PSI by default.
+	DriftMetric runner.DriftMetric
+	// DriftThreshold is the drift score above which a feature is flagged. Zero uses the metric's default.
+	DriftThreshold float64
+	// MetadataAddress is where the monitoring job sets the feature's status.
+	MetadataAddress string
 }
 
 type ETCDConfig struct {
@@ -142,7 +183,7 @@ func (c *Coordinator) AwaitPendingSource(sourceNameVariant metadata.NameVariant)
 
 func (c *Coordinator) AwaitPendingFeature(featureNameVariant metadata.NameVariant) (*metadata.FeatureVariant, error) {
 	featureStatus := metadata.PENDING
-	for featureStatus != metadata.READY {
+	for !featureStatus.IsReady() {
 		feature, err := c.Metadata.GetFeatureVariant(context.Background(), featureNameVariant)
 		if err != nil {
 			return nil, err
@@ -152,7 +193,7 @@ func (c *Coordinator) AwaitPendingFeature(featureNameVariant metadata.NameVarian
 			err := fferr.NewResourceFailedError(featureNameVariant.Name, featureNameVariant.Variant, fferr.FEATURE_VARIANT, fmt.Errorf("required feature is in a failed state"))
 			return nil, err
 		}
-		if featureStatus == metadata.READY {
+		if featureStatus.IsReady() {
 			return feature, nil
 		}
 		time.Sleep(1 * time.Second)
@@ -181,7 +222,7 @@ func (c *Coordinator) AwaitPendingLabel(labelNameVariant metadata.NameVariant) (
 }
 
 func (c *Coordinator) setPending(resID metadata.ResourceID, currentStatus metadata.ResourceStatus) error {
-	if currentStatus == metadata.READY {
+	if currentStatus.IsReady() {
 		return fferr.NewResourceAlreadyCompleteError(resID.Name, resID.Variant, fferr.ResourceType(resID.Type.String()), nil)
 	}
 	if currentStatus == metadata.FAILED {
@@ -243,18 +284,40 @@ func (k *MemoryJobSpawner) GetJobRunner(jobName runner.RunnerName, config runner
 func NewCoordinator(meta *metadata.Client, logger *zap.SugaredLogger, cli *clientv3.Client, spawner JobSpawner) (*Coordinator, error) {
 	logger.Info("Creating new coordinator")
 	kvc := clientv3.NewKV(cli)
-	return &Coordinator{
+	coord := &Coordinator{
 		Metadata:   meta,
 		Logger:     logger,
 		EtcdClient: cli,
 		KVClient:   &kvc,
 		Spawner:    spawner,
 		Timeout:    600,
-	}, nil
+		JobRetries: scheduling.RetryPolicy{MaxAttempts: MAX_ATTEMPTS, InitialBackoff: JOB_RETRY_BACKOFF},
+	}
+	tasks := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	coord.Scheduler = scheduling.NewScheduler(&tasks, coord, time.Minute, logging.WrapZapLogger(logger))
+	return coord, nil
 }
 
 const MAX_ATTEMPTS = 3
 
+// JOB_RETRY_BACKOFF is how long the coordinator waits to attempt a failed job again by default.
+const JOB_RETRY_BACKOFF = 10 * time.Second
+
+// jobFunction returns the function that runs the coordinator job for resources of the type.
+func (c *Coordinator) jobFunction(resourceType metadata.ResourceType) (jobFunction, bool) {
+	fns := c.jobFunctions
+	if fns == nil {
+		fns = map[metadata.ResourceType]jobFunction{
+			metadata.TRAINING_SET_VARIANT: c.runTrainingSetJob,
+			metadata.FEATURE_VARIANT:      c.runFeatureMaterializeJob,
+			metadata.LABEL_VARIANT:        c.runLabelRegisterJob,
+			metadata.SOURCE_VARIANT:       c.runRegisterSourceJob,
+		}
+	}
+	fn, has := fns[resourceType]
+	return fn, has
+}
+
 func (c *Coordinator) checkError(err error, jobName string) {
 	switch err.(type) {
 	case *fferr.JobDoesNotExistError:
@@ -354,6 +417,37 @@ func (c *Coordinator) WatchForScheduleChanges() error {
 	return nil
 }
 
+func (c *Coordinator) WatchForDeleteJobs() error {
+	c.Logger.Info("Watching for new delete jobs")
+	getResp, err := (*c.KVClient).Get(context.Background(), "DELETEJOB_", clientv3.WithPrefix())
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	for _, kv := range getResp.Kvs {
+		go func(kv *mvccpb.KeyValue) {
+			err := c.runDeleteJob(string(kv.Key), string(kv.Value))
+			if err != nil {
+				c.Logger.Errorw("Error executing delete job: Initial search", "error", err)
+			}
+		}(kv)
+	}
+	for {
+		rch := c.EtcdClient.Watch(context.Background(), "DELETEJOB_", clientv3.WithPrefix())
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == 0 {
+					go func(ev *clientv3.Event) {
+						err := c.runDeleteJob(string(ev.Kv.Key), string(ev.Kv.Value))
+						if err != nil {
+							c.Logger.Errorw("Error executing delete job: Polling search", "error", err)
+						}
+					}(ev)
+				}
+			}
+		}
+	}
+}
+
 func (c *Coordinator) mapNameVariantsToTables(sources []metadata.NameVariant) (map[string]string, error) {
 	sourceMap := make(map[string]string)
 	for _, nameVariant := range sources {
@@ -438,6 +532,7 @@ func (c *Coordinator) runTransformationJob(transformationConfig provider.Transfo
 		OfflineConfig:        sourceProvider.SerializedConfig(),
 		TransformationConfig: transformationConfig,
 		IsUpdate:             false,
+		MetadataAddress:      c.MetadataAddress,
 	}
 	c.Logger.Debugw("Transformation Serialize Config")
 	serialized, err := createTransformationConfig.Serialize()
@@ -463,28 +558,22 @@ func (c *Coordinator) runTransformationJob(transformationConfig provider.Transfo
 		return err
 	}
 	c.Logger.Debugw("Transformation Complete")
+	scheduleCreateTransformationConfig := runner.CreateTransformationConfig{
+		OfflineType:          pt.Type(sourceProvider.Type()),
+		OfflineConfig:        sourceProvider.SerializedConfig(),
+		TransformationConfig: transformationConfig,
+		IsUpdate:             true,
+		MetadataAddress:      c.MetadataAddress,
+	}
+	serializedUpdate, err := scheduleCreateTransformationConfig.Serialize()
+	if err != nil {
+		return err
+	}
+	upstream := transformationSources(transformation)
+	if err := c.ScheduleJob(resID, runner.CREATE_TRANSFORMATION, serializedUpdate, schedule, upstream); err != nil {
+		return err
+	}
 	if schedule != "" {
-		scheduleCreateTransformationConfig := runner.CreateTransformationConfig{
-			OfflineType:          pt.Type(sourceProvider.Type()),
-			OfflineConfig:        sourceProvider.SerializedConfig(),
-			TransformationConfig: transformationConfig,
-			IsUpdate:             true,
-		}
-		serializedUpdate, err := scheduleCreateTransformationConfig.Serialize()
-		if err != nil {
-			return err
-		}
-		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, serializedUpdate, resID)
-		if err != nil {
-			return err
-		}
-		cronRunner, isCronRunner := jobRunnerUpdate.(kubernetes.CronRunner)
-		if !isCronRunner {
-			return fferr.NewInternalError(fmt.Errorf("kubernetes runner does not implement schedule"))
-		}
-		if err := cronRunner.ScheduleJob(kubernetes.CronSchedule(schedule)); err != nil {
-			return err
-		}
 		if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 			return err
 		}
@@ -492,6 +581,21 @@ func (c *Coordinator) runTransformationJob(transformationConfig provider.Transfo
 	return nil
 }
 
+// transformationSources returns the sources a transformation reads from.
+func transformationSources(transformation *metadata.SourceVariant) []metadata.ResourceID {
+	var sources []metadata.NameVariant
+	if transformation.IsSQLTransformation() {
+		sources = transformation.SQLTransformationSources()
+	} else if transformation.IsDFTransformation() {
+		sources = transformation.DFTransformationSources()
+	}
+	ids := make([]metadata.ResourceID, len(sources))
+	for i, source := range sources {
+		ids[i] = metadata.ResourceID{Name: source.Name, Variant: source.Variant, Type: metadata.SOURCE_VARIANT}
+	}
+	return ids
+}
+
 func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVariant, resID metadata.ResourceID, offlineStore provider.OfflineStore, schedule string, sourceProvider *metadata.Provider) error {
 	c.Logger.Info("Running SQL transformation job on resource: ", resID)
 	templateString := transformSource.SQLTransformationQuery()
@@ -598,7 +702,30 @@ func (c *Coordinator) runPrimaryTableJob(source *metadata.SourceVariant, resID m
 	if sourceName == "" {
 		return fferr.NewInvalidArgumentError(fmt.Errorf("source name is not set"))
 	}
-	if _, err := offlineStore.RegisterPrimaryFromSourceTable(providerResourceID, sourceName); err != nil {
+	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+	if err != nil {
+		return err
+	}
+	registerSourceConfig := runner.RegisterSourceConfig{
+		OfflineType:     pt.Type(sourceProvider.Type()),
+		OfflineConfig:   sourceProvider.SerializedConfig(),
+		ResourceID:      providerResourceID,
+		SourceTableName: sourceName,
+		MetadataAddress: c.MetadataAddress,
+	}
+	serialized, err := registerSourceConfig.Serialize()
+	if err != nil {
+		return err
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.REGISTER_SOURCE, serialized, resID)
+	if err != nil {
+		return err
+	}
+	completionWatcher, err := jobRunner.Run()
+	if err != nil {
+		return err
+	}
+	if err := completionWatcher.Wait(); err != nil {
 		return err
 	}
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
@@ -695,6 +822,9 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 		}
 		sourceTableName = sourceTable.GetName()
 	}
+	if _, err := runner.CheckSourceSchema(c.Metadata, source, sourceStore); err != nil {
+		return err
+	}
 
 	labelID := provider.ResourceID{
 		Name:    resID.Name,
@@ -786,6 +916,9 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		}
 		sourceTableName = sourceTable.GetName()
 	}
+	if _, err := runner.CheckSourceSchema(c.Metadata, source, sourceStore); err != nil {
+		return err
+	}
 
 	featID := provider.ResourceID{
 		Name:    resID.Name,
@@ -827,25 +960,69 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		return err
 	}
 
-	var materializationErr error
-	if schedule != "" {
-		materializationErr = c.materializeFeatureOnSchedule(resID, materializedRunnerConfig, schedule)
-	} else if isImportToS3Enabled {
-		materializationErr = c.materializeFeatureViaS3Import(resID, materializedRunnerConfig, sourceStore)
-	} else {
-		materializationErr = c.materializeFeature(resID, materializedRunnerConfig)
+	// Scheduled features are materialized by their first scheduled run.
+	if schedule == "" {
+		var materializationErr error
+		if isImportToS3Enabled {
+			materializationErr = c.materializeFeatureViaS3Import(resID, materializedRunnerConfig, sourceStore)
+		} else {
+			materializationErr = c.materializeFeature(resID, materializedRunnerConfig)
+		}
+		if materializationErr != nil {
+			return materializationErr
+		}
 	}
-	if materializationErr != nil {
-		return materializationErr
+	sourceID := metadata.ResourceID{Name: sourceNameVariant.Name, Variant: sourceNameVariant.Variant, Type: metadata.SOURCE_VARIANT}
+	if err := c.materializeFeatureOnSchedule(resID, materializedRunnerConfig, schedule, sourceID); err != nil {
+		return err
 	}
 
 	c.Logger.Debugw("Setting status to ready", "id", featID)
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return clientv3.ErrNoAvailableEndpoints
 	}
+	if c.Monitoring.Enabled {
+		// Monitoring only flags the feature, so a failed monitoring job doesn't fail the materialization.
+		if err := c.monitorFeature(resID, sourceProvider, schedule); err != nil {
+			c.Logger.Errorw("Feature monitoring failed", "id", resID, "error", err)
+		}
+	}
 	return nil
 }
 
+// monitorFeature runs the monitoring job over a feature's materialization. Features materialized on a
+// schedule are monitored on the same schedule.
+func (c *Coordinator) monitorFeature(resID metadata.ResourceID, sourceProvider *metadata.Provider, schedule string) error {
+	c.Logger.Infow("Monitoring feature", "id", resID)
+	config := runner.MonitorRunnerConfig{
+		OfflineType:     pt.Type(sourceProvider.Type()),
+		OfflineConfig:   sourceProvider.SerializedConfig(),
+		ResourceID:      provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+		DriftMetric:     c.Monitoring.DriftMetric,
+		DriftThreshold:  c.Monitoring.DriftThreshold,
+		MetadataAddress: c.Monitoring.MetadataAddress,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		return err
+	}
+	if schedule != "" {
+		return c.ScheduleJob(resID, runner.MONITOR_FEATURE, serialized, schedule, nil)
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MONITOR_FEATURE, serialized, resID)
+	if err != nil {
+		return err
+	}
+	if err := c.prepareRunner(jobRunner); err != nil {
+		return err
+	}
+	completionWatcher, err := jobRunner.Run()
+	if err != nil {
+		return err
+	}
+	return completionWatcher.Wait()
+}
+
 func (c *Coordinator) materializeFeature(id metadata.ResourceID, config runner.MaterializedRunnerConfig) error {
 	c.Logger.Infow("Starting Feature Materialization", "id", id)
 	serialized, err := config.Serialize()
@@ -866,25 +1043,18 @@ func (c *Coordinator) materializeFeature(id metadata.ResourceID, config runner.M
 	return nil
 }
 
-func (c *Coordinator) materializeFeatureOnSchedule(id metadata.ResourceID, config runner.MaterializedRunnerConfig, schedule string) error {
+// materializeFeatureOnSchedule updates the feature's materialization on its schedule, if it has one, and
+// after each scheduled run of its source's job.
+func (c *Coordinator) materializeFeatureOnSchedule(id metadata.ResourceID, config runner.MaterializedRunnerConfig, schedule string, source metadata.ResourceID) error {
 	c.Logger.Infow("Scheduling Feature Materialization", "id", id)
 	config.IsUpdate = true
+	// Scheduled runs only copy rows since the last run's watermark.
+	config.Incremental = true
 	serialized, err := config.Serialize()
 	if err != nil {
 		return err
 	}
-	jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serialized, id)
-	if err != nil {
-		return err
-	}
-	cronRunner, isCronRunner := jobRunnerUpdate.(kubernetes.CronRunner)
-	if !isCronRunner {
-		return err
-	}
-	if err := cronRunner.ScheduleJob(kubernetes.CronSchedule(schedule)); err != nil {
-		return err
-	}
-	return nil
+	return c.ScheduleJob(id, runner.MATERIALIZE, serialized, schedule, []metadata.ResourceID{source})
 }
 
 func (c *Coordinator) materializeFeatureViaS3Import(id metadata.ResourceID, config runner.MaterializedRunnerConfig, sourceStore provider.OfflineStore) error {
@@ -1010,6 +1180,18 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 		Label:       provider.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: provider.Label},
 		Features:    featureList,
 		LagFeatures: lagFeaturesList,
+		StartTime:   ts.StartTime(),
+		EndTime:     ts.EndTime(),
+	}
+	if filter := ts.EntityFilter(); filter != nil {
+		filterSource, err := c.AwaitPendingSource(filter.Source)
+		if err != nil {
+			return err
+		}
+		trainingSetDef.EntityFilter = &provider.EntityFilterDef{
+			Source:       runner.SourceTableID(filterSource),
+			EntityColumn: filter.EntityColumn,
+		}
 	}
 	tsRunnerConfig := runner.TrainingSetRunnerConfig{
 		OfflineType:   pt.Type(providerEntry.Type()),
@@ -1032,28 +1214,25 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return err
 	}
+	scheduleTrainingSetRunnerConfig := runner.TrainingSetRunnerConfig{
+		OfflineType:   pt.Type(providerEntry.Type()),
+		OfflineConfig: providerEntry.SerializedConfig(),
+		Def:           trainingSetDef,
+		IsUpdate:      true,
+	}
+	serializedUpdate, err := scheduleTrainingSetRunnerConfig.Serialize()
+	if err != nil {
+		return err
+	}
+	upstream := make([]metadata.ResourceID, 0, len(features)+1)
+	for _, feature := range features {
+		upstream = append(upstream, metadata.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: metadata.FEATURE_VARIANT})
+	}
+	upstream = append(upstream, metadata.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: metadata.LABEL_VARIANT})
+	if err := c.ScheduleJob(resID, runner.CREATE_TRAINING_SET, serializedUpdate, schedule, upstream); err != nil {
+		return err
+	}
 	if schedule != "" {
-		scheduleTrainingSetRunnerConfig := runner.TrainingSetRunnerConfig{
-			OfflineType:   pt.Type(providerEntry.Type()),
-			OfflineConfig: providerEntry.SerializedConfig(),
-			Def:           trainingSetDef,
-			IsUpdate:      true,
-		}
-		serializedUpdate, err := scheduleTrainingSetRunnerConfig.Serialize()
-		if err != nil {
-			return err
-		}
-		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRAINING_SET, serializedUpdate, resID)
-		if err != nil {
-			return err
-		}
-		cronRunner, isCronRunner := jobRunnerUpdate.(kubernetes.CronRunner)
-		if !isCronRunner {
-			return fferr.NewInternalError(fmt.Errorf("kubernetes runner does not implement schedule"))
-		}
-		if err := cronRunner.ScheduleJob(kubernetes.CronSchedule(schedule)); err != nil {
-			return err
-		}
 		if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 			return err
 		}
@@ -1085,24 +1264,6 @@ func (c *Coordinator) getJob(mtx *concurrency.Mutex, key string) (*metadata.Coor
 	return job, nil
 }
 
-func (c *Coordinator) incrementJobAttempts(mtx *concurrency.Mutex, job *metadata.CoordinatorJob, jobKey string) error {
-	job.Attempts += 1
-	serializedJob, err := job.Serialize()
-	if err != nil {
-		return err
-	}
-	txn := (*c.KVClient).Txn(context.Background())
-	response, err := txn.If(mtx.IsOwner()).Then(clientv3.OpPut(jobKey, string(serializedJob))).Commit()
-	if err != nil {
-		return fferr.NewInternalError(err)
-	}
-	isOwner := response.Succeeded //response.Succeeded sets true if transaction "if" statement true
-	if !isOwner {
-		return fferr.NewInternalError(fmt.Errorf("was not owner of lock"))
-	}
-	return nil
-}
-
 func (c *Coordinator) deleteJob(mtx *concurrency.Mutex, key string) error {
 	c.Logger.Info("Deleting job with key: ", key)
 	txn := (*c.KVClient).Txn(context.Background())
@@ -1164,30 +1325,8 @@ func (c *Coordinator) ExecuteJob(jobKey string) error {
 	if err != nil {
 		return err
 	}
-	c.Logger.Debugf("Job %s is on attempt %d", jobKey, job.Attempts)
-	if job.Attempts > MAX_ATTEMPTS {
-		if err := c.deleteJob(mtx, jobKey); err != nil {
-			c.Logger.Debugw("Error deleting job", "error", err)
-			return err
-		}
-		return fferr.NewInternalError(fmt.Errorf("job failed after %d attempts. Cancelling coordinator flow", MAX_ATTEMPTS))
-	}
-	if err := c.incrementJobAttempts(mtx, job, jobKey); err != nil {
-		return err
-	}
-	type jobFunction func(metadata.ResourceID, string) error
-	fns := map[metadata.ResourceType]jobFunction{
-		metadata.TRAINING_SET_VARIANT: c.runTrainingSetJob,
-		metadata.FEATURE_VARIANT:      c.runFeatureMaterializeJob,
-		metadata.LABEL_VARIANT:        c.runLabelRegisterJob,
-		metadata.SOURCE_VARIANT:       c.runRegisterSourceJob,
-	}
-	jobFunc, has := fns[job.Resource.Type]
-	if !has {
-		return fferr.NewInvalidResourceTypeError(job.Resource.Name, job.Resource.Variant, fferr.ResourceType(job.Resource.Type.String()), nil)
-	}
-
-	if err := jobFunc(job.Resource, job.Schedule); err != nil {
+	// The job is attempted, retried and timed out as a task run, following JobRetries and JobTimeout.
+	if err := c.runJob(job.Resource, job.Schedule); err != nil {
 		switch err.(type) {
 		case *fferr.ResourceAlreadyFailedError:
 			return err
@@ -1255,7 +1394,7 @@ func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 }
 
 func (c *Coordinator) changeJobSchedule(key string, value string) error {
-	c.Logger.Info("Updating schedule of currently made cronjob in kubernetes: ", key)
+	c.Logger.Info("Updating schedule of scheduled job: ", key)
 	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
 	if err != nil {
 		return err
@@ -1279,11 +1418,32 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	if err := coordinatorScheduleJob.Deserialize(Config(value)); err != nil {
 		return err
 	}
+	// Jobs on the scheduler are rescheduled there, and the rest are Kubernetes cron jobs.
+	rescheduled, err := c.rescheduleJobs(coordinatorScheduleJob.Resource, coordinatorScheduleJob.Schedule)
+	if err != nil {
+		return err
+	}
+	if !rescheduled {
+		if err := c.updateCronJobSchedule(coordinatorScheduleJob.Resource, coordinatorScheduleJob.Schedule); err != nil {
+			return err
+		}
+	}
+	if err := c.Metadata.SetStatus(context.Background(), coordinatorScheduleJob.Resource, metadata.READY, ""); err != nil {
+		return err
+	}
+	c.Logger.Info("Successfully updated schedule for job with key: ", key)
+	if err := c.deleteJob(mtx, key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Coordinator) updateCronJobSchedule(resID metadata.ResourceID, schedule string) error {
 	namespace, err := kubernetes.GetCurrentNamespace()
 	if err != nil {
 		return err
 	}
-	jobName := kubernetes.CreateJobName(coordinatorScheduleJob.Resource)
+	jobName := kubernetes.CreateJobName(resID)
 	jobClient, err := kubernetes.NewKubernetesJobClient(jobName, namespace)
 	if err != nil {
 		return err
@@ -1292,15 +1452,133 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	if err != nil {
 		return err
 	}
-	cronJob.Spec.Schedule = coordinatorScheduleJob.Schedule
-	if _, err := jobClient.UpdateCronJob(cronJob); err != nil {
+	cronJob.Spec.Schedule = schedule
+	_, err = jobClient.UpdateCronJob(cronJob)
+	return err
+}
+
+func (c *Coordinator) runDeleteJob(key string, value string) error {
+	c.Logger.Info("Deleting materialized data for job with key: ", key)
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	defer func(s *concurrency.Session) {
+		err := s.Close()
+		if err != nil {
+			c.Logger.Debugw("Error closing delete session", "error", err)
+		}
+	}(s)
+	mtx, err := c.createJobLock(key, s)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := mtx.Unlock(context.Background()); err != nil {
+			c.Logger.Debugw("Error unlocking mutex:", "error", err)
+		}
+	}()
+	deleteJob := &metadata.CoordinatorDeleteJob{}
+	if err := deleteJob.Deserialize(Config(value)); err != nil {
 		return err
 	}
-	if err := c.Metadata.SetStatus(context.Background(), coordinatorScheduleJob.Resource, metadata.READY, ""); err != nil {
+	resID, err := deleteResourceID(deleteJob)
+	if err != nil {
 		return err
 	}
-	c.Logger.Info("Successfully updated schedule for job in kubernetes with key: ", key)
-	if err := c.deleteJob(mtx, key); err != nil {
+	if deleteJob.OfflineProvider != "" {
+		if err := c.deleteOfflineTables(deleteJob.OfflineProvider, resID); err != nil {
+			return err
+		}
+	}
+	if deleteJob.OnlineProvider != "" && resID.Type == provider.Feature {
+		if err := c.deleteOnlineTable(deleteJob.OnlineProvider, resID); err != nil {
+			return err
+		}
+	}
+	c.Logger.Info("Successfully deleted materialized data for job with key: ", key)
+	return c.deleteJob(mtx, key)
+}
+
+// deleteResourceID returns the ID the offline store holds the deleted variant's tables under.
+func deleteResourceID(job *metadata.CoordinatorDeleteJob) (provider.ResourceID, error) {
+	resID := provider.ResourceID{Name: job.Resource.Name, Variant: job.Resource.Variant}
+	switch job.Resource.Type {
+	case metadata.FEATURE_VARIANT:
+		resID.Type = provider.Feature
+	case metadata.LABEL_VARIANT:
+		resID.Type = provider.Label
+	case metadata.TRAINING_SET_VARIANT:
+		resID.Type = provider.TrainingSet
+	case metadata.SOURCE_VARIANT:
+		resID.Type = provider.Primary
+		if job.Transformation {
+			resID.Type = provider.Transformation
+		}
+	default:
+		return provider.ResourceID{}, fferr.NewInvalidResourceTypeError(job.Resource.Name, job.Resource.Variant, fferr.ResourceType(job.Resource.Type.String()), nil)
+	}
+	return resID, nil
+}
+
+// deleteOfflineTables drops a feature's materialization and the table backing the deleted variant. Stores
+// that can't drop resource tables keep them, and tables that are already gone are skipped.
+func (c *Coordinator) deleteOfflineTables(providerName string, resID provider.ResourceID) error {
+	providerEntry, err := c.Metadata.GetProvider(context.Background(), providerName)
+	if err != nil {
+		return err
+	}
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return err
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return err
+	}
+	defer func(store provider.OfflineStore) {
+		if err := store.Close(); err != nil {
+			c.Logger.Errorf("could not close offline store: %v", err)
+		}
+	}(store)
+	if resID.Type == provider.Feature {
+		err = store.DeleteMaterialization(provider.MaterializationIDForResource(store, resID))
+		if _, isNotFound := err.(*fferr.DatasetNotFoundError); err != nil && !isNotFound {
+			return err
+		}
+	}
+	tableStore, ok := store.(provider.ResourceTableOfflineStore)
+	if !ok {
+		c.Logger.Warnw("Offline store can't delete resource tables, keeping them", "provider", providerName, "resource", resID)
+		return nil
+	}
+	err = tableStore.DeleteResourceTable(resID)
+	if _, isNotFound := err.(*fferr.DatasetNotFoundError); err != nil && !isNotFound {
+		return err
+	}
+	return nil
+}
+
+func (c *Coordinator) deleteOnlineTable(providerName string, resID provider.ResourceID) error {
+	providerEntry, err := c.Metadata.GetProvider(context.Background(), providerName)
+	if err != nil {
+		return err
+	}
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return err
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return err
+	}
+	defer func(store provider.OnlineStore) {
+		if err := store.Close(); err != nil {
+			c.Logger.Errorf("could not close online store: %v", err)
+		}
+	}(store)
+	err = store.DeleteTable(resID.Name, resID.Variant)
+	if _, isNotFound := err.(*fferr.DatasetNotFoundError); err != nil && !isNotFound {
 		return err
 	}
 	return nil
@@ -2027,3 +2027,32 @@ func TestCoordinator_checkError(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteResourceID(t *testing.T) {
+	tests := []struct {
+		name string
+		job  metadata.CoordinatorDeleteJob
+		want provider.OfflineResourceType
+	}{
+		{"Feature", metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "f", Variant: "v", Type: metadata.FEATURE_VARIANT}}, provider.Feature},
+		{"Label", metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "l", Variant: "v", Type: metadata.LABEL_VARIANT}}, provider.Label},
+		{"Training Set", metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "ts", Variant: "v", Type: metadata.TRAINING_SET_VARIANT}}, provider.TrainingSet},
+		{"Primary", metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "s", Variant: "v", Type: metadata.SOURCE_VARIANT}}, provider.Primary},
+		{"Transformation", metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "s", Variant: "v", Type: metadata.SOURCE_VARIANT}, Transformation: true}, provider.Transformation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resID, err := deleteResourceID(&tt.job)
+			if err != nil {
+				t.Fatalf("Failed to get resource ID: %s", err)
+			}
+			want := provider.ResourceID{Name: tt.job.Resource.Name, Variant: tt.job.Resource.Variant, Type: tt.want}
+			if resID != want {
+				t.Fatalf("Expected %v, got %v", want, resID)
+			}
+		})
+	}
+	if _, err := deleteResourceID(&metadata.CoordinatorDeleteJob{Resource: metadata.ResourceID{Name: "m", Type: metadata.MODEL}}); err == nil {
+		t.Fatalf("Expected a model delete job to fail")
+	}
+}
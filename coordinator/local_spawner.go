@@ -0,0 +1,543 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
+	"github.com/featureform/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type LocalJobMode string
+
+const (
+	// LocalProcessMode runs each job as a worker subprocess, the same way a Kubernetes job runs it.
+	LocalProcessMode LocalJobMode = "process"
+	// LocalGoroutineMode runs each job in the coordinator's process, like the MemoryJobSpawner.
+	LocalGoroutineMode LocalJobMode = "goroutine"
+)
+
+// logFlushInterval is how often a job's output is appended to its task run while it runs.
+const logFlushInterval = time.Second
+
+// workerWaitDelay is how long to wait for a killed worker's output to close. Processes the worker started
+// can hold it open after the worker is gone.
+const workerWaitDelay = 5 * time.Second
+
+// LocalJobLimits caps the resources of each job. Zero values are unlimited. Only Timeout applies to jobs
+// run as goroutines. A goroutine can't be killed, so one that times out, or whose task run ends, is failed
+// but left running, holding its slot until it returns. Hung goroutines can take up every slot, so
+// deployments whose jobs can hang should use process mode.
+type LocalJobLimits struct {
+	Timeout time.Duration
+	// CPUTime is the CPU time a worker process can use before it's killed.
+	CPUTime time.Duration
+	// MemoryBytes is the virtual memory a worker process can allocate.
+	MemoryBytes int64
+}
+
+// command returns the command that runs the worker at path, through a shell that sets the limits first
+// if there are any.
+func (limits LocalJobLimits) command(ctx context.Context, path string) *exec.Cmd {
+	ulimits := make([]string, 0)
+	if limits.CPUTime > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", int64(math.Ceil(limits.CPUTime.Seconds()))))
+	}
+	if limits.MemoryBytes > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", limits.MemoryBytes/1024))
+	}
+	if len(ulimits) == 0 {
+		return exec.CommandContext(ctx, path)
+	}
+	script := strings.Join(append(ulimits, `exec "$0"`), " && ")
+	return exec.CommandContext(ctx, "/bin/sh", "-c", script, path)
+}
+
+type LocalJobSpawnerConfig struct {
+	Mode LocalJobMode
+	// Concurrency is the number of jobs that run at once, the number of CPUs by default. Other jobs wait
+	// for a slot.
+	Concurrency int
+	// WorkerPath is the worker binary run in process mode, looked up in PATH if it has no slashes.
+	WorkerPath string
+	EtcdConfig clientv3.Config
+	Limits     LocalJobLimits
+	// Tasks, if set, gets the output of each job appended to its resource's unfinished task run, or to a run
+	// created for the job if there isn't one.
+	Tasks  *scheduling.TaskManager
+	Logger *zap.SugaredLogger
+}
+
+// LocalJobSpawner runs jobs on the coordinator's node with a bounded pool of workers, so single node
+// deployments run jobs in parallel without Kubernetes.
+type LocalJobSpawner struct {
+	mode       LocalJobMode
+	workerPath string
+	etcdConfig Config
+	limits     LocalJobLimits
+	tasks      *scheduling.TaskManager
+	logger     *zap.SugaredLogger
+	slots      chan struct{}
+}
+
+func NewLocalJobSpawner(config LocalJobSpawnerConfig) (*LocalJobSpawner, error) {
+	if config.Mode == "" {
+		config.Mode = LocalProcessMode
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = runtime.NumCPU()
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop().Sugar()
+	}
+	spawner := &LocalJobSpawner{
+		mode:   config.Mode,
+		limits: config.Limits,
+		tasks:  config.Tasks,
+		logger: config.Logger,
+		slots:  make(chan struct{}, config.Concurrency),
+	}
+	switch config.Mode {
+	case LocalProcessMode:
+		if config.WorkerPath == "" {
+			config.WorkerPath = "worker"
+		}
+		path, err := exec.LookPath(config.WorkerPath)
+		if err != nil {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("worker binary %s not found: %v", config.WorkerPath, err))
+		}
+		spawner.workerPath = path
+		etcdConfig := &ETCDConfig{Endpoints: config.EtcdConfig.Endpoints, Username: config.EtcdConfig.Username, Password: config.EtcdConfig.Password}
+		if spawner.etcdConfig, err = etcdConfig.Serialize(); err != nil {
+			return nil, err
+		}
+	case LocalGoroutineMode:
+	default:
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("unknown local job mode %s", config.Mode))
+	}
+	return spawner, nil
+}
+
+func (k *LocalJobSpawner) GetJobRunner(jobName runner.RunnerName, config runner.Config, resourceId metadata.ResourceID) (types.Runner, error) {
+	jobRunner := &localJobRunner{spawner: k, name: jobName, config: config, resource: resourceId}
+	if k.mode == LocalGoroutineMode {
+		if _, err := jobRunner.build(); err != nil {
+			return nil, err
+		}
+	}
+	return jobRunner, nil
+}
+
+type localJobRunner struct {
+	spawner  *LocalJobSpawner
+	name     runner.RunnerName
+	config   runner.Config
+	resource metadata.ResourceID
+
+	// built is the runner created from the job's config. It's what runs in goroutine mode; in process mode
+	// the worker creates its own, and built is only created to answer IsUpdateJob.
+	built     types.Runner
+	buildErr  error
+	buildOnce sync.Once
+	// taskRun is the run the job is executed for, if the Scheduler executes it.
+	taskRun *scheduling.TaskRunMetadata
+}
+
+func (r *localJobRunner) build() (types.Runner, error) {
+	r.buildOnce.Do(func() {
+		r.built, r.buildErr = runner.Create(r.name, r.config)
+	})
+	return r.built, r.buildErr
+}
+
+func (r *localJobRunner) Resource() metadata.ResourceID {
+	return r.resource
+}
+
+func (r *localJobRunner) IsUpdateJob() bool {
+	built, err := r.build()
+	if err != nil {
+		r.spawner.logger.Errorw("Could not create runner to check whether it's an update", "job", r.name, "resource", r.resource, "error", err)
+		return false
+	}
+	return built.IsUpdateJob()
+}
+
+// SetStatsStore passes the store to a runner that runs as a goroutine. Worker processes connect to etcd
+// and set up their own store.
+func (r *localJobRunner) SetStatsStore(store runner.StatsStore) error {
+	if r.spawner.mode != LocalGoroutineMode {
+		return nil
+	}
+	if statsRunner, isStatsRunner := r.built.(runner.StatsRunner); isStatsRunner {
+		return statsRunner.SetStatsStore(store)
+	}
+	return nil
+}
+
+// SetWatermarkStore passes the store to a runner that runs as a goroutine, like SetStatsStore.
+func (r *localJobRunner) SetWatermarkStore(store runner.WatermarkStore) error {
+	if r.spawner.mode != LocalGoroutineMode {
+		return nil
+	}
+	if watermarkRunner, isWatermarkRunner := r.built.(runner.WatermarkRunner); isWatermarkRunner {
+		return watermarkRunner.SetWatermarkStore(store)
+	}
+	return nil
+}
+
+// SetTaskRun makes the job report to the run it's executed for, instead of the run jobTaskRun finds.
+func (r *localJobRunner) SetTaskRun(run scheduling.TaskRunMetadata) {
+	r.taskRun = &run
+}
+
+// Run queues the job for the next free slot and returns without waiting for it to start.
+func (r *localJobRunner) Run() (types.CompletionWatcher, error) {
+	watcher := &runner.SyncWatcher{
+		ResultSync:  &runner.ResultSync{},
+		DoneChannel: make(chan interface{}),
+	}
+	go func() {
+		watcher.EndWatch(r.run())
+	}()
+	return watcher, nil
+}
+
+func (r *localJobRunner) run() error {
+	r.spawner.slots <- struct{}{}
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	ctx, cancel := runCtx, cancelRun
+	if r.spawner.limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(runCtx, r.spawner.limits.Timeout)
+	}
+	output := r.spawner.newRunLog(r.name, r.resource, r.taskRun, cancelRun)
+	output.Append(fmt.Sprintf("Starting %s job", r.name))
+
+	// stopped is closed once the job is no longer running.
+	stopped := make(chan struct{})
+	var err error
+	if r.spawner.mode == LocalGoroutineMode {
+		err = r.runGoroutine(ctx, output, stopped)
+	} else {
+		err = r.runProcess(ctx, output)
+		close(stopped)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fferr.NewInternalError(fmt.Errorf("%s job for %s timed out after %s", r.name, r.resource, r.spawner.limits.Timeout))
+	} else if err != nil && ctx.Err() == context.Canceled {
+		err = fferr.NewInternalError(fmt.Errorf("%s job for %s was stopped because its task run was cancelled or timed out", r.name, r.resource))
+	}
+	if err != nil {
+		output.Append(fmt.Sprintf("Job failed: %v", err))
+	} else {
+		output.Append("Job completed")
+	}
+	output.Finish(err)
+
+	release := func() {
+		cancel()
+		cancelRun()
+		output.Close()
+		<-r.spawner.slots
+	}
+	select {
+	case <-stopped:
+		release()
+	default:
+		// A goroutine can't be killed, so one that's still running keeps its slot, and its output is still
+		// collected, until it returns.
+		output.Append("Job is still running, its slot will be freed when it returns")
+		go func() {
+			<-stopped
+			release()
+		}()
+	}
+	return err
+}
+
+func (r *localJobRunner) runProcess(ctx context.Context, output *runLog) error {
+	cmd := r.spawner.limits.command(ctx, r.spawner.workerPath)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("NAME=%s", r.name),
+		fmt.Sprintf("CONFIG=%s", r.config),
+		fmt.Sprintf("ETCD_CONFIG=%s", r.spawner.etcdConfig),
+	)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = workerWaitDelay
+	if err := cmd.Run(); err != nil {
+		return fferr.NewInternalError(fmt.Errorf("%s job for %s failed: %v", r.name, r.resource, err))
+	}
+	return nil
+}
+
+// runGoroutine runs the job in the coordinator's process, collecting the output of runners that log, and
+// closes stopped once the runner returns, which can be after ctx is done.
+func (r *localJobRunner) runGoroutine(ctx context.Context, output *runLog, stopped chan<- struct{}) error {
+	if loggingRunner, isLoggingRunner := r.built.(runner.LoggingRunner); isLoggingRunner {
+		loggingRunner.SetLogger(output.zapLogger())
+	}
+	watcher, err := r.built.Run()
+	if err != nil {
+		close(stopped)
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Wait()
+		close(stopped)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runLog forwards a job's output to the logger line by line and periodically appends it to the job's
+// task run, if it has one. If the task run, or the attempt at it that the job is part of, ends while the
+// job runs, as when the run is cancelled or times out, the job is stopped.
+type runLog struct {
+	logger   *zap.SugaredLogger
+	resource metadata.ResourceID
+	tasks    *scheduling.TaskManager
+	run      scheduling.TaskRunMetadata
+	hasRun   bool
+	// ownsRun is set if the run was created for the job, so the job sets its status.
+	ownsRun bool
+	// attempt is the number of the run's attempts when the job started, if the run was Running.
+	attempt int
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	partial []byte
+	pending []string
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+func (k *LocalJobSpawner) newRunLog(jobName runner.RunnerName, resource metadata.ResourceID, taskRun *scheduling.TaskRunMetadata, cancel context.CancelFunc) *runLog {
+	log := &runLog{logger: k.logger, resource: resource, tasks: k.tasks, cancel: cancel, stop: make(chan struct{})}
+	if k.tasks != nil && taskRun != nil {
+		log.run, log.hasRun = *taskRun, true
+	} else if k.tasks != nil {
+		run, ownsRun, err := jobTaskRun(k.tasks, jobName, resource)
+		if err != nil {
+			k.logger.Warnw("Could not find or create the task run of job, its output will only be logged", "resource", resource, "error", err)
+		} else {
+			log.run, log.hasRun, log.ownsRun = run, true, ownsRun
+		}
+	}
+	if log.ownsRun {
+		log.setStatus(scheduling.Running, nil)
+	}
+	if log.hasRun {
+		if run, err := k.tasks.GetRunByID(log.run.TaskId, log.run.ID); err == nil && run.Status == scheduling.Running {
+			log.attempt = len(run.Attempts)
+		}
+		log.stopped.Add(1)
+		go log.flushEvery(logFlushInterval)
+	}
+	return log
+}
+
+// jobTaskRun finds the run that a job for the resource belongs to: the most recent run of the tasks that
+// target the resource, if it hasn't finished. Otherwise the job wasn't started by a run, as when a resource is
+// applied, and a run is created for it, along with a task for the resource if there isn't one. Whether the
+// run was created for the job is returned with it.
+func jobTaskRun(tasks *scheduling.TaskManager, jobName runner.RunnerName, resource metadata.ResourceID) (scheduling.TaskRunMetadata, bool, error) {
+	allTasks, err := tasks.GetAllTasks()
+	if _, notFound := err.(*sp.KeyNotFoundError); err != nil && !notFound {
+		return scheduling.TaskRunMetadata{}, false, err
+	}
+	var task scheduling.TaskMetadata
+	hasTask := false
+	var latest scheduling.TaskRunMetadata
+	found := false
+	for _, candidate := range allTasks {
+		target, ok := candidate.Target.(scheduling.NameVariant)
+		if !ok || target.Name != resource.Name || target.Variant != resource.Variant {
+			continue
+		}
+		if !hasTask {
+			task, hasTask = candidate, true
+		}
+		run, hasRun, err := tasks.GetLatestRun(candidate.ID)
+		if err != nil {
+			return scheduling.TaskRunMetadata{}, false, err
+		}
+		if hasRun && (!found || run.StartTime.After(latest.StartTime)) {
+			latest, found = run, true
+		}
+	}
+	if found && !latest.Status.IsFinished() {
+		return latest, false, nil
+	}
+	if !hasTask {
+		target := scheduling.NameVariant{Name: resource.Name, Variant: resource.Variant}
+		if task, err = tasks.CreateTask(resource.Name, scheduling.ResourceCreation, target); err != nil {
+			return scheduling.TaskRunMetadata{}, false, err
+		}
+	}
+	run, err := tasks.CreateTaskRun(resource.Name, task.ID, scheduling.OneOffTrigger{TriggerName: jobName.String()})
+	if err != nil {
+		return scheduling.TaskRunMetadata{}, false, err
+	}
+	return run, true, nil
+}
+
+func (l *runLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial = append(l.partial, p...)
+	for {
+		end := bytes.IndexByte(l.partial, '\n')
+		if end < 0 {
+			break
+		}
+		l.appendLocked(string(l.partial[:end]))
+		l.partial = l.partial[end+1:]
+	}
+	return len(p), nil
+}
+
+// zapLogger returns a logger whose entries are written to the job's output.
+func (l *runLog) zapLogger() *zap.SugaredLogger {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(l), zapcore.DebugLevel)
+	return zap.New(core).Sugar()
+}
+
+func (l *runLog) Append(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.appendLocked(line)
+}
+
+func (l *runLog) appendLocked(line string) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return
+	}
+	l.logger.Infow("Job output", "resource", l.resource, "output", line)
+	if l.hasRun {
+		l.pending = append(l.pending, line)
+	}
+}
+
+func (l *runLog) flushEvery(interval time.Duration) {
+	defer l.stopped.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.flush()
+			l.stopIfEnded()
+		}
+	}
+}
+
+// stopIfEnded stops the job if its task run has finished, or if the attempt the job started in has ended,
+// as when the Scheduler times it out and the run waits to be retried.
+func (l *runLog) stopIfEnded() {
+	run, err := l.tasks.GetRunByID(l.run.TaskId, l.run.ID)
+	if err != nil {
+		l.logger.Warnw("Could not check whether the task run has ended", "task", l.run.TaskId, "run", l.run.ID, "error", err)
+		return
+	}
+	attemptEnded := l.attempt > 0 && (run.Status != scheduling.Running || len(run.Attempts) != l.attempt)
+	if (run.Status.IsFinished() && !l.ownsRun) || run.Status == scheduling.Cancelled || attemptEnded {
+		l.logger.Infow("Stopping job of ended task run", "resource", l.resource, "task", l.run.TaskId, "run", l.run.ID, "status", run.Status)
+		l.cancel()
+	}
+}
+
+// flush appends the lines written since the last flush to the task run as a single log entry.
+func (l *runLog) flush() {
+	l.mu.Lock()
+	lines := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if len(lines) == 0 {
+		return
+	}
+	if err := l.appendRunLog(strings.Join(lines, "\n")); err != nil {
+		l.logger.Errorw("Failed to append job output to task run", "task", l.run.TaskId, "run", l.run.ID, "error", err)
+	}
+}
+
+// Finish sets the status of a run that was created for the job to Success, or to Failed if err is set. Runs
+// that were cancelled keep their status.
+func (l *runLog) Finish(err error) {
+	if !l.ownsRun {
+		return
+	}
+	if err != nil {
+		l.setStatus(scheduling.Failed, err)
+	} else {
+		l.setStatus(scheduling.Success, nil)
+	}
+}
+
+func (l *runLog) setStatus(status scheduling.Status, err error) {
+	lock, lockErr := l.tasks.LockTaskRun(l.run.TaskId, l.run.ID)
+	if lockErr != nil {
+		l.logger.Errorw("Failed to lock task run", "task", l.run.TaskId, "run", l.run.ID, "error", lockErr)
+		return
+	}
+	defer l.tasks.UnlockTaskRun(l.run.TaskId, l.run.ID, lock)
+	run, getErr := l.tasks.GetRunByID(l.run.TaskId, l.run.ID)
+	if getErr == nil && run.Status == scheduling.Cancelled {
+		return
+	}
+	if setErr := l.tasks.SetRunStatus(l.run.ID, l.run.TaskId, status, err, lock); setErr != nil {
+		l.logger.Errorw("Failed to set task run status", "task", l.run.TaskId, "run", l.run.ID, "status", status, "error", setErr)
+	}
+}
+
+func (l *runLog) appendRunLog(entry string) error {
+	lock, err := l.tasks.LockTaskRun(l.run.TaskId, l.run.ID)
+	if err != nil {
+		return err
+	}
+	defer l.tasks.UnlockTaskRun(l.run.TaskId, l.run.ID, lock)
+	return l.tasks.AppendRunLog(l.run.ID, l.run.TaskId, entry, lock)
+}
+
+// Close appends any output that's left, including a last line without a newline.
+func (l *runLog) Close() {
+	l.mu.Lock()
+	l.appendLocked(string(l.partial))
+	l.partial = nil
+	l.mu.Unlock()
+	if !l.hasRun {
+		return
+	}
+	close(l.stop)
+	l.stopped.Wait()
+	l.flush()
+}
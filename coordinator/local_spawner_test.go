@@ -0,0 +1,545 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package coordinator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
+	"github.com/featureform/types"
+	"go.uber.org/zap"
+)
+
+// testWorker stands in for the worker binary. It prints its job, records how many jobs are running at
+// once in $JOBS_DIR, and fails or hangs depending on the job's config.
+const testWorker = `#!/bin/sh
+echo "running $NAME with $CONFIG"
+echo "cpu limit $(ulimit -t)" >&2
+touch "$JOBS_DIR/running.$$"
+ls "$JOBS_DIR" | grep -c running >> "$JOBS_DIR/counts"
+case "$CONFIG" in
+	fail) rm "$JOBS_DIR/running.$$"; echo "failing" >&2; exit 1 ;;
+	hang) rm "$JOBS_DIR/running.$$"; exec sleep 10 ;;
+esac
+sleep 0.2
+rm "$JOBS_DIR/running.$$"
+printf "done"
+`
+
+func newTestWorker(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "worker")
+	if err := os.WriteFile(path, []byte(testWorker), 0755); err != nil {
+		t.Fatalf("Failed to write worker: %s", err)
+	}
+	jobsDir := filepath.Join(dir, "jobs")
+	if err := os.Mkdir(jobsDir, 0755); err != nil {
+		t.Fatalf("Failed to create jobs dir: %s", err)
+	}
+	t.Setenv("JOBS_DIR", jobsDir)
+	return path, jobsDir
+}
+
+func newTestTaskRun(t *testing.T, manager *scheduling.TaskManager, resource metadata.ResourceID) scheduling.TaskRunMetadata {
+	task, err := manager.CreateTask(resource.Name, scheduling.ResourceCreation, scheduling.NameVariant{Name: resource.Name, Variant: resource.Variant})
+	if err != nil {
+		t.Fatalf("Failed to create task: %s", err)
+	}
+	run, err := manager.CreateTaskRun(resource.Name, task.ID, scheduling.OneOffTrigger{TriggerName: "apply"})
+	if err != nil {
+		t.Fatalf("Failed to create task run: %s", err)
+	}
+	return run
+}
+
+func TestLocalJobSpawnerProcesses(t *testing.T) {
+	worker, jobsDir := newTestWorker(t)
+	manager := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	resource := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	run := newTestTaskRun(t, &manager, resource)
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{
+		Mode:        LocalProcessMode,
+		Concurrency: 2,
+		WorkerPath:  worker,
+		Limits:      LocalJobLimits{CPUTime: 1500 * time.Millisecond, Timeout: time.Second},
+		Tasks:       &manager,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+
+	configs := []string{"a", "b", "c", "d", "fail", "hang"}
+	watchers := make([]types.CompletionWatcher, len(configs))
+	for i, config := range configs {
+		jobRunner, err := spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, runner.Config(config), resource)
+		if err != nil {
+			t.Fatalf("Failed to get job runner: %s", err)
+		}
+		if watchers[i], err = jobRunner.Run(); err != nil {
+			t.Fatalf("Failed to run job: %s", err)
+		}
+	}
+	for i, watcher := range watchers {
+		err := watcher.Wait()
+		switch configs[i] {
+		case "fail":
+			if err == nil {
+				t.Fatalf("Expected a failing worker to fail the job")
+			}
+		case "hang":
+			if err == nil || !strings.Contains(err.Error(), "timed out") {
+				t.Fatalf("Expected a hanging worker to time out, got %v", err)
+			}
+		default:
+			if err != nil {
+				t.Fatalf("Job %s failed: %s", configs[i], err)
+			}
+		}
+		if !watcher.Complete() {
+			t.Fatalf("Expected job %s to be complete", configs[i])
+		}
+	}
+
+	counts, err := os.ReadFile(filepath.Join(jobsDir, "counts"))
+	if err != nil {
+		t.Fatalf("Failed to read running counts: %s", err)
+	}
+	for _, count := range strings.Fields(string(counts)) {
+		if count != "1" && count != "2" {
+			t.Fatalf("Expected at most 2 jobs to run at once, got %s", counts)
+		}
+	}
+
+	updated, err := manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %s", err)
+	}
+	logs := strings.Join(updated.Logs, "\n")
+	for _, expected := range []string{
+		fmt.Sprintf("running %s with a", runner.CREATE_TRANSFORMATION),
+		"cpu limit 2",
+		"failing",
+		"done",
+		"Job completed",
+		"timed out after 1s",
+	} {
+		if !strings.Contains(logs, expected) {
+			t.Fatalf("Expected the run's logs to contain %q:\n%s", expected, logs)
+		}
+	}
+}
+
+func TestLocalJobSpawnerCreatesTaskRuns(t *testing.T) {
+	worker, _ := newTestWorker(t)
+	manager := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalProcessMode, WorkerPath: worker, Tasks: &manager})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	resource := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	for _, config := range []string{"a", "fail"} {
+		jobRunner, err := spawner.GetJobRunner(runner.REGISTER_SOURCE, runner.Config(config), resource)
+		if err != nil {
+			t.Fatalf("Failed to get job runner: %s", err)
+		}
+		watcher, err := jobRunner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run job: %s", err)
+		}
+		watcher.Wait()
+	}
+
+	tasks, err := manager.GetAllTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("Expected a task to be created for the resource, got %v %v", tasks, err)
+	}
+	runs, err := manager.GetAllTaskRuns()
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("Expected a run to be created for each job, got %v %v", runs, err)
+	}
+	statuses := map[string]scheduling.Status{"a": scheduling.Success, "fail": scheduling.Failed}
+	for _, run := range runs {
+		if run.Trigger.Name() != runner.REGISTER_SOURCE.String() {
+			t.Fatalf("Expected the run to be triggered by the job, got %s", run.Trigger.Name())
+		}
+		logs := strings.Join(run.Logs, "\n")
+		config := "a"
+		if strings.Contains(logs, "failing") {
+			config = "fail"
+		}
+		if !strings.Contains(logs, fmt.Sprintf("running %s with %s", runner.REGISTER_SOURCE, config)) {
+			t.Fatalf("Expected the run's logs to contain its job's output:\n%s", logs)
+		}
+		if run.Status != statuses[config] {
+			t.Fatalf("Expected the run of job %s to be %s, got %s", config, statuses[config], run.Status)
+		}
+	}
+}
+
+func TestLocalJobSpawnerStopsCancelledRuns(t *testing.T) {
+	worker, jobsDir := newTestWorker(t)
+	manager := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	resource := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	run := newTestTaskRun(t, &manager, resource)
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalProcessMode, WorkerPath: worker, Tasks: &manager})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	jobRunner, err := spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, runner.Config("hang"), resource)
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %s", err)
+	}
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %s", err)
+	}
+	// Jobs started once their resource's run has finished get a run of their own, so wait for the job to
+	// start before cancelling.
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(filepath.Join(jobsDir, "counts")); err == nil {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Expected the job to start")
+		}
+	}
+
+	lock, err := manager.LockTaskRun(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to lock run: %s", err)
+	}
+	if err := manager.CancelRun(run.ID, run.TaskId, lock); err != nil {
+		t.Fatalf("Failed to cancel run: %s", err)
+	}
+	if err := manager.UnlockTaskRun(run.TaskId, run.ID, lock); err != nil {
+		t.Fatalf("Failed to unlock run: %s", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Fatalf("Expected the job to be stopped, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected cancelling the task run to stop the job")
+	}
+}
+
+type blockingRunner struct {
+	running *int32
+	maxSeen *int32
+}
+
+func (r blockingRunner) Run() (types.CompletionWatcher, error) {
+	watcher := &runner.SyncWatcher{ResultSync: &runner.ResultSync{}, DoneChannel: make(chan interface{})}
+	go func() {
+		running := atomic.AddInt32(r.running, 1)
+		for {
+			seen := atomic.LoadInt32(r.maxSeen)
+			if running <= seen || atomic.CompareAndSwapInt32(r.maxSeen, seen, running) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(r.running, -1)
+		watcher.EndWatch(nil)
+	}()
+	return watcher, nil
+}
+
+func (r blockingRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (r blockingRunner) IsUpdateJob() bool {
+	return false
+}
+
+func TestLocalJobSpawnerGoroutines(t *testing.T) {
+	var running, maxSeen int32
+	name := runner.RunnerName("LOCAL_SPAWNER_TEST")
+	if err := runner.RegisterFactory(name, func(config runner.Config) (types.Runner, error) {
+		return blockingRunner{running: &running, maxSeen: &maxSeen}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register runner: %s", err)
+	}
+	defer runner.UnregisterFactory(name)
+
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalGoroutineMode, Concurrency: 3})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	if _, err := spawner.GetJobRunner("ghost_job", []byte{}, metadata.ResourceID{}); err == nil {
+		t.Fatalf("Expected an unknown runner to fail")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		jobRunner, err := spawner.GetJobRunner(name, []byte{}, metadata.ResourceID{Name: "feature", Variant: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatalf("Failed to get job runner: %s", err)
+		}
+		watcher, err := jobRunner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run job: %s", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := watcher.Wait(); err != nil {
+				t.Errorf("Job failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxSeen < 2 || maxSeen > 3 {
+		t.Fatalf("Expected up to 3 jobs to run at once, saw %d", maxSeen)
+	}
+}
+
+type statsTestRunner struct {
+	blockingRunner
+	store runner.StatsStore
+}
+
+func (r *statsTestRunner) SetStatsStore(store runner.StatsStore) error {
+	r.store = store
+	return nil
+}
+
+func TestLocalJobSpawnerSetsStatsStore(t *testing.T) {
+	inProcess := &statsTestRunner{}
+	name := runner.RunnerName("LOCAL_SPAWNER_STATS_TEST")
+	if err := runner.RegisterFactory(name, func(config runner.Config) (types.Runner, error) {
+		return inProcess, nil
+	}); err != nil {
+		t.Fatalf("Failed to register runner: %s", err)
+	}
+	defer runner.UnregisterFactory(name)
+
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalGoroutineMode})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	jobRunner, err := spawner.GetJobRunner(name, []byte{}, metadata.ResourceID{})
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %s", err)
+	}
+	statsRunner, isStatsRunner := jobRunner.(runner.StatsRunner)
+	if !isStatsRunner {
+		t.Fatalf("Expected the local job runner to accept a stats store")
+	}
+	store := runner.EtcdStatsStore{}
+	if err := statsRunner.SetStatsStore(store); err != nil {
+		t.Fatalf("Failed to set stats store: %s", err)
+	}
+	if inProcess.store != store {
+		t.Fatalf("Expected the stats store to be passed to the runner in the coordinator's process")
+	}
+}
+
+// hangingRunner logs and then runs until release is closed, ignoring timeouts like any goroutine.
+type hangingRunner struct {
+	started *int32
+	release chan struct{}
+	logger  *zap.SugaredLogger
+}
+
+func (r *hangingRunner) SetLogger(logger *zap.SugaredLogger) {
+	r.logger = logger
+}
+
+func (r *hangingRunner) Run() (types.CompletionWatcher, error) {
+	watcher := &runner.SyncWatcher{ResultSync: &runner.ResultSync{}, DoneChannel: make(chan interface{})}
+	go func() {
+		atomic.AddInt32(r.started, 1)
+		r.logger.Infow("Copying rows", "chunk", 1)
+		<-r.release
+		r.logger.Info("Finished copying")
+		watcher.EndWatch(nil)
+	}()
+	return watcher, nil
+}
+
+func (r *hangingRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (r *hangingRunner) IsUpdateJob() bool {
+	return true
+}
+
+func registerHangingRunner(t *testing.T, name runner.RunnerName) (*int32, chan struct{}) {
+	var started int32
+	release := make(chan struct{})
+	if err := runner.RegisterFactory(name, func(config runner.Config) (types.Runner, error) {
+		return &hangingRunner{started: &started, release: release, logger: zap.NewNop().Sugar()}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register runner: %s", err)
+	}
+	t.Cleanup(func() { runner.UnregisterFactory(name) })
+	return &started, release
+}
+
+func TestLocalJobSpawnerGoroutineTimeout(t *testing.T) {
+	name := runner.RunnerName("LOCAL_SPAWNER_TIMEOUT_TEST")
+	started, release := registerHangingRunner(t, name)
+	manager := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	resource := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.FEATURE_VARIANT}
+	run := newTestTaskRun(t, &manager, resource)
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{
+		Mode:        LocalGoroutineMode,
+		Concurrency: 1,
+		Limits:      LocalJobLimits{Timeout: 100 * time.Millisecond},
+		Tasks:       &manager,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	runJob := func() types.CompletionWatcher {
+		jobRunner, err := spawner.GetJobRunner(name, []byte{}, resource)
+		if err != nil {
+			t.Fatalf("Failed to get job runner: %s", err)
+		}
+		watcher, err := jobRunner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run job: %s", err)
+		}
+		return watcher
+	}
+	if err := runJob().Wait(); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected the first job to time out, got %v", err)
+	}
+	second := runJob()
+	time.Sleep(200 * time.Millisecond)
+	if count := atomic.LoadInt32(started); count != 1 {
+		t.Fatalf("Expected the timed out goroutine to keep its slot, %d jobs started", count)
+	}
+	close(release)
+	if err := second.Wait(); err != nil {
+		t.Fatalf("Expected the second job to run once the first returned, got %s", err)
+	}
+
+	updated, err := manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %s", err)
+	}
+	logs := strings.Join(updated.Logs, "\n")
+	for _, expected := range []string{"Copying rows", `{"chunk": 1}`, "timed out after 100ms", "Job is still running", "Finished copying"} {
+		if !strings.Contains(logs, expected) {
+			t.Fatalf("Expected the run's logs to contain %q:\n%s", expected, logs)
+		}
+	}
+}
+
+func TestLocalJobSpawnerStopsTimedOutAttempts(t *testing.T) {
+	name := runner.RunnerName("LOCAL_SPAWNER_ATTEMPT_TEST")
+	started, release := registerHangingRunner(t, name)
+	defer close(release)
+	manager := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	resource := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.FEATURE_VARIANT}
+	run := newTestTaskRun(t, &manager, resource)
+	if _, err := manager.SetTaskPolicy(run.TaskId, scheduling.RetryPolicy{MaxAttempts: 2}, time.Minute); err != nil {
+		t.Fatalf("Failed to set task policy: %s", err)
+	}
+	setStatus := func(status scheduling.Status, err error) {
+		lock, lockErr := manager.LockTaskRun(run.TaskId, run.ID)
+		if lockErr != nil {
+			t.Fatalf("Failed to lock run: %s", lockErr)
+		}
+		defer manager.UnlockTaskRun(run.TaskId, run.ID, lock)
+		if err := manager.SetRunStatus(run.ID, run.TaskId, status, err, lock); err != nil {
+			t.Fatalf("Failed to set run status: %s", err)
+		}
+	}
+	// The scheduler starts an attempt at the run before executing its job.
+	setStatus(scheduling.Running, nil)
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalGoroutineMode, Concurrency: 1, Tasks: &manager})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	jobRunner, err := spawner.GetJobRunner(name, []byte{}, resource)
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %s", err)
+	}
+	jobRunner.(taskRunRunner).SetTaskRun(run)
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %s", err)
+	}
+	for start := time.Now(); atomic.LoadInt32(started) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Expected the job to start")
+		}
+	}
+
+	// The attempt times out and the run waits to be retried, so the job is stopped.
+	setStatus(scheduling.Failed, fmt.Errorf("attempt timed out"))
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("Expected the job to be stopped, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the end of the job's attempt to stop it")
+	}
+	updated, err := manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %s", err)
+	}
+	if updated.Status != scheduling.Pending {
+		t.Fatalf("Expected the job not to change the status of a run it doesn't own, got %s", updated.Status)
+	}
+}
+
+func TestLocalJobSpawnerIsUpdateJob(t *testing.T) {
+	name := runner.RunnerName("LOCAL_SPAWNER_UPDATE_TEST")
+	registerHangingRunner(t, name)
+	worker, _ := newTestWorker(t)
+	spawner, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalProcessMode, WorkerPath: worker})
+	if err != nil {
+		t.Fatalf("Failed to create spawner: %s", err)
+	}
+	jobRunner, err := spawner.GetJobRunner(name, []byte{}, metadata.ResourceID{})
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %s", err)
+	}
+	if !jobRunner.IsUpdateJob() {
+		t.Fatalf("Expected a process job to answer IsUpdateJob from its runner")
+	}
+	missing, err := spawner.GetJobRunner("ghost_job", []byte{}, metadata.ResourceID{})
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %s", err)
+	}
+	if missing.IsUpdateJob() {
+		t.Fatalf("Expected a job whose runner can't be created not to be an update")
+	}
+}
+
+func TestNewLocalJobSpawnerErrors(t *testing.T) {
+	if _, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: LocalProcessMode, WorkerPath: "/nonexistent/worker"}); err == nil {
+		t.Fatalf("Expected a missing worker binary to fail")
+	}
+	if _, err := NewLocalJobSpawner(LocalJobSpawnerConfig{Mode: "threads"}); err == nil {
+		t.Fatalf("Expected an unknown mode to fail")
+	}
+}
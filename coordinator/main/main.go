@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/featureform/coordinator"
 	help "github.com/featureform/helpers"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -19,6 +24,7 @@ func main() {
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
 	metadataUrl := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
 	useK8sRunner := help.GetEnv("K8S_RUNNER_ENABLE", "false")
+	localRunnerMode := help.GetEnv("LOCAL_RUNNER_MODE", "")
 	fmt.Printf("connecting to etcd: %s\n", etcdUrl)
 	fmt.Printf("connecting to metadata: %s\n", metadataUrl)
 	etcdConfig := clientv3.Config{
@@ -47,17 +53,49 @@ func main() {
 		panic(err)
 	}
 	logger.Debug("Connected to Metadata")
+	tasks, err := newTaskManager()
+	if err != nil {
+		logger.Errorw("Failed to set up task storage", "error", err)
+		panic(err)
+	}
 	var spawner coordinator.JobSpawner
-	if useK8sRunner == "false" {
-		spawner = &coordinator.MemoryJobSpawner{}
-	} else {
+	if useK8sRunner != "false" {
 		spawner = &coordinator.KubernetesJobSpawner{EtcdConfig: etcdConfig}
+	} else if localRunnerMode != "" {
+		spawner, err = newLocalJobSpawner(coordinator.LocalJobMode(localRunnerMode), etcdConfig, tasks, logger)
+		if err != nil {
+			logger.Errorw("Failed to set up local job spawner", "error", err)
+			panic(err)
+		}
+	} else {
+		spawner = &coordinator.MemoryJobSpawner{}
 	}
 	coord, err := coordinator.NewCoordinator(client, logger.SugaredLogger, cli, spawner)
 	if err != nil {
 		logger.Errorw("Failed to set up coordinator: %v", err)
 		panic(err)
 	}
+	coord.MetadataAddress = metadataUrl
+	if err := setJobPolicy(coord); err != nil {
+		logger.Errorw("Invalid coordinator job settings", "error", err)
+		panic(err)
+	}
+	scheduler, err := newScheduler(coord, tasks, logger)
+	if err != nil {
+		logger.Errorw("Failed to set up scheduler", "error", err)
+		panic(err)
+	}
+	go scheduler.Start(context.Background())
+	coord.Monitoring, err = featureMonitoring(metadataUrl)
+	if err != nil {
+		logger.Errorw("Invalid feature monitoring settings", "error", err)
+		panic(err)
+	}
+	go func() {
+		if err := coord.WatchForDeleteJobs(); err != nil {
+			logger.Errorw("Delete job watch failed", "error", err)
+		}
+	}()
 	logger.Debug("Begin Job Watch")
 	if err := coord.WatchForNewJobs(); err != nil {
 		logger.Errorw(err.Error())
@@ -65,3 +103,102 @@ func main() {
 		return
 	}
 }
+
+// featureMonitoring configures feature monitoring from FEATURE_MONITORING_* variables.
+func featureMonitoring(metadataUrl string) (coordinator.FeatureMonitoring, error) {
+	enabled, err := strconv.ParseBool(help.GetEnv("FEATURE_MONITORING_ENABLED", "false"))
+	if err != nil {
+		return coordinator.FeatureMonitoring{}, fmt.Errorf("invalid FEATURE_MONITORING_ENABLED: %v", err)
+	}
+	threshold, err := strconv.ParseFloat(help.GetEnv("FEATURE_MONITORING_DRIFT_THRESHOLD", "0"), 64)
+	if err != nil {
+		return coordinator.FeatureMonitoring{}, fmt.Errorf("invalid FEATURE_MONITORING_DRIFT_THRESHOLD: %v", err)
+	}
+	metric := runner.DriftMetric(help.GetEnv("FEATURE_MONITORING_DRIFT_METRIC", string(runner.PSIDrift)))
+	if metric != runner.PSIDrift && metric != runner.KSDrift {
+		return coordinator.FeatureMonitoring{}, fmt.Errorf("invalid FEATURE_MONITORING_DRIFT_METRIC: %s", metric)
+	}
+	return coordinator.FeatureMonitoring{
+		Enabled:         enabled,
+		DriftMetric:     metric,
+		DriftThreshold:  threshold,
+		MetadataAddress: metadataUrl,
+	}, nil
+}
+
+// setJobPolicy sets how coordinator jobs are retried and timed out from the COORDINATOR_JOB_* variables.
+func setJobPolicy(coord *coordinator.Coordinator) error {
+	attempts, err := strconv.Atoi(help.GetEnv("COORDINATOR_JOB_MAX_ATTEMPTS", strconv.Itoa(coordinator.MAX_ATTEMPTS)))
+	if err != nil {
+		return fmt.Errorf("invalid COORDINATOR_JOB_MAX_ATTEMPTS: %v", err)
+	}
+	backoff, err := time.ParseDuration(help.GetEnv("COORDINATOR_JOB_RETRY_BACKOFF", coordinator.JOB_RETRY_BACKOFF.String()))
+	if err != nil {
+		return fmt.Errorf("invalid COORDINATOR_JOB_RETRY_BACKOFF: %v", err)
+	}
+	timeout, err := time.ParseDuration(help.GetEnv("COORDINATOR_JOB_TIMEOUT", "0s"))
+	if err != nil {
+		return fmt.Errorf("invalid COORDINATOR_JOB_TIMEOUT: %v", err)
+	}
+	coord.JobRetries = scheduling.RetryPolicy{MaxAttempts: attempts, InitialBackoff: backoff}
+	coord.JobTimeout = timeout
+	return nil
+}
+
+// newScheduler creates the scheduler that runs the coordinator's scheduled jobs, with the coordinator as its
+// executor. It checks for runs to start every SCHEDULER_INTERVAL.
+func newScheduler(coord *coordinator.Coordinator, tasks *scheduling.TaskManager, logger logging.Logger) (*scheduling.Scheduler, error) {
+	interval, err := time.ParseDuration(help.GetEnv("SCHEDULER_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL: %v", err)
+	}
+	scheduler := scheduling.NewScheduler(tasks, coord, interval, logger)
+	coord.Scheduler = scheduler
+	return scheduler, nil
+}
+
+// newTaskManager stores tasks, task runs and scheduler triggers in the Bolt file at TASKS_DB_PATH, or in
+// memory if it isn't set.
+func newTaskManager() (*scheduling.TaskManager, error) {
+	var storage sp.StorageProvider = sp.NewMemoryStorageProvider()
+	if path := help.GetEnv("TASKS_DB_PATH", ""); path != "" {
+		bolt, err := sp.NewBoltStorageProvider(path)
+		if err != nil {
+			return nil, err
+		}
+		storage = bolt
+	}
+	tasks := scheduling.NewTaskManager(storage)
+	return &tasks, nil
+}
+
+// newLocalJobSpawner configures a local job spawner from LOCAL_RUNNER_* variables. Job output is recorded in
+// task runs in tasks, the same task manager the scheduler uses.
+func newLocalJobSpawner(mode coordinator.LocalJobMode, etcdConfig clientv3.Config, tasks *scheduling.TaskManager, logger logging.Logger) (*coordinator.LocalJobSpawner, error) {
+	concurrency, err := strconv.Atoi(help.GetEnv("LOCAL_RUNNER_CONCURRENCY", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCAL_RUNNER_CONCURRENCY: %v", err)
+	}
+	timeout, err := time.ParseDuration(help.GetEnv("LOCAL_RUNNER_TIMEOUT", "0s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCAL_RUNNER_TIMEOUT: %v", err)
+	}
+	cpuTime, err := time.ParseDuration(help.GetEnv("LOCAL_RUNNER_CPU_TIME", "0s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCAL_RUNNER_CPU_TIME: %v", err)
+	}
+	memory, err := strconv.ParseInt(help.GetEnv("LOCAL_RUNNER_MEMORY_BYTES", "0"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOCAL_RUNNER_MEMORY_BYTES: %v", err)
+	}
+	config := coordinator.LocalJobSpawnerConfig{
+		Mode:        mode,
+		Concurrency: concurrency,
+		WorkerPath:  help.GetEnv("LOCAL_WORKER_PATH", "worker"),
+		EtcdConfig:  etcdConfig,
+		Limits:      coordinator.LocalJobLimits{Timeout: timeout, CPUTime: cpuTime, MemoryBytes: memory},
+		Tasks:       tasks,
+		Logger:      logger.SugaredLogger,
+	}
+	return coordinator.NewLocalJobSpawner(config)
+}
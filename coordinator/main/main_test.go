@@ -0,0 +1,237 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/featureform/coordinator"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	"github.com/featureform/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap/zaptest"
+)
+
+// recordingSpawner reports each job that's run on jobs.
+type recordingSpawner struct {
+	jobs chan string
+}
+
+func (s *recordingSpawner) GetJobRunner(jobName runner.RunnerName, config runner.Config, id metadata.ResourceID) (types.Runner, error) {
+	return &recordingRunner{jobs: s.jobs, name: jobName, id: id}, nil
+}
+
+type recordingRunner struct {
+	jobs chan string
+	name runner.RunnerName
+	id   metadata.ResourceID
+}
+
+func (r *recordingRunner) Run() (types.CompletionWatcher, error) {
+	r.jobs <- fmt.Sprintf("%s %s", r.name, r.id.Name)
+	watcher := &runner.SyncWatcher{ResultSync: &runner.ResultSync{}, DoneChannel: make(chan interface{})}
+	watcher.EndWatch(nil)
+	return watcher, nil
+}
+
+func (r *recordingRunner) Resource() metadata.ResourceID {
+	return r.id
+}
+
+func (r *recordingRunner) IsUpdateJob() bool {
+	return true
+}
+
+func newTestScheduler(t *testing.T) (*coordinator.Coordinator, *scheduling.Scheduler, chan string) {
+	t.Setenv("TASKS_DB_PATH", "")
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	tasks, err := newTaskManager()
+	if err != nil {
+		t.Fatalf("Failed to create task manager: %v", err)
+	}
+	jobs := make(chan string, 10)
+	coord := &coordinator.Coordinator{Logger: logger.SugaredLogger, Spawner: &recordingSpawner{jobs: jobs}}
+	scheduler, err := newScheduler(coord, tasks, logger)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	if coord.Scheduler != scheduler {
+		t.Fatalf("Expected the coordinator to schedule jobs on the new scheduler")
+	}
+	return coord, scheduler, jobs
+}
+
+func expectJob(t *testing.T, jobs chan string, expected string) {
+	select {
+	case job := <-jobs:
+		if job != expected {
+			t.Fatalf("Expected %s job, got %s", expected, job)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected %s job to run", expected)
+	}
+}
+
+func expectNoJob(t *testing.T, jobs chan string) {
+	select {
+	case job := <-jobs:
+		t.Fatalf("Expected no job to run, got %s", job)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// waitForSuccess waits for the latest run of every task to succeed.
+func waitForSuccess(t *testing.T, scheduler *scheduling.Scheduler) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runs, err := scheduler.Tasks().GetAllTaskRuns()
+		if err != nil {
+			t.Fatalf("Failed to get runs: %v", err)
+		}
+		done := true
+		for _, run := range runs {
+			done = done && run.Status == scheduling.Success
+		}
+		if done {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected runs to succeed, got %+v", runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsScheduledJobs(t *testing.T) {
+	coord, scheduler, jobs := newTestScheduler(t)
+	source := metadata.ResourceID{Name: "transactions", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	feature := metadata.ResourceID{Name: "avg_transaction", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	if err := coord.ScheduleJob(source, runner.CREATE_TRANSFORMATION, []byte("{}"), "0 0 1 1 *", nil); err != nil {
+		t.Fatalf("Failed to schedule transformation: %v", err)
+	}
+	// The feature has no schedule of its own, so it's only updated after its source.
+	if err := coord.ScheduleJob(feature, runner.MATERIALIZE, []byte("{}"), "", []metadata.ResourceID{source}); err != nil {
+		t.Fatalf("Failed to schedule materialization: %v", err)
+	}
+
+	now := time.Now().UTC()
+	if err := scheduler.Tick(now); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	expectNoJob(t, jobs)
+
+	// A tick after the yearly schedule fires creates the run, and the next tick executes it.
+	fired := time.Date(now.Year()+1, 1, 1, 0, 1, 0, 0, time.UTC)
+	if err := scheduler.Tick(fired); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	if err := scheduler.Tick(now); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	expectJob(t, jobs, fmt.Sprintf("%s %s", runner.CREATE_TRANSFORMATION, source.Name))
+	waitForSuccess(t, scheduler)
+
+	// The successful transformation fires the materialization.
+	for i := 0; i < 2; i++ {
+		if err := scheduler.Tick(time.Now().UTC()); err != nil {
+			t.Fatalf("Failed to tick: %v", err)
+		}
+	}
+	expectJob(t, jobs, fmt.Sprintf("%s %s", runner.MATERIALIZE, feature.Name))
+	waitForSuccess(t, scheduler)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	expectNoJob(t, jobs)
+}
+
+func TestSchedulerRunsLocalJobs(t *testing.T) {
+	t.Setenv("TASKS_DB_PATH", "")
+	name := runner.RunnerName("MAIN_LOCAL_SCHEDULE_TEST")
+	jobs := make(chan string, 10)
+	if err := runner.RegisterFactory(name, func(config runner.Config) (types.Runner, error) {
+		return &recordingRunner{jobs: jobs, name: name, id: metadata.ResourceID{Name: string(config)}}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register runner: %v", err)
+	}
+	defer runner.UnregisterFactory(name)
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	tasks, err := newTaskManager()
+	if err != nil {
+		t.Fatalf("Failed to create task manager: %v", err)
+	}
+	spawner, err := newLocalJobSpawner(coordinator.LocalGoroutineMode, clientv3.Config{}, tasks, logger)
+	if err != nil {
+		t.Fatalf("Failed to create local job spawner: %v", err)
+	}
+	coord := &coordinator.Coordinator{Logger: logger.SugaredLogger, Spawner: spawner}
+	scheduler, err := newScheduler(coord, tasks, logger)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	// Local runners can't schedule themselves, so the job is run by the scheduler.
+	source := metadata.ResourceID{Name: "transactions", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	if err := coord.ScheduleJob(source, name, []byte(source.Name), "0 0 1 1 *", nil); err != nil {
+		t.Fatalf("Failed to schedule local job: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := scheduler.Tick(time.Date(now.Year()+1, 1, 1, 0, 1, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	if err := scheduler.Tick(now); err != nil {
+		t.Fatalf("Failed to tick: %v", err)
+	}
+	expectJob(t, jobs, fmt.Sprintf("%s %s", name, source.Name))
+	waitForSuccess(t, scheduler)
+
+	// The job's output goes to the scheduler's run, instead of a run of its own.
+	runs, err := scheduler.Tasks().GetAllTaskRuns()
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || !strings.Contains(strings.Join(runs[0].Logs, "\n"), "Starting") {
+		t.Fatalf("Expected the job to report to the scheduled run, got %+v", runs)
+	}
+}
+
+func TestCoordinatorWithoutSchedulerRejectsSchedules(t *testing.T) {
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	coord := &coordinator.Coordinator{Logger: logger.SugaredLogger, Spawner: &recordingSpawner{jobs: make(chan string, 1)}}
+	id := metadata.ResourceID{Name: "transactions", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	if err := coord.ScheduleJob(id, runner.CREATE_TRANSFORMATION, []byte("{}"), "* * * * *", nil); err == nil {
+		t.Fatalf("Expected scheduling a job without a scheduler or cron runner to fail")
+	}
+	if err := coord.ScheduleJob(id, runner.CREATE_TRANSFORMATION, []byte("{}"), "", nil); err != nil {
+		t.Fatalf("Expected a job without a schedule or upstream jobs to need no scheduler: %v", err)
+	}
+}
+
+func TestSetJobPolicy(t *testing.T) {
+	coord := &coordinator.Coordinator{}
+	if err := setJobPolicy(coord); err != nil {
+		t.Fatalf("Failed to set default job policy: %v", err)
+	}
+	if coord.JobRetries.MaxAttempts != coordinator.MAX_ATTEMPTS || coord.JobRetries.InitialBackoff != coordinator.JOB_RETRY_BACKOFF || coord.JobTimeout != 0 {
+		t.Fatalf("Unexpected default job policy: %+v %v", coord.JobRetries, coord.JobTimeout)
+	}
+	t.Setenv("COORDINATOR_JOB_MAX_ATTEMPTS", "5")
+	t.Setenv("COORDINATOR_JOB_TIMEOUT", "1h")
+	if err := setJobPolicy(coord); err != nil {
+		t.Fatalf("Failed to set job policy: %v", err)
+	}
+	if coord.JobRetries.MaxAttempts != 5 || coord.JobTimeout != time.Hour {
+		t.Fatalf("Unexpected job policy: %+v %v", coord.JobRetries, coord.JobTimeout)
+	}
+	t.Setenv("COORDINATOR_JOB_TIMEOUT", "soon")
+	if err := setJobPolicy(coord); err == nil {
+		t.Fatalf("Expected an invalid timeout to fail")
+	}
+}
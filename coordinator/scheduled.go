@@ -0,0 +1,364 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/kubernetes"
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
+	"github.com/featureform/types"
+)
+
+const (
+	scheduleTriggerName   = "schedule"
+	dependencyTriggerName = "upstream"
+	resourceJobTrigger    = "coordinator job"
+)
+
+// runPollInterval is how often a resource's coordinator job checks on its task run.
+var runPollInterval = time.Second
+
+// taskRunRunner is implemented by runners that report to the task run they're executed for.
+type taskRunRunner interface {
+	SetTaskRun(run scheduling.TaskRunMetadata)
+}
+
+// taskJob is what the coordinator runs for the runs of a task: the job named Runner with Config, spawned
+// with the coordinator's JobSpawner, or if there's no Runner, the coordinator job that creates Resource.
+type taskJob struct {
+	Runner   runner.RunnerName   `json:"runner"`
+	Config   runner.Config       `json:"config"`
+	Resource metadata.ResourceID `json:"resource"`
+	// Schedule is the schedule of the resource's coordinator job.
+	Schedule string `json:"schedule"`
+}
+
+// Execute runs the job of a task the coordinator created. It makes the coordinator the scheduler's
+// Executor. It returns once ctx is done, but the job is only stopped if it watches the task run, as
+// the LocalJobSpawner's runners do.
+func (c *Coordinator) Execute(ctx context.Context, task scheduling.TaskMetadata, run scheduling.TaskRunMetadata) error {
+	job := taskJob{}
+	if err := json.Unmarshal(task.Job, &job); err != nil {
+		return fferr.NewInternalError(fmt.Errorf("task %d has an invalid job: %v", task.ID, err))
+	}
+	if job.Runner == "" {
+		return c.executeResourceJob(ctx, job, run)
+	}
+	c.Logger.Infow("Running scheduled job", "task", task.ID, "run", run.ID, "job", job.Runner, "resource", job.Resource)
+	jobRunner, err := c.Spawner.GetJobRunner(job.Runner, job.Config, job.Resource)
+	if err != nil {
+		return err
+	}
+	if err := c.prepareRunner(jobRunner); err != nil {
+		return err
+	}
+	if runRunner, isRunRunner := jobRunner.(taskRunRunner); isRunRunner {
+		runRunner.SetTaskRun(run)
+	}
+	completionWatcher, err := jobRunner.Run()
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- completionWatcher.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// executeResourceJob makes an attempt at a resource's coordinator job. Its error is kept for runJob, which
+// returns the error of the run's last attempt.
+func (c *Coordinator) executeResourceJob(ctx context.Context, job taskJob, run scheduling.TaskRunMetadata) error {
+	key := runKey{taskID: run.TaskId, runID: run.ID}
+	c.attemptErrors.Delete(key)
+	jobFunc, has := c.jobFunction(job.Resource.Type)
+	if !has {
+		err := fferr.NewInvalidResourceTypeError(job.Resource.Name, job.Resource.Variant, fferr.ResourceType(job.Resource.Type.String()), nil)
+		c.attemptErrors.Store(key, err)
+		return &scheduling.NonRetryableError{Err: err}
+	}
+	c.Logger.Infow("Running coordinator job", "task", run.TaskId, "run", run.ID, "attempt", len(run.Attempts), "resource", job.Resource)
+	done := make(chan error, 1)
+	go func() {
+		done <- jobFunc(job.Resource, job.Schedule)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// The job can't be stopped, so a timed out attempt keeps running alongside a retry.
+		return ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+	c.attemptErrors.Store(key, err)
+	switch err.(type) {
+	case *fferr.ResourceAlreadyFailedError, *fferr.ResourceAlreadyCompleteError:
+		return &scheduling.NonRetryableError{Err: err}
+	default:
+		return err
+	}
+}
+
+// runJob runs a resource's coordinator job as a task run, so it's attempted, retried and timed out by the
+// coordinator's Scheduler following JobRetries and JobTimeout. The caller holds the job's lock, so a run
+// that's still unfinished was abandoned by a coordinator that stopped, and it's cancelled. It returns once
+// the run has finished, with the error of its last attempt.
+func (c *Coordinator) runJob(resID metadata.ResourceID, schedule string) error {
+	if c.Scheduler == nil {
+		return fferr.NewInternalError(fmt.Errorf("running the job of %s requires a scheduler", resID))
+	}
+	if _, has := c.jobFunction(resID.Type); !has {
+		return fferr.NewInvalidResourceTypeError(resID.Name, resID.Variant, fferr.ResourceType(resID.Type.String()), nil)
+	}
+	tasks := c.Scheduler.Tasks()
+	task, err := c.jobTask(taskJob{Resource: resID, Schedule: schedule})
+	if err != nil {
+		return err
+	}
+	if task, err = tasks.SetTaskPolicy(task.ID, c.JobRetries, c.JobTimeout); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	latest, hasRun, err := tasks.GetLatestRun(task.ID)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	if hasRun && !latest.Status.IsFinished() {
+		c.Logger.Infow("Cancelling abandoned run of coordinator job", "task", task.ID, "run", latest.ID, "resource", resID)
+		if err := c.Scheduler.CancelRun(task.ID, latest.ID); err != nil {
+			return fferr.NewInternalError(err)
+		}
+	}
+	run, err := tasks.CreateTaskRun(task.Name, task.ID, scheduling.OneOffTrigger{TriggerName: resourceJobTrigger})
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return c.awaitRun(run)
+}
+
+// awaitRun waits for a run to finish. It ticks the scheduler itself, so the run is executed and its
+// timeouts are checked as often as it's polled, even if the scheduler's interval is longer.
+func (c *Coordinator) awaitRun(run scheduling.TaskRunMetadata) error {
+	key := runKey{taskID: run.TaskId, runID: run.ID}
+	defer c.attemptErrors.Delete(key)
+	ticker := time.NewTicker(runPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.Scheduler.Tick(time.Now().UTC()); err != nil {
+			c.Logger.Warnw("Scheduler tick failed while waiting for job", "task", run.TaskId, "run", run.ID, "error", err)
+		}
+		current, err := c.Scheduler.Tasks().GetRunByID(run.TaskId, run.ID)
+		if err != nil {
+			return fferr.NewInternalError(err)
+		}
+		switch current.Status {
+		case scheduling.Success:
+			return nil
+		case scheduling.Cancelled:
+			return fferr.NewInternalError(fmt.Errorf("run %d of task %d was cancelled", run.ID, run.TaskId))
+		case scheduling.Failed:
+			if err, has := c.attemptErrors.Load(key); has {
+				return err.(error)
+			}
+			// The last attempt timed out.
+			return fferr.NewInternalError(errors.New(current.Error))
+		}
+		<-ticker.C
+	}
+}
+
+// prepareRunner gives runners that run in the coordinator's process the stores that workers connect to
+// themselves.
+func (c *Coordinator) prepareRunner(jobRunner types.Runner) error {
+	storage := metadata.EtcdStorage{Client: c.EtcdClient}
+	if statsRunner, isStatsRunner := jobRunner.(runner.StatsRunner); isStatsRunner {
+		if err := statsRunner.SetStatsStore(runner.EtcdStatsStore{Storage: storage}); err != nil {
+			return err
+		}
+	}
+	if watermarkRunner, isWatermarkRunner := jobRunner.(runner.WatermarkRunner); isWatermarkRunner {
+		if err := watermarkRunner.SetWatermarkStore(runner.EtcdWatermarkStore{Storage: storage}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduleJob runs a job for the resource on the cron schedule, if it's set, and after each successful run
+// of a job the scheduler runs for one of the upstream resources. Runners that support schedules, like
+// Kubernetes jobs, run on the schedule themselves. Everything else is registered as a task with the
+// coordinator's Scheduler, which must be set unless there's nothing for it to run.
+func (c *Coordinator) ScheduleJob(id metadata.ResourceID, jobName runner.RunnerName, config runner.Config, schedule string, upstream []metadata.ResourceID) error {
+	triggers := make([]scheduling.Trigger, 0)
+	if schedule != "" {
+		jobRunner, err := c.Spawner.GetJobRunner(jobName, config, id)
+		if err != nil {
+			return err
+		}
+		if cronRunner, isCronRunner := jobRunner.(kubernetes.CronRunner); isCronRunner {
+			if err := cronRunner.ScheduleJob(kubernetes.CronSchedule(schedule)); err != nil {
+				return err
+			}
+		} else {
+			triggers = append(triggers, scheduling.ScheduleTrigger{TriggerName: scheduleTriggerName, Schedule: schedule})
+		}
+	}
+	upstreamTasks, err := c.upstreamTasks(upstream)
+	if err != nil {
+		return err
+	}
+	if len(upstreamTasks) > 0 {
+		triggers = append(triggers, scheduling.DependencyTrigger{TriggerName: dependencyTriggerName, Upstream: upstreamTasks})
+	}
+	if len(triggers) == 0 {
+		return nil
+	}
+	if c.Scheduler == nil {
+		return fferr.NewInternalError(fmt.Errorf("scheduling the %s job of %s requires a runner that supports schedules or a scheduler", jobName, id))
+	}
+	task, err := c.jobTask(taskJob{Runner: jobName, Config: config, Resource: id})
+	if err != nil {
+		return err
+	}
+	for _, trigger := range triggers {
+		if err := c.Scheduler.AddTrigger(task.ID, trigger); err != nil {
+			return fferr.NewInternalError(err)
+		}
+		c.Logger.Infow("Scheduled job", "job", jobName, "resource", id, "task", task.ID, "trigger", trigger.Name())
+	}
+	return nil
+}
+
+// jobTask returns the task that runs the job for its resource, creating it if there isn't one. The task's
+// job is set to the given one, replacing the config of earlier versions of the job.
+func (c *Coordinator) jobTask(job taskJob) (scheduling.TaskMetadata, error) {
+	tasks := c.Scheduler.Tasks()
+	jobs, err := c.scheduledJobs()
+	if err != nil {
+		return scheduling.TaskMetadata{}, err
+	}
+	id := job.Resource
+	task, found := scheduling.TaskMetadata{}, false
+	for _, scheduled := range jobs {
+		if scheduled.job.Resource == id && scheduled.job.Runner == job.Runner {
+			task, found = scheduled.task, true
+			break
+		}
+	}
+	if !found {
+		target := scheduling.NameVariant{Name: id.Name, Variant: id.Variant}
+		if task, err = tasks.CreateTask(id.Name, scheduling.ResourceCreation, target); err != nil {
+			return scheduling.TaskMetadata{}, fferr.NewInternalError(err)
+		}
+	}
+	serialized, err := json.Marshal(job)
+	if err != nil {
+		return scheduling.TaskMetadata{}, fferr.NewInternalError(err)
+	}
+	if task, err = tasks.SetTaskJob(task.ID, serialized); err != nil {
+		return scheduling.TaskMetadata{}, fferr.NewInternalError(err)
+	}
+	return task, nil
+}
+
+// upstreamTasks returns the tasks of the jobs the scheduler runs for the upstream resources. Coordinator jobs
+// only create a resource and monitoring jobs don't change a feature, so downstream jobs don't wait for them.
+func (c *Coordinator) upstreamTasks(upstream []metadata.ResourceID) ([]scheduling.TaskID, error) {
+	if c.Scheduler == nil || len(upstream) == 0 {
+		return nil, nil
+	}
+	jobs, err := c.scheduledJobs()
+	if err != nil {
+		return nil, err
+	}
+	taskIDs := make([]scheduling.TaskID, 0)
+	for _, scheduled := range jobs {
+		if scheduled.job.Runner == "" || scheduled.job.Runner == runner.MONITOR_FEATURE {
+			continue
+		}
+		for _, id := range upstream {
+			if scheduled.job.Resource == id {
+				taskIDs = append(taskIDs, scheduled.task.ID)
+				break
+			}
+		}
+	}
+	return taskIDs, nil
+}
+
+// rescheduleJobs changes the schedule of the resource's jobs that run on the scheduler. It returns false
+// if the resource has none.
+func (c *Coordinator) rescheduleJobs(id metadata.ResourceID, schedule string) (bool, error) {
+	if c.Scheduler == nil {
+		return false, nil
+	}
+	jobs, err := c.scheduledJobs()
+	if err != nil {
+		return false, err
+	}
+	triggers, err := c.Scheduler.Tasks().GetAllTriggers()
+	if err != nil {
+		return false, fferr.NewInternalError(err)
+	}
+	rescheduled := false
+	for _, scheduled := range jobs {
+		if scheduled.job.Resource != id {
+			continue
+		}
+		for _, trigger := range triggers {
+			if trigger.TaskID != scheduled.task.ID || trigger.TriggerType != scheduling.ScheduleTriggerType {
+				continue
+			}
+			updated := scheduling.ScheduleTrigger{TriggerName: trigger.Trigger.Name(), Schedule: schedule}
+			if err := c.Scheduler.AddTrigger(scheduled.task.ID, updated); err != nil {
+				return false, fferr.NewInternalError(err)
+			}
+			rescheduled = true
+		}
+	}
+	return rescheduled, nil
+}
+
+type scheduledJob struct {
+	task scheduling.TaskMetadata
+	job  taskJob
+}
+
+// scheduledJobs returns the tasks the coordinator created, along with their jobs.
+func (c *Coordinator) scheduledJobs() ([]scheduledJob, error) {
+	tasks, err := c.Scheduler.Tasks().GetAllTasks()
+	if _, notFound := err.(*sp.KeyNotFoundError); notFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	jobs := make([]scheduledJob, 0)
+	for _, task := range tasks {
+		if len(task.Job) == 0 {
+			continue
+		}
+		job := taskJob{}
+		if err := json.Unmarshal(task.Job, &job); err != nil {
+			return nil, fferr.NewInternalError(fmt.Errorf("task %d has an invalid job: %v", task.ID, err))
+		}
+		jobs = append(jobs, scheduledJob{task: task, job: job})
+	}
+	return jobs, nil
+}
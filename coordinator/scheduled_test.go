@@ -0,0 +1,237 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/kubernetes"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/featureform/scheduling"
+	sp "github.com/featureform/scheduling/storage_providers"
+	"github.com/featureform/types"
+	"go.uber.org/zap/zaptest"
+)
+
+// cronSpawner returns runners that support schedules, recording the schedules they're given.
+type cronSpawner struct {
+	schedules []kubernetes.CronSchedule
+	// release, if set, is waited on by jobs that are run.
+	release chan struct{}
+}
+
+func (s *cronSpawner) GetJobRunner(jobName runner.RunnerName, config runner.Config, id metadata.ResourceID) (types.Runner, error) {
+	return &cronRunner{spawner: s, id: id}, nil
+}
+
+type cronRunner struct {
+	spawner *cronSpawner
+	id      metadata.ResourceID
+}
+
+func (r *cronRunner) Run() (types.CompletionWatcher, error) {
+	watcher := &runner.SyncWatcher{ResultSync: &runner.ResultSync{}, DoneChannel: make(chan interface{})}
+	go func() {
+		if r.spawner.release != nil {
+			<-r.spawner.release
+		}
+		watcher.EndWatch(nil)
+	}()
+	return watcher, nil
+}
+
+func (r *cronRunner) ScheduleJob(schedule kubernetes.CronSchedule) error {
+	r.spawner.schedules = append(r.spawner.schedules, schedule)
+	return nil
+}
+
+func (r *cronRunner) Resource() metadata.ResourceID {
+	return r.id
+}
+
+func (r *cronRunner) IsUpdateJob() bool {
+	return true
+}
+
+// plainSpawner wraps a cronSpawner so its runners don't support schedules.
+type plainSpawner struct {
+	cronSpawner
+}
+
+func (s *plainSpawner) GetJobRunner(jobName runner.RunnerName, config runner.Config, id metadata.ResourceID) (types.Runner, error) {
+	jobRunner, err := s.cronSpawner.GetJobRunner(jobName, config, id)
+	return struct{ types.Runner }{jobRunner}, err
+}
+
+func newScheduledTestCoordinator(t *testing.T, spawner JobSpawner) *Coordinator {
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	tasks := scheduling.NewTaskManager(sp.NewMemoryStorageProvider())
+	coord := &Coordinator{Logger: logger.SugaredLogger, Spawner: spawner}
+	coord.Scheduler = scheduling.NewScheduler(&tasks, coord, time.Minute, logger)
+	return coord
+}
+
+func TestScheduleJobUsesCronRunners(t *testing.T) {
+	spawner := &cronSpawner{}
+	coord := newScheduledTestCoordinator(t, spawner)
+	id := metadata.ResourceID{Name: "transactions", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	if err := coord.ScheduleJob(id, runner.CREATE_TRANSFORMATION, []byte("{}"), "0 * * * *", nil); err != nil {
+		t.Fatalf("Failed to schedule job: %v", err)
+	}
+	if len(spawner.schedules) != 1 || spawner.schedules[0] != "0 * * * *" {
+		t.Fatalf("Expected the runner to be scheduled, got %v", spawner.schedules)
+	}
+	triggers, err := coord.Scheduler.Tasks().GetAllTriggers()
+	if err != nil {
+		t.Fatalf("Failed to get triggers: %v", err)
+	}
+	if len(triggers) != 0 {
+		t.Fatalf("Expected a job its runner schedules not to be on the scheduler, got %+v", triggers)
+	}
+}
+
+func TestRescheduleJobs(t *testing.T) {
+	coord := newScheduledTestCoordinator(t, &plainSpawner{})
+	id := metadata.ResourceID{Name: "avg_transaction", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	if rescheduled, err := coord.rescheduleJobs(id, "0 0 * * *"); err != nil || rescheduled {
+		t.Fatalf("Expected a resource without jobs on the scheduler not to be rescheduled: %v %v", rescheduled, err)
+	}
+	if err := coord.ScheduleJob(id, runner.MATERIALIZE, []byte("{}"), "0 * * * *", nil); err != nil {
+		t.Fatalf("Failed to schedule job: %v", err)
+	}
+	// Scheduling the job again replaces its config instead of adding a task.
+	if err := coord.ScheduleJob(id, runner.MATERIALIZE, []byte(`{"updated":true}`), "0 * * * *", nil); err != nil {
+		t.Fatalf("Failed to schedule job: %v", err)
+	}
+	if rescheduled, err := coord.rescheduleJobs(id, "0 0 * * *"); err != nil || !rescheduled {
+		t.Fatalf("Expected the job to be rescheduled: %v %v", rescheduled, err)
+	}
+
+	tasks, err := coord.Scheduler.Tasks().GetAllTasks()
+	if err != nil {
+		t.Fatalf("Failed to get tasks: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected a single task for the job, got %+v", tasks)
+	}
+	job := taskJob{}
+	if err := json.Unmarshal(tasks[0].Job, &job); err != nil {
+		t.Fatalf("Failed to parse task job: %v", err)
+	}
+	if job.Runner != runner.MATERIALIZE || job.Resource != id || string(job.Config) != `{"updated":true}` {
+		t.Fatalf("Unexpected task job: %+v", job)
+	}
+	triggers, err := coord.Scheduler.Tasks().GetAllTriggers()
+	if err != nil {
+		t.Fatalf("Failed to get triggers: %v", err)
+	}
+	if len(triggers) != 1 || triggers[0].Trigger.(scheduling.ScheduleTrigger).Schedule != "0 0 * * *" {
+		t.Fatalf("Expected the new schedule, got %+v", triggers)
+	}
+}
+
+func TestExecuteReturnsWhenStopped(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	coord := newScheduledTestCoordinator(t, &cronSpawner{release: release})
+	job, err := json.Marshal(taskJob{Runner: runner.MATERIALIZE, Config: []byte("{}")})
+	if err != nil {
+		t.Fatalf("Failed to serialize job: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- coord.Execute(ctx, scheduling.TaskMetadata{ID: 1, Job: job}, scheduling.TaskRunMetadata{ID: 1})
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Expected the stopped execution to return its context's error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected Execute to return once its context is done")
+	}
+	if err := coord.Execute(context.Background(), scheduling.TaskMetadata{ID: 1, Job: []byte("{")}, scheduling.TaskRunMetadata{ID: 1}); err == nil {
+		t.Fatalf("Expected a task with an invalid job to fail")
+	}
+}
+
+func TestRunJobRetriesAndTimesOut(t *testing.T) {
+	defer func(interval time.Duration) { runPollInterval = interval }(runPollInterval)
+	runPollInterval = 10 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	coord := newScheduledTestCoordinator(t, &plainSpawner{})
+	coord.JobRetries = scheduling.RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond}
+	coord.JobTimeout = 200 * time.Millisecond
+	var attempts int32
+	coord.jobFunctions = map[metadata.ResourceType]jobFunction{
+		metadata.FEATURE_VARIANT: func(metadata.ResourceID, string) error {
+			// The first attempt fails and the retry hangs.
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return errors.New("provider unavailable")
+			}
+			<-release
+			return nil
+		},
+	}
+	id := metadata.ResourceID{Name: "avg_transaction", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	err := coord.runJob(id, "")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected the job to time out, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("Expected the failed job to be retried once, got %d attempts", got)
+	}
+	runs, err := coord.Scheduler.Tasks().GetAllTaskRuns()
+	if err != nil {
+		t.Fatalf("Failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != scheduling.Failed || len(runs[0].Attempts) != 2 {
+		t.Fatalf("Expected a single failed run with two attempts, got %+v", runs)
+	}
+	if first := runs[0].Attempts[0]; first.Error != "provider unavailable" {
+		t.Fatalf("Expected the first attempt to fail with the job's error, got %+v", first)
+	}
+}
+
+func TestRunJobDoesNotRetryFailedResources(t *testing.T) {
+	defer func(interval time.Duration) { runPollInterval = interval }(runPollInterval)
+	runPollInterval = 10 * time.Millisecond
+	coord := newScheduledTestCoordinator(t, &plainSpawner{})
+	coord.JobRetries = scheduling.RetryPolicy{MaxAttempts: 3}
+	var attempts int32
+	coord.jobFunctions = map[metadata.ResourceType]jobFunction{
+		metadata.LABEL_VARIANT: func(id metadata.ResourceID, schedule string) error {
+			atomic.AddInt32(&attempts, 1)
+			return fferr.NewResourceAlreadyFailedError(id.Name, id.Variant, fferr.LABEL_VARIANT, nil)
+		},
+	}
+	id := metadata.ResourceID{Name: "is_fraud", Variant: "v1", Type: metadata.LABEL_VARIANT}
+	err := coord.runJob(id, "")
+	if _, isFailed := err.(*fferr.ResourceAlreadyFailedError); !isFailed {
+		t.Fatalf("Expected the job's own error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("Expected a resource that already failed not to be retried, got %d attempts", got)
+	}
+	// Running the job again starts a new run.
+	if err := coord.runJob(id, ""); err == nil {
+		t.Fatalf("Expected the job to fail again")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("Expected a second run, got %d attempts", got)
+	}
+}
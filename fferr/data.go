@@ -2,6 +2,7 @@ package fferr
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 )
@@ -229,3 +230,41 @@ func NewResourceChangedError(resourceName, resourceVariant string, resourceType
 type ResourceChangedError struct {
 	baseError
 }
+
+func NewResourceHasDependentsError(resourceName, resourceVariant string, resourceType ResourceType, dependents []string, err error) *ResourceHasDependentsError {
+	if err == nil {
+		err = fmt.Errorf("resource has dependents; delete them first or retry with cascade")
+	}
+	baseError := newBaseError(err, RESOURCE_HAS_DEPENDENTS, codes.FailedPrecondition)
+	baseError.AddDetail("resource_name", resourceName)
+	baseError.AddDetail("resource_variant", resourceVariant)
+	baseError.AddDetail("resource_type", string(resourceType))
+	baseError.AddDetail("dependents", strings.Join(dependents, ", "))
+
+	return &ResourceHasDependentsError{
+		baseError,
+	}
+}
+
+type ResourceHasDependentsError struct {
+	baseError
+}
+
+func NewSourceSchemaChangedError(resourceName, resourceVariant string, changes []string, err error) *SourceSchemaChangedError {
+	if err == nil {
+		err = fmt.Errorf("source columns that downstream resources depend on were dropped or changed type since the source was last registered")
+	}
+	baseError := newBaseError(err, SOURCE_SCHEMA_CHANGED, codes.FailedPrecondition)
+	baseError.AddDetail("resource_name", resourceName)
+	baseError.AddDetail("resource_variant", resourceVariant)
+	baseError.AddDetail("resource_type", string(SOURCE_VARIANT))
+	baseError.AddDetail("changes", strings.Join(changes, "; "))
+
+	return &SourceSchemaChangedError{
+		baseError,
+	}
+}
+
+type SourceSchemaChangedError struct {
+	baseError
+}
@@ -35,11 +35,16 @@ const (
 	INVALID_RESOURCE_NAME_VARIANT = "Invalid Resource Name Variant"
 	INVALID_FILE_TYPE             = "Invalid File Type"
 	RESOURCE_CHANGED              = "Resource Changed"
+	RESOURCE_HAS_DEPENDENTS       = "Resource Has Dependents"
+	SOURCE_SCHEMA_CHANGED         = "Source Schema Changed"
 	TYPE_ERROR                    = "Type Error"
 
 	// MISCELLANEOUS:
-	INTERNAL_ERROR   = "Internal Error"
-	INVALID_ARGUMENT = "Invalid Argument"
+	INTERNAL_ERROR    = "Internal Error"
+	INVALID_ARGUMENT  = "Invalid Argument"
+	UNAUTHENTICATED   = "Unauthenticated"
+	PERMISSION_DENIED = "Permission Denied"
+	UNSUPPORTED       = "Unsupported"
 
 	// JOBS:
 	JOB_DOES_NOT_EXIST        = "Job Does Not Exist"
@@ -40,10 +40,20 @@ func setErrorType(err baseError, errorType string) error {
 		return &InvalidFileTypeError{err}
 	case RESOURCE_CHANGED:
 		return &ResourceChangedError{err}
+	case RESOURCE_HAS_DEPENDENTS:
+		return &ResourceHasDependentsError{err}
+	case SOURCE_SCHEMA_CHANGED:
+		return &SourceSchemaChangedError{err}
 	case INTERNAL_ERROR:
 		return &InternalError{err}
 	case INVALID_ARGUMENT:
 		return &InvalidArgumentError{err}
+	case UNAUTHENTICATED:
+		return &UnauthenticatedError{err}
+	case PERMISSION_DENIED:
+		return &PermissionDeniedError{err}
+	case UNSUPPORTED:
+		return &UnsupportedError{err}
 
 	// JOBS:
 	case JOB_DOES_NOT_EXIST:
@@ -89,8 +99,13 @@ func TestNewError(t *testing.T) {
 		{"Invalid Resource Type Error", NewInvalidResourceTypeError("name", "variant", "type", fmt.Errorf("test error")), fmt.Errorf("test error"), INVALID_RESOURCE_TYPE, codes.InvalidArgument, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": "type"}}},
 		{"Invalid File Type Error", NewInvalidFileTypeError("parquet", fmt.Errorf("test error")), fmt.Errorf("test error"), INVALID_FILE_TYPE, codes.InvalidArgument, []map[string]string{{"extension": "parquet"}}},
 		{"Resource Changed Error", NewResourceChangedError("name", "variant", FEATURE_VARIANT, fmt.Errorf("test error")), fmt.Errorf("test error"), RESOURCE_CHANGED, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}}},
+		{"Resource Has Dependents Error", NewResourceHasDependentsError("name", "variant", FEATURE_VARIANT, []string{"dep1", "dep2"}, fmt.Errorf("test error")), fmt.Errorf("test error"), RESOURCE_HAS_DEPENDENTS, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}, {"dependents": "dep1, dep2"}}},
+		{"Source Schema Changed Error", NewSourceSchemaChangedError("name", "variant", []string{"change1", "change2"}, fmt.Errorf("test error")), fmt.Errorf("test error"), SOURCE_SCHEMA_CHANGED, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(SOURCE_VARIANT)}, {"changes": "change1; change2"}}},
 		{"Internal Error", NewInternalError(fmt.Errorf("test error")), fmt.Errorf("test error"), INTERNAL_ERROR, codes.Internal, []map[string]string{}},
 		{"Invalid Argument Error", NewInvalidArgumentError(fmt.Errorf("test error")), fmt.Errorf("test error"), INVALID_ARGUMENT, codes.InvalidArgument, []map[string]string{}},
+		{"Unauthenticated Error", NewUnauthenticatedError(fmt.Errorf("test error")), fmt.Errorf("test error"), UNAUTHENTICATED, codes.Unauthenticated, []map[string]string{}},
+		{"Permission Denied Error", NewPermissionDeniedError("user", "method", fmt.Errorf("test error")), fmt.Errorf("test error"), PERMISSION_DENIED, codes.PermissionDenied, []map[string]string{{"user": "user"}, {"method": "method"}}},
+		{"Unsupported Error", NewUnsupportedError(fmt.Errorf("test error")), fmt.Errorf("test error"), UNSUPPORTED, codes.Unimplemented, []map[string]string{}},
 		{"Job Already Exists Error", NewJobAlreadyExistsError("name", fmt.Errorf("test error")), fmt.Errorf("test error"), JOB_ALREADY_EXISTS, codes.AlreadyExists, []map[string]string{{"key": "name"}}},
 		{"Job Does Not Exist Error", NewJobDoesNotExistError("name", fmt.Errorf("test error")), fmt.Errorf("test error"), JOB_DOES_NOT_EXIST, codes.NotFound, []map[string]string{{"key": "name"}}},
 		{"Resource Already Complete Error", NewResourceAlreadyCompleteError("name", "variant", FEATURE_VARIANT, fmt.Errorf("test error")), fmt.Errorf("test error"), RESOURCE_ALREADY_COMPLETE, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}}},
@@ -141,8 +156,13 @@ func TestNewErrorEmptyInner(t *testing.T) {
 		{"Invalid Resource Type Error", NewInvalidResourceTypeError("name", "variant", "type", nil), fmt.Errorf("invalid resource type"), INVALID_RESOURCE_TYPE, codes.InvalidArgument, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": "type"}}},
 		{"Invalid File Type Error", NewInvalidFileTypeError("parquet", nil), fmt.Errorf("invalid filetype"), INVALID_FILE_TYPE, codes.InvalidArgument, []map[string]string{{"extension": "parquet"}}},
 		{"Resource Changed Error", NewResourceChangedError("name", "variant", FEATURE_VARIANT, nil), fmt.Errorf("a resource with the same name and variant already exists but differs from the one you're trying to create; use a different variant name or autogenerated variant name"), RESOURCE_CHANGED, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}}},
+		{"Resource Has Dependents Error", NewResourceHasDependentsError("name", "variant", FEATURE_VARIANT, []string{"dep"}, nil), fmt.Errorf("resource has dependents; delete them first or retry with cascade"), RESOURCE_HAS_DEPENDENTS, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}, {"dependents": "dep"}}},
+		{"Source Schema Changed Error", NewSourceSchemaChangedError("name", "variant", []string{"change"}, nil), fmt.Errorf("source columns that downstream resources depend on were dropped or changed type since the source was last registered"), SOURCE_SCHEMA_CHANGED, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(SOURCE_VARIANT)}, {"changes": "change"}}},
 		{"Internal Error", NewInternalError(nil), fmt.Errorf("internal"), INTERNAL_ERROR, codes.Internal, []map[string]string{}},
 		{"Invalid Argument Error", NewInvalidArgumentError(nil), fmt.Errorf("invalid argument"), INVALID_ARGUMENT, codes.InvalidArgument, []map[string]string{}},
+		{"Unauthenticated Error", NewUnauthenticatedError(nil), fmt.Errorf("request has no valid client certificate or bearer token"), UNAUTHENTICATED, codes.Unauthenticated, []map[string]string{}},
+		{"Permission Denied Error", NewPermissionDeniedError("user", "method", nil), fmt.Errorf("permission denied"), PERMISSION_DENIED, codes.PermissionDenied, []map[string]string{{"user": "user"}, {"method": "method"}}},
+		{"Unsupported Error", NewUnsupportedError(nil), fmt.Errorf("unsupported"), UNSUPPORTED, codes.Unimplemented, []map[string]string{}},
 		{"Job Already Exists Error", NewJobAlreadyExistsError("name", nil), fmt.Errorf("job already exists"), JOB_ALREADY_EXISTS, codes.AlreadyExists, []map[string]string{{"key": "name"}}},
 		{"Job Does Not Exist Error", NewJobDoesNotExistError("name", nil), fmt.Errorf("job does not exist"), JOB_DOES_NOT_EXIST, codes.NotFound, []map[string]string{{"key": "name"}}},
 		{"Resource Already Complete Error", NewResourceAlreadyCompleteError("name", "variant", FEATURE_VARIANT, nil), fmt.Errorf("resource already complete"), RESOURCE_ALREADY_COMPLETE, codes.FailedPrecondition, []map[string]string{{"resource_name": "name"}, {"resource_variant": "variant"}, {"resource_type": string(FEATURE_VARIANT)}}},
@@ -40,6 +40,54 @@ type InvalidArgumentError struct {
 	baseError
 }
 
+// NewUnsupportedError is returned when a provider doesn't implement an operation or option.
+func NewUnsupportedError(err error) *UnsupportedError {
+	if err == nil {
+		err = fmt.Errorf("unsupported")
+	}
+	baseError := newBaseError(err, UNSUPPORTED, codes.Unimplemented)
+
+	return &UnsupportedError{
+		baseError,
+	}
+}
+
+type UnsupportedError struct {
+	baseError
+}
+
+func NewUnauthenticatedError(err error) *UnauthenticatedError {
+	if err == nil {
+		err = fmt.Errorf("request has no valid client certificate or bearer token")
+	}
+	baseError := newBaseError(err, UNAUTHENTICATED, codes.Unauthenticated)
+
+	return &UnauthenticatedError{
+		baseError,
+	}
+}
+
+type UnauthenticatedError struct {
+	baseError
+}
+
+func NewPermissionDeniedError(user, method string, err error) *PermissionDeniedError {
+	if err == nil {
+		err = fmt.Errorf("permission denied")
+	}
+	baseError := newBaseError(err, PERMISSION_DENIED, codes.PermissionDenied)
+	baseError.AddDetail("user", user)
+	baseError.AddDetail("method", method)
+
+	return &PermissionDeniedError{
+		baseError,
+	}
+}
+
+type PermissionDeniedError struct {
+	baseError
+}
+
 // TODO: Consider moving to etcd.go
 func NewKeyNotFoundError(key string, err error) *KeyNotFoundError {
 	if err == nil {
@@ -0,0 +1,241 @@
+package helpers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+	// ForwardedUserHeader names the user a service is making a call on behalf of.
+	ForwardedUserHeader = "x-featureform-user"
+)
+
+// Identity is the authenticated caller of a gRPC method.
+type Identity struct {
+	Name string
+}
+
+type identityKey struct{}
+
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// TokenVerifier returns the name of the user a bearer token was issued to.
+type TokenVerifier interface {
+	VerifyToken(token string) (string, error)
+}
+
+// StaticTokens is a TokenVerifier backed by a fixed map from token to user name.
+type StaticTokens map[string]string
+
+func (tokens StaticTokens) VerifyToken(token string) (string, error) {
+	user, has := tokens[token]
+	if !has {
+		return "", fferr.NewUnauthenticatedError(fmt.Errorf("unknown bearer token"))
+	}
+	return user, nil
+}
+
+// LoadStaticTokens reads a JSON object mapping tokens to user names.
+func LoadStaticTokens(path string) (StaticTokens, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	tokens := make(StaticTokens)
+	if err := json.Unmarshal(file, &tokens); err != nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("failed to parse tokens file %s: %w", path, err))
+	}
+	return tokens, nil
+}
+
+// Authenticate identifies the caller of a gRPC method by the common name of its verified mTLS client
+// certificate or, failing that, by an "authorization: Bearer <token>" header. Bearer tokens are rejected
+// if tokens is nil.
+func Authenticate(ctx context.Context, tokens TokenVerifier) (Identity, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			for _, chain := range tlsInfo.State.VerifiedChains {
+				if len(chain) > 0 && chain[0].Subject.CommonName != "" {
+					return Identity{Name: chain[0].Subject.CommonName}, nil
+				}
+			}
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get(authorizationHeader) {
+		if !strings.HasPrefix(header, bearerPrefix) || tokens == nil {
+			continue
+		}
+		user, err := tokens.VerifyToken(strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			return Identity{}, err
+		}
+		return Identity{Name: user}, nil
+	}
+	return Identity{}, fferr.NewUnauthenticatedError(nil)
+}
+
+// ForwardedUser returns the user named in an incoming ForwardedUserHeader, if any. Only callers trusted to
+// act for others should be allowed to use it.
+func ForwardedUser(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if users := md.Get(ForwardedUserHeader); len(users) > 0 {
+		return users[0]
+	}
+	return ""
+}
+
+// ForwardUser names user in the ForwardedUserHeader of calls made with the returned context.
+func ForwardUser(ctx context.Context, user string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ForwardedUserHeader, user)
+}
+
+// BearerToken attaches an "authorization: Bearer <token>" header to every call made over a client connection.
+// Tokens are only sent over TLS unless AllowInsecure is set, e.g. within a cluster whose network is trusted.
+type BearerToken struct {
+	Token         string
+	AllowInsecure bool
+}
+
+func (token BearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: bearerPrefix + token.Token}, nil
+}
+
+func (token BearerToken) RequireTransportSecurity() bool {
+	return !token.AllowInsecure
+}
+
+// Authorizer decides whether the caller may make a request to a gRPC method. It returns the context to
+// handle the request with, e.g. with the caller's Identity attached. Streaming methods are authorized
+// before any message is received, so req is nil for them.
+type Authorizer interface {
+	Authorize(ctx context.Context, fullMethod string, req interface{}) (context.Context, error)
+}
+
+// UnaryServerAuthInterceptor rejects unary calls that auth doesn't allow.
+func UnaryServerAuthInterceptor(auth Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := auth.Authorize(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// StreamServerAuthInterceptor rejects streaming calls that auth doesn't allow.
+func StreamServerAuthInterceptor(auth Authorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := auth.Authorize(ss.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fferr.NewInternalError(fmt.Errorf("no certificates found in %s", caFile))
+	}
+	return pool, nil
+}
+
+// ServerTLSCredentials serves with the given certificate and, if clientCAFile is set, verifies client
+// certificates signed by it so that callers can be identified by them. Clients without a certificate can
+// still connect and authenticate with a bearer token.
+func ServerTLSCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		pool, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ServerTLSFromEnv returns the credentials set by FEATUREFORM_TLS_CERT, FEATUREFORM_TLS_KEY and
+// FEATUREFORM_TLS_CLIENT_CA, or nil if the server should run without TLS.
+func ServerTLSFromEnv() (credentials.TransportCredentials, error) {
+	certFile := GetEnv("FEATUREFORM_TLS_CERT", "")
+	if certFile == "" {
+		return nil, nil
+	}
+	return ServerTLSCredentials(certFile, GetEnv("FEATUREFORM_TLS_KEY", ""), GetEnv("FEATUREFORM_TLS_CLIENT_CA", ""))
+}
+
+// ClientDialOptionsFromEnv returns how a service should authenticate to another. If FEATUREFORM_TLS_CA is
+// set, servers are verified against it and the certificate in FEATUREFORM_TLS_CLIENT_CERT and
+// FEATUREFORM_TLS_CLIENT_KEY, if any, is presented. FEATUREFORM_AUTH_TOKEN is sent as a bearer token, which
+// needs TLS unless FEATUREFORM_AUTH_TOKEN_INSECURE is true.
+func ClientDialOptionsFromEnv() ([]grpc.DialOption, error) {
+	transport := insecure.NewCredentials()
+	secure := false
+	if caFile := GetEnv("FEATUREFORM_TLS_CA", ""); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		if certFile := GetEnv("FEATUREFORM_TLS_CLIENT_CERT", ""); certFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, GetEnv("FEATUREFORM_TLS_CLIENT_KEY", ""))
+			if err != nil {
+				return nil, fferr.NewInternalError(err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		transport = credentials.NewTLS(config)
+		secure = true
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transport)}
+	if token := GetEnv("FEATUREFORM_AUTH_TOKEN", ""); token != "" {
+		allowInsecure := GetEnvBool("FEATUREFORM_AUTH_TOKEN_INSECURE", false)
+		if !secure && !allowInsecure {
+			return nil, fferr.NewInternalError(fmt.Errorf("FEATUREFORM_AUTH_TOKEN is set without TLS: set FEATUREFORM_TLS_CA, or FEATUREFORM_AUTH_TOKEN_INSECURE to send it in plaintext"))
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(BearerToken{Token: token, AllowInsecure: allowInsecure}))
+	}
+	return opts, nil
+}
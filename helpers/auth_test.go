@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/featureform/fferr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthenticate(t *testing.T) {
+	tokens := StaticTokens{"secret": "alice"}
+	bearer := func(header string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, header))
+	}
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		tokens  TokenVerifier
+		want    string
+		wantErr bool
+	}{
+		{"Bearer Token", bearer("Bearer secret"), tokens, "alice", false},
+		{"Unknown Token", bearer("Bearer wrong"), tokens, "", true},
+		{"Not Bearer", bearer("Basic secret"), tokens, "", true},
+		{"Tokens Disabled", bearer("Bearer secret"), nil, "", true},
+		{"No Credentials", context.Background(), tokens, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := Authenticate(tt.ctx, tt.tokens)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var unauthenticated *fferr.UnauthenticatedError
+			if err != nil && !errors.As(err, &unauthenticated) {
+				t.Fatalf("Expected an unauthenticated error, got %T", err)
+			}
+			if identity.Name != tt.want {
+				t.Fatalf("Authenticate() = %s, want %s", identity.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardUser(t *testing.T) {
+	outgoing := ForwardUser(context.Background(), "bob")
+	md, _ := metadata.FromOutgoingContext(outgoing)
+	incoming := metadata.NewIncomingContext(context.Background(), md)
+	if user := ForwardedUser(incoming); user != "bob" {
+		t.Fatalf("Expected forwarded user bob, got %s", user)
+	}
+	if user := ForwardedUser(context.Background()); user != "" {
+		t.Fatalf("Expected no forwarded user, got %s", user)
+	}
+}
+
+type nameAuthorizer string
+
+func (allowed nameAuthorizer) Authorize(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	identity, err := Authenticate(ctx, StaticTokens{"token": string(allowed)})
+	if err != nil {
+		return nil, err
+	}
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+func TestUnaryServerAuthInterceptor(t *testing.T) {
+	interceptor := UnaryServerAuthInterceptor(nameAuthorizer("alice"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ := IdentityFromContext(ctx)
+		return identity.Name, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer token"))
+	resp, err := interceptor(ctx, nil, info, handler)
+	if err != nil {
+		t.Fatalf("Expected call to be allowed: %s", err)
+	}
+	if resp != "alice" {
+		t.Fatalf("Expected handler to see identity alice, got %v", resp)
+	}
+	if _, err := interceptor(context.Background(), nil, info, handler); err == nil {
+		t.Fatalf("Expected call without credentials to be rejected")
+	}
+}
+
+func TestClientDialOptionsFromEnv(t *testing.T) {
+	t.Setenv("FEATUREFORM_TLS_CA", "")
+	t.Setenv("FEATUREFORM_AUTH_TOKEN", "token")
+	t.Setenv("FEATUREFORM_AUTH_TOKEN_INSECURE", "false")
+	if _, err := ClientDialOptionsFromEnv(); err == nil {
+		t.Fatalf("Expected a bearer token without TLS to be rejected")
+	}
+	t.Setenv("FEATUREFORM_AUTH_TOKEN_INSECURE", "true")
+	if _, err := ClientDialOptionsFromEnv(); err != nil {
+		t.Fatalf("Expected a bearer token without TLS to be allowed once opted in: %s", err)
+	}
+}
+
+func TestBearerTokenRequiresTransportSecurity(t *testing.T) {
+	if !(BearerToken{Token: "token"}).RequireTransportSecurity() {
+		t.Fatalf("Expected bearer tokens to require TLS by default")
+	}
+	if (BearerToken{Token: "token", AllowInsecure: true}).RequireTransportSecurity() {
+		t.Fatalf("Expected insecure bearer tokens not to require TLS")
+	}
+}
@@ -0,0 +1,154 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/featureform/fferr"
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltMetadataBucket = []byte("metadata")
+
+// BoltStorage stores resources and jobs in a local bbolt file. It lets single node
+// deployments keep their metadata across restarts without running etcd.
+type BoltStorage struct {
+	DB *bolt.DB
+}
+
+// OpenBoltStorage opens or creates the bbolt file at path. bbolt holds an exclusive
+// lock on the file until it's closed, so only one process can use it at a time.
+func OpenBoltStorage(path string) (BoltStorage, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second * 5})
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("path", path)
+		return BoltStorage{}, wrapped
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltMetadataBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("path", path)
+		return BoltStorage{}, wrapped
+	}
+	return BoltStorage{DB: db}, nil
+}
+
+func (s BoltStorage) Close() error {
+	if err := s.DB.Close(); err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func (s BoltStorage) Put(key string, value string) error {
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltMetadataBucket).Put([]byte(key), []byte(value))
+	})
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return wrapped
+	}
+	return nil
+}
+
+// Deletes a single key. Deleting a key that doesn't exist is not an error
+func (s BoltStorage) Delete(key string) error {
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltMetadataBucket).Delete([]byte(key))
+	})
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return wrapped
+	}
+	return nil
+}
+
+func (s BoltStorage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("key cannot be empty"))
+	}
+	var value []byte
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		// Values are only valid for the life of the transaction so they have to be copied out.
+		if v := tx.Bucket(boltMetadataBucket).Get([]byte(key)); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	if value == nil {
+		return nil, fferr.NewKeyNotFoundError(key, fmt.Errorf("key not found in bolt"))
+	}
+	return value, nil
+}
+
+// Gets all values with a key starting with the 'key' argument.
+// All stored values can be retrieved using an empty string as the 'key'
+func (s BoltStorage) GetWithPrefix(key string) ([][]byte, error) {
+	values := make([][]byte, 0)
+	err := s.forEachWithPrefix(key, func(v []byte) {
+		values = append(values, append([]byte{}, v...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (s BoltStorage) GetCountWithPrefix(key string) (int64, error) {
+	var count int64
+	err := s.forEachWithPrefix(key, func([]byte) {
+		count++
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s BoltStorage) forEachWithPrefix(key string, fn func([]byte)) error {
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltMetadataBucket).Cursor()
+		prefix := []byte(key)
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			fn(v)
+		}
+		return nil
+	})
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return wrapped
+	}
+	return nil
+}
+
+// BoltStorageProvider persists metadata to the bbolt file at Path.
+type BoltStorageProvider struct {
+	Path string
+}
+
+func (sp BoltStorageProvider) GetResourceLookup() (ResourceLookup, error) {
+	storage, err := OpenBoltStorage(sp.Path)
+	if err != nil {
+		return nil, err
+	}
+	lookup := EtcdResourceLookup{
+		Connection: storage,
+	}
+	return lookup, nil
+}
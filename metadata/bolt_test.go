@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/featureform/fferr"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/provider/types"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestBoltResourceLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	id := ResourceID{Name: "feature", Variant: "v1", Type: FEATURE_VARIANT}
+	res := &featureVariantResource{&pb.FeatureVariant{
+		Name:    "feature",
+		Variant: "v1",
+		Type:    types.Float32.ToProto(),
+		Created: tspb.Now(),
+	}}
+
+	lookup, err := BoltStorageProvider{Path: path}.GetResourceLookup()
+	if err != nil {
+		t.Fatalf("Failed to open lookup: %v", err)
+	}
+	if err := lookup.Set(id, res); err != nil {
+		t.Fatalf("Failed to set resource: %v", err)
+	}
+	if err := lookup.SetJob(id, ""); err != nil {
+		t.Fatalf("Failed to set job: %v", err)
+	}
+	if err := lookup.(EtcdResourceLookup).Connection.(BoltStorage).Close(); err != nil {
+		t.Fatalf("Failed to close lookup: %v", err)
+	}
+
+	// The resource should survive reopening the file.
+	lookup, err = BoltStorageProvider{Path: path}.GetResourceLookup()
+	if err != nil {
+		t.Fatalf("Failed to reopen lookup: %v", err)
+	}
+	defer lookup.(EtcdResourceLookup).Connection.(BoltStorage).Close()
+	found, err := lookup.Lookup(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to lookup resource: %v", err)
+	}
+	if found.ID() != id {
+		t.Fatalf("Expected %v, got %v", id, found.ID())
+	}
+	if has, err := lookup.HasJob(id); err != nil || !has {
+		t.Fatalf("Expected job to be set: %v %v", has, err)
+	}
+	variants, err := lookup.ListForType(FEATURE_VARIANT)
+	if err != nil {
+		t.Fatalf("Failed to list resources: %v", err)
+	}
+	if len(variants) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(variants))
+	}
+
+	if err := lookup.Delete(id); err != nil {
+		t.Fatalf("Failed to delete resource: %v", err)
+	}
+	if has, err := lookup.Has(id); err != nil || has {
+		t.Fatalf("Expected resource to be deleted: %v %v", has, err)
+	}
+	if _, err := lookup.Lookup(context.Background(), id); err == nil {
+		t.Fatalf("Expected lookup of deleted resource to fail")
+	} else if _, ok := err.(*fferr.KeyNotFoundError); !ok {
+		t.Fatalf("Expected KeyNotFoundError, got %T: %v", err, err)
+	}
+}
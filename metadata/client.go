@@ -12,14 +12,15 @@ import (
 	"time"
 
 	"github.com/featureform/fferr"
+	help "github.com/featureform/helpers"
 	"github.com/featureform/logging"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/provider/types"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	grpc_status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -134,6 +135,53 @@ func (client *Client) SetStatus(ctx context.Context, resID ResourceID, status Re
 	return err
 }
 
+func (client *Client) SetSourceSchema(ctx context.Context, id NameVariant, columns []SourceColumn) error {
+	schema := &pb.SourceSchema{Columns: make([]*pb.SourceColumn, len(columns))}
+	for i, column := range columns {
+		schema.Columns[i] = &pb.SourceColumn{Name: column.Name, Type: column.Type}
+	}
+	req := &pb.SetSourceSchemaRequest{Source: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, Schema: schema}
+	_, err := client.GrpcConn.SetSourceSchema(ctx, req)
+	return err
+}
+
+// SetUserAccess assigns a user's role and teams, creating the user if it doesn't exist.
+func (client *Client) SetUserAccess(ctx context.Context, user string, role Role, teams []string) error {
+	req := &pb.UserAccessRequest{
+		User:      user,
+		Role:      pb.Role(role),
+		Teams:     teams,
+		RequestId: logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.SetUserAccess(ctx, req)
+	return err
+}
+
+// GetLineage returns the resources upstream and downstream of id, following at most depth edges each way.
+// A depth of zero or less returns everything connected to id.
+func (client *Client) GetLineage(ctx context.Context, id ResourceID, depth int) (*Lineage, error) {
+	req := &pb.LineageRequest{
+		Resource:  &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Depth:     int32(depth),
+		RequestId: logging.GetRequestIDFromContext(ctx),
+	}
+	resp, err := client.GrpcConn.GetLineage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lineage := &Lineage{
+		Nodes: make([]LineageNode, len(resp.GetNodes())),
+		Edges: make([]LineageEdge, len(resp.GetEdges())),
+	}
+	for i, node := range resp.GetNodes() {
+		lineage.Nodes[i] = lineageNodeFromProto(node)
+	}
+	for i, edge := range resp.GetEdges() {
+		lineage.Edges[i] = lineageEdgeFromProto(edge)
+	}
+	return lineage, nil
+}
+
 func (client *Client) CreateAll(ctx context.Context, defs []ResourceDef) error {
 	for _, def := range defs {
 		if err := client.Create(ctx, def); err != nil {
@@ -250,6 +298,10 @@ type FeatureDef struct {
 	IsOnDemand  bool
 	Definition  string
 	Type        types.ValueType
+	// TTL is how long a served value stays fresh. Zero means values never expire.
+	TTL time.Duration
+	// ValueCache configures the feature server's cache of served values. The zero value disables it.
+	ValueCache ValueCacheConfig
 }
 
 type ResourceVariantColumns struct {
@@ -291,6 +343,44 @@ func (p PythonFunction) SerializePythonFunction() *pb.FeatureVariant_Function {
 	}
 }
 
+// ValueCacheConfig bounds the feature server's in-process cache of a feature variant's values.
+type ValueCacheConfig struct {
+	MaxEntries int
+	// TTL is how long a cached value is served for. Zero means values are cached until they're
+	// evicted or the feature variant is rematerialized.
+	TTL time.Duration
+}
+
+func (c ValueCacheConfig) Serialize() *pb.ValueCacheConfig {
+	serialized := &pb.ValueCacheConfig{
+		MaxEntries: int64(c.MaxEntries),
+	}
+	if c.TTL > 0 {
+		serialized.Ttl = durationpb.New(c.TTL)
+	}
+	return serialized
+}
+
+// Expression is the location of a SERVER_COMPUTED feature. The feature server evaluates the CEL
+// expression at request time with each of the Inputs' online values bound to its key.
+type Expression struct {
+	Expression string
+	Inputs     map[string]NameVariant
+}
+
+func (e Expression) SerializeExpression() *pb.FeatureVariant_Expression {
+	inputs := make(map[string]*pb.NameVariant, len(e.Inputs))
+	for name, input := range e.Inputs {
+		inputs[name] = input.Serialize()
+	}
+	return &pb.FeatureVariant_Expression{
+		Expression: &pb.Expression{
+			Expression: e.Expression,
+			Inputs:     inputs,
+		},
+	}
+}
+
 func (def FeatureDef) ResourceType() ResourceType {
 	return FEATURE_VARIANT
 }
@@ -320,12 +410,20 @@ func (def FeatureDef) Serialize(requestID string) (*pb.FeatureVariantRequest, er
 		},
 		RequestId: requestID,
 	}
+	if def.TTL > 0 {
+		serialized.FeatureVariant.Ttl = durationpb.New(def.TTL)
+	}
+	if def.ValueCache.MaxEntries > 0 {
+		serialized.FeatureVariant.ValueCache = def.ValueCache.Serialize()
+	}
 
 	switch x := def.Location.(type) {
 	case ResourceVariantColumns:
 		serialized.FeatureVariant.Location = def.Location.(ResourceVariantColumns).SerializeFeatureColumns()
 	case PythonFunction:
 		serialized.FeatureVariant.Location = def.Location.(PythonFunction).SerializePythonFunction()
+	case Expression:
+		serialized.FeatureVariant.Location = x.SerializeExpression()
 	case nil:
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("FeatureDef Columns not set"))
 	default:
@@ -344,6 +442,29 @@ func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef)
 	return err
 }
 
+func (client *Client) DeleteFeatureVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteFeatureVariant(ctx, req)
+	return err
+}
+
+// ArchiveFeatureVariant keeps the variant and its data but marks it as ARCHIVED. With cascade, the variants that
+// depend on it are archived too.
+func (client *Client) ArchiveFeatureVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		Archive:     true,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteFeatureVariant(ctx, req)
+	return err
+}
+
 type featureStream interface {
 	Recv() (*pb.Feature, error)
 }
@@ -480,6 +601,29 @@ func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) erro
 	return err
 }
 
+func (client *Client) DeleteLabelVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteLabelVariant(ctx, req)
+	return err
+}
+
+// ArchiveLabelVariant keeps the variant and its data but marks it as ARCHIVED. With cascade, the variants that
+// depend on it are archived too.
+func (client *Client) ArchiveLabelVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		Archive:     true,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteLabelVariant(ctx, req)
+	return err
+}
+
 func (client *Client) GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error) {
 	logger := logging.GetLoggerFromContext(ctx)
 	stream, err := client.GrpcConn.GetLabelVariants(ctx)
@@ -591,6 +735,27 @@ type TrainingSetDef struct {
 	Features    NameVariants
 	Tags        Tags
 	Properties  Properties
+	// StartTime and EndTime bound the label timestamps included in the training set to
+	// [StartTime, EndTime). A zero value leaves that side unbounded.
+	StartTime    time.Time
+	EndTime      time.Time
+	EntityFilter *TrainingSetEntityFilter
+}
+
+// TrainingSetEntityFilter restricts a training set to the entities found in a column of a source.
+type TrainingSetEntityFilter struct {
+	Source       NameVariant
+	EntityColumn string
+}
+
+func (filter *TrainingSetEntityFilter) Serialize() *pb.TrainingSetEntityFilter {
+	if filter == nil {
+		return nil
+	}
+	return &pb.TrainingSetEntityFilter{
+		Source:       filter.Source.Serialize(),
+		EntityColumn: filter.EntityColumn,
+	}
 }
 
 func (def TrainingSetDef) ResourceType() ResourceType {
@@ -600,23 +765,34 @@ func (def TrainingSetDef) ResourceType() ResourceType {
 func (def TrainingSetDef) Serialize(requestID string) *pb.TrainingSetVariantRequest {
 	return &pb.TrainingSetVariantRequest{
 		TrainingSetVariant: &pb.TrainingSetVariant{
-			Name:        def.Name,
-			Variant:     def.Variant,
-			Description: def.Description,
-			Owner:       def.Owner,
-			Provider:    def.Provider,
-			Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
-			Label:       def.Label.Serialize(),
-			Features:    def.Features.Serialize(),
-			Schedule:    def.Schedule,
-			Tags:        &pb.Tags{Tag: def.Tags},
-			Properties:  def.Properties.Serialize(),
+			Name:         def.Name,
+			Variant:      def.Variant,
+			Description:  def.Description,
+			Owner:        def.Owner,
+			Provider:     def.Provider,
+			Status:       &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+			Label:        def.Label.Serialize(),
+			Features:     def.Features.Serialize(),
+			Schedule:     def.Schedule,
+			Tags:         &pb.Tags{Tag: def.Tags},
+			Properties:   def.Properties.Serialize(),
+			StartTime:    serializeOptionalTime(def.StartTime),
+			EndTime:      serializeOptionalTime(def.EndTime),
+			EntityFilter: def.EntityFilter.Serialize(),
 		},
 		RequestId: requestID,
 	}
 
 }
 
+// serializeOptionalTime leaves zero times unset so they can be told apart from the Unix epoch.
+func serializeOptionalTime(t time.Time) *tspb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return tspb.New(t)
+}
+
 func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
 	requestID := logging.GetRequestIDFromContext(ctx)
 	serialized := def.Serialize(requestID)
@@ -624,6 +800,29 @@ func (client *Client) CreateTrainingSetVariant(ctx context.Context, def Training
 	return err
 }
 
+func (client *Client) DeleteTrainingSetVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteTrainingSetVariant(ctx, req)
+	return err
+}
+
+// ArchiveTrainingSetVariant keeps the variant and its data but marks it as ARCHIVED. With cascade, the variants that
+// depend on it are archived too.
+func (client *Client) ArchiveTrainingSetVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		Archive:     true,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteTrainingSetVariant(ctx, req)
+	return err
+}
+
 func (client *Client) GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error) {
 	variants, err := client.GetTrainingSetVariants(ctx, []NameVariant{id})
 	if err != nil {
@@ -872,6 +1071,29 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 	return err
 }
 
+func (client *Client) DeleteSourceVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteSourceVariant(ctx, req)
+	return err
+}
+
+// ArchiveSourceVariant keeps the variant and its data but marks it as ARCHIVED. With cascade, the variants that
+// depend on it are archived too.
+func (client *Client) ArchiveSourceVariant(ctx context.Context, id NameVariant, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: id.Serialize(),
+		Cascade:     cascade,
+		Archive:     true,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteSourceVariant(ctx, req)
+	return err
+}
+
 func (client *Client) GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error) {
 	logger := logging.GetLoggerFromContext(ctx)
 	stream, err := client.GrpcConn.GetSourceVariants(ctx)
@@ -1273,6 +1495,16 @@ func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
 	return err
 }
 
+func (client *Client) DeleteModel(ctx context.Context, model string, cascade bool) error {
+	req := &pb.DeleteRequest{
+		NameVariant: &pb.NameVariant{Name: model},
+		Cascade:     cascade,
+		RequestId:   logging.GetRequestIDFromContext(ctx),
+	}
+	_, err := client.GrpcConn.DeleteModel(ctx, req)
+	return err
+}
+
 type modelStream interface {
 	Recv() (*pb.Model, error)
 }
@@ -1608,6 +1840,30 @@ func (variant *FeatureVariant) ToShallowMap() FeatureVariantResource {
 			IsOnDemand:  variant.IsOnDemand(),
 			Definition:  variant.Definition(),
 		}
+	case SERVER_COMPUTED:
+		location := make(map[string]string)
+		if expr, ok := variant.LocationExpression().(Expression); ok {
+			location["expression"] = expr.Expression
+			for name, input := range expr.Inputs {
+				location[name] = input.ClientString()
+			}
+		}
+		fv = FeatureVariantResource{
+			Created:     variant.Created(),
+			Description: variant.Description(),
+			Entity:      variant.Entity(),
+			Name:        variant.Name(),
+			DataType:    typeString(variant),
+			Variant:     variant.Variant(),
+			Owner:       variant.Owner(),
+			Location:    location,
+			Status:      variant.Status().String(),
+			Error:       variant.Error(),
+			Tags:        variant.Tags(),
+			Properties:  variant.Properties(),
+			Mode:        variant.Mode().String(),
+			IsOnDemand:  variant.IsOnDemand(),
+		}
 	default:
 		fmt.Printf("Unknown computation mode %v\n", variant.Mode())
 	}
@@ -1692,6 +1948,21 @@ func (variant *FeatureVariant) LocationFunction() interface{} {
 	return function
 }
 
+func (variant *FeatureVariant) LocationExpression() interface{} {
+	if variant.Mode() != SERVER_COMPUTED {
+		return nil
+	}
+	src := variant.serialized.GetExpression()
+	inputs := make(map[string]NameVariant, len(src.GetInputs()))
+	for name, input := range src.GetInputs() {
+		inputs[name] = parseNameVariant(input)
+	}
+	return Expression{
+		Expression: src.GetExpression(),
+		Inputs:     inputs,
+	}
+}
+
 func (variant *FeatureVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
@@ -1700,6 +1971,21 @@ func (variant *FeatureVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
 
+// TTL returns how long a served value stays fresh. Zero means values never expire.
+func (variant *FeatureVariant) TTL() time.Duration {
+	return variant.serialized.GetTtl().AsDuration()
+}
+
+// ValueCache returns how the feature server caches the variant's values. MaxEntries is zero if
+// values aren't cached.
+func (variant *FeatureVariant) ValueCache() ValueCacheConfig {
+	cache := variant.serialized.GetValueCache()
+	return ValueCacheConfig{
+		MaxEntries: int(cache.GetMaxEntries()),
+		TTL:        cache.GetTtl().AsDuration(),
+	}
+}
+
 func (variant *FeatureVariant) Mode() ComputationMode {
 	return ComputationMode(variant.serialized.GetMode())
 }
@@ -1708,7 +1994,7 @@ func (variant *FeatureVariant) IsOnDemand() bool {
 	switch variant.Mode() {
 	case PRECOMPUTED:
 		return false
-	case CLIENT_COMPUTED:
+	case CLIENT_COMPUTED, SERVER_COMPUTED:
 		return true
 	default:
 		fmt.Printf("Unknown computation mode: %v\n", variant.Mode())
@@ -1792,6 +2078,14 @@ func (user *User) Properties() Properties {
 	return user.fetchPropertiesFn.Properties()
 }
 
+func (user *User) Role() Role {
+	return Role(user.serialized.GetRole())
+}
+
+func (user *User) Teams() []string {
+	return user.serialized.GetTeams()
+}
+
 type Provider struct {
 	serialized *pb.Provider
 	fetchTrainingSetsFns
@@ -1844,6 +2138,12 @@ func (provider *Provider) SerializedConfig() []byte {
 	return provider.serialized.GetSerializedConfig()
 }
 
+// RedactedConfig returns the provider's config with its secrets redacted, as in exported definitions.
+// Secret references are kept as they are, unresolved.
+func (provider *Provider) RedactedConfig() interface{} {
+	return redactConfig(provider.SerializedConfig())
+}
+
 func (provider *Provider) Status() ResourceStatus {
 	if provider.serialized.GetStatus() != nil {
 		return ResourceStatus(provider.serialized.GetStatus().Status)
@@ -2137,6 +2437,33 @@ func (variant *TrainingSetVariant) LagFeatures() []*pb.FeatureLag {
 	return variant.serialized.GetFeatureLags()
 }
 
+// StartTime returns the inclusive lower bound on label timestamps, or a zero time if there isn't one.
+func (variant *TrainingSetVariant) StartTime() time.Time {
+	if variant.serialized.GetStartTime() == nil {
+		return time.Time{}
+	}
+	return variant.serialized.GetStartTime().AsTime()
+}
+
+// EndTime returns the exclusive upper bound on label timestamps, or a zero time if there isn't one.
+func (variant *TrainingSetVariant) EndTime() time.Time {
+	if variant.serialized.GetEndTime() == nil {
+		return time.Time{}
+	}
+	return variant.serialized.GetEndTime().AsTime()
+}
+
+func (variant *TrainingSetVariant) EntityFilter() *TrainingSetEntityFilter {
+	filter := variant.serialized.GetEntityFilter()
+	if filter == nil {
+		return nil
+	}
+	return &TrainingSetEntityFilter{
+		Source:       parseNameVariant(filter.GetSource()),
+		EntityColumn: filter.GetEntityColumn(),
+	}
+}
+
 func (variant *TrainingSetVariant) FetchLabel(client *Client, ctx context.Context) (*LabelVariant, error) {
 	labelList, err := client.GetLabelVariants(ctx, []NameVariant{variant.Label()})
 	if err != nil {
@@ -2426,6 +2753,25 @@ func (variant *SourceVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
 
+// Schema returns the columns the source's table had the last time it was registered, or nil if none were recorded.
+func (variant *SourceVariant) Schema() []SourceColumn {
+	columns := variant.serialized.GetSchema().GetColumns()
+	if len(columns) == 0 {
+		return nil
+	}
+	schema := make([]SourceColumn, len(columns))
+	for i, column := range columns {
+		schema[i] = SourceColumn{Name: column.GetName(), Type: column.GetType()}
+	}
+	return schema
+}
+
+// SourceColumn is a column of a source variant's table along with the type the offline store reported for it.
+type SourceColumn struct {
+	Name string
+	Type string
+}
+
 type Entity struct {
 	serialized *pb.Entity
 	fetchTrainingSetsFns
@@ -2483,11 +2829,12 @@ func (entity *Entity) Properties() Properties {
 }
 
 func NewClient(host string, logger logging.Logger) (*Client, error) {
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		// grpc.WithUnaryInterceptor(fferr.UnaryClientInterceptor()),
-		// grpc.WithStreamInterceptor(fferr.StreamClientInterceptor()),
+	opts, err := help.ClientDialOptionsFromEnv()
+	if err != nil {
+		return nil, err
 	}
+	// opts = append(opts, grpc.WithUnaryInterceptor(fferr.UnaryClientInterceptor()))
+	// opts = append(opts, grpc.WithStreamInterceptor(fferr.StreamClientInterceptor()))
 	conn, err := grpc.Dial(host, opts...)
 	if err != nil {
 		return nil, fferr.NewInternalError(err)
@@ -643,3 +643,39 @@ func TestTrainingSetVariant_ToShallowMap(t *testing.T) {
 		})
 	}
 }
+
+func TestTrainingSetDef_Filters(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	filter := &TrainingSetEntityFilter{
+		Source:       NameVariant{Name: "customers", Variant: "v1"},
+		EntityColumn: "customer_id",
+	}
+	def := TrainingSetDef{
+		Name:         "training-set",
+		Variant:      "v1",
+		Label:        NameVariant{Name: "label", Variant: "v1"},
+		Features:     NameVariants{{Name: "feature", Variant: "v1"}},
+		StartTime:    start,
+		EndTime:      end,
+		EntityFilter: filter,
+	}
+	variant := wrapProtoTrainingSetVariant(def.Serialize("").TrainingSetVariant)
+	if !variant.StartTime().Equal(start) {
+		t.Fatalf("Expected start time %s, got %s", start, variant.StartTime())
+	}
+	if !variant.EndTime().Equal(end) {
+		t.Fatalf("Expected end time %s, got %s", end, variant.EndTime())
+	}
+	if !reflect.DeepEqual(filter, variant.EntityFilter()) {
+		t.Fatalf("Expected entity filter %v, got %v", filter, variant.EntityFilter())
+	}
+
+	unfiltered := wrapProtoTrainingSetVariant(TrainingSetDef{Name: "training-set", Variant: "v2"}.Serialize("").TrainingSetVariant)
+	if !unfiltered.StartTime().IsZero() || !unfiltered.EndTime().IsZero() {
+		t.Fatalf("Expected unbounded time window, got [%s, %s)", unfiltered.StartTime(), unfiltered.EndTime())
+	}
+	if unfiltered.EntityFilter() != nil {
+		t.Fatalf("Expected no entity filter, got %v", unfiltered.EntityFilter())
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	filestore "github.com/featureform/filestore"
 	help "github.com/featureform/helpers"
@@ -26,6 +29,8 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var SearchClient search.Searcher
@@ -47,6 +52,16 @@ type MetadataServer struct {
 	client          *metadata.Client
 	logger          *zap.SugaredLogger
 	StorageProvider StorageProvider
+	// searchSync is set when search uses an embedded index.
+	searchSync *searchSync
+}
+
+// searchSync keeps an embedded search index up to date with metadata, as the metadata server only writes
+// to Meilisearch. Searches first sync the index if it was last synced more than maxAge ago.
+type searchSync struct {
+	mu     sync.Mutex
+	synced time.Time
+	maxAge time.Duration
 }
 
 func NewMetadataServer(logger *zap.SugaredLogger, client *metadata.Client, storageProvider *metadata.EtcdStorageProvider) (*MetadataServer, error) {
@@ -138,6 +153,7 @@ type ProviderResource struct {
 	ProviderType string                                           `json:"provider-type"`
 	Software     string                                           `json:"software"`
 	Team         string                                           `json:"team"`
+	Config       interface{}                                      `json:"config"`
 	Sources      map[string][]metadata.SourceVariantResource      `json:"sources"`
 	Features     map[string][]metadata.FeatureVariantResource     `json:"features"`
 	Labels       map[string][]metadata.LabelVariantResource       `json:"labels"`
@@ -589,6 +605,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			ProviderType: provider.Type(),
 			Software:     provider.Software(),
 			Team:         provider.Team(),
+			Config:       provider.RedactedConfig(),
 			Status:       provider.Status().String(),
 			Error:        provider.Error(),
 			Tags:         provider.Tags(),
@@ -820,6 +837,7 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Description:  provider.Description(),
 				Software:     provider.Software(),
 				Team:         provider.Team(),
+				Config:       provider.RedactedConfig(),
 				ProviderType: provider.Type(),
 				Status:       provider.Status().String(),
 				Tags:         provider.Tags(),
@@ -837,13 +855,24 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 
 }
 
+// GetSearch searches for resources matching q, optionally filtered by the type, owner, status and tag
+// query parameters. Every tag parameter given must be on a result.
 func (m *MetadataServer) GetSearch(c *gin.Context) {
-	query, ok := c.GetQuery("q")
-	if !ok {
-		c.JSON(500, "Missing query")
+	query := c.Query("q")
+	filter := search.Filter{
+		Type:   c.Query("type"),
+		Owner:  c.Query("owner"),
+		Status: c.Query("status"),
+		Tags:   c.QueryArray("tag"),
 	}
 
-	result, err := SearchClient.RunSearch(query)
+	if m.searchSync != nil {
+		if err := m.syncSearch(m.searchSync.maxAge); err != nil {
+			// Results may be stale, but searching is still better than failing.
+			m.logger.Errorw("Failed to sync search", "error", err)
+		}
+	}
+	result, err := SearchClient.RunSearchWithFilter(query, filter)
 	if err != nil {
 		m.logger.Errorw("Failed to fetch resources", "error", err)
 		c.JSON(500, "Failed to fetch resources")
@@ -934,6 +963,89 @@ func (m *MetadataServer) GetSourceData(c *gin.Context) {
 	c.JSON(200, response)
 }
 
+type LineageNodeResource struct {
+	Name    string `json:"name"`
+	Variant string `json:"variant"`
+	Type    string `json:"type"`
+	Column  string `json:"column,omitempty"`
+}
+
+type LineageEdgeResource struct {
+	Upstream   LineageNodeResource `json:"upstream"`
+	Downstream LineageNodeResource `json:"downstream"`
+	Type       string              `json:"type"`
+}
+
+type LineageResource struct {
+	Nodes []LineageNodeResource `json:"nodes"`
+	Edges []LineageEdgeResource `json:"edges"`
+}
+
+func lineageNodeResource(node metadata.LineageNode) LineageNodeResource {
+	return LineageNodeResource{
+		Name:    node.Name,
+		Variant: node.Variant,
+		Type:    node.Type.String(),
+		Column:  node.Column,
+	}
+}
+
+func lineageResource(lineage *metadata.Lineage) LineageResource {
+	resource := LineageResource{
+		Nodes: make([]LineageNodeResource, len(lineage.Nodes)),
+		Edges: make([]LineageEdgeResource, len(lineage.Edges)),
+	}
+	for i, node := range lineage.Nodes {
+		resource.Nodes[i] = lineageNodeResource(node)
+	}
+	for i, edge := range lineage.Edges {
+		resource.Edges[i] = LineageEdgeResource{
+			Upstream:   lineageNodeResource(edge.Upstream),
+			Downstream: lineageNodeResource(edge.Downstream),
+			Type:       edge.Type.String(),
+		}
+	}
+	return resource
+}
+
+// GetLineage returns the lineage graph around a resource. The variant and depth query parameters pick the
+// resource variant and how many edges to follow each way; without a depth, the whole graph is returned.
+func (m *MetadataServer) GetLineage(c *gin.Context) {
+	switch c.Param("type") {
+	case "features", "labels", "training-sets", "sources", "entities", "models":
+	default:
+		fetchError := &FetchError{StatusCode: 400, Type: fmt.Sprintf("GetLineage - Resource type %s has no lineage", c.Param("type"))}
+		m.logger.Errorw(fetchError.Error(), "Metadata error")
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil {
+		fetchError := &FetchError{StatusCode: 400, Type: "GetLineage - Could not parse the depth query parameter"}
+		m.logger.Errorw(fetchError.Error(), "Metadata error", err)
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	id := metadata.ResourceID{
+		Name:    c.Param("resource"),
+		Variant: c.Query("variant"),
+		Type:    getResourceType(c.Param("type")),
+	}
+	lineage, err := m.client.GetLineage(context.Background(), id, depth)
+	if status.Code(err) == codes.NotFound {
+		fetchError := &FetchError{StatusCode: 404, Type: fmt.Sprintf("GetLineage - Could not find %s %s (%s)", c.Param("type"), id.Name, id.Variant)}
+		m.logger.Errorw(fetchError.Error(), "Metadata error", err)
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	} else if err != nil {
+		fetchError := &FetchError{StatusCode: 500, Type: "lineage"}
+		m.logger.Errorw(fetchError.Error(), "Metadata error", err)
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	c.JSON(http.StatusOK, lineageResource(lineage))
+}
+
 func (m *MetadataServer) GetFeatureFileStats(c *gin.Context) {
 	// feature name and variant
 	name := c.Query("name")
@@ -1277,14 +1389,8 @@ func (m *MetadataServer) PostTags(c *gin.Context) {
 
 	m.lookup.Set(objID, foundResource)
 
-	updatedResource := search.ResourceDoc{
-		Name:    name,
-		Variant: variant,
-		Type:    resourceType.String(),
-		Tags:    requestBody.Tags,
-	}
-	// Update search index for Meilisearch
-	SearchClient.Upsert(updatedResource)
+	// Update search index
+	SearchClient.Upsert(metadata.SearchDocument(objID, foundResource))
 
 	c.JSON(http.StatusOK, TagResult{
 		Name:    name,
@@ -1356,11 +1462,37 @@ func replaceTags(resourceTypeParam string, currentResource metadata.Resource, ne
 	return nil
 }
 
+// syncSearch brings the embedded search index up to date unless it was synced less than maxAge ago.
+func (m *MetadataServer) syncSearch(maxAge time.Duration) error {
+	m.searchSync.mu.Lock()
+	defer m.searchSync.mu.Unlock()
+	if time.Since(m.searchSync.synced) < maxAge {
+		return nil
+	}
+	indexed, err := metadata.Reindex(SearchClient, m.lookup)
+	if err != nil {
+		return err
+	}
+	m.searchSync.synced = time.Now()
+	m.logger.Debugw("Synced search", "resources", indexed)
+	return nil
+}
+
+func (m *MetadataServer) syncSearchEvery(interval time.Duration) {
+	for {
+		if err := m.syncSearch(interval); err != nil {
+			m.logger.Errorw("Failed to sync search", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
 func (m *MetadataServer) Start(port string) {
 	router := gin.Default()
 	router.Use(cors.Default())
 	router.GET("/data/:type", m.GetMetadataList)
 	router.GET("/data/:type/:resource", m.GetMetadata)
+	router.GET("/data/:type/:resource/lineage", m.GetLineage)
 	router.GET("/data/search", m.GetSearch)
 	router.GET("/data/version", m.GetVersionMap)
 	router.GET("/data/sourcedata", m.GetSourceData)
@@ -1374,18 +1506,9 @@ func main() {
 	logger := zap.NewExample().Sugar()
 	metadataHost := help.GetEnv("METADATA_HOST", "localhost")
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
-	searchHost := help.GetEnv("MEILISEARCH_HOST", "localhost")
-	searchPort := help.GetEnv("MEILISEARCH_PORT", "7700")
-	searchEndpoint := fmt.Sprintf("http://%s:%s", searchHost, searchPort)
-	searchApiKey := help.GetEnv("MEILISEARCH_APIKEY", "xyz")
-	logger.Infof("Connecting to typesense at: %s\n", searchEndpoint)
-	sc, err := search.NewMeilisearch(&search.MeilisearchParams{
-		Host:   searchHost,
-		Port:   searchPort,
-		ApiKey: searchApiKey,
-	})
+	sc, err := search.NewSearcherFromEnv()
 	if err != nil {
-		logger.Panicw("Failed to create new meil search", err)
+		logger.Panicw("Failed to create searcher", "error", err)
 	}
 
 	SearchClient = sc
@@ -1410,6 +1533,20 @@ func main() {
 	if err != nil {
 		logger.Panicw("Failed to create server", "error", err)
 	}
+	// The metadata server only keeps Meilisearch up to date, so an embedded index is synced with metadata
+	// in the background and before searches.
+	if _, embedded := sc.(*search.Bleve); embedded {
+		interval, err := time.ParseDuration(help.GetEnv("SEARCH_REINDEX_INTERVAL", "1m"))
+		if err != nil {
+			logger.Panicw("Invalid SEARCH_REINDEX_INTERVAL", "error", err)
+		}
+		maxAge, err := time.ParseDuration(help.GetEnv("SEARCH_MAX_STALENESS", "5s"))
+		if err != nil {
+			logger.Panicw("Invalid SEARCH_MAX_STALENESS", "error", err)
+		}
+		metadataServer.searchSync = &searchSync{maxAge: maxAge}
+		go metadataServer.syncSearchEvery(interval)
+	}
 	metadataHTTPPort := help.GetEnv("METADATA_HTTP_PORT", "3001")
 	metadataServingPort := fmt.Sprintf(":%s", metadataHTTPPort)
 	logger.Infof("Serving HTTP Metadata on port: %s\n", metadataServingPort)
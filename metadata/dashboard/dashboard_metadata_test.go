@@ -2,20 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
 
+	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metadata/search"
 	"github.com/featureform/provider"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
 )
 
 func GetTestGinContext(mockRecorder *httptest.ResponseRecorder) *gin.Context {
@@ -273,3 +277,154 @@ func TestGetSourceFaultyOrNilGrpcClientPanic(t *testing.T) {
 
 	assert.Panics(t, didPanic)
 }
+
+func TestGetLineageBadRequest(t *testing.T) {
+	type testCase struct {
+		name         string
+		resourceType string
+		depth        string
+		expectedMsg  string
+	}
+	testCases := []testCase{
+		{"Unknown Type", "users", "1", "Error 400: Failed to fetch GetLineage - Resource type users has no lineage"},
+		{"Invalid Depth", "sources", "deep", "Error 400: Failed to fetch GetLineage - Could not parse the depth query parameter"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRecorder := httptest.NewRecorder()
+			ctx := GetTestGinContext(mockRecorder)
+			params := gin.Params{
+				{Key: "type", Value: tc.resourceType},
+				{Key: "resource", Value: "transactions"},
+			}
+			u := url.Values{}
+			u.Add("variant", "default")
+			u.Add("depth", tc.depth)
+			MockGetSourceGet(ctx, params, u)
+
+			serv := MetadataServer{
+				logger: zap.NewExample().Sugar(),
+			}
+			serv.GetLineage(ctx)
+
+			var actualErrorMsg string
+			json.Unmarshal(mockRecorder.Body.Bytes(), &actualErrorMsg)
+			assert.Equal(t, http.StatusBadRequest, mockRecorder.Code)
+			assert.Equal(t, tc.expectedMsg, actualErrorMsg)
+		})
+	}
+}
+
+// newTestMetadataClient starts a metadata server with local storage and connects to it.
+func newTestMetadataClient(t *testing.T) *metadata.Client {
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	metadataServ, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:          logger,
+		StorageProvider: metadata.LocalStorageProvider{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go metadataServ.ServeOnListener(lis)
+	t.Cleanup(func() { metadataServ.Stop() })
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create metadata client: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestGetLineageNotFound(t *testing.T) {
+	client := newTestMetadataClient(t)
+
+	mockRecorder := httptest.NewRecorder()
+	ctx := GetTestGinContext(mockRecorder)
+	params := gin.Params{
+		{Key: "type", Value: "sources"},
+		{Key: "resource", Value: "transactions"},
+	}
+	u := url.Values{}
+	u.Add("variant", "default")
+	MockGetSourceGet(ctx, params, u)
+
+	serv := MetadataServer{
+		client: client,
+		logger: zap.NewExample().Sugar(),
+	}
+	serv.GetLineage(ctx)
+
+	var actualErrorMsg string
+	json.Unmarshal(mockRecorder.Body.Bytes(), &actualErrorMsg)
+	assert.Equal(t, http.StatusNotFound, mockRecorder.Code)
+	assert.Equal(t, "Error 404: Failed to fetch GetLineage - Could not find sources transactions (default)", actualErrorMsg)
+}
+
+func TestGetProvidersRedactsConfig(t *testing.T) {
+	client := newTestMetadataClient(t)
+	config := []byte(`{"Addr": "localhost:6379", "Password": "hunter2", "Executor": {"SecretKey": "secret://env/FEATUREFORM_SECRET_REDIS_KEY"}}`)
+	if err := client.CreateProvider(context.Background(), metadata.ProviderDef{
+		Name:             "redis",
+		Type:             "REDIS_ONLINE",
+		Software:         "redis",
+		SerializedConfig: config,
+	}); err != nil {
+		t.Fatalf("Failed to create provider: %s", err)
+	}
+	serv := MetadataServer{client: client, logger: zap.NewExample().Sugar()}
+	expected := map[string]interface{}{
+		"Addr":     "localhost:6379",
+		"Password": metadata.RedactedSecret,
+		"Executor": map[string]interface{}{"SecretKey": "secret://env/FEATUREFORM_SECRET_REDIS_KEY"},
+	}
+
+	mockRecorder := httptest.NewRecorder()
+	ctx := GetTestGinContext(mockRecorder)
+	MockJsonGet(ctx, gin.Params{{Key: "type", Value: "providers"}})
+	serv.GetMetadataList(ctx)
+	var list []ProviderResource
+	if err := json.Unmarshal(mockRecorder.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to parse providers %s: %s", mockRecorder.Body.String(), err)
+	}
+	assert.Len(t, list, 1)
+	assert.Equal(t, expected, list[0].Config)
+
+	mockRecorder = httptest.NewRecorder()
+	ctx = GetTestGinContext(mockRecorder)
+	MockJsonGet(ctx, gin.Params{{Key: "type", Value: "providers"}, {Key: "resource", Value: "redis"}})
+	serv.GetMetadata(ctx)
+	var single ProviderResource
+	if err := json.Unmarshal(mockRecorder.Body.Bytes(), &single); err != nil {
+		t.Fatalf("Failed to parse provider %s: %s", mockRecorder.Body.String(), err)
+	}
+	assert.Equal(t, expected, single.Config)
+	assert.NotContains(t, mockRecorder.Body.String(), "hunter2")
+}
+
+func TestLineageResource(t *testing.T) {
+	source := metadata.LineageNode{ResourceID: metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}}
+	column := metadata.LineageNode{ResourceID: source.ResourceID, Column: "amount"}
+	feature := metadata.LineageNode{ResourceID: metadata.ResourceID{Name: "avg_amount", Variant: "default", Type: metadata.FEATURE_VARIANT}}
+	lineage := &metadata.Lineage{
+		Nodes: []metadata.LineageNode{source, column, feature},
+		Edges: []metadata.LineageEdge{
+			{Upstream: source, Downstream: feature, Type: metadata.DEPENDENCY_EDGE},
+			{Upstream: column, Downstream: feature, Type: metadata.COLUMN_EDGE},
+		},
+	}
+	sourceResource := LineageNodeResource{Name: "transactions", Variant: "default", Type: "SOURCE_VARIANT"}
+	columnResource := LineageNodeResource{Name: "transactions", Variant: "default", Type: "SOURCE_VARIANT", Column: "amount"}
+	featureResource := LineageNodeResource{Name: "avg_amount", Variant: "default", Type: "FEATURE_VARIANT"}
+	expected := LineageResource{
+		Nodes: []LineageNodeResource{sourceResource, columnResource, featureResource},
+		Edges: []LineageEdgeResource{
+			{Upstream: sourceResource, Downstream: featureResource, Type: "DEPENDENCY"},
+			{Upstream: columnResource, Downstream: featureResource, Type: "COLUMN"},
+		},
+	}
+	assert.Equal(t, expected, lineageResource(lineage))
+}
@@ -0,0 +1,1017 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/provider/secrets"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"sigs.k8s.io/yaml"
+)
+
+// RedactedSecret replaces the secrets in exported provider configs. When a definition is applied, redacted
+// values are filled in from the registered provider of the same name.
+const RedactedSecret = "<redacted>"
+
+// secretConfigKeys are the substrings of lowercased provider config keys, without underscores, that hold secrets.
+var secretConfigKeys = []string{"password", "passphrase", "secret", "token", "credential", "apikey", "accesskey", "accountkey", "privatekey", "connectionstring"}
+
+// Definitions are the resources registered in metadata in the form they're declared in. Fields that the
+// server manages, like statuses, timestamps and the lists of dependent resources, are left out.
+type Definitions struct {
+	Users        []*pb.User
+	Providers    []*pb.Provider
+	Entities     []*pb.Entity
+	Sources      []*pb.SourceVariant
+	Features     []*pb.FeatureVariant
+	Labels       []*pb.LabelVariant
+	TrainingSets []*pb.TrainingSetVariant
+	Models       []*pb.Model
+}
+
+// definitionsDocument is the YAML layout of Definitions, each resource being its proto's JSON form.
+type definitionsDocument struct {
+	Users        []json.RawMessage `json:"users,omitempty"`
+	Providers    []json.RawMessage `json:"providers,omitempty"`
+	Entities     []json.RawMessage `json:"entities,omitempty"`
+	Sources      []json.RawMessage `json:"sources,omitempty"`
+	Features     []json.RawMessage `json:"features,omitempty"`
+	Labels       []json.RawMessage `json:"labels,omitempty"`
+	TrainingSets []json.RawMessage `json:"training_sets,omitempty"`
+	Models       []json.RawMessage `json:"models,omitempty"`
+}
+
+// ExportDefinitions returns every registered resource, sorted by name and variant so exports diff cleanly.
+func (client *Client) ExportDefinitions(ctx context.Context) (*Definitions, error) {
+	registered, err := client.registeredDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defs := &Definitions{}
+	for _, resource := range registered.resources() {
+		declared, err := declaredProto(resource.Proto())
+		if err != nil {
+			return nil, err
+		}
+		defs.add(declared)
+	}
+	defs.sort()
+	return defs, nil
+}
+
+// registeredDefinitions returns every registered resource as it's stored, including server managed fields.
+func (client *Client) registeredDefinitions(ctx context.Context) (*Definitions, error) {
+	defs := &Definitions{}
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		defs.Users = append(defs.Users, user.serialized)
+	}
+	providers, err := client.ListProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		defs.Providers = append(defs.Providers, provider.serialized)
+	}
+	entities, err := client.ListEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		defs.Entities = append(defs.Entities, entity.serialized)
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sourceVariants, err := client.GetSourceVariants(ctx, allNameVariants(len(sources), func(i int) NameVariants { return sources[i].NameVariants() }))
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range sourceVariants {
+		defs.Sources = append(defs.Sources, variant.serialized)
+	}
+	features, err := client.ListFeatures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	featureVariants, err := client.GetFeatureVariants(ctx, allNameVariants(len(features), func(i int) NameVariants { return features[i].NameVariants() }))
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range featureVariants {
+		defs.Features = append(defs.Features, variant.serialized)
+	}
+	labels, err := client.ListLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labelVariants, err := client.GetLabelVariants(ctx, allNameVariants(len(labels), func(i int) NameVariants { return labels[i].NameVariants() }))
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range labelVariants {
+		defs.Labels = append(defs.Labels, variant.serialized)
+	}
+	trainingSets, err := client.ListTrainingSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trainingSetVariants, err := client.GetTrainingSetVariants(ctx, allNameVariants(len(trainingSets), func(i int) NameVariants { return trainingSets[i].NameVariants() }))
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range trainingSetVariants {
+		defs.TrainingSets = append(defs.TrainingSets, variant.serialized)
+	}
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		defs.Models = append(defs.Models, model.serialized)
+	}
+	return defs, nil
+}
+
+func allNameVariants(n int, variants func(i int) NameVariants) []NameVariant {
+	all := make([]NameVariant, 0, n)
+	for i := 0; i < n; i++ {
+		all = append(all, variants(i)...)
+	}
+	return all
+}
+
+func (defs *Definitions) add(msg proto.Message) {
+	switch casted := msg.(type) {
+	case *pb.User:
+		defs.Users = append(defs.Users, casted)
+	case *pb.Provider:
+		defs.Providers = append(defs.Providers, casted)
+	case *pb.Entity:
+		defs.Entities = append(defs.Entities, casted)
+	case *pb.SourceVariant:
+		defs.Sources = append(defs.Sources, casted)
+	case *pb.FeatureVariant:
+		defs.Features = append(defs.Features, casted)
+	case *pb.LabelVariant:
+		defs.Labels = append(defs.Labels, casted)
+	case *pb.TrainingSetVariant:
+		defs.TrainingSets = append(defs.TrainingSets, casted)
+	case *pb.Model:
+		defs.Models = append(defs.Models, casted)
+	}
+}
+
+func (defs *Definitions) sort() {
+	sort.Slice(defs.Users, func(i, j int) bool { return defs.Users[i].Name < defs.Users[j].Name })
+	sort.Slice(defs.Providers, func(i, j int) bool { return defs.Providers[i].Name < defs.Providers[j].Name })
+	sort.Slice(defs.Entities, func(i, j int) bool { return defs.Entities[i].Name < defs.Entities[j].Name })
+	sort.Slice(defs.Models, func(i, j int) bool { return defs.Models[i].Name < defs.Models[j].Name })
+	sort.Slice(defs.Sources, func(i, j int) bool {
+		return variantLess(defs.Sources[i].Name, defs.Sources[i].Variant, defs.Sources[j].Name, defs.Sources[j].Variant)
+	})
+	sort.Slice(defs.Features, func(i, j int) bool {
+		return variantLess(defs.Features[i].Name, defs.Features[i].Variant, defs.Features[j].Name, defs.Features[j].Variant)
+	})
+	sort.Slice(defs.Labels, func(i, j int) bool {
+		return variantLess(defs.Labels[i].Name, defs.Labels[i].Variant, defs.Labels[j].Name, defs.Labels[j].Variant)
+	})
+	sort.Slice(defs.TrainingSets, func(i, j int) bool {
+		return variantLess(defs.TrainingSets[i].Name, defs.TrainingSets[i].Variant, defs.TrainingSets[j].Name, defs.TrainingSets[j].Variant)
+	})
+}
+
+func variantLess(name, variant, otherName, otherVariant string) bool {
+	if name != otherName {
+		return name < otherName
+	}
+	return variant < otherVariant
+}
+
+// resources returns the definitions in the order they have to be created in: every resource comes after
+// the resources it depends on.
+func (defs *Definitions) resources() []Resource {
+	resources := make([]Resource, 0)
+	for _, user := range defs.Users {
+		resources = append(resources, &userResource{user})
+	}
+	for _, provider := range defs.Providers {
+		resources = append(resources, &providerResource{provider})
+	}
+	for _, entity := range defs.Entities {
+		resources = append(resources, &entityResource{entity})
+	}
+	sources := make([]Resource, len(defs.Sources))
+	for i, source := range defs.Sources {
+		sources[i] = &sourceVariantResource{source}
+	}
+	resources = append(resources, dependencyOrder(sources)...)
+	features := make([]Resource, len(defs.Features))
+	for i, feature := range defs.Features {
+		features[i] = &featureVariantResource{feature}
+	}
+	resources = append(resources, dependencyOrder(features)...)
+	for _, label := range defs.Labels {
+		resources = append(resources, &labelVariantResource{label})
+	}
+	for _, trainingSet := range defs.TrainingSets {
+		resources = append(resources, &trainingSetVariantResource{trainingSet})
+	}
+	for _, model := range defs.Models {
+		resources = append(resources, &modelResource{model})
+	}
+	return resources
+}
+
+// dependencyOrder orders variants of one type, like transformations or expression features, so that each
+// comes after the variants of that type that it reads.
+func dependencyOrder(variants []Resource) []Resource {
+	byID := make(map[ResourceID]Resource, len(variants))
+	for _, variant := range variants {
+		byID[variant.ID()] = variant
+	}
+	ordered := make([]Resource, 0, len(variants))
+	visited := make(map[ResourceID]bool, len(variants))
+	var visit func(Resource)
+	visit = func(variant Resource) {
+		if visited[variant.ID()] {
+			return
+		}
+		visited[variant.ID()] = true
+		for _, ref := range references(variant.Proto()) {
+			if dependency, has := byID[ref.id()]; has {
+				visit(dependency)
+			}
+		}
+		ordered = append(ordered, variant)
+	}
+	for _, variant := range variants {
+		visit(variant)
+	}
+	return ordered
+}
+
+// reference is a variant that a resource depends on. Its variant is a pointer into the resource's proto so
+// that the reference can be pointed at an equivalent variant.
+type reference struct {
+	resourceType ResourceType
+	name         string
+	variant      *string
+}
+
+func (ref reference) id() ResourceID {
+	return ResourceID{Name: ref.name, Variant: *ref.variant, Type: ref.resourceType}
+}
+
+func nameVariantReferences(resourceType ResourceType, variants ...*pb.NameVariant) []reference {
+	refs := make([]reference, 0, len(variants))
+	for _, variant := range variants {
+		if variant != nil {
+			refs = append(refs, reference{resourceType, variant.Name, &variant.Variant})
+		}
+	}
+	return refs
+}
+
+func references(msg proto.Message) []reference {
+	switch casted := msg.(type) {
+	case *pb.SourceVariant:
+		transformation := casted.GetTransformation()
+		if sql := transformation.GetSQLTransformation(); sql != nil {
+			return nameVariantReferences(SOURCE_VARIANT, sql.Source...)
+		}
+		if df := transformation.GetDFTransformation(); df != nil {
+			return nameVariantReferences(SOURCE_VARIANT, df.Inputs...)
+		}
+	case *pb.FeatureVariant:
+		refs := nameVariantReferences(SOURCE_VARIANT, casted.Source)
+		inputs := casted.GetExpression().GetInputs()
+		keys := make([]string, 0, len(inputs))
+		for key := range inputs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			refs = append(refs, nameVariantReferences(FEATURE_VARIANT, inputs[key])...)
+		}
+		return refs
+	case *pb.LabelVariant:
+		return nameVariantReferences(SOURCE_VARIANT, casted.Source)
+	case *pb.TrainingSetVariant:
+		refs := nameVariantReferences(FEATURE_VARIANT, casted.Features...)
+		refs = append(refs, nameVariantReferences(LABEL_VARIANT, casted.Label)...)
+		for _, lag := range casted.FeatureLags {
+			refs = append(refs, reference{FEATURE_VARIANT, lag.Feature, &lag.Variant})
+		}
+		return refs
+	case *pb.Model:
+		refs := nameVariantReferences(FEATURE_VARIANT, casted.Features...)
+		refs = append(refs, nameVariantReferences(LABEL_VARIANT, casted.Labels...)...)
+		return append(refs, nameVariantReferences(TRAINING_SET_VARIANT, casted.Trainingsets...)...)
+	}
+	return nil
+}
+
+// resolveReferences points a declared resource's references to variants that resolved to an equivalent
+// registered variant at that variant instead, including the templates in a SQL transformation's query.
+func resolveReferences(msg proto.Message, resolved map[ResourceID]string) {
+	sql := func() *pb.SQLTransformation {
+		if source, ok := msg.(*pb.SourceVariant); ok {
+			return source.GetTransformation().GetSQLTransformation()
+		}
+		return nil
+	}()
+	for _, ref := range references(msg) {
+		variant, has := resolved[ref.id()]
+		if !has {
+			continue
+		}
+		if sql != nil {
+			template := regexp.MustCompile(fmt.Sprintf(`\{\{\s*%s\.%s\s*\}\}`, regexp.QuoteMeta(ref.name), regexp.QuoteMeta(*ref.variant)))
+			sql.Query = template.ReplaceAllString(sql.Query, fmt.Sprintf("{{ %s.%s }}", ref.name, variant))
+		}
+		*ref.variant = variant
+	}
+}
+
+// declaredProto returns a copy of a resource without the fields the server manages.
+func declaredProto(msg proto.Message) (proto.Message, error) {
+	msg = proto.Clone(msg)
+	switch casted := msg.(type) {
+	case *pb.User:
+		casted.Status, casted.Features, casted.Labels, casted.Trainingsets, casted.Sources = nil, nil, nil, nil, nil
+		casted.Role, casted.Teams = pb.Role_ROLE_UNASSIGNED, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.Provider:
+		casted.Status, casted.Sources, casted.Features, casted.Trainingsets, casted.Labels = nil, nil, nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+		config, err := canonicalConfig(casted.SerializedConfig)
+		if err != nil {
+			return nil, err
+		}
+		casted.SerializedConfig = config
+	case *pb.Entity:
+		casted.Status, casted.Features, casted.Labels, casted.Trainingsets = nil, nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.SourceVariant:
+		casted.Created, casted.LastUpdated, casted.Status, casted.Table, casted.Schema = nil, nil, nil, "", nil
+		casted.Trainingsets, casted.Features, casted.Labels = nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.FeatureVariant:
+		casted.Created, casted.LastUpdated, casted.Status, casted.Trainingsets = nil, nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.LabelVariant:
+		casted.Created, casted.Status, casted.Trainingsets = nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.TrainingSetVariant:
+		casted.Created, casted.LastUpdated, casted.Status = nil, nil, nil
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	case *pb.Model:
+		casted.Tags, casted.Properties = declaredTags(casted.Tags), declaredProperties(casted.Properties)
+	default:
+		return nil, fferr.NewInternalError(fmt.Errorf("%T can't be declared", msg))
+	}
+	return msg, nil
+}
+
+// declaredTags makes missing tags empty, as the server expects when it merges them.
+func declaredTags(tags *pb.Tags) *pb.Tags {
+	if tags == nil {
+		return &pb.Tags{}
+	}
+	return tags
+}
+
+func declaredProperties(properties *pb.Properties) *pb.Properties {
+	if properties == nil {
+		return &pb.Properties{}
+	}
+	return properties
+}
+
+// canonicalConfig re-encodes a JSON provider config with sorted keys so that configs can be compared.
+// Other configs are returned as they are.
+func canonicalConfig(config []byte) ([]byte, error) {
+	parsed, isJSON := parseConfig(config)
+	if !isJSON {
+		return config, nil
+	}
+	return marshalConfig(parsed)
+}
+
+// marshalConfig encodes a parsed config without escaping HTML characters, so RedactedSecret stays readable.
+func marshalConfig(config interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(config); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func parseConfig(config []byte) (interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
+func isSecretConfigKey(key string) bool {
+	normalized := strings.ReplaceAll(strings.ToLower(key), "_", "")
+	for _, secret := range secretConfigKeys {
+		if strings.Contains(normalized, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfig replaces every non-empty value under a secret-like key with RedactedSecret, except secret
+// references, which are safe to export. A config that isn't JSON is redacted as a whole.
+func redactConfig(config []byte) interface{} {
+	if len(config) == 0 {
+		return nil
+	}
+	parsed, isJSON := parseConfig(config)
+	if !isJSON {
+		return RedactedSecret
+	}
+	return redactValue(parsed, false)
+}
+
+func redactValue(value interface{}, secret bool) interface{} {
+	switch casted := value.(type) {
+	case map[string]interface{}:
+		if secret && len(casted) > 0 {
+			return RedactedSecret
+		}
+		redacted := make(map[string]interface{}, len(casted))
+		for key, val := range casted {
+			redacted[key] = redactValue(val, isSecretConfigKey(key))
+		}
+		return redacted
+	case []interface{}:
+		if secret && len(casted) > 0 {
+			return RedactedSecret
+		}
+		redacted := make([]interface{}, len(casted))
+		for i, val := range casted {
+			redacted[i] = redactValue(val, false)
+		}
+		return redacted
+	case string:
+		if secret && casted != "" && !secrets.IsReference(casted) {
+			return RedactedSecret
+		}
+	}
+	return value
+}
+
+// restoreSecrets fills the redacted values of a declared provider config in from the registered one.
+func restoreSecrets(provider string, declared, registered []byte) ([]byte, error) {
+	if !bytes.Contains(declared, []byte(RedactedSecret)) {
+		return declared, nil
+	}
+	if string(declared) == RedactedSecret {
+		if registered == nil {
+			return nil, missingSecretError(provider, "config")
+		}
+		return registered, nil
+	}
+	declaredConfig, isJSON := parseConfig(declared)
+	if !isJSON {
+		return declared, nil
+	}
+	registeredConfig, _ := parseConfig(registered)
+	restored, err := restoreValue(provider, "config", declaredConfig, registeredConfig)
+	if err != nil {
+		return nil, err
+	}
+	return marshalConfig(restored)
+}
+
+func restoreValue(provider, path string, declared, registered interface{}) (interface{}, error) {
+	switch casted := declared.(type) {
+	case string:
+		if casted != RedactedSecret {
+			return declared, nil
+		}
+		if registered == nil {
+			return nil, missingSecretError(provider, path)
+		}
+		return registered, nil
+	case map[string]interface{}:
+		registeredMap, _ := registered.(map[string]interface{})
+		restored := make(map[string]interface{}, len(casted))
+		for key, val := range casted {
+			var err error
+			if restored[key], err = restoreValue(provider, path+"."+key, val, registeredMap[key]); err != nil {
+				return nil, err
+			}
+		}
+		return restored, nil
+	case []interface{}:
+		registeredList, _ := registered.([]interface{})
+		restored := make([]interface{}, len(casted))
+		for i, val := range casted {
+			var registeredVal interface{}
+			if i < len(registeredList) {
+				registeredVal = registeredList[i]
+			}
+			var err error
+			if restored[i], err = restoreValue(provider, fmt.Sprintf("%s[%d]", path, i), val, registeredVal); err != nil {
+				return nil, err
+			}
+		}
+		return restored, nil
+	}
+	return declared, nil
+}
+
+func missingSecretError(provider, path string) error {
+	return fferr.NewInvalidArgumentError(fmt.Errorf("%s of provider %s is redacted and there's no registered provider to take it from", path, provider))
+}
+
+var declarativeMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+// YAML encodes the definitions with provider secrets redacted.
+func (defs *Definitions) YAML() ([]byte, error) {
+	doc := definitionsDocument{}
+	sections := []struct {
+		section *[]json.RawMessage
+		msgs    []proto.Message
+	}{
+		{&doc.Users, protoList(defs.Users)},
+		{&doc.Providers, protoList(defs.Providers)},
+		{&doc.Entities, protoList(defs.Entities)},
+		{&doc.Sources, protoList(defs.Sources)},
+		{&doc.Features, protoList(defs.Features)},
+		{&doc.Labels, protoList(defs.Labels)},
+		{&doc.TrainingSets, protoList(defs.TrainingSets)},
+		{&doc.Models, protoList(defs.Models)},
+	}
+	for _, section := range sections {
+		for _, msg := range section.msgs {
+			encoded, err := encodeDefinition(msg)
+			if err != nil {
+				return nil, err
+			}
+			*section.section = append(*section.section, encoded)
+		}
+	}
+	encoded, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return encoded, nil
+}
+
+func protoList[T proto.Message](msgs []T) []proto.Message {
+	list := make([]proto.Message, len(msgs))
+	for i, msg := range msgs {
+		list[i] = msg
+	}
+	return list
+}
+
+// encodeDefinition encodes a resource as JSON, replacing a provider's serialized config with its redacted
+// form so that it's readable.
+func encodeDefinition(msg proto.Message) (json.RawMessage, error) {
+	config := []byte(nil)
+	if provider, ok := msg.(*pb.Provider); ok {
+		config = provider.SerializedConfig
+		provider = proto.Clone(provider).(*pb.Provider)
+		provider.SerializedConfig = nil
+		msg = provider
+	}
+	encoded, err := declarativeMarshaler.Marshal(msg)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	if config == nil {
+		return encoded, nil
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	fields["config"] = redactConfig(config)
+	if encoded, err = json.Marshal(fields); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return encoded, nil
+}
+
+// ParseDefinitions decodes definitions written by Definitions.YAML.
+func ParseDefinitions(data []byte) (*Definitions, error) {
+	encoded, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("invalid YAML: %w", err))
+	}
+	doc := definitionsDocument{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("invalid definitions: %w", err))
+	}
+	defs := &Definitions{}
+	sections := []struct {
+		encoded []json.RawMessage
+		empty   func() proto.Message
+	}{
+		{doc.Users, func() proto.Message { return &pb.User{} }},
+		{doc.Providers, func() proto.Message { return &pb.Provider{} }},
+		{doc.Entities, func() proto.Message { return &pb.Entity{} }},
+		{doc.Sources, func() proto.Message { return &pb.SourceVariant{} }},
+		{doc.Features, func() proto.Message { return &pb.FeatureVariant{} }},
+		{doc.Labels, func() proto.Message { return &pb.LabelVariant{} }},
+		{doc.TrainingSets, func() proto.Message { return &pb.TrainingSetVariant{} }},
+		{doc.Models, func() proto.Message { return &pb.Model{} }},
+	}
+	for _, section := range sections {
+		for _, encoded := range section.encoded {
+			msg, err := decodeDefinition(encoded, section.empty())
+			if err != nil {
+				return nil, err
+			}
+			defs.add(msg)
+		}
+	}
+	return defs, nil
+}
+
+func decodeDefinition(encoded json.RawMessage, msg proto.Message) (proto.Message, error) {
+	var config []byte
+	if _, isProvider := msg.(*pb.Provider); isProvider {
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(encoded, &fields); err != nil {
+			return nil, fferr.NewInvalidArgumentError(err)
+		}
+		if raw, has := fields["config"]; has {
+			var str string
+			if err := json.Unmarshal(raw, &str); err == nil {
+				config = []byte(str)
+			} else if string(raw) != "null" {
+				config = raw
+			}
+			delete(fields, "config")
+		}
+		var err error
+		if encoded, err = json.Marshal(fields); err != nil {
+			return nil, fferr.NewInternalError(err)
+		}
+	}
+	if err := protojson.Unmarshal(encoded, msg); err != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("invalid %s definition: %w", msg.ProtoReflect().Descriptor().Name(), err))
+	}
+	if provider, isProvider := msg.(*pb.Provider); isProvider {
+		provider.SerializedConfig = config
+	}
+	return declaredProto(msg)
+}
+
+type PlanAction string
+
+const (
+	CREATE_ACTION   PlanAction = "create"
+	UPDATE_ACTION   PlanAction = "update"
+	NO_ACTION       PlanAction = "unchanged"
+	CONFLICT_ACTION PlanAction = "conflict"
+)
+
+// FieldDiff is a field of a declared resource that differs from the registered one, with both values
+// rendered as JSON.
+type FieldDiff struct {
+	Field      string
+	Registered string
+	Declared   string
+}
+
+type PlannedChange struct {
+	ID     ResourceID
+	Action PlanAction
+	// Diffs are set for updates.
+	Diffs []FieldDiff
+	// Equivalent is the registered variant that a declared variant which isn't registered resolved to
+	// through GetEquivalent. The variant isn't created, and resources that depend on it use Equivalent.
+	Equivalent *NameVariant
+	// Reason explains why a conflicting change can't be applied.
+	Reason string
+	// request is the resource to send to its Create RPC.
+	request proto.Message
+}
+
+// Plan is what applying definitions would change. Apply only ever adds, the same as the Create RPCs: tags
+// and properties missing from a definition are left registered.
+type Plan struct {
+	Changes []PlannedChange
+}
+
+func (plan *Plan) Conflicts() []PlannedChange {
+	conflicts := make([]PlannedChange, 0)
+	for _, change := range plan.Changes {
+		if change.Action == CONFLICT_ACTION {
+			conflicts = append(conflicts, change)
+		}
+	}
+	return conflicts
+}
+
+// HasChanges is true if applying the plan would create or update any resource.
+func (plan *Plan) HasChanges() bool {
+	for _, change := range plan.Changes {
+		if change.Action == CREATE_ACTION || change.Action == UPDATE_ACTION {
+			return true
+		}
+	}
+	return false
+}
+
+func (plan *Plan) String() string {
+	var out strings.Builder
+	counts := make(map[PlanAction]int)
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+		switch change.Action {
+		case CREATE_ACTION:
+			fmt.Fprintf(&out, "+ %s\n", change.ID)
+		case UPDATE_ACTION:
+			fmt.Fprintf(&out, "~ %s\n", change.ID)
+			for _, diff := range change.Diffs {
+				fmt.Fprintf(&out, "    %s: %s => %s\n", diff.Field, diff.Registered, diff.Declared)
+			}
+		case CONFLICT_ACTION:
+			fmt.Fprintf(&out, "! %s: %s\n", change.ID, change.Reason)
+		case NO_ACTION:
+			if change.Equivalent != nil {
+				fmt.Fprintf(&out, "= %s is equivalent to registered variant %s\n", change.ID, change.Equivalent.Variant)
+			}
+		}
+	}
+	fmt.Fprintf(&out, "Plan: %d to create, %d to update, %d unchanged, %d conflicting.\n",
+		counts[CREATE_ACTION], counts[UPDATE_ACTION], counts[NO_ACTION], counts[CONFLICT_ACTION])
+	return out.String()
+}
+
+// Plan compares definitions to the registered resources. A variant that's registered under the same name
+// and variant must match it, and a variant that isn't is matched against the registered ones with
+// GetEquivalent, so that applying the same definitions again changes nothing.
+func (client *Client) Plan(ctx context.Context, defs *Definitions) (*Plan, error) {
+	registered, err := client.registeredDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registeredByID := make(map[ResourceID]Resource)
+	for _, resource := range registered.resources() {
+		registeredByID[resource.ID()] = resource
+	}
+	plan := &Plan{}
+	resolved := make(map[ResourceID]string)
+	for _, declared := range defs.resources() {
+		msg := proto.Clone(declared.Proto())
+		resolveReferences(msg, resolved)
+		change, err := client.planChange(ctx, msg, registeredByID)
+		if err != nil {
+			return nil, err
+		}
+		if change.Equivalent != nil {
+			resolved[change.ID] = change.Equivalent.Variant
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	return plan, nil
+}
+
+func (client *Client) planChange(ctx context.Context, msg proto.Message, registeredByID map[ResourceID]Resource) (PlannedChange, error) {
+	declared := wrapDeclared(msg)
+	change := PlannedChange{ID: declared.ID(), request: msg}
+	registered, has := registeredByID[change.ID]
+	if provider, ok := msg.(*pb.Provider); ok {
+		var registeredConfig []byte
+		if has {
+			registeredConfig = registered.Proto().(*pb.Provider).SerializedConfig
+		}
+		config, err := restoreSecrets(provider.Name, provider.SerializedConfig, registeredConfig)
+		if err != nil {
+			return PlannedChange{}, err
+		}
+		provider.SerializedConfig = config
+	}
+	if !has {
+		change.Action = CREATE_ACTION
+		if variant, ok := declared.(ResourceVariant); ok {
+			equivalent, err := client.equivalentVariant(ctx, variant)
+			if err != nil {
+				return PlannedChange{}, err
+			}
+			if equivalent != nil {
+				change.Action, change.Equivalent = NO_ACTION, equivalent
+			}
+		}
+		return change, nil
+	}
+	registeredProto, err := declaredProto(registered.Proto())
+	if err != nil {
+		return PlannedChange{}, err
+	}
+	declaredForm, err := declaredProto(msg)
+	if err != nil {
+		return PlannedChange{}, err
+	}
+	if proto.Equal(declaredForm, registeredProto) {
+		change.Action = NO_ACTION
+		return change, nil
+	}
+	if variant, ok := declared.(ResourceVariant); ok {
+		if !ResourceStatus(registered.GetStatus().GetStatus()).IsReady() {
+			change.Action, change.Reason = CONFLICT_ACTION, "the registered variant differs and isn't ready, declare a new variant instead"
+			return change, nil
+		}
+		equivalent, err := variant.IsEquivalent(registered.(ResourceVariant))
+		if err != nil {
+			return PlannedChange{}, err
+		}
+		if !equivalent {
+			change.Action, change.Reason = CONFLICT_ACTION, "the registered variant has a different definition, declare a new variant instead"
+			return change, nil
+		}
+	}
+	merged := wrapDeclared(proto.Clone(registeredProto))
+	if err := merged.Update(nil, declared); err != nil {
+		change.Action, change.Reason = CONFLICT_ACTION, err.Error()
+		return change, nil
+	}
+	mergedProto, err := declaredProto(merged.Proto())
+	if err != nil {
+		return PlannedChange{}, err
+	}
+	if proto.Equal(mergedProto, registeredProto) {
+		change.Action = NO_ACTION
+		return change, nil
+	}
+	change.Action, change.Diffs = UPDATE_ACTION, fieldDiffs(registeredProto, mergedProto)
+	return change, nil
+}
+
+func wrapDeclared(msg proto.Message) Resource {
+	switch casted := msg.(type) {
+	case *pb.User:
+		return &userResource{casted}
+	case *pb.Provider:
+		return &providerResource{casted}
+	case *pb.Entity:
+		return &entityResource{casted}
+	case *pb.SourceVariant:
+		return &sourceVariantResource{casted}
+	case *pb.FeatureVariant:
+		return &featureVariantResource{casted}
+	case *pb.LabelVariant:
+		return &labelVariantResource{casted}
+	case *pb.TrainingSetVariant:
+		return &trainingSetVariantResource{casted}
+	case *pb.Model:
+		return &modelResource{casted}
+	}
+	return nil
+}
+
+func (client *Client) equivalentVariant(ctx context.Context, variant ResourceVariant) (*NameVariant, error) {
+	req := &pb.ResourceVariantRequest{
+		ResourceVariant: variant.ToResourceVariantProto(),
+		RequestId:       logging.GetRequestIDFromContext(ctx),
+	}
+	equivalent, err := client.GrpcConn.GetEquivalent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var id interface {
+		GetName() string
+		GetVariant() string
+	}
+	switch resource := equivalent.GetResource().(type) {
+	case *pb.ResourceVariant_SourceVariant:
+		id = resource.SourceVariant
+	case *pb.ResourceVariant_FeatureVariant:
+		id = resource.FeatureVariant
+	case *pb.ResourceVariant_LabelVariant:
+		id = resource.LabelVariant
+	case *pb.ResourceVariant_TrainingSetVariant:
+		id = resource.TrainingSetVariant
+	default:
+		return nil, nil
+	}
+	return &NameVariant{Name: id.GetName(), Variant: id.GetVariant()}, nil
+}
+
+// fieldDiffs lists the top level fields that differ between two resources of the same type.
+func fieldDiffs(registered, declared proto.Message) []FieldDiff {
+	diffs := make([]FieldDiff, 0)
+	registeredReflect, declaredReflect := registered.ProtoReflect(), declared.ProtoReflect()
+	fields := registeredReflect.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		registeredField, declaredField := onlyField(registeredReflect, field), onlyField(declaredReflect, field)
+		if proto.Equal(registeredField, declaredField) {
+			continue
+		}
+		diffs = append(diffs, FieldDiff{
+			Field:      string(field.Name()),
+			Registered: renderField(registeredField, field),
+			Declared:   renderField(declaredField, field),
+		})
+	}
+	return diffs
+}
+
+func onlyField(msg protoreflect.Message, field protoreflect.FieldDescriptor) proto.Message {
+	only := msg.New()
+	if msg.Has(field) {
+		only.Set(field, msg.Get(field))
+	}
+	return only.Interface()
+}
+
+func renderField(msg proto.Message, field protoreflect.FieldDescriptor) string {
+	if provider, ok := msg.(*pb.Provider); ok && field.Name() == "serialized_config" {
+		rendered, _ := marshalConfig(redactConfig(provider.SerializedConfig))
+		return string(rendered)
+	}
+	encoded, err := declarativeMarshaler.Marshal(msg)
+	if err != nil {
+		return err.Error()
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return string(encoded)
+	}
+	if value, has := fields[string(field.Name())]; has {
+		return string(value)
+	}
+	return "null"
+}
+
+// Apply creates and updates the resources in a plan in dependency order. Nothing is applied if the plan
+// has conflicts.
+func (client *Client) Apply(ctx context.Context, plan *Plan) error {
+	if conflicts := plan.Conflicts(); len(conflicts) > 0 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("plan has %d conflicting changes, the first being %s: %s", len(conflicts), conflicts[0].ID, conflicts[0].Reason))
+	}
+	requestID := logging.GetRequestIDFromContext(ctx)
+	for _, change := range plan.Changes {
+		if change.Action != CREATE_ACTION && change.Action != UPDATE_ACTION {
+			continue
+		}
+		var err error
+		switch resource := proto.Clone(change.request).(type) {
+		case *pb.User:
+			_, err = client.GrpcConn.CreateUser(ctx, &pb.UserRequest{User: resource, RequestId: requestID})
+		case *pb.Provider:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS}
+			_, err = client.GrpcConn.CreateProvider(ctx, &pb.ProviderRequest{Provider: resource, RequestId: requestID})
+		case *pb.Entity:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS}
+			_, err = client.GrpcConn.CreateEntity(ctx, &pb.EntityRequest{Entity: resource, RequestId: requestID})
+		case *pb.SourceVariant:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED}
+			_, err = client.GrpcConn.CreateSourceVariant(ctx, &pb.SourceVariantRequest{SourceVariant: resource, RequestId: requestID})
+		case *pb.FeatureVariant:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED}
+			_, err = client.GrpcConn.CreateFeatureVariant(ctx, &pb.FeatureVariantRequest{FeatureVariant: resource, RequestId: requestID})
+		case *pb.LabelVariant:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS}
+			_, err = client.GrpcConn.CreateLabelVariant(ctx, &pb.LabelVariantRequest{LabelVariant: resource, RequestId: requestID})
+		case *pb.TrainingSetVariant:
+			resource.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED}
+			_, err = client.GrpcConn.CreateTrainingSetVariant(ctx, &pb.TrainingSetVariantRequest{TrainingSetVariant: resource, RequestId: requestID})
+		case *pb.Model:
+			_, err = client.GrpcConn.CreateModel(ctx, &pb.ModelRequest{Model: resource, RequestId: requestID})
+		}
+		if err != nil {
+			logging.GetLoggerFromContext(ctx).Errorw("Failed to apply change", "resource", change.ID, "action", change.Action, "error", err)
+			return err
+		}
+	}
+	return nil
+}
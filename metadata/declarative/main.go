@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Command declarative exports the resources registered in metadata to YAML and applies YAML definitions
+// back to metadata, so definitions can be promoted between environments and kept in version control.
+//
+//	declarative export [-out definitions.yaml]
+//	declarative apply [-plan] [-in definitions.yaml]
+//
+// It connects to METADATA_HOST:METADATA_PORT.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: declarative export|apply [flags]")
+		os.Exit(2)
+	}
+	logger := logging.NewLogger("declarative")
+	addr := fmt.Sprintf("%s:%s", help.GetEnv("METADATA_HOST", "localhost"), help.GetEnv("METADATA_PORT", "8080"))
+	client, err := metadata.NewClient(addr, logger)
+	if err != nil {
+		logger.Fatalw("Failed to connect to metadata", "address", addr, "error", err)
+	}
+	defer client.Close()
+	ctx := logging.AttachRequestID(logging.NewRequestID().String(), context.Background(), logger)
+
+	switch os.Args[1] {
+	case "export":
+		err = export(ctx, client, os.Args[2:])
+	case "apply":
+		err = apply(ctx, client, os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %s, expected export or apply", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func export(ctx context.Context, client *metadata.Client, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "", "file to write the definitions to, stdout if empty")
+	flags.Parse(args)
+
+	defs, err := client.ExportDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	encoded, err := defs.YAML()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	return os.WriteFile(*out, encoded, 0644)
+}
+
+func apply(ctx context.Context, client *metadata.Client, args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	in := flags.String("in", "", "file to read the definitions from, stdin if empty")
+	planOnly := flags.Bool("plan", false, "print what would change without applying it")
+	flags.Parse(args)
+
+	var encoded []byte
+	var err error
+	if *in == "" {
+		encoded, err = io.ReadAll(os.Stdin)
+	} else {
+		encoded, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return err
+	}
+	defs, err := metadata.ParseDefinitions(encoded)
+	if err != nil {
+		return err
+	}
+	plan, err := client.Plan(ctx, defs)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	if *planOnly || !plan.HasChanges() {
+		return nil
+	}
+	return client.Apply(ctx, plan)
+}
@@ -0,0 +1,190 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+func planActions(plan *Plan) map[PlanAction]int {
+	actions := make(map[PlanAction]int)
+	for _, change := range plan.Changes {
+		actions[change.Action]++
+	}
+	return actions
+}
+
+func TestDeclarativeExportAndApply(t *testing.T) {
+	ctx := context.Background()
+	testCtx := testContext{Defs: filledResourceDefs()}
+	registered, err := testCtx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer testCtx.Destroy()
+
+	exported, err := registered.ExportDefinitions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %s", err)
+	}
+	encoded, err := exported.YAML()
+	if err != nil {
+		t.Fatalf("Failed to encode: %s", err)
+	}
+	if !strings.Contains(string(encoded), RedactedSecret) {
+		t.Fatalf("Expected provider secrets to be redacted:\n%s", encoded)
+	}
+	defs, err := ParseDefinitions(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	for _, provider := range defs.Providers {
+		config := make(map[string]interface{})
+		if err := json.Unmarshal(provider.SerializedConfig, &config); err != nil {
+			t.Fatalf("Failed to parse config of %s: %s", provider.Name, err)
+		}
+		if config["Password"] != RedactedSecret {
+			t.Fatalf("Expected password of %s to be redacted, got %v", provider.Name, config["Password"])
+		}
+	}
+
+	plan, err := registered.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if plan.HasChanges() || len(plan.Conflicts()) > 0 {
+		t.Fatalf("Expected re-applying an export to change nothing:\n%s", plan)
+	}
+
+	serv, addr := startServ(t)
+	defer serv.Stop()
+	target := client(t, addr)
+	defer target.Close()
+	if _, err := target.Plan(ctx, defs); err == nil {
+		t.Fatalf("Expected redacted secrets of unregistered providers to fail")
+	}
+	for _, def := range testCtx.Defs {
+		if provider, ok := def.(ProviderDef); ok {
+			if err := target.CreateProvider(ctx, provider); err != nil {
+				t.Fatalf("Failed to create provider: %s", err)
+			}
+		}
+	}
+	plan, err = target.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if actions := planActions(plan); actions[CREATE_ACTION] != len(plan.Changes)-len(defs.Providers) {
+		t.Fatalf("Expected everything but the providers to be created:\n%s", plan)
+	}
+	if err := target.Apply(ctx, plan); err != nil {
+		t.Fatalf("Failed to apply: %s", err)
+	}
+	plan, err = target.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if plan.HasChanges() {
+		t.Fatalf("Expected applying twice to be idempotent:\n%s", plan)
+	}
+	reexported, err := target.ExportDefinitions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %s", err)
+	}
+	reencoded, err := reexported.YAML()
+	if err != nil {
+		t.Fatalf("Failed to encode: %s", err)
+	}
+	if string(reencoded) != string(encoded) {
+		t.Fatalf("Expected applied definitions to export the same:\n%s\n\n%s", encoded, reencoded)
+	}
+
+	updated := proto.Clone(defs.Entities[0]).(*pb.Entity)
+	updated.Tags = &pb.Tags{Tag: []string{"gitops"}}
+	changed := &Definitions{Entities: []*pb.Entity{updated}, Sources: []*pb.SourceVariant{proto.Clone(defs.Sources[0]).(*pb.SourceVariant)}}
+	changed.Sources[0].Owner = "Other"
+	plan, err = target.Plan(ctx, changed)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if plan.Changes[0].Action != UPDATE_ACTION || len(plan.Changes[0].Diffs) != 1 || plan.Changes[0].Diffs[0].Field != "tags" {
+		t.Fatalf("Expected the entity's tags to be updated:\n%s", plan)
+	}
+	if plan.Changes[1].Action != CONFLICT_ACTION {
+		t.Fatalf("Expected changing a registered source's owner to conflict:\n%s", plan)
+	}
+	if err := target.Apply(ctx, plan); err == nil {
+		t.Fatalf("Expected a plan with conflicts not to apply")
+	}
+}
+
+func TestDeclarativeEquivalentVariants(t *testing.T) {
+	ctx := context.Background()
+	testCtx := testContext{Defs: filledResourceDefs()}
+	registered, err := testCtx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer testCtx.Destroy()
+	defs, err := registered.ExportDefinitions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %s", err)
+	}
+	source := ResourceID{Name: "mockSource", Variant: "var2", Type: SOURCE_VARIANT}
+	if err := registered.SetStatus(ctx, source, READY, ""); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+
+	// Renaming a variant that's registered under another variant resolves to the registered one, and so
+	// do the resources that depend on it.
+	for _, variant := range defs.Sources {
+		if variant.Name == source.Name && variant.Variant == source.Variant {
+			variant.Variant = "renamed"
+		}
+	}
+	dependents := 0
+	for _, feature := range defs.Features {
+		if feature.Source.GetName() == source.Name && feature.Source.GetVariant() == source.Variant {
+			feature.Source.Variant = "renamed"
+			dependents++
+		}
+	}
+	if dependents == 0 {
+		t.Fatalf("Expected a feature to depend on %s", source)
+	}
+	plan, err := registered.Plan(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if plan.HasChanges() || len(plan.Conflicts()) > 0 {
+		t.Fatalf("Expected the renamed variant to resolve to the registered one:\n%s", plan)
+	}
+	for _, change := range plan.Changes {
+		if change.ID.Type == SOURCE_VARIANT && change.ID.Variant == "renamed" {
+			if change.Equivalent == nil || change.Equivalent.Variant != source.Variant {
+				t.Fatalf("Expected %s to be equivalent to %s, got %v", change.ID, source, change.Equivalent)
+			}
+			return
+		}
+	}
+	t.Fatalf("Expected the renamed variant in the plan:\n%s", plan)
+}
+
+func TestRedactConfigKeepsSecretReferences(t *testing.T) {
+	config := []byte(`{"Username": "featureformer", "Password": "password", "PrivateKey": "secret://vault/secret/data/snowflake#key"}`)
+	redacted := redactConfig(config).(map[string]interface{})
+	if redacted["Password"] != RedactedSecret {
+		t.Fatalf("Expected the password to be redacted, got %v", redacted["Password"])
+	}
+	if redacted["PrivateKey"] != "secret://vault/secret/data/snowflake#key" || redacted["Username"] != "featureformer" {
+		t.Fatalf("Expected the secret reference to be exported as is, got %v", redacted)
+	}
+}
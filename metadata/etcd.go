@@ -64,6 +64,32 @@ func (c *CoordinatorScheduleJob) Deserialize(serialized []byte) error {
 	return nil
 }
 
+// CoordinatorDeleteJob is written when a resource variant is deleted so the coordinator
+// can clean up any data it materialized in the providers.
+type CoordinatorDeleteJob struct {
+	Resource        ResourceID
+	OfflineProvider string
+	OnlineProvider  string
+	// Transformation is set when a deleted source variant is a transformation rather than a primary table.
+	Transformation bool
+}
+
+func (c *CoordinatorDeleteJob) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(c)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return serialized, nil
+}
+
+func (c *CoordinatorDeleteJob) Deserialize(serialized []byte) error {
+	err := json.Unmarshal(serialized, c)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
 type TempJob struct {
 	Attempts int
 	Name     string
@@ -123,9 +149,19 @@ type EtcdStorage struct {
 	Client *clientv3.Client
 }
 
+// KVStorage is the key value store that resources and coordinator jobs are persisted in.
+// EtcdStorage and BoltStorage implement it.
+type KVStorage interface {
+	Put(key string, value string) error
+	Get(key string) ([]byte, error)
+	GetWithPrefix(key string) ([][]byte, error)
+	GetCountWithPrefix(key string) (int64, error)
+	Delete(key string) error
+}
+
 // Create Resource Lookup Using ETCD
 type EtcdResourceLookup struct {
-	Connection EtcdStorage
+	Connection KVStorage
 }
 
 // Wrapper around Resource/Job messages. Allows top level storage for info about saved value
@@ -167,6 +203,19 @@ func (s EtcdStorage) Put(key string, value string) error {
 	return nil
 }
 
+// Deletes a single key from ETCD. Deleting a key that doesn't exist is not an error
+func (s EtcdStorage) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	_, err := s.Client.Delete(ctx, key)
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return wrapped
+	}
+	return nil
+}
+
 func (s EtcdStorage) genericGet(key string, withPrefix bool) (*clientv3.GetResponse, error) {
 	if key == "" && !withPrefix {
 		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("key cannot be empty"))
@@ -231,6 +280,10 @@ func (s EtcdStorage) GetCountWithPrefix(key string) (int64, error) {
 // Checks to make sure the given ETCD Storage Object contains a Resource, not job
 // Deserializes Resource value into the provided Resource object
 func (s EtcdStorage) ParseResource(res EtcdRow, resType Resource) (Resource, error) {
+	return parseResource(res, resType)
+}
+
+func parseResource(res EtcdRow, resType Resource) (Resource, error) {
 	if res.StorageType != RESOURCE {
 		return nil, fferr.NewInternalError(fmt.Errorf("payload is not resource type"))
 	}
@@ -347,7 +400,7 @@ func (lookup EtcdResourceLookup) Lookup(ctx context.Context, id ResourceID) (Res
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to create empty resource: %s", id))
 	}
 	logger.Infow("Parse resource", "key", key)
-	resource, err := lookup.Connection.ParseResource(msg, resType)
+	resource, err := parseResource(msg, resType)
 	if err != nil {
 		return nil, err
 	}
@@ -375,6 +428,21 @@ func GetScheduleJobKey(id ResourceID) string {
 	return fmt.Sprintf("SCHEDULEJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
 }
 
+func GetDeleteJobKey(id ResourceID) string {
+	return fmt.Sprintf("DELETEJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+// GetWatermarkKey returns the key of the latest event time that a scheduled materialization has
+// copied to its online store.
+func GetWatermarkKey(id ResourceID) string {
+	return fmt.Sprintf("WATERMARK__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+// GetStatsKey returns the key of the value statistics that the monitoring runner last computed for a feature.
+func GetStatsKey(id ResourceID) string {
+	return fmt.Sprintf("STATS__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
 func (lookup EtcdResourceLookup) HasJob(id ResourceID) (bool, error) {
 	job_key := GetJobKey(id)
 	count, err := lookup.Connection.GetCountWithPrefix(job_key)
@@ -424,6 +492,35 @@ func (lookup EtcdResourceLookup) SetSchedule(id ResourceID, schedule string) err
 	return nil
 }
 
+func (lookup EtcdResourceLookup) SetDeleteJob(job CoordinatorDeleteJob) error {
+	serialized, err := job.Serialize()
+	if err != nil {
+		return err
+	}
+	jobKey := GetDeleteJobKey(job.Resource)
+	if err := lookup.Connection.Put(jobKey, string(serialized)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the resource along with any job that hasn't been picked up by the coordinator yet.
+func (lookup EtcdResourceLookup) Delete(id ResourceID) error {
+	if err := lookup.Connection.Delete(GetJobKey(id)); err != nil {
+		return err
+	}
+	if err := lookup.Connection.Delete(GetScheduleJobKey(id)); err != nil {
+		return err
+	}
+	if err := lookup.Connection.Delete(GetWatermarkKey(id)); err != nil {
+		return err
+	}
+	if err := lookup.Connection.Delete(GetStatsKey(id)); err != nil {
+		return err
+	}
+	return lookup.Connection.Delete(createKey(id))
+}
+
 func (lookup EtcdResourceLookup) Set(id ResourceID, res Resource) error {
 
 	serRes, err := lookup.serializeResource(res)
@@ -457,7 +554,7 @@ func (lookup EtcdResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error
 			return nil, err
 		}
 
-		res, err := lookup.Connection.ParseResource(etcdStore, resource)
+		res, err := parseResource(etcdStore, resource)
 		if err != nil {
 			return nil, err
 		}
@@ -481,7 +578,7 @@ func (lookup EtcdResourceLookup) ListForType(t ResourceType) ([]Resource, error)
 		if err != nil {
 			return nil, err
 		}
-		resource, err = lookup.Connection.ParseResource(etcdStore, resource)
+		resource, err = parseResource(etcdStore, resource)
 		if resource.ID().Type == t {
 			resources = append(resources, resource)
 		}
@@ -504,7 +601,7 @@ func (lookup EtcdResourceLookup) List() ([]Resource, error) {
 		if err != nil {
 			return nil, err
 		}
-		resource, err = lookup.Connection.ParseResource(etcdStore, resource)
+		resource, err = parseResource(etcdStore, resource)
 		resources = append(resources, resource)
 	}
 	return resources, nil
@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/featureform/fferr"
+)
+
+const (
+	// Variables that every expression can read, on top of its inputs.
+	ExpressionRequestVariable = "request"
+	ExpressionNowVariable     = "now"
+	// expressionCostLimit bounds how much work a single evaluation can do, so a bad
+	// expression can't stall the serving path.
+	expressionCostLimit = 100000
+)
+
+// Compile type checks a SERVER_COMPUTED feature's expression. Each input is declared as a
+// dyn variable since its type depends on the online store. The metadata server compiles
+// expressions on registration so invalid ones are rejected before they're ever served.
+func (e Expression) Compile() (cel.Program, error) {
+	opts := []cel.EnvOption{
+		cel.Variable(ExpressionRequestVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(ExpressionNowVariable, cel.TimestampType),
+	}
+	for name := range e.Inputs {
+		if name == ExpressionRequestVariable || name == ExpressionNowVariable {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("expression input %s shadows a built in variable", name))
+		}
+		opts = append(opts, cel.Variable(name, cel.DynType))
+	}
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentError(err)
+	}
+	ast, issues := env.Compile(e.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("invalid expression %q: %w", e.Expression, issues.Err()))
+	}
+	program, err := env.Program(ast, cel.CostLimit(expressionCostLimit))
+	if err != nil {
+		return nil, fferr.NewInvalidArgumentError(err)
+	}
+	return program, nil
+}
@@ -0,0 +1,336 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	pb "github.com/featureform/metadata/proto"
+)
+
+// LineageNode is a node of the lineage graph. Column nodes share the ResourceID of their source variant.
+type LineageNode struct {
+	ResourceID
+	Column string
+}
+
+func (node LineageNode) String() string {
+	return fmt.Sprintf("%s %s %s %s", node.Type, node.Name, node.Variant, node.Column)
+}
+
+func (node LineageNode) proto() *pb.LineageNode {
+	return &pb.LineageNode{
+		Resource: &pb.ResourceID{Resource: node.ResourceID.Proto(), ResourceType: node.Type.Serialized()},
+		Column:   node.Column,
+	}
+}
+
+func lineageNodeFromProto(node *pb.LineageNode) LineageNode {
+	return LineageNode{
+		ResourceID: ResourceID{
+			Name:    node.GetResource().GetResource().GetName(),
+			Variant: node.GetResource().GetResource().GetVariant(),
+			Type:    ResourceType(node.GetResource().GetResourceType()),
+		},
+		Column: node.GetColumn(),
+	}
+}
+
+type LineageEdgeType int32
+
+const (
+	DEPENDENCY_EDGE LineageEdgeType = LineageEdgeType(pb.LineageEdgeType_LINEAGE_DEPENDENCY)
+	ENTITY_EDGE                     = LineageEdgeType(pb.LineageEdgeType_LINEAGE_ENTITY)
+	COLUMN_EDGE                     = LineageEdgeType(pb.LineageEdgeType_LINEAGE_COLUMN)
+	MODEL_EDGE                      = LineageEdgeType(pb.LineageEdgeType_LINEAGE_MODEL)
+)
+
+func (t LineageEdgeType) String() string {
+	return strings.TrimPrefix(pb.LineageEdgeType_name[int32(t)], "LINEAGE_")
+}
+
+// LineageEdge points from a resource to one that's built from or uses it.
+type LineageEdge struct {
+	Upstream   LineageNode
+	Downstream LineageNode
+	Type       LineageEdgeType
+}
+
+func (edge LineageEdge) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", edge.Upstream, edge.Downstream, edge.Type)
+}
+
+func lineageEdgeFromProto(edge *pb.LineageEdge) LineageEdge {
+	return LineageEdge{
+		Upstream:   lineageNodeFromProto(edge.GetUpstream()),
+		Downstream: lineageNodeFromProto(edge.GetDownstream()),
+		Type:       LineageEdgeType(edge.GetType()),
+	}
+}
+
+type Lineage struct {
+	Nodes []LineageNode
+	Edges []LineageEdge
+}
+
+// lineageEdges returns the edges leading into a resource: the resource variants it's built from, the entity
+// it's keyed on, the source columns it reads and, for models, what they use. Resources that don't take part
+// in lineage, like users and providers, have none.
+func lineageEdges(res Resource) []LineageEdge {
+	id := LineageNode{ResourceID: res.ID()}
+	edges := make([]LineageEdge, 0)
+	addEdge := func(upstream LineageNode, edgeType LineageEdgeType) {
+		edges = append(edges, LineageEdge{Upstream: upstream, Downstream: id, Type: edgeType})
+	}
+	addVariants := func(nameVariants []*pb.NameVariant, t ResourceType, edgeType LineageEdgeType) {
+		for _, nv := range nameVariants {
+			addEdge(LineageNode{ResourceID: ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t}}, edgeType)
+		}
+	}
+	addSourceColumns := func(source *pb.NameVariant, entity string, columns *pb.Columns) {
+		sourceID := ResourceID{Name: source.GetName(), Variant: source.GetVariant(), Type: SOURCE_VARIANT}
+		addEdge(LineageNode{ResourceID: sourceID}, DEPENDENCY_EDGE)
+		addEdge(LineageNode{ResourceID: ResourceID{Name: entity, Type: ENTITY}}, ENTITY_EDGE)
+		for _, column := range []string{columns.GetEntity(), columns.GetValue(), columns.GetTs()} {
+			if column != "" {
+				addEdge(LineageNode{ResourceID: sourceID, Column: column}, COLUMN_EDGE)
+			}
+		}
+	}
+	switch casted := res.(type) {
+	case *sourceVariantResource:
+		transformation := casted.serialized.GetTransformation()
+		addVariants(transformation.GetSQLTransformation().GetSource(), SOURCE_VARIANT, DEPENDENCY_EDGE)
+		addVariants(transformation.GetDFTransformation().GetInputs(), SOURCE_VARIANT, DEPENDENCY_EDGE)
+	case *featureVariantResource:
+		serialized := casted.serialized
+		if PRECOMPUTED.Equals(serialized.Mode) {
+			addSourceColumns(serialized.Source, serialized.Entity, serialized.GetColumns())
+		}
+		if SERVER_COMPUTED.Equals(serialized.Mode) {
+			inputs := serialized.GetExpression().GetInputs()
+			names := make([]string, 0, len(inputs))
+			for name := range inputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				addVariants([]*pb.NameVariant{inputs[name]}, FEATURE_VARIANT, DEPENDENCY_EDGE)
+			}
+		}
+	case *labelVariantResource:
+		addSourceColumns(casted.serialized.Source, casted.serialized.Entity, casted.serialized.GetColumns())
+	case *trainingSetVariantResource:
+		addVariants([]*pb.NameVariant{casted.serialized.Label}, LABEL_VARIANT, DEPENDENCY_EDGE)
+		addVariants(casted.serialized.Features, FEATURE_VARIANT, DEPENDENCY_EDGE)
+	case *modelResource:
+		addVariants(casted.serialized.Features, FEATURE_VARIANT, MODEL_EDGE)
+		addVariants(casted.serialized.Labels, LABEL_VARIANT, MODEL_EDGE)
+		addVariants(casted.serialized.Trainingsets, TRAINING_SET_VARIANT, MODEL_EDGE)
+	}
+	return edges
+}
+
+// lineageGraph holds the nodes and edges found while walking from a resource.
+type lineageGraph struct {
+	nodes map[LineageNode]struct{}
+	edges map[LineageEdge]struct{}
+}
+
+// walk follows edges breadth first from root for up to depth edges, or without a limit if depth is zero or
+// less. edgesOf returns the edges leading away from a resource in the walk's direction, and next picks the
+// far end of an edge. Column nodes aren't walked further, as their source is reached through its own edge.
+func (graph *lineageGraph) walk(root ResourceID, depth int, edgesOf func(ResourceID) ([]LineageEdge, error), next func(LineageEdge) LineageNode) error {
+	visited := map[ResourceID]struct{}{root: {}}
+	frontier := []ResourceID{root}
+	for level := 0; len(frontier) > 0 && (depth <= 0 || level < depth); level++ {
+		nextFrontier := make([]ResourceID, 0)
+		for _, id := range frontier {
+			edges, err := edgesOf(id)
+			if err != nil {
+				return err
+			}
+			for _, edge := range edges {
+				graph.edges[edge] = struct{}{}
+				graph.nodes[edge.Upstream] = struct{}{}
+				graph.nodes[edge.Downstream] = struct{}{}
+				node := next(edge)
+				if node.Column != "" {
+					continue
+				}
+				if _, has := visited[node.ResourceID]; has {
+					continue
+				}
+				visited[node.ResourceID] = struct{}{}
+				nextFrontier = append(nextFrontier, node.ResourceID)
+			}
+		}
+		frontier = nextFrontier
+	}
+	return nil
+}
+
+func (graph *lineageGraph) proto() *pb.Lineage {
+	nodes := make([]LineageNode, 0, len(graph.nodes))
+	for node := range graph.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].String() < nodes[j].String() })
+	edges := make([]LineageEdge, 0, len(graph.edges))
+	for edge := range graph.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].String() < edges[j].String() })
+
+	lineage := &pb.Lineage{
+		Nodes: make([]*pb.LineageNode, len(nodes)),
+		Edges: make([]*pb.LineageEdge, len(edges)),
+	}
+	for i, node := range nodes {
+		lineage.Nodes[i] = node.proto()
+	}
+	for i, edge := range edges {
+		lineage.Edges[i] = &pb.LineageEdge{
+			Upstream:   edge.Upstream.proto(),
+			Downstream: edge.Downstream.proto(),
+			Type:       pb.LineageEdgeType(edge.Type),
+		}
+	}
+	return lineage
+}
+
+// downstreamCandidates returns the resources recorded as built from or using res. The lists are filled in
+// as dependencies are propagated, which is transitive, so they can hold resources that only use res
+// indirectly; callers keep the ones with an edge from res.
+func downstreamCandidates(res Resource) []ResourceID {
+	ids := make([]ResourceID, 0)
+	addVariants := func(nameVariants []*pb.NameVariant, t ResourceType) {
+		for _, nv := range nameVariants {
+			ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t})
+		}
+	}
+	addModels := func(models []string) {
+		for _, model := range models {
+			ids = append(ids, ResourceID{Name: model, Type: MODEL})
+		}
+	}
+	switch casted := res.(type) {
+	case *sourceVariantResource:
+		addVariants(casted.serialized.Features, FEATURE_VARIANT)
+		addVariants(casted.serialized.Labels, LABEL_VARIANT)
+		addVariants(casted.serialized.Transformations, SOURCE_VARIANT)
+	case *featureVariantResource:
+		addVariants(casted.serialized.Trainingsets, TRAINING_SET_VARIANT)
+		addVariants(casted.serialized.DependentFeatures, FEATURE_VARIANT)
+		addModels(casted.serialized.Models)
+	case *labelVariantResource:
+		addVariants(casted.serialized.Trainingsets, TRAINING_SET_VARIANT)
+		addModels(casted.serialized.Models)
+	case *trainingSetVariantResource:
+		addModels(casted.serialized.Models)
+	case *entityResource:
+		addVariants(casted.serialized.Features, FEATURE_VARIANT)
+		addVariants(casted.serialized.Labels, LABEL_VARIANT)
+	}
+	return ids
+}
+
+// notifyLineageInputs records res on the resources it's built from that dependency propagation doesn't
+// reach: a transformation's inputs aren't among its dependencies, and models are only propagated when
+// they're first created, not when later registrations add features, labels or training sets to them.
+func (serv *MetadataServer) notifyLineageInputs(ctx context.Context, res Resource, op operation) error {
+	switch res.(type) {
+	case *sourceVariantResource, *modelResource:
+	default:
+		return nil
+	}
+	for _, edge := range lineageEdges(res) {
+		input, err := serv.lookup.Lookup(ctx, edge.Upstream.ResourceID)
+		if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := input.Notify(serv.lookup, op, res); err != nil {
+			return err
+		}
+		if err := serv.lookup.Set(input.ID(), input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upstreamEdges returns the edges leading into the resource with the given ID, or none if it doesn't exist.
+func (serv *MetadataServer) upstreamEdges(ctx context.Context, id ResourceID) ([]LineageEdge, error) {
+	res, err := serv.lookup.Lookup(ctx, id)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return lineageEdges(res), nil
+}
+
+// downstreamEdges returns the edges leading away from the resource with the given ID, found through the
+// dependents recorded on it rather than by scanning every resource.
+func (serv *MetadataServer) downstreamEdges(ctx context.Context, id ResourceID) ([]LineageEdge, error) {
+	res, err := serv.lookup.Lookup(ctx, id)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	edges := make([]LineageEdge, 0)
+	for _, candidateID := range downstreamCandidates(res) {
+		candidateEdges, err := serv.upstreamEdges(ctx, candidateID)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range candidateEdges {
+			if edge.Upstream.ResourceID == id {
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges, nil
+}
+
+func (serv *MetadataServer) GetLineage(ctx context.Context, req *pb.LineageRequest) (*pb.Lineage, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx)
+	root := ResourceID{
+		Name:    req.GetResource().GetResource().GetName(),
+		Variant: req.GetResource().GetResource().GetVariant(),
+		Type:    ResourceType(req.GetResource().GetResourceType()),
+	}
+	logger.Infow("Getting lineage", "resource_id", root, "depth", req.Depth)
+	if _, err := serv.lookup.Lookup(ctx, root); err != nil {
+		logger.Errorw("Error looking up resource", "error", err)
+		return nil, err
+	}
+
+	graph := &lineageGraph{
+		nodes: map[LineageNode]struct{}{{ResourceID: root}: {}},
+		edges: make(map[LineageEdge]struct{}),
+	}
+	depth := int(req.Depth)
+	upstream := func(id ResourceID) ([]LineageEdge, error) { return serv.upstreamEdges(ctx, id) }
+	if err := graph.walk(root, depth, upstream, func(edge LineageEdge) LineageNode { return edge.Upstream }); err != nil {
+		logger.Errorw("Error walking upstream lineage", "error", err)
+		return nil, err
+	}
+	downstream := func(id ResourceID) ([]LineageEdge, error) { return serv.downstreamEdges(ctx, id) }
+	if err := graph.walk(root, depth, downstream, func(edge LineageEdge) LineageNode { return edge.Downstream }); err != nil {
+		logger.Errorw("Error walking downstream lineage", "error", err)
+		return nil, err
+	}
+	return graph.proto(), nil
+}
@@ -0,0 +1,219 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/featureform/provider/types"
+)
+
+func hasLineageEdge(lineage *Lineage, upstream, downstream LineageNode, edgeType LineageEdgeType) bool {
+	for _, edge := range lineage.Edges {
+		if edge.Upstream == upstream && edge.Downstream == downstream && edge.Type == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLineageNode(lineage *Lineage, node LineageNode) bool {
+	for _, n := range lineage.Nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetLineage(t *testing.T) {
+	defs := append(filledResourceDefs(), ModelDef{
+		Name:         "churn",
+		Description:  "churn model",
+		Features:     NameVariants{{"feature", "variant"}},
+		Trainingsets: NameVariants{{"training-set", "variant"}},
+		Tags:         Tags{},
+		Properties:   Properties{},
+	})
+	ctx := testContext{
+		Defs: defs,
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	sourceID := ResourceID{Name: "mockSource", Variant: "var", Type: SOURCE_VARIANT}
+	source := LineageNode{ResourceID: sourceID}
+	inputSource := LineageNode{ResourceID: ResourceID{Name: "mockName", Variant: "mockVariant", Type: SOURCE_VARIANT}}
+	feature := LineageNode{ResourceID: ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}}
+	label := LineageNode{ResourceID: ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}}
+	trainingSet := LineageNode{ResourceID: ResourceID{Name: "training-set", Variant: "variant", Type: TRAINING_SET_VARIANT}}
+	model := LineageNode{ResourceID: ResourceID{Name: "churn", Type: MODEL}}
+	entity := LineageNode{ResourceID: ResourceID{Name: "user", Type: ENTITY}}
+	valueColumn := LineageNode{ResourceID: sourceID, Column: "col2"}
+
+	lineage, err := client.GetLineage(context.Background(), sourceID, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	expectedEdges := []LineageEdge{
+		{inputSource, source, DEPENDENCY_EDGE},
+		{source, feature, DEPENDENCY_EDGE},
+		{valueColumn, feature, COLUMN_EDGE},
+		{source, label, DEPENDENCY_EDGE},
+		{feature, trainingSet, DEPENDENCY_EDGE},
+		{label, trainingSet, DEPENDENCY_EDGE},
+		{feature, model, MODEL_EDGE},
+		{trainingSet, model, MODEL_EDGE},
+	}
+	for _, edge := range expectedEdges {
+		if !hasLineageEdge(lineage, edge.Upstream, edge.Downstream, edge.Type) {
+			t.Fatalf("Expected lineage of %s to have edge %s, got %v", sourceID, edge, lineage.Edges)
+		}
+		if !hasLineageNode(lineage, edge.Upstream) || !hasLineageNode(lineage, edge.Downstream) {
+			t.Fatalf("Expected lineage of %s to have the nodes of edge %s, got %v", sourceID, edge, lineage.Nodes)
+		}
+	}
+
+	lineage, err = client.GetLineage(context.Background(), sourceID, 1)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	if !hasLineageEdge(lineage, source, feature, DEPENDENCY_EDGE) {
+		t.Fatalf("Expected lineage of depth 1 to have the source's features, got %v", lineage.Edges)
+	}
+	if hasLineageNode(lineage, trainingSet) || hasLineageNode(lineage, model) {
+		t.Fatalf("Expected lineage of depth 1 to stop at the source's features, got %v", lineage.Nodes)
+	}
+
+	lineage, err = client.GetLineage(context.Background(), model.ResourceID, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	for _, edge := range []LineageEdge{
+		{trainingSet, model, MODEL_EDGE},
+		{label, trainingSet, DEPENDENCY_EDGE},
+		{entity, feature, ENTITY_EDGE},
+		{valueColumn, feature, COLUMN_EDGE},
+		{inputSource, source, DEPENDENCY_EDGE},
+	} {
+		if !hasLineageEdge(lineage, edge.Upstream, edge.Downstream, edge.Type) {
+			t.Fatalf("Expected lineage of %s to have edge %s, got %v", model, edge, lineage.Edges)
+		}
+	}
+	for _, edge := range lineage.Edges {
+		if edge.Upstream == model {
+			t.Fatalf("Expected nothing downstream of a model, got %s", edge)
+		}
+	}
+
+	if _, err := client.GetLineage(context.Background(), ResourceID{Name: "missing", Variant: "var", Type: SOURCE_VARIANT}, 0); err == nil {
+		t.Fatalf("Expected lineage of a missing resource to fail")
+	}
+}
+
+func TestGetLineageFromStoredDependents(t *testing.T) {
+	defs := append(filledResourceDefs(),
+		SourceDef{
+			Name:        "derived",
+			Variant:     "var",
+			Description: "A transformation on a primary source",
+			Definition: TransformationSource{
+				TransformationType: SQLTransformationType{
+					Query:   "SELECT * FROM dummy",
+					Sources: []NameVariant{{Name: "mockSource", Variant: "var2"}},
+				},
+			},
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Tags:       Tags{},
+			Properties: Properties{},
+		},
+		FeatureDef{
+			Name:        "feature-expr",
+			Variant:     "variant",
+			Entity:      "user",
+			Type:        types.String,
+			Description: "Expression on feature variant",
+			Owner:       "Featureform",
+			Location: Expression{
+				Expression: `x > 10.0 ? "high" : "low"`,
+				Inputs:     map[string]NameVariant{"x": {Name: "feature", Variant: "variant"}},
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       SERVER_COMPUTED,
+		},
+		ModelDef{
+			Name:         "churn",
+			Description:  "churn model",
+			Features:     NameVariants{{"feature", "variant"}},
+			Trainingsets: NameVariants{},
+			Tags:         Tags{},
+			Properties:   Properties{},
+		},
+	)
+	ctx := testContext{
+		Defs: defs,
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	// Registering the model again with a training set updates it without propagating it to its dependencies.
+	if err := client.CreateModel(context.Background(), ModelDef{
+		Name:         "churn",
+		Description:  "churn model",
+		Features:     NameVariants{},
+		Trainingsets: NameVariants{{"training-set", "variant"}},
+		Tags:         Tags{},
+		Properties:   Properties{},
+	}); err != nil {
+		t.Fatalf("Failed to update model: %s", err)
+	}
+
+	primary := LineageNode{ResourceID: ResourceID{Name: "mockSource", Variant: "var2", Type: SOURCE_VARIANT}}
+	transformation := LineageNode{ResourceID: ResourceID{Name: "derived", Variant: "var", Type: SOURCE_VARIANT}}
+	source := LineageNode{ResourceID: ResourceID{Name: "mockSource", Variant: "var", Type: SOURCE_VARIANT}}
+	feature := LineageNode{ResourceID: ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}}
+	exprFeature := LineageNode{ResourceID: ResourceID{Name: "feature-expr", Variant: "variant", Type: FEATURE_VARIANT}}
+	trainingSet := LineageNode{ResourceID: ResourceID{Name: "training-set", Variant: "variant", Type: TRAINING_SET_VARIANT}}
+	model := LineageNode{ResourceID: ResourceID{Name: "churn", Type: MODEL}}
+
+	cases := []struct {
+		root ResourceID
+		edge LineageEdge
+	}{
+		{primary.ResourceID, LineageEdge{primary, transformation, DEPENDENCY_EDGE}},
+		{feature.ResourceID, LineageEdge{feature, exprFeature, DEPENDENCY_EDGE}},
+		{feature.ResourceID, LineageEdge{feature, model, MODEL_EDGE}},
+		{trainingSet.ResourceID, LineageEdge{trainingSet, model, MODEL_EDGE}},
+	}
+	for _, c := range cases {
+		lineage, err := client.GetLineage(context.Background(), c.root, 1)
+		if err != nil {
+			t.Fatalf("Failed to get lineage of %s: %s", c.root, err)
+		}
+		if !hasLineageEdge(lineage, c.edge.Upstream, c.edge.Downstream, c.edge.Type) {
+			t.Fatalf("Expected lineage of %s to have edge %s, got %v", c.root, c.edge, lineage.Edges)
+		}
+	}
+
+	// Stored dependents can include resources that only use the root indirectly; those aren't edges from it.
+	lineage, err := client.GetLineage(context.Background(), source.ResourceID, 1)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	for _, edge := range lineage.Edges {
+		if edge.Upstream.ResourceID == source.ResourceID && edge.Downstream == exprFeature {
+			t.Fatalf("Expected no edge from %s to %s, got %s", source, exprFeature, edge)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -39,6 +40,7 @@ type operation int
 
 const (
 	create_op operation = iota
+	delete_op
 )
 
 type ResourceType int32
@@ -106,12 +108,19 @@ const (
 	PENDING                  = ResourceStatus(pb.ResourceStatus_PENDING)
 	READY                    = ResourceStatus(pb.ResourceStatus_READY)
 	FAILED                   = ResourceStatus(pb.ResourceStatus_FAILED)
+	WARNING                  = ResourceStatus(pb.ResourceStatus_WARNING)
+	ARCHIVED                 = ResourceStatus(pb.ResourceStatus_ARCHIVED)
 )
 
 func (r ResourceStatus) String() string {
 	return pb.ResourceStatus_Status_name[int32(r)]
 }
 
+// IsReady returns true if the resource can be used, including when monitoring has flagged it with a warning.
+func (r ResourceStatus) IsReady() bool {
+	return r == READY || r == WARNING
+}
+
 func (r ResourceStatus) Serialized() pb.ResourceStatus_Status {
 	return pb.ResourceStatus_Status(r)
 }
@@ -121,6 +130,7 @@ type ComputationMode int32
 const (
 	PRECOMPUTED     ComputationMode = ComputationMode(pb.ComputationMode_PRECOMPUTED)
 	CLIENT_COMPUTED                 = ComputationMode(pb.ComputationMode_CLIENT_COMPUTED)
+	SERVER_COMPUTED                 = ComputationMode(pb.ComputationMode_SERVER_COMPUTED)
 )
 
 func (cm ComputationMode) Equals(mode pb.ComputationMode) bool {
@@ -255,6 +265,8 @@ type ResourceLookup interface {
 	SetJob(ResourceID, string) error
 	SetStatus(context.Context, ResourceID, pb.ResourceStatus) error
 	SetSchedule(ResourceID, string) error
+	SetDeleteJob(CoordinatorDeleteJob) error
+	Delete(ResourceID) error
 }
 
 type SearchWrapper struct {
@@ -266,29 +278,102 @@ func (wrapper SearchWrapper) Set(id ResourceID, res Resource) error {
 	if err := wrapper.ResourceLookup.Set(id, res); err != nil {
 		return err
 	}
+	return wrapper.Searcher.Upsert(SearchDocument(id, res))
+}
 
-	var allTags []string
-	switch res.(type) {
-	case *sourceVariantResource:
-		allTags = res.(*sourceVariantResource).serialized.Tags.Tag
-
-	case *featureVariantResource:
-		allTags = res.(*featureVariantResource).serialized.Tags.Tag
+// SetStatus reindexes the resource so that searches can filter by its new status.
+func (wrapper SearchWrapper) SetStatus(ctx context.Context, id ResourceID, status pb.ResourceStatus) error {
+	if err := wrapper.ResourceLookup.SetStatus(ctx, id, status); err != nil {
+		return err
+	}
+	res, err := wrapper.ResourceLookup.Lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	return wrapper.Searcher.Upsert(SearchDocument(id, res))
+}
 
-	case *labelVariantResource:
-		allTags = res.(*labelVariantResource).serialized.Tags.Tag
+// SearchDocument is how a resource is indexed for search.
+func SearchDocument(id ResourceID, res Resource) search.ResourceDoc {
+	doc := search.ResourceDoc{
+		Name:    id.Name,
+		Type:    id.Type.String(),
+		Variant: id.Variant,
+	}
+	if tagged, ok := res.Proto().(interface{ GetTags() *pb.Tags }); ok {
+		doc.Tags = tagged.GetTags().GetTag()
+	}
+	if owned, ok := res.Proto().(interface{ GetOwner() string }); ok {
+		doc.Owner = owned.GetOwner()
+	}
+	if statused, ok := res.Proto().(interface{ GetStatus() *pb.ResourceStatus }); ok && statused.GetStatus() != nil {
+		doc.Status = statused.GetStatus().Status.String()
+	}
+	return doc
+}
 
-	case *trainingSetVariantResource:
-		allTags = res.(*trainingSetVariantResource).serialized.Tags.Tag
+// Reindex brings a search index up to date with the resources in lookup. Indexes that can list their
+// documents only have changed documents upserted and removed ones deleted; others are cleared and refilled.
+func Reindex(searcher search.Searcher, lookup ResourceLookup) (int, error) {
+	resources, err := lookup.List()
+	if err != nil {
+		return 0, err
 	}
+	lister, canList := searcher.(search.DocumentLister)
+	if !canList {
+		if err := searcher.DeleteAll(); err != nil {
+			return 0, fferr.NewInternalError(err)
+		}
+	}
+	// Documents are keyed by their type, name and variant, which identify them in every index.
+	type docKey struct{ Type, Name, Variant string }
+	indexed := make(map[docKey]search.ResourceDoc)
+	if canList {
+		docs, err := lister.Documents()
+		if err != nil {
+			return 0, fferr.NewInternalError(err)
+		}
+		for _, doc := range docs {
+			indexed[docKey{doc.Type, doc.Name, doc.Variant}] = doc
+		}
+	}
+	for _, res := range resources {
+		doc := SearchDocument(res.ID(), res)
+		key := docKey{doc.Type, doc.Name, doc.Variant}
+		existing, isIndexed := indexed[key]
+		delete(indexed, key)
+		if isIndexed && sameSearchDocument(existing, doc) {
+			continue
+		}
+		if err := searcher.Upsert(doc); err != nil {
+			return 0, fferr.NewInternalError(err)
+		}
+	}
+	for _, stale := range indexed {
+		if err := searcher.Delete(stale); err != nil {
+			return 0, fferr.NewInternalError(err)
+		}
+	}
+	return len(resources), nil
+}
 
+// sameSearchDocument compares documents field by field, treating missing and empty tags alike as an index
+// may not store empty lists.
+func sameSearchDocument(a, b search.ResourceDoc) bool {
+	return a.Name == b.Name && a.Variant == b.Variant && a.Type == b.Type && a.Owner == b.Owner &&
+		a.Status == b.Status && slices.Equal(a.Tags, b.Tags)
+}
+
+func (wrapper SearchWrapper) Delete(id ResourceID) error {
+	if err := wrapper.ResourceLookup.Delete(id); err != nil {
+		return err
+	}
 	doc := search.ResourceDoc{
 		Name:    id.Name,
 		Type:    id.Type.String(),
-		Tags:    allTags,
 		Variant: id.Variant,
 	}
-	return wrapper.Searcher.Upsert(doc)
+	return wrapper.Searcher.Delete(doc)
 }
 
 type LocalResourceLookup map[ResourceID]Resource
@@ -383,6 +468,15 @@ func (lookup LocalResourceLookup) HasJob(id ResourceID) (bool, error) {
 	return false, nil
 }
 
+func (lookup LocalResourceLookup) SetDeleteJob(job CoordinatorDeleteJob) error {
+	return nil
+}
+
+func (lookup LocalResourceLookup) Delete(id ResourceID) error {
+	delete(lookup, id)
+	return nil
+}
+
 type SourceResource struct {
 	serialized *pb.Source
 }
@@ -412,6 +506,10 @@ func (this *SourceResource) Notify(lookup ResourceLookup, op operation, that Res
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants = removeVariant(this.serialized.Variants, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -487,6 +585,19 @@ func (sourceVariantResource *sourceVariantResource) Notify(lookup ResourceLookup
 	t := id.Type
 	key := id.Proto()
 	serialized := sourceVariantResource.serialized
+	if op == delete_op {
+		switch t {
+		case TRAINING_SET_VARIANT:
+			serialized.Trainingsets = removeNameVariant(serialized.Trainingsets, key)
+		case FEATURE_VARIANT:
+			serialized.Features = removeNameVariant(serialized.Features, key)
+		case LABEL_VARIANT:
+			serialized.Labels = removeNameVariant(serialized.Labels, key)
+		case SOURCE_VARIANT:
+			serialized.Transformations = removeNameVariant(serialized.Transformations, key)
+		}
+		return nil
+	}
 	switch t {
 	case TRAINING_SET_VARIANT:
 		serialized.Trainingsets = append(serialized.Trainingsets, key)
@@ -494,6 +605,8 @@ func (sourceVariantResource *sourceVariantResource) Notify(lookup ResourceLookup
 		serialized.Features = append(serialized.Features, key)
 	case LABEL_VARIANT:
 		serialized.Labels = append(serialized.Labels, key)
+	case SOURCE_VARIANT:
+		serialized.Transformations = unionNameVariants(serialized.Transformations, []*pb.NameVariant{key})
 	}
 	return nil
 }
@@ -632,6 +745,10 @@ func (this *featureResource) Notify(lookup ResourceLookup, op operation, that Re
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants = removeVariant(this.serialized.Variants, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -706,6 +823,15 @@ func (resource *featureVariantResource) Dependencies(lookup ResourceLookup) (Res
 			})
 		}
 	}
+	if SERVER_COMPUTED.Equals(serialized.Mode) {
+		for _, input := range serialized.GetExpression().GetInputs() {
+			depIds = append(depIds, ResourceID{
+				Name:    input.Name,
+				Variant: input.Variant,
+				Type:    FEATURE_VARIANT,
+			})
+		}
+	}
 	deps, err := lookup.Submap(depIds)
 	if err != nil {
 		return nil, err
@@ -718,15 +844,26 @@ func (resource *featureVariantResource) Proto() proto.Message {
 }
 
 func (this *featureVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	id := that.ID()
+	switch id.Type {
+	case FEATURE_VARIANT:
+		this.serialized.DependentFeatures = updateNameVariants(this.serialized.DependentFeatures, op, id.Proto())
+		return nil
+	case MODEL:
+		this.serialized.Models = updateModels(this.serialized.Models, op, id.Name)
+		return nil
+	}
 	if !PRECOMPUTED.Equals(this.serialized.Mode) {
 		return nil
 	}
-	id := that.ID()
-	relevantOp := op == create_op && id.Type == TRAINING_SET_VARIANT
-	if !relevantOp {
+	if id.Type != TRAINING_SET_VARIANT {
 		return nil
 	}
 	key := id.Proto()
+	if op == delete_op {
+		this.serialized.Trainingsets = removeNameVariant(this.serialized.Trainingsets, key)
+		return nil
+	}
 	this.serialized.Trainingsets = append(this.serialized.Trainingsets, key)
 	return nil
 }
@@ -767,6 +904,7 @@ func (resource *featureVariantResource) IsEquivalent(other ResourceVariant) (boo
 	- Entity
 	- Owner
 	- Type
+	- TTL
 	*/
 
 	otherVariant, ok := other.(*featureVariantResource)
@@ -789,6 +927,7 @@ func (resource *featureVariantResource) IsEquivalent(other ResourceVariant) (boo
 		thisProto.GetProvider() == otherProto.GetProvider() &&
 		thisProto.GetEntity() == otherProto.GetEntity() &&
 		proto.Equal(thisProto.GetType(), otherProto.GetType()) &&
+		thisProto.GetTtl().AsDuration() == otherProto.GetTtl().AsDuration() &&
 		isEquivalentLocation &&
 		thisProto.Owner == otherProto.Owner {
 
@@ -842,6 +981,10 @@ func (this *labelResource) Notify(lookup ResourceLookup, op operation, that Reso
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants = removeVariant(this.serialized.Variants, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -923,11 +1066,18 @@ func (resource *labelVariantResource) Proto() proto.Message {
 
 func (this *labelVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
 	id := that.ID()
-	releventOp := op == create_op && id.Type == TRAINING_SET_VARIANT
-	if !releventOp {
+	if id.Type == MODEL {
+		this.serialized.Models = updateModels(this.serialized.Models, op, id.Name)
+		return nil
+	}
+	if id.Type != TRAINING_SET_VARIANT {
 		return nil
 	}
 	key := id.Proto()
+	if op == delete_op {
+		this.serialized.Trainingsets = removeNameVariant(this.serialized.Trainingsets, key)
+		return nil
+	}
 	this.serialized.Trainingsets = append(this.serialized.Trainingsets, key)
 	return nil
 }
@@ -1020,6 +1170,10 @@ func (this *trainingSetResource) Notify(lookup ResourceLookup, op operation, tha
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants = removeVariant(this.serialized.Variants, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -1091,6 +1245,13 @@ func (resource *trainingSetVariantResource) Dependencies(lookup ResourceLookup)
 			Type:    FEATURE_VARIANT,
 		})
 	}
+	if filter := serialized.GetEntityFilter(); filter != nil {
+		depIds = append(depIds, ResourceID{
+			Name:    filter.GetSource().GetName(),
+			Variant: filter.GetSource().GetVariant(),
+			Type:    SOURCE_VARIANT,
+		})
+	}
 	deps, err := lookup.Submap(depIds)
 	if err != nil {
 		return nil, err
@@ -1103,6 +1264,9 @@ func (resource *trainingSetVariantResource) Proto() proto.Message {
 }
 
 func (this *trainingSetVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	if id := that.ID(); id.Type == MODEL {
+		this.serialized.Models = updateModels(this.serialized.Models, op, id.Name)
+	}
 	return nil
 }
 
@@ -1280,6 +1444,19 @@ func (this *userResource) Notify(lookup ResourceLookup, op operation, that Resou
 	key := id.Proto()
 	t := id.Type
 	serialized := this.serialized
+	if op == delete_op {
+		switch t {
+		case TRAINING_SET_VARIANT:
+			serialized.Trainingsets = removeNameVariant(serialized.Trainingsets, key)
+		case FEATURE_VARIANT:
+			serialized.Features = removeNameVariant(serialized.Features, key)
+		case LABEL_VARIANT:
+			serialized.Labels = removeNameVariant(serialized.Labels, key)
+		case SOURCE_VARIANT:
+			serialized.Sources = removeNameVariant(serialized.Sources, key)
+		}
+		return nil
+	}
 	switch t {
 	case TRAINING_SET_VARIANT:
 		serialized.Trainingsets = append(serialized.Trainingsets, key)
@@ -1350,6 +1527,19 @@ func (this *providerResource) Notify(lookup ResourceLookup, op operation, that R
 	key := id.Proto()
 	t := id.Type
 	serialized := this.serialized
+	if op == delete_op {
+		switch t {
+		case SOURCE_VARIANT:
+			serialized.Sources = removeNameVariant(serialized.Sources, key)
+		case FEATURE_VARIANT:
+			serialized.Features = removeNameVariant(serialized.Features, key)
+		case TRAINING_SET_VARIANT:
+			serialized.Trainingsets = removeNameVariant(serialized.Trainingsets, key)
+		case LABEL_VARIANT:
+			serialized.Labels = removeNameVariant(serialized.Labels, key)
+		}
+		return nil
+	}
 	switch t {
 	case SOURCE_VARIANT:
 		serialized.Sources = append(serialized.Sources, key)
@@ -1402,6 +1592,9 @@ func (resource *providerResource) Update(lookup ResourceLookup, resourceUpdate R
 	return nil
 }
 
+// isValidConfigUpdate checks that an update only changes mutable config fields. Secret references are
+// compared as written and never resolved, so pointing an immutable field at another secret is rejected even
+// if both hold the same value, and rotating the secret a reference points at isn't an update at all.
 func (resource *providerResource) isValidConfigUpdate(configUpdate pc.SerializedConfig) (bool, error) {
 	switch pt.Type(resource.serialized.Type) {
 	case pt.BigQueryOffline:
@@ -1414,10 +1607,12 @@ func (resource *providerResource) isValidConfigUpdate(configUpdate pc.Serialized
 		return isValidFirestoreConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.MongoDBOnline:
 		return isValidMongoConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
-	case pt.PostgresOffline:
+	case pt.PostgresOffline, pt.PostgresOnline:
 		return isValidPostgresConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.ClickHouseOffline:
 		return isValidClickHouseConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.DuckDBOffline:
+		return isValidDuckDBConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.RedisOnline:
 		return isValidRedisConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SnowflakeOffline:
@@ -1463,6 +1658,17 @@ func (this *entityResource) Notify(lookup ResourceLookup, op operation, that Res
 	key := id.Proto()
 	t := id.Type
 	serialized := this.serialized
+	if op == delete_op {
+		switch t {
+		case TRAINING_SET_VARIANT:
+			serialized.Trainingsets = removeNameVariant(serialized.Trainingsets, key)
+		case FEATURE_VARIANT:
+			serialized.Features = removeNameVariant(serialized.Features, key)
+		case LABEL_VARIANT:
+			serialized.Labels = removeNameVariant(serialized.Labels, key)
+		}
+		return nil
+	}
 	switch t {
 	case TRAINING_SET_VARIANT:
 		serialized.Trainingsets = append(serialized.Trainingsets, key)
@@ -1504,6 +1710,9 @@ type MetadataServer struct {
 	address    string
 	grpcServer *grpc.Server
 	listener   net.Listener
+	// authorizer is nil when access control is off.
+	authorizer  helpers.Authorizer
+	credentials credentials.TransportCredentials
 	pb.UnimplementedMetadataServer
 }
 
@@ -1526,11 +1735,16 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 			ResourceLookup: lookup,
 		}
 	}
-	return &MetadataServer{
-		lookup:  lookup,
-		address: config.Address,
-		Logger:  config.Logger,
-	}, nil
+	serv := &MetadataServer{
+		lookup:      lookup,
+		address:     config.Address,
+		Logger:      config.Logger,
+		credentials: config.Credentials,
+	}
+	if config.RBAC != nil {
+		serv.authorizer = newServerRBACAuthorizer(lookup, *config.RBAC)
+	}
+	return serv, nil
 }
 
 func (serv *MetadataServer) Serve() error {
@@ -1546,7 +1760,17 @@ func (serv *MetadataServer) Serve() error {
 
 func (serv *MetadataServer) ServeOnListener(lis net.Listener) error {
 	serv.listener = lis
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(helpers.UnaryServerErrorInterceptor), grpc.StreamInterceptor(helpers.StreamServerErrorInterceptor))
+	unary := []grpc.UnaryServerInterceptor{helpers.UnaryServerErrorInterceptor}
+	stream := []grpc.StreamServerInterceptor{helpers.StreamServerErrorInterceptor}
+	if serv.authorizer != nil {
+		unary = append(unary, helpers.UnaryServerAuthInterceptor(serv.authorizer))
+		stream = append(stream, helpers.StreamServerAuthInterceptor(serv.authorizer))
+	}
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+	if serv.credentials != nil {
+		opts = append(opts, grpc.Creds(serv.credentials))
+	}
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterMetadataServer(grpcServer, serv)
 	serv.grpcServer = grpcServer
 	serv.Logger.Infow("Server starting", "Address", serv.listener.Addr().String())
@@ -1608,6 +1832,10 @@ type Config struct {
 	SearchParams    *search.MeilisearchParams
 	StorageProvider StorageProvider
 	Address         string
+	// RBAC turns on access control when set.
+	RBAC *RBACConfig
+	// Credentials serves over TLS when set, which lets callers be identified by their client certificates.
+	Credentials credentials.TransportCredentials
 }
 
 func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.ScheduleChangeRequest) (*pb.Empty, error) {
@@ -1627,6 +1855,63 @@ func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetSt
 	return &pb.Empty{}, err
 }
 
+// SetSourceSchema records the columns a source variant's table had when it was last registered, so that
+// re-runs can detect columns that were dropped or changed type underneath its features and labels.
+func (serv *MetadataServer) SetSourceSchema(ctx context.Context, req *pb.SetSourceSchemaRequest) (*pb.Empty, error) {
+	logger := logging.GetLoggerFromContext(ctx)
+	resID := ResourceID{Name: req.Source.Name, Variant: req.Source.Variant, Type: SOURCE_VARIANT}
+	logger.Infow("Setting source schema", "resource_id", resID, "columns", len(req.Schema.GetColumns()))
+	res, err := serv.lookup.Lookup(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := res.(*sourceVariantResource)
+	if !ok {
+		return nil, fferr.NewInvalidResourceTypeError(resID.Name, resID.Variant, fferr.SOURCE_VARIANT, nil)
+	}
+	source.serialized.Schema = req.Schema
+	if err := serv.lookup.Set(resID, source); err != nil {
+		logger.Errorw("Could not set source schema", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *MetadataServer) DeleteFeatureVariant(ctx context.Context, req *pb.DeleteRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logging.GetLoggerFromContext(ctx).WithResource(logging.FeatureVariant, req.NameVariant.Name, req.NameVariant.Variant).Info("Deleting Feature Variant")
+	id := ResourceID{Name: req.NameVariant.Name, Variant: req.NameVariant.Variant, Type: FEATURE_VARIANT}
+	return serv.genericDelete(ctx, id, req.Cascade, req.Archive)
+}
+
+func (serv *MetadataServer) DeleteLabelVariant(ctx context.Context, req *pb.DeleteRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logging.GetLoggerFromContext(ctx).WithResource(logging.LabelVariant, req.NameVariant.Name, req.NameVariant.Variant).Info("Deleting Label Variant")
+	id := ResourceID{Name: req.NameVariant.Name, Variant: req.NameVariant.Variant, Type: LABEL_VARIANT}
+	return serv.genericDelete(ctx, id, req.Cascade, req.Archive)
+}
+
+func (serv *MetadataServer) DeleteSourceVariant(ctx context.Context, req *pb.DeleteRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logging.GetLoggerFromContext(ctx).WithResource(logging.SourceVariant, req.NameVariant.Name, req.NameVariant.Variant).Info("Deleting Source Variant")
+	id := ResourceID{Name: req.NameVariant.Name, Variant: req.NameVariant.Variant, Type: SOURCE_VARIANT}
+	return serv.genericDelete(ctx, id, req.Cascade, req.Archive)
+}
+
+func (serv *MetadataServer) DeleteTrainingSetVariant(ctx context.Context, req *pb.DeleteRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logging.GetLoggerFromContext(ctx).WithResource(logging.TrainingSetVariant, req.NameVariant.Name, req.NameVariant.Variant).Info("Deleting Training Set Variant")
+	id := ResourceID{Name: req.NameVariant.Name, Variant: req.NameVariant.Variant, Type: TRAINING_SET_VARIANT}
+	return serv.genericDelete(ctx, id, req.Cascade, req.Archive)
+}
+
+func (serv *MetadataServer) DeleteModel(ctx context.Context, req *pb.DeleteRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logging.GetLoggerFromContext(ctx).WithResource(logging.Model, req.NameVariant.Name, "").Info("Deleting Model")
+	id := ResourceID{Name: req.NameVariant.Name, Type: MODEL}
+	return serv.genericDelete(ctx, id, req.Cascade, req.Archive)
+}
+
 func (serv *MetadataServer) ListFeatures(request *pb.ListRequest, stream pb.Metadata_ListFeaturesServer) error {
 	ctx := logging.AttachRequestID(request.RequestId, stream.Context(), serv.Logger)
 	logging.GetLoggerFromContext(ctx).Info("Opened List Features stream")
@@ -1641,6 +1926,13 @@ func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variantReq
 	logger.Info("Creating Feature Variant")
 
 	variant := variantRequest.FeatureVariant
+	variant.Owner = ownerFromContext(ctx, variant.Owner)
+	if expr, ok := wrapProtoFeatureVariant(variant).LocationExpression().(Expression); ok {
+		if _, err := expr.Compile(); err != nil {
+			logger.Errorw("Invalid feature expression", "error", err)
+			return nil, err
+		}
+	}
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &featureVariantResource{variant}, func(name, variant string) Resource {
 		return &featureResource{
@@ -1684,6 +1976,7 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variantReque
 	logger.Info("Creating Label Variant")
 
 	variant := variantRequest.LabelVariant
+	variant.Owner = ownerFromContext(ctx, variant.Owner)
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &labelVariantResource{variant}, func(name, variant string) Resource {
 		return &labelResource{
@@ -1727,6 +2020,7 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, varian
 	logger.Info("Creating TrainingSet Variant")
 
 	variant := variantRequest.TrainingSetVariant
+	variant.Owner = ownerFromContext(ctx, variant.Owner)
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &trainingSetVariantResource{variant}, func(name, variant string) Resource {
 		return &trainingSetResource{
@@ -1770,6 +2064,7 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variantRequ
 	logger.Info("Creating Source Variant")
 
 	variant := variantRequest.SourceVariant
+	variant.Owner = ownerFromContext(ctx, variant.Owner)
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &sourceVariantResource{variant}, func(name, variant string) Resource {
 		return &SourceResource{
@@ -1812,9 +2107,42 @@ func (serv *MetadataServer) CreateUser(ctx context.Context, userRequest *pb.User
 	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.User, userRequest.User.Name, logging.NoVariant)
 	logger.Info("Creating User")
 
+	// Roles and teams can only be assigned through SetUserAccess.
+	userRequest.User.Role = pb.Role_ROLE_UNASSIGNED
+	userRequest.User.Teams = nil
 	return serv.genericCreate(ctx, &userResource{userRequest.User}, nil)
 }
 
+func (serv *MetadataServer) SetUserAccess(ctx context.Context, req *pb.UserAccessRequest) (*pb.Empty, error) {
+	ctx = logging.AttachRequestID(req.RequestId, ctx, serv.Logger)
+	logger := logging.GetLoggerFromContext(ctx).WithResource(logging.User, req.User, logging.NoVariant)
+	logger.Infow("Setting user access", "role", Role(req.Role), "teams", req.Teams)
+	resID := ResourceID{Name: req.User, Type: USER}
+	has, err := serv.lookup.Has(resID)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		user := &pb.User{Name: req.User, Role: req.Role, Teams: req.Teams, Tags: &pb.Tags{}, Properties: &pb.Properties{}}
+		return serv.genericCreate(ctx, &userResource{user}, nil)
+	}
+	res, err := serv.lookup.Lookup(ctx, resID)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := res.(*userResource)
+	if !ok {
+		return nil, fferr.NewInvalidResourceTypeError(resID.Name, "", fferr.USER, nil)
+	}
+	user.serialized.Role = req.Role
+	user.serialized.Teams = req.Teams
+	if err := serv.lookup.Set(resID, user); err != nil {
+		logger.Errorw("Could not set user access", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
 func (serv *MetadataServer) GetUsers(stream pb.Metadata_GetUsersServer) error {
 	ctx := logging.AddLoggerToContext(stream.Context(), serv.Logger)
 	serv.Logger.Info("Opened Get Users stream")
@@ -1977,6 +2305,7 @@ func isValidStatusForEquivalent(res Resource) bool {
 	resourceStatus := res.GetStatus()
 	equivalentStatuses := mapset.NewSet(
 		pb.ResourceStatus_READY,
+		pb.ResourceStatus_WARNING,
 		pb.ResourceStatus_PENDING,
 		pb.ResourceStatus_RUNNING,
 		pb.ResourceStatus_CREATED,
@@ -2066,6 +2395,10 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 			return nil, err
 		}
 	}
+	if err := serv.notifyLineageInputs(ctx, res, create_op); err != nil {
+		logger.Errorw("Error notifying lineage inputs", "error", err)
+		return nil, err
+	}
 	return &pb.Empty{}, nil
 }
 
@@ -2116,7 +2449,7 @@ func (serv *MetadataServer) validateExisting(newRes Resource, existing Resource)
 
 func (serv *MetadataServer) isEquivalent(newRes Resource, existing Resource) (bool, error) {
 	// only works on resource variants right now
-	if existing.GetStatus() != nil && existing.GetStatus().Status != pb.ResourceStatus_READY {
+	if existing.GetStatus() != nil && !ResourceStatus(existing.GetStatus().Status).IsReady() {
 		return false, nil
 	}
 
@@ -2136,6 +2469,14 @@ func (serv *MetadataServer) isEquivalent(newRes Resource, existing Resource) (bo
 }
 
 func (serv *MetadataServer) propagateChange(newRes Resource) error {
+	return serv.propagate(newRes, create_op)
+}
+
+func (serv *MetadataServer) propagateDelete(deletedRes Resource) error {
+	return serv.propagate(deletedRes, delete_op)
+}
+
+func (serv *MetadataServer) propagate(newRes Resource, op operation) error {
 	visited := make(map[ResourceID]struct{})
 	// We have to make it a var so that the anonymous function can call itself.
 	var propagateChange func(parent Resource) error
@@ -2154,7 +2495,7 @@ func (serv *MetadataServer) propagateChange(newRes Resource) error {
 				continue
 			}
 			visited[id] = struct{}{}
-			if err := res.Notify(serv.lookup, create_op, newRes); err != nil {
+			if err := res.Notify(serv.lookup, op, newRes); err != nil {
 				return err
 			}
 			if err := serv.lookup.Set(res.ID(), res); err != nil {
@@ -2169,6 +2510,193 @@ func (serv *MetadataServer) propagateChange(newRes Resource) error {
 	return propagateChange(newRes)
 }
 
+// dependentTypes are the resource types that can depend on another resource variant.
+var dependentTypes = []ResourceType{
+	SOURCE_VARIANT,
+	FEATURE_VARIANT,
+	LABEL_VARIANT,
+	TRAINING_SET_VARIANT,
+	MODEL,
+}
+
+func (serv *MetadataServer) dependents(id ResourceID) ([]Resource, error) {
+	dependents := make([]Resource, 0)
+	for _, t := range dependentTypes {
+		resources, err := serv.lookup.ListForType(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range resources {
+			if res.ID() == id {
+				continue
+			}
+			deps, err := res.Dependencies(serv.lookup)
+			if err != nil {
+				return nil, err
+			}
+			if has, err := deps.Has(id); err != nil {
+				return nil, err
+			} else if has {
+				dependents = append(dependents, res)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// genericDelete removes a resource, or with archive marks it as ARCHIVED and keeps it and its data. Either
+// way it refuses while other resources depend on it unless cascade is set, in which case they're deleted
+// or archived first. Dependents that are already archived don't block archiving.
+func (serv *MetadataServer) genericDelete(ctx context.Context, id ResourceID, cascade, archive bool) (*pb.Empty, error) {
+	logger := logging.GetLoggerFromContext(ctx).WithResource(id.Type.ToLoggingResourceType(), id.Name, id.Variant)
+	logger.Debugw("Deleting Generic Resource", "cascade", cascade, "archive", archive)
+
+	if archive && id.Type == MODEL {
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("models can't be archived"))
+	}
+	res, err := serv.lookup.Lookup(ctx, id)
+	if err != nil {
+		logger.Errorw("Error looking up resource", "error", err)
+		return nil, err
+	}
+	dependents, err := serv.dependents(id)
+	if err != nil {
+		logger.Errorw("Error finding dependents", "error", err)
+		return nil, err
+	}
+	if archive {
+		dependents = slices.DeleteFunc(dependents, func(dep Resource) bool {
+			return dep.ID().Type == MODEL || isArchived(dep)
+		})
+	}
+	if len(dependents) > 0 && !cascade {
+		dependentNames := make([]string, len(dependents))
+		for i, dep := range dependents {
+			dependentNames[i] = dep.ID().String()
+		}
+		return nil, fferr.NewResourceHasDependentsError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), dependentNames, nil)
+	}
+	for _, dep := range dependents {
+		// A dependent may already have been removed while cascading through another one.
+		if has, err := serv.lookup.Has(dep.ID()); err != nil {
+			return nil, err
+		} else if !has {
+			continue
+		}
+		logger.Infow("Cascading delete to dependent", "dependent", dep.ID().String())
+		if _, err := serv.genericDelete(ctx, dep.ID(), cascade, archive); err != nil {
+			return nil, err
+		}
+	}
+	if archive {
+		if err := serv.lookup.SetStatus(ctx, id, pb.ResourceStatus{Status: pb.ResourceStatus_ARCHIVED}); err != nil {
+			logger.Errorw("Error archiving resource", "error", err)
+			return nil, err
+		}
+		logger.Info("Successfully Archived Resource")
+		return &pb.Empty{}, nil
+	}
+	if err := serv.setDeleteJob(ctx, res); err != nil {
+		logger.Errorw("Error creating delete job", "error", err)
+		return nil, err
+	}
+	if err := serv.propagateDelete(res); err != nil {
+		logger.Errorw("Error propagating delete", "error", err)
+		return nil, err
+	}
+	if err := serv.notifyLineageInputs(ctx, res, delete_op); err != nil {
+		logger.Errorw("Error notifying lineage inputs", "error", err)
+		return nil, err
+	}
+	if err := serv.lookup.Delete(id); err != nil {
+		logger.Errorw("Error deleting resource from lookup", "error", err)
+		return nil, err
+	}
+	if parentId, hasParent := id.Parent(); hasParent {
+		if err := serv.cleanupParent(ctx, parentId); err != nil {
+			logger.Errorw("Error updating parent", "parent-id", parentId, "error", err)
+			return nil, err
+		}
+	}
+	logger.Info("Successfully Deleted Resource")
+	return &pb.Empty{}, nil
+}
+
+func isArchived(res Resource) bool {
+	status := res.GetStatus()
+	return status != nil && status.Status == pb.ResourceStatus_ARCHIVED
+}
+
+// setDeleteJob asks the coordinator to drop the tables backing a deleted variant: the resource table and
+// materialization of a precomputed feature, the resource table of a label, the primary or transformation
+// table of a source and the table of a training set.
+func (serv *MetadataServer) setDeleteJob(ctx context.Context, res Resource) error {
+	job := CoordinatorDeleteJob{Resource: res.ID()}
+	switch casted := res.(type) {
+	case *featureVariantResource:
+		if !PRECOMPUTED.Equals(casted.serialized.Mode) {
+			return nil
+		}
+		provider, err := serv.sourceProvider(ctx, casted.serialized.Source)
+		if err != nil {
+			return err
+		}
+		job.OfflineProvider = provider
+		job.OnlineProvider = casted.serialized.Provider
+	case *labelVariantResource:
+		provider, err := serv.sourceProvider(ctx, casted.serialized.Source)
+		if err != nil {
+			return err
+		}
+		job.OfflineProvider = provider
+	case *sourceVariantResource:
+		job.OfflineProvider = casted.serialized.Provider
+		job.Transformation = casted.serialized.GetTransformation() != nil
+	case *trainingSetVariantResource:
+		job.OfflineProvider = casted.serialized.Provider
+	default:
+		return nil
+	}
+	return serv.lookup.SetDeleteJob(job)
+}
+
+func (serv *MetadataServer) sourceProvider(ctx context.Context, source *pb.NameVariant) (string, error) {
+	sourceId := ResourceID{Name: source.Name, Variant: source.Variant, Type: SOURCE_VARIANT}
+	res, err := serv.lookup.Lookup(ctx, sourceId)
+	if err != nil {
+		return "", err
+	}
+	return res.(*sourceVariantResource).serialized.Provider, nil
+}
+
+// cleanupParent removes a parent once its last variant is gone, otherwise it makes sure
+// the default variant still points at a variant that exists.
+func (serv *MetadataServer) cleanupParent(ctx context.Context, parentId ResourceID) error {
+	parent, err := serv.lookup.Lookup(ctx, parentId)
+	if err != nil {
+		return err
+	}
+	var variants []string
+	var defaultVariant string
+	switch casted := parent.(type) {
+	case *SourceResource:
+		variants, defaultVariant = casted.serialized.Variants, casted.serialized.DefaultVariant
+	case *featureResource:
+		variants, defaultVariant = casted.serialized.Variants, casted.serialized.DefaultVariant
+	case *labelResource:
+		variants, defaultVariant = casted.serialized.Variants, casted.serialized.DefaultVariant
+	case *trainingSetResource:
+		variants, defaultVariant = casted.serialized.Variants, casted.serialized.DefaultVariant
+	}
+	if len(variants) == 0 {
+		return serv.lookup.Delete(parentId)
+	}
+	if slices.Contains(variants, defaultVariant) {
+		return nil
+	}
+	return serv.setDefaultVariant(ctx, parentId, variants[len(variants)-1])
+}
+
 func (serv *MetadataServer) genericGet(ctx context.Context, stream interface{}, t ResourceType, send sendFn) error {
 	logger := logging.GetLoggerFromContext(ctx)
 	for {
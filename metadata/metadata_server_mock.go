@@ -201,6 +201,30 @@ func (MetadataServerMock) GetModels(ctx context.Context, opts ...grpc.CallOption
 func (MetadataServerMock) SetResourceStatus(ctx context.Context, in *pb.SetStatusRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
 	return nil, nil
 }
+func (MetadataServerMock) SetSourceSchema(ctx context.Context, in *pb.SetSourceSchemaRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) SetUserAccess(ctx context.Context, in *pb.UserAccessRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetLineage(ctx context.Context, in *pb.LineageRequest, opts ...grpc.CallOption) (*pb.Lineage, error) {
+	return nil, nil
+}
 func (MetadataServerMock) RequestScheduleChange(ctx context.Context, in *pb.ScheduleChangeRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
 	return nil, nil
 }
+func (MetadataServerMock) DeleteFeatureVariant(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) DeleteLabelVariant(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) DeleteSourceVariant(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) DeleteTrainingSetVariant(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) DeleteModel(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
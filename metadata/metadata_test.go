@@ -2,15 +2,19 @@ package metadata
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/featureform/fferr"
 	"github.com/featureform/logging"
 	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metadata/search"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	grpc_status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
@@ -1478,6 +1482,106 @@ func TestTrainingSet(t *testing.T) {
 	testGetResources(t, TRAINING_SET_VARIANT, expectedTrainingSetVariants())
 }
 
+func TestDeleteFeatureVariant(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	featureId := NameVariant{"feature", "variant"}
+	err = client.DeleteFeatureVariant(context.Background(), featureId, false)
+	if err == nil {
+		t.Fatalf("Expected delete of feature variant with dependents to fail without cascade")
+	}
+	if _, err := client.GetTrainingSetVariant(context.Background(), NameVariant{"training-set", "variant"}); err != nil {
+		t.Fatalf("Failed delete should not remove dependents: %s", err)
+	}
+
+	if err := client.DeleteFeatureVariant(context.Background(), featureId, true); err != nil {
+		t.Fatalf("Failed to cascade delete feature variant: %s", err)
+	}
+	if _, err := client.GetFeatureVariant(context.Background(), featureId); err == nil {
+		t.Fatalf("Expected feature variant to be deleted")
+	}
+	if _, err := client.GetTrainingSetVariant(context.Background(), NameVariant{"training-set", "variant"}); err == nil {
+		t.Fatalf("Expected dependent training set variant to be deleted")
+	}
+	feature, err := client.GetFeature(context.Background(), "feature")
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	assertEqual(t, feature.Variants(), []string{"variant2"})
+	assertEqual(t, feature.DefaultVariant(), "variant2")
+	trainingSet, err := client.GetTrainingSet(context.Background(), "training-set")
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	assertEqual(t, trainingSet.Variants(), []string{"variant2"})
+	source, err := client.GetSourceVariant(context.Background(), NameVariant{"mockSource", "var"})
+	if err != nil {
+		t.Fatalf("Failed to get source variant: %s", err)
+	}
+	for _, feature := range source.Features() {
+		if feature == featureId {
+			t.Fatalf("Source variant still references deleted feature variant")
+		}
+	}
+
+	if err := client.DeleteTrainingSetVariant(context.Background(), NameVariant{"training-set", "variant2"}, false); err != nil {
+		t.Fatalf("Failed to delete training set variant: %s", err)
+	}
+	if _, err := client.GetTrainingSet(context.Background(), "training-set"); err == nil {
+		t.Fatalf("Expected training set to be deleted with its last variant")
+	}
+}
+
+func TestArchiveFeatureVariant(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	featureId := NameVariant{"feature", "variant"}
+	trainingSetId := NameVariant{"training-set", "variant"}
+	if err := client.ArchiveFeatureVariant(context.Background(), featureId, false); err == nil {
+		t.Fatalf("Expected archive of feature variant with dependents to fail without cascade")
+	}
+	if err := client.ArchiveTrainingSetVariant(context.Background(), trainingSetId, false); err != nil {
+		t.Fatalf("Failed to archive training set variant: %s", err)
+	}
+	if err := client.ArchiveFeatureVariant(context.Background(), featureId, false); err != nil {
+		t.Fatalf("Expected archived dependents not to block archiving: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(context.Background(), featureId)
+	if err != nil {
+		t.Fatalf("Expected archived feature variant to be kept: %s", err)
+	}
+	assertEqual(t, feature.Status(), ARCHIVED)
+	trainingSet, err := client.GetTrainingSetVariant(context.Background(), trainingSetId)
+	if err != nil {
+		t.Fatalf("Expected archived training set variant to be kept: %s", err)
+	}
+	assertEqual(t, trainingSet.Status(), ARCHIVED)
+
+	labelId := NameVariant{"label", "variant"}
+	if err := client.ArchiveLabelVariant(context.Background(), labelId, true); err != nil {
+		t.Fatalf("Failed to cascade archive label variant: %s", err)
+	}
+	label, err := client.GetLabelVariant(context.Background(), labelId)
+	if err != nil {
+		t.Fatalf("Expected archived label variant to be kept: %s", err)
+	}
+	assertEqual(t, label.Status(), ARCHIVED)
+}
+
 type ModelTest struct {
 	Name         string
 	Description  string
@@ -1651,6 +1755,45 @@ func testResourceUpdates(t *testing.T, typ ResourceType, arranged, expected Reso
 	}
 }
 
+func TestSetSourceSchema(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	sourceId := NameVariant{"mockSource", "var"}
+	source, err := client.GetSourceVariant(context.Background(), sourceId)
+	if err != nil {
+		t.Fatalf("Failed to get source variant: %s", err)
+	}
+	if source.Schema() != nil {
+		t.Fatalf("Expected no schema before one is recorded, got %v", source.Schema())
+	}
+	schema := []SourceColumn{
+		{Name: "entity", Type: "VARCHAR"},
+		{Name: "value", Type: "BIGINT"},
+		{Name: "ts", Type: "TIMESTAMP"},
+	}
+	if err := client.SetSourceSchema(context.Background(), sourceId, schema); err != nil {
+		t.Fatalf("Failed to set source schema: %s", err)
+	}
+	source, err = client.GetSourceVariant(context.Background(), sourceId)
+	if err != nil {
+		t.Fatalf("Failed to get source variant: %s", err)
+	}
+	assertEqual(t, source.Schema(), schema)
+	if len(source.Features()) == 0 {
+		t.Fatalf("Setting the schema should not drop the source's features")
+	}
+	if err := client.SetSourceSchema(context.Background(), NameVariant{"mockSource", "missing"}, schema); err == nil {
+		t.Fatalf("Expected setting the schema of a missing source variant to fail")
+	}
+}
+
 func TestModel(t *testing.T) {
 	testListResources(t, MODEL, expectedModels())
 	testGetResources(t, MODEL, expectedModels())
@@ -2771,3 +2914,121 @@ func Test_findEquivalentStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestReindexSearch(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	if _, err := ctx.Create(t); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	searcher, err := search.NewBleve(&search.BleveParams{Path: t.TempDir() + "/index.bleve"})
+	if err != nil {
+		t.Fatalf("Failed to open index: %s", err)
+	}
+	defer searcher.Close()
+	indexed, err := Reindex(searcher, ctx.serv.lookup)
+	if err != nil {
+		t.Fatalf("Failed to reindex: %s", err)
+	}
+	if indexed == 0 {
+		t.Fatalf("Expected resources to be indexed")
+	}
+	results, err := searcher.RunSearchWithFilter("mockSource", search.Filter{Type: SOURCE_VARIANT.String(), Owner: "Featureform"})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected both variants of mockSource, got %v", results)
+	}
+
+	wrapper := SearchWrapper{Searcher: searcher, ResourceLookup: ctx.serv.lookup}
+	id := ResourceID{Name: "mockSource", Variant: "var2", Type: SOURCE_VARIANT}
+	if err := wrapper.SetStatus(context.Background(), id, pb.ResourceStatus{Status: pb.ResourceStatus_READY}); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	results, err = searcher.RunSearchWithFilter("mockSource", search.Filter{Status: pb.ResourceStatus_READY.String()})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != 1 || results[0].Variant != "var2" {
+		t.Fatalf("Expected status changes to be indexed, got %v", results)
+	}
+
+	if err := ctx.serv.lookup.Delete(ResourceID{Name: "mockSource", Variant: "var", Type: SOURCE_VARIANT}); err != nil {
+		t.Fatalf("Failed to delete resource: %s", err)
+	}
+	counting := &upsertCountingSearcher{Bleve: searcher}
+	if _, err := Reindex(counting, ctx.serv.lookup); err != nil {
+		t.Fatalf("Failed to reindex: %s", err)
+	}
+	if counting.upserts != 0 {
+		t.Fatalf("Expected unchanged documents not to be rewritten, got %d upserts", counting.upserts)
+	}
+	results, err = searcher.RunSearchWithFilter("mockSource", search.Filter{Type: SOURCE_VARIANT.String()})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != 1 || results[0].Variant != "var2" {
+		t.Fatalf("Expected the deleted variant to be removed from the index, got %v", results)
+	}
+}
+
+type upsertCountingSearcher struct {
+	*search.Bleve
+	upserts int
+}
+
+func (s *upsertCountingSearcher) Upsert(doc search.ResourceDoc) error {
+	s.upserts++
+	return s.Bleve.Upsert(doc)
+}
+
+func TestExpressionCompile(t *testing.T) {
+	inputs := map[string]NameVariant{"x": {Name: "feature", Variant: "variant"}}
+	valid := Expression{Expression: `x > 10.0 ? "high" : "low"`, Inputs: inputs}
+	if _, err := valid.Compile(); err != nil {
+		t.Fatalf("Failed to compile valid expression: %s", err)
+	}
+	invalid := map[string]Expression{
+		"Syntax":    {Expression: "x +", Inputs: inputs},
+		"Undefined": {Expression: "y * 2.0", Inputs: inputs},
+		"Shadowed":  {Expression: "now", Inputs: map[string]NameVariant{ExpressionNowVariable: {Name: "feature", Variant: "variant"}}},
+	}
+	for name, expr := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := expr.Compile()
+			var invalidArg *fferr.InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("Expected invalid argument error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateFeatureVariantRejectsInvalidExpression(t *testing.T) {
+	serv, addr := startServ(t)
+	defer serv.Stop()
+	client := client(t, addr)
+	defer client.Close()
+	expr := Expression{
+		Expression: "x +",
+		Inputs:     map[string]NameVariant{"x": {Name: "feature", Variant: "variant"}},
+	}
+	req := &pb.FeatureVariantRequest{
+		FeatureVariant: &pb.FeatureVariant{
+			Name:     "feature-expr",
+			Variant:  "invalid",
+			Entity:   "user",
+			Owner:    "Featureform",
+			Mode:     pb.ComputationMode_SERVER_COMPUTED,
+			Location: expr.SerializeExpression(),
+		},
+	}
+	_, err := client.GrpcConn.CreateFeatureVariant(context.Background(), req)
+	if code := grpc_status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("Expected %s registering an invalid expression, got %s: %v", codes.InvalidArgument, code, err)
+	}
+	if _, err := client.GetFeatureVariant(context.Background(), NameVariant{Name: "feature-expr", Variant: "invalid"}); err == nil {
+		t.Fatalf("Invalid expression feature should not have been created")
+	}
+}
@@ -209,3 +209,19 @@ func isValidSparkConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	}
 	return a.MutableFields().Contains(diff), nil
 }
+
+func isValidDuckDBConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.DuckDBConfig{}
+	b := pc.DuckDBConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
@@ -100,6 +100,16 @@ func TestProviderConfigUpdates(t *testing.T) {
 			valid:        false,
 			providerType: pt.ClickHouseOffline,
 		},
+		{
+			name:         "Valid DuckDB Configuration Update",
+			valid:        true,
+			providerType: pt.DuckDBOffline,
+		},
+		{
+			name:         "Invalid DuckDB Configuration Update",
+			valid:        false,
+			providerType: pt.DuckDBOffline,
+		},
 		{
 			name:         "Valid Redis Configuration Update",
 			valid:        true,
@@ -170,6 +180,8 @@ func TestProviderConfigUpdates(t *testing.T) {
 				testPostgresConfigUpdates(t, c.providerType, c.valid)
 			case pt.ClickHouseOffline:
 				testClickHouseConfigUpdates(t, c.providerType, c.valid)
+			case pt.DuckDBOffline:
+				testDuckDBConfigUpdates(t, c.providerType, c.valid)
 			case pt.RedisOnline:
 				testRedisConfigUpdates(t, c.providerType, c.valid)
 			case pt.SnowflakeOffline:
@@ -447,6 +459,32 @@ func testClickHouseConfigUpdates(t *testing.T, providerType pt.Type, valid bool)
 	assertConfigUpdateResult(t, valid, actual, err, providerType)
 }
 
+func testDuckDBConfigUpdates(t *testing.T, providerType pt.Type, valid bool) {
+	path := "/tmp/featureform.duckdb"
+	dirPath := "file:///tmp/featureform"
+
+	configA := pc.DuckDBConfig{
+		Path:    path,
+		DirPath: dirPath,
+	}
+	a := configA.Serialize()
+
+	if valid {
+		dirPath += updateSuffix
+	} else {
+		path += updateSuffix
+	}
+
+	configB := pc.DuckDBConfig{
+		Path:    path,
+		DirPath: dirPath,
+	}
+	b := configB.Serialize()
+
+	actual, err := isValidDuckDBConfigUpdate(a, b)
+	assertConfigUpdateResult(t, valid, actual, err, providerType)
+}
+
 func testPostgresConfigUpdates(t *testing.T, providerType pt.Type, valid bool) {
 	host := "0.0.0.0"
 	port := "5432"
@@ -724,6 +762,39 @@ func testSparkConfigUpdates(t *testing.T, providerType pt.Type, valid bool) {
 	assertConfigUpdateResult(t, valid, actual, err, providerType)
 }
 
+func TestProviderConfigUpdatesWithSecretReferences(t *testing.T) {
+	base := pc.SnowflakeConfig{
+		Username:     "featureformer",
+		Password:     "secret://env/FEATUREFORM_SECRET_SNOWFLAKE_PASSWORD",
+		Organization: "featureform",
+		Account:      "secret://vault/secret/data/snowflake#account",
+		Database:     "transactions_db",
+	}
+	tests := []struct {
+		name   string
+		update func(config *pc.SnowflakeConfig)
+		valid  bool
+	}{
+		{"Unchanged References", func(config *pc.SnowflakeConfig) {}, true},
+		{"Mutable Field To Another Reference", func(config *pc.SnowflakeConfig) {
+			config.Password = "secret://file/snowflake-password"
+		}, true},
+		{"Mutable Field To Plaintext", func(config *pc.SnowflakeConfig) { config.Password = "password" }, true},
+		{"Immutable Field To Another Reference", func(config *pc.SnowflakeConfig) {
+			config.Account = "secret://vault/secret/data/snowflake-prod#account"
+		}, false},
+		{"Immutable Field To Plaintext", func(config *pc.SnowflakeConfig) { config.Account = "featureform-test" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base
+			tt.update(&updated)
+			actual, err := isValidSnowflakeConfigUpdate(base.Serialize(), updated.Serialize())
+			assertConfigUpdateResult(t, tt.valid, actual, err, pt.SnowflakeOffline)
+		})
+	}
+}
+
 // ARRANGE FUNCTIONS
 func getGCPExampleCreds() (map[string]interface{}, error) {
 	gcpCredsBytes, err := ioutil.ReadFile("../provider/test_files/gcp_creds.json")
@@ -0,0 +1,379 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/featureform/fferr"
+	help "github.com/featureform/helpers"
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+type Role int32
+
+const (
+	UNASSIGNED_ROLE Role = Role(pb.Role_ROLE_UNASSIGNED)
+	VIEWER_ROLE          = Role(pb.Role_ROLE_VIEWER)
+	EDITOR_ROLE          = Role(pb.Role_ROLE_EDITOR)
+	ADMIN_ROLE           = Role(pb.Role_ROLE_ADMIN)
+)
+
+func (r Role) String() string {
+	return strings.TrimPrefix(pb.Role_name[int32(r)], "ROLE_")
+}
+
+func RoleFromString(role string) (Role, error) {
+	value, ok := pb.Role_value["ROLE_"+strings.ToUpper(role)]
+	if !ok {
+		return UNASSIGNED_ROLE, fferr.NewInvalidArgumentError(fmt.Errorf("unknown role %s", role))
+	}
+	return Role(value), nil
+}
+
+// methodRoles is the least role needed to call each method of the Metadata, Api and Feature services.
+// Methods that aren't listed need ADMIN_ROLE.
+var methodRoles = map[string]Role{
+	"GetEquivalent":            VIEWER_ROLE,
+	"GetLineage":               VIEWER_ROLE,
+	"TrainingData":             VIEWER_ROLE,
+	"TrainTestSplit":           VIEWER_ROLE,
+	"TrainingDataColumns":      VIEWER_ROLE,
+	"FeatureServe":             VIEWER_ROLE,
+	"SourceData":               VIEWER_ROLE,
+	"SourceColumns":            VIEWER_ROLE,
+	"Nearest":                  VIEWER_ROLE,
+	"BatchFeatureServe":        VIEWER_ROLE,
+	"GetResourceLocation":      VIEWER_ROLE,
+	"HistoricalFeatures":       VIEWER_ROLE,
+	"TrainingDataArrow":        VIEWER_ROLE,
+	"CreateUser":               EDITOR_ROLE,
+	"CreateProvider":           EDITOR_ROLE,
+	"CreateSourceVariant":      EDITOR_ROLE,
+	"CreateEntity":             EDITOR_ROLE,
+	"CreateFeatureVariant":     EDITOR_ROLE,
+	"CreateLabelVariant":       EDITOR_ROLE,
+	"CreateTrainingSetVariant": EDITOR_ROLE,
+	"CreateModel":              EDITOR_ROLE,
+	"RequestScheduleChange":    EDITOR_ROLE,
+	"DeleteFeatureVariant":     EDITOR_ROLE,
+	"DeleteLabelVariant":       EDITOR_ROLE,
+	"DeleteSourceVariant":      EDITOR_ROLE,
+	"DeleteTrainingSetVariant": EDITOR_ROLE,
+	"DeleteModel":              EDITOR_ROLE,
+	"SetResourceStatus":        ADMIN_ROLE,
+	"SetSourceSchema":          ADMIN_ROLE,
+	"SetUserAccess":            ADMIN_ROLE,
+}
+
+func methodRole(method string) Role {
+	if role, has := methodRoles[method]; has {
+		return role
+	}
+	// Each resource type has its own Get and List methods, all of them reads.
+	if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List") {
+		return VIEWER_ROLE
+	}
+	return ADMIN_ROLE
+}
+
+// Principal is a user as far as access control is concerned.
+type Principal struct {
+	Name  string
+	Role  Role
+	Teams []string
+}
+
+// SharesTeam returns true if the principal is on any of teams.
+func (principal Principal) SharesTeam(teams []string) bool {
+	for _, team := range teams {
+		for _, own := range principal.Teams {
+			if team != "" && team == own {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// principalLookup finds the role and teams a user has been assigned. Users that aren't in metadata are
+// returned with UNASSIGNED_ROLE.
+type principalLookup interface {
+	principal(ctx context.Context, name string) (Principal, error)
+}
+
+type resourcePrincipals struct {
+	lookup ResourceLookup
+}
+
+func (principals resourcePrincipals) principal(ctx context.Context, name string) (Principal, error) {
+	id := ResourceID{Name: name, Type: USER}
+	if has, err := principals.lookup.Has(id); err != nil {
+		return Principal{}, err
+	} else if !has {
+		return Principal{Name: name}, nil
+	}
+	res, err := principals.lookup.Lookup(ctx, id)
+	if err != nil {
+		return Principal{}, err
+	}
+	user, ok := res.(*userResource)
+	if !ok {
+		return Principal{}, fferr.NewInvalidResourceTypeError(name, "", fferr.USER, nil)
+	}
+	return Principal{Name: name, Role: Role(user.serialized.Role), Teams: user.serialized.Teams}, nil
+}
+
+func (client *Client) principal(ctx context.Context, name string) (Principal, error) {
+	user, err := client.GetUser(ctx, name)
+	if grpc_status.Code(err) == codes.NotFound {
+		return Principal{Name: name}, nil
+	} else if err != nil {
+		return Principal{}, err
+	}
+	return Principal{Name: name, Role: user.Role(), Teams: user.Teams()}, nil
+}
+
+// RBACConfig turns on role based access control for a server.
+type RBACConfig struct {
+	// Tokens verifies bearer tokens. If it's nil, callers can only be identified by mTLS client certificates.
+	Tokens help.TokenVerifier
+	// Admins always have ADMIN_ROLE, so the services and the first administrators don't need to be set up
+	// in metadata beforehand.
+	Admins []string
+	// DefaultRole is given to users that haven't been assigned one.
+	DefaultRole Role
+}
+
+// RBACConfigFromEnv returns the config set by FEATUREFORM_RBAC_ENABLED, FEATUREFORM_AUTH_TOKENS_FILE,
+// FEATUREFORM_ADMINS and FEATUREFORM_DEFAULT_ROLE, or nil if access control is off.
+func RBACConfigFromEnv() (*RBACConfig, error) {
+	if !help.GetEnvBool("FEATUREFORM_RBAC_ENABLED", false) {
+		return nil, nil
+	}
+	config := &RBACConfig{DefaultRole: VIEWER_ROLE}
+	if path := help.GetEnv("FEATUREFORM_AUTH_TOKENS_FILE", ""); path != "" {
+		tokens, err := help.LoadStaticTokens(path)
+		if err != nil {
+			return nil, err
+		}
+		config.Tokens = tokens
+	}
+	for _, admin := range strings.Split(help.GetEnv("FEATUREFORM_ADMINS", ""), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			config.Admins = append(config.Admins, admin)
+		}
+	}
+	if role := help.GetEnv("FEATUREFORM_DEFAULT_ROLE", ""); role != "" {
+		defaultRole, err := RoleFromString(role)
+		if err != nil {
+			return nil, err
+		}
+		config.DefaultRole = defaultRole
+	}
+	return config, nil
+}
+
+// RBACAuthorizer allows calls by the role of the caller and, when it can look resources up, only lets
+// callers change or delete resources that they or their teams own.
+type RBACAuthorizer struct {
+	config     RBACConfig
+	principals principalLookup
+	// resources is nil outside the metadata server, which checks ownership itself.
+	resources ResourceLookup
+	// forward names the caller in calls made while handling its request, so the metadata server can
+	// check ownership for them.
+	forward bool
+}
+
+// NewRBACAuthorizer returns an authorizer for servers in front of metadata, like the API and feature
+// servers, which looks callers up through client.
+func NewRBACAuthorizer(client *Client, config RBACConfig) *RBACAuthorizer {
+	return &RBACAuthorizer{config: config, principals: client, forward: true}
+}
+
+func newServerRBACAuthorizer(lookup ResourceLookup, config RBACConfig) *RBACAuthorizer {
+	return &RBACAuthorizer{config: config, principals: resourcePrincipals{lookup}, resources: lookup}
+}
+
+func (auth *RBACAuthorizer) principal(ctx context.Context, name string) (Principal, error) {
+	principal, err := auth.principals.principal(ctx, name)
+	if err != nil {
+		return Principal{}, err
+	}
+	for _, admin := range auth.config.Admins {
+		if admin == name {
+			principal.Role = ADMIN_ROLE
+		}
+	}
+	if principal.Role == UNASSIGNED_ROLE {
+		principal.Role = auth.config.DefaultRole
+	}
+	return principal, nil
+}
+
+func (auth *RBACAuthorizer) Authorize(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	identity, err := help.Authenticate(ctx, auth.config.Tokens)
+	if err != nil {
+		return nil, err
+	}
+	principal, err := auth.principal(ctx, identity.Name)
+	if err != nil {
+		return nil, err
+	}
+	// Services in front of metadata are admins and name the user they're calling for.
+	if forwarded := help.ForwardedUser(ctx); forwarded != "" && forwarded != principal.Name {
+		if principal.Role != ADMIN_ROLE {
+			return nil, fferr.NewPermissionDeniedError(principal.Name, fullMethod, fmt.Errorf("only admins can make calls on behalf of other users"))
+		}
+		if principal, err = auth.principal(ctx, forwarded); err != nil {
+			return nil, err
+		}
+	}
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if required := methodRole(method); principal.Role < required {
+		return nil, fferr.NewPermissionDeniedError(principal.Name, fullMethod, fmt.Errorf("%s needs the %s role, %s has %s", method, required, principal.Name, principal.Role))
+	}
+	if auth.resources != nil {
+		if err := auth.checkOwnership(ctx, principal, fullMethod, req); err != nil {
+			return nil, err
+		}
+	}
+	ctx = help.ContextWithIdentity(ctx, help.Identity{Name: principal.Name})
+	if auth.forward {
+		ctx = help.ForwardUser(ctx, principal.Name)
+	}
+	return ctx, nil
+}
+
+// modifiedResource returns the existing resource a request would change or delete, if there is one.
+func modifiedResource(method string, req interface{}) (ResourceID, bool) {
+	switch casted := req.(type) {
+	case *pb.UserRequest:
+		return ResourceID{Name: casted.GetUser().GetName(), Type: USER}, true
+	case *pb.ProviderRequest:
+		return ResourceID{Name: casted.GetProvider().GetName(), Type: PROVIDER}, true
+	case *pb.SourceVariantRequest:
+		return ResourceID{Name: casted.GetSourceVariant().GetName(), Variant: casted.GetSourceVariant().GetVariant(), Type: SOURCE_VARIANT}, true
+	case *pb.FeatureVariantRequest:
+		return ResourceID{Name: casted.GetFeatureVariant().GetName(), Variant: casted.GetFeatureVariant().GetVariant(), Type: FEATURE_VARIANT}, true
+	case *pb.LabelVariantRequest:
+		return ResourceID{Name: casted.GetLabelVariant().GetName(), Variant: casted.GetLabelVariant().GetVariant(), Type: LABEL_VARIANT}, true
+	case *pb.TrainingSetVariantRequest:
+		return ResourceID{Name: casted.GetTrainingSetVariant().GetName(), Variant: casted.GetTrainingSetVariant().GetVariant(), Type: TRAINING_SET_VARIANT}, true
+	case *pb.ScheduleChangeRequest:
+		resource := casted.GetResourceId()
+		return ResourceID{Name: resource.GetResource().GetName(), Variant: resource.GetResource().GetVariant(), Type: ResourceType(resource.GetResourceType())}, true
+	case *pb.DeleteRequest:
+		deleteTypes := map[string]ResourceType{
+			"DeleteFeatureVariant":     FEATURE_VARIANT,
+			"DeleteLabelVariant":       LABEL_VARIANT,
+			"DeleteSourceVariant":      SOURCE_VARIANT,
+			"DeleteTrainingSetVariant": TRAINING_SET_VARIANT,
+			"DeleteModel":              MODEL,
+		}
+		t, has := deleteTypes[method]
+		return ResourceID{Name: casted.GetNameVariant().GetName(), Variant: casted.GetNameVariant().GetVariant(), Type: t}, has
+	}
+	return ResourceID{}, false
+}
+
+// requestedOwner returns the owner a request would create a resource with and, for providers, its team.
+func requestedOwner(req interface{}) (owner, team string) {
+	switch casted := req.(type) {
+	case *pb.ProviderRequest:
+		return "", casted.GetProvider().GetTeam()
+	case *pb.SourceVariantRequest:
+		return casted.GetSourceVariant().GetOwner(), ""
+	case *pb.FeatureVariantRequest:
+		return casted.GetFeatureVariant().GetOwner(), ""
+	case *pb.LabelVariantRequest:
+		return casted.GetLabelVariant().GetOwner(), ""
+	case *pb.TrainingSetVariantRequest:
+		return casted.GetTrainingSetVariant().GetOwner(), ""
+	}
+	return "", ""
+}
+
+// checkNewOwner stops callers from creating resources owned by someone else or providers of a team they're
+// not on, which would let the owner's teammates change them and keep the caller's teammates from doing so.
+func checkNewOwner(principal Principal, fullMethod string, req interface{}) error {
+	owner, team := requestedOwner(req)
+	if owner != "" && owner != principal.Name {
+		return fferr.NewPermissionDeniedError(principal.Name, fullMethod, fmt.Errorf("%s can't create resources owned by %s", principal.Name, owner))
+	}
+	if team != "" && !principal.SharesTeam([]string{team}) {
+		return fferr.NewPermissionDeniedError(principal.Name, fullMethod, fmt.Errorf("%s can't create providers for team %s", principal.Name, team))
+	}
+	return nil
+}
+
+// ownerFromContext returns owner or, if it isn't set, the authenticated caller, so resources created while
+// access control is on are owned by whoever registered them.
+func ownerFromContext(ctx context.Context, owner string) string {
+	if identity, ok := help.IdentityFromContext(ctx); ok && owner == "" {
+		return identity.Name
+	}
+	return owner
+}
+
+// checkOwnership lets admins change anything, but otherwise only lets callers change users they are,
+// providers of their teams, and resource variants owned by them or a teammate. New resources must be owned
+// by the caller.
+func (auth *RBACAuthorizer) checkOwnership(ctx context.Context, principal Principal, fullMethod string, req interface{}) error {
+	if principal.Role == ADMIN_ROLE {
+		return nil
+	}
+	id, ok := modifiedResource(fullMethod[strings.LastIndex(fullMethod, "/")+1:], req)
+	if !ok {
+		return nil
+	}
+	if has, err := auth.resources.Has(id); err != nil {
+		return err
+	} else if !has {
+		return checkNewOwner(principal, fullMethod, req)
+	}
+	res, err := auth.resources.Lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	var owner string
+	var teams []string
+	switch casted := res.(type) {
+	case *userResource:
+		owner = casted.serialized.Name
+	case *providerResource:
+		teams = []string{casted.serialized.Team}
+	case *sourceVariantResource:
+		owner = casted.serialized.Owner
+	case *featureVariantResource:
+		owner = casted.serialized.Owner
+	case *labelVariantResource:
+		owner = casted.serialized.Owner
+	case *trainingSetVariantResource:
+		owner = casted.serialized.Owner
+	default:
+		// Entities and models have no owner and only ever get merged into.
+		return nil
+	}
+	if owner != "" && owner == principal.Name {
+		return nil
+	}
+	if owner != "" && id.Type != USER {
+		ownerPrincipal, err := auth.principals.principal(ctx, owner)
+		if err != nil {
+			return err
+		}
+		teams = ownerPrincipal.Teams
+	}
+	if principal.SharesTeam(teams) {
+		return nil
+	}
+	return fferr.NewPermissionDeniedError(principal.Name, fullMethod, fmt.Errorf("%s is not owned by %s or their teams", id, principal.Name))
+}
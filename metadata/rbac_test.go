@@ -0,0 +1,176 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+)
+
+func startRBACServ(t *testing.T, rbac RBACConfig) string {
+	config := &Config{
+		Logger:          logging.WrapZapLogger(zaptest.NewLogger(t).Sugar()),
+		StorageProvider: LocalStorageProvider{},
+		RBAC:            &rbac,
+	}
+	serv, err := NewMetadataServer(config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go func() {
+		if err := serv.ServeOnListener(lis); err != nil {
+			t.Logf("Server error: %s", err)
+		}
+	}()
+	t.Cleanup(func() { serv.Stop() })
+	return lis.Addr().String()
+}
+
+// tokenClient connects as the user token was issued to, or without credentials if token is empty.
+func tokenClient(t *testing.T, addr, token string) *Client {
+	t.Setenv("FEATUREFORM_AUTH_TOKEN", token)
+	t.Setenv("FEATUREFORM_AUTH_TOKEN_INSECURE", "true")
+	c := client(t, addr)
+	t.Cleanup(c.Close)
+	return c
+}
+
+func assertCode(t *testing.T, err error, code codes.Code, action string) {
+	t.Helper()
+	if grpc_status.Code(err) != code {
+		t.Fatalf("Expected %s to fail with %s, got %v", action, code, err)
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	addr := startRBACServ(t, RBACConfig{
+		Tokens: help.StaticTokens{
+			"admin-token": "admin",
+			"alice-token": "alice",
+			"bob-token":   "bob",
+			"carol-token": "carol",
+			"dave-token":  "dave",
+		},
+		Admins:      []string{"admin"},
+		DefaultRole: VIEWER_ROLE,
+	})
+	ctx := context.Background()
+	admin := tokenClient(t, addr, "admin-token")
+	alice := tokenClient(t, addr, "alice-token")
+	bob := tokenClient(t, addr, "bob-token")
+	carol := tokenClient(t, addr, "carol-token")
+	dave := tokenClient(t, addr, "dave-token")
+	anonymous := tokenClient(t, addr, "")
+
+	for user, teams := range map[string][]string{"alice": {"fraud"}, "bob": {"fraud"}, "carol": {"growth"}} {
+		if err := admin.SetUserAccess(ctx, user, EDITOR_ROLE, teams); err != nil {
+			t.Fatalf("Failed to set access of %s: %s", user, err)
+		}
+	}
+	user, err := admin.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %s", err)
+	}
+	if user.Role() != EDITOR_ROLE || len(user.Teams()) != 1 || user.Teams()[0] != "fraud" {
+		t.Fatalf("Expected alice to be an editor on fraud, got %s %v", user.Role(), user.Teams())
+	}
+
+	snowflakeConfig := pc.SnowflakeConfig{Username: "featureformer"}
+	provider := ProviderDef{
+		Name:             "warehouse",
+		Type:             string(pt.SnowflakeOffline),
+		Software:         "snowflake",
+		Team:             "fraud",
+		SerializedConfig: snowflakeConfig.Serialize(),
+		Tags:             Tags{},
+		Properties:       Properties{},
+	}
+	source := SourceDef{
+		Name:        "transactions",
+		Variant:     "v1",
+		Description: "Transactions",
+		Definition:  PrimaryDataSource{Location: SQLTable{Name: "transactions"}},
+		Owner:       "alice",
+		Provider:    "warehouse",
+		Tags:        Tags{},
+		Properties:  Properties{},
+	}
+	if err := alice.CreateProvider(ctx, provider); err != nil {
+		t.Fatalf("Expected an editor to create a provider: %s", err)
+	}
+	if err := alice.CreateSourceVariant(ctx, source); err != nil {
+		t.Fatalf("Expected an editor to create a source: %s", err)
+	}
+
+	if _, err := dave.ListUsers(ctx); err != nil {
+		t.Fatalf("Expected a viewer to list users: %s", err)
+	}
+	assertCode(t, dave.CreateUser(ctx, UserDef{Name: "dave"}), codes.PermissionDenied, "a viewer creating a user")
+	assertCode(t, dave.SetUserAccess(ctx, "dave", ADMIN_ROLE, nil), codes.PermissionDenied, "a viewer making themselves admin")
+	assertCode(t, alice.SetUserAccess(ctx, "alice", ADMIN_ROLE, nil), codes.PermissionDenied, "an editor making themselves admin")
+	_, err = anonymous.ListUsers(ctx)
+	assertCode(t, err, codes.Unauthenticated, "a call without credentials")
+
+	if err := bob.CreateProvider(ctx, provider); err != nil {
+		t.Fatalf("Expected a teammate to update a provider: %s", err)
+	}
+	assertCode(t, carol.CreateProvider(ctx, provider), codes.PermissionDenied, "another team overwriting a provider")
+	assertCode(t, carol.CreateSourceVariant(ctx, source), codes.PermissionDenied, "another team overwriting a source")
+	assertCode(t, carol.DeleteSourceVariant(ctx, NameVariant{source.Name, source.Variant}, false), codes.PermissionDenied, "another team deleting a source")
+	if err := bob.DeleteSourceVariant(ctx, NameVariant{source.Name, source.Variant}, false); err != nil {
+		t.Fatalf("Expected a teammate of the owner to delete a source: %s", err)
+	}
+	if err := admin.CreateProvider(ctx, provider); err != nil {
+		t.Fatalf("Expected an admin to update any provider: %s", err)
+	}
+
+	// New resources can't be registered as someone else's.
+	claimed := source
+	claimed.Variant = "v2"
+	assertCode(t, carol.CreateSourceVariant(ctx, claimed), codes.PermissionDenied, "a user creating a source owned by another user")
+	claimedProvider := provider
+	claimedProvider.Name = "other-warehouse"
+	assertCode(t, carol.CreateProvider(ctx, claimedProvider), codes.PermissionDenied, "a user creating a provider for another team")
+	if _, err := admin.GetSourceVariant(ctx, NameVariant{claimed.Name, claimed.Variant}); err == nil {
+		t.Fatalf("Source claimed for another user should not have been created")
+	}
+	unowned := source
+	unowned.Variant = "v3"
+	unowned.Owner = ""
+	if err := carol.CreateSourceVariant(ctx, unowned); err != nil {
+		t.Fatalf("Expected a source without an owner to be created: %s", err)
+	}
+	created, err := admin.GetSourceVariant(ctx, NameVariant{unowned.Name, unowned.Variant})
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if created.Owner() != "carol" {
+		t.Fatalf("Expected a source without an owner to be owned by its creator carol, got %s", created.Owner())
+	}
+
+	// Editors can't raise their own access by registering themselves again.
+	if err := carol.CreateUser(ctx, UserDef{Name: "carol", Tags: Tags{}, Properties: Properties{}}); err != nil {
+		t.Fatalf("Expected a user to update themselves: %s", err)
+	}
+	assertCode(t, carol.CreateUser(ctx, UserDef{Name: "alice", Tags: Tags{}, Properties: Properties{}}), codes.PermissionDenied, "a user overwriting another user")
+
+	// Only admins may make calls on behalf of other users.
+	_, err = alice.ListUsers(help.ForwardUser(ctx, "admin"))
+	assertCode(t, err, codes.PermissionDenied, "an editor acting as an admin")
+	assertCode(t, admin.CreateProvider(help.ForwardUser(ctx, "carol"), provider), codes.PermissionDenied, "an admin acting as another team")
+}
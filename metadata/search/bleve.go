@@ -0,0 +1,227 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// searchLimit is the number of results returned, the same as Meilisearch's default.
+const searchLimit = 20
+
+// BleveParams configures an embedded index, which needs no search service to run.
+type BleveParams struct {
+	// Path is the directory the index is stored in. It's created if it doesn't exist. Only one process
+	// can have an index open at a time.
+	Path string
+}
+
+type Bleve struct {
+	index bleve.Index
+}
+
+func NewBleve(params *BleveParams) (*Bleve, error) {
+	index, err := openBleveIndex(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &Bleve{index: index}, nil
+}
+
+func openBleveIndex(path string) (bleve.Index, error) {
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, bleveMapping())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open search index at %s: %v", path, err)
+	}
+	return index, nil
+}
+
+// bleveMapping indexes the fields that results are filtered by as exact keywords, and the name, variant,
+// type and tags together as full text.
+func bleveMapping() mapping.IndexMapping {
+	doc := bleve.NewDocumentStaticMapping()
+	for _, field := range []string{"Name", "Variant", "Type", "Owner", "Status", "Tags"} {
+		doc.AddFieldMappingsAt(field, bleve.NewKeywordFieldMapping())
+	}
+	parsed := bleve.NewTextFieldMapping()
+	parsed.Store = false
+	doc.AddFieldMappingsAt("Parsed", parsed)
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = doc
+	return indexMapping
+}
+
+// parsedText splits names like avg_transaction-amount into words so they can be searched for.
+func parsedText(parts ...string) string {
+	return strings.NewReplacer("_", " ", "-", " ", ".", " ").Replace(strings.Join(parts, " "))
+}
+
+func (b *Bleve) Upsert(doc ResourceDoc) error {
+	document := map[string]interface{}{
+		"Name":    doc.Name,
+		"Variant": doc.Variant,
+		"Type":    doc.Type,
+		"Owner":   doc.Owner,
+		"Status":  doc.Status,
+		"Tags":    doc.Tags,
+		"Parsed":  parsedText(append([]string{doc.Name, doc.Variant, doc.Type}, doc.Tags...)...),
+	}
+	if err := b.index.Index(docID(doc), document); err != nil {
+		return fmt.Errorf("failed to index document: %v", err)
+	}
+	return nil
+}
+
+func (b *Bleve) Delete(doc ResourceDoc) error {
+	if err := b.index.Delete(docID(doc)); err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return nil
+}
+
+// DeleteAll deletes every document from the index in one batch. The index's files are left in place, so
+// it stays searchable and nothing outside of it can be removed.
+func (b *Bleve) DeleteAll() error {
+	results, err := b.all()
+	if err != nil {
+		return err
+	}
+	batch := b.index.NewBatch()
+	for _, hit := range results.Hits {
+		batch.Delete(hit.ID)
+	}
+	if err := b.index.Batch(batch); err != nil {
+		return fmt.Errorf("failed to delete documents: %v", err)
+	}
+	return nil
+}
+
+// Documents returns every document in the index.
+func (b *Bleve) Documents() ([]ResourceDoc, error) {
+	results, err := b.all()
+	if err != nil {
+		return nil, err
+	}
+	return hitDocs(results), nil
+}
+
+// all returns every document in the index with its stored fields.
+func (b *Bleve) all() (*bleve.SearchResult, error) {
+	count, err := b.index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %v", err)
+	}
+	request := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(count), 0, false)
+	request.Fields = storedFields
+	results, err := b.index.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+	return results, nil
+}
+
+func (b *Bleve) Close() error {
+	return b.index.Close()
+}
+
+func (b *Bleve) RunSearch(q string) ([]ResourceDoc, error) {
+	return b.RunSearchWithFilter(q, Filter{})
+}
+
+func (b *Bleve) RunSearchWithFilter(q string, filter Filter) ([]ResourceDoc, error) {
+	request := bleve.NewSearchRequestOptions(bleveQuery(q, filter), searchLimit, 0, false)
+	request.Fields = storedFields
+	results, err := b.index.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	return hitDocs(results), nil
+}
+
+// storedFields are the fields of a ResourceDoc, which are read back from search results.
+var storedFields = []string{"Name", "Variant", "Type", "Owner", "Status", "Tags"}
+
+func hitDocs(results *bleve.SearchResult) []ResourceDoc {
+	docs := make([]ResourceDoc, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		docs = append(docs, ResourceDoc{
+			Name:    fieldString(hit.Fields["Name"]),
+			Variant: fieldString(hit.Fields["Variant"]),
+			Type:    fieldString(hit.Fields["Type"]),
+			Owner:   fieldString(hit.Fields["Owner"]),
+			Status:  fieldString(hit.Fields["Status"]),
+			Tags:    fieldStrings(hit.Fields["Tags"]),
+		})
+	}
+	return docs
+}
+
+// bleveQuery matches documents that have every word of q, ranking exact words above words they start
+// with, and those above words with a typo in them. Like Meilisearch, words of five letters or more may
+// have one typo.
+func bleveQuery(q string, filter Filter) query.Query {
+	conjuncts := make([]query.Query, 0)
+	for _, word := range strings.Fields(strings.ToLower(parsedText(q))) {
+		match := bleve.NewMatchQuery(word)
+		match.SetField("Parsed")
+		match.SetBoost(3)
+		prefix := bleve.NewPrefixQuery(word)
+		prefix.SetField("Parsed")
+		prefix.SetBoost(2)
+		disjuncts := []query.Query{match, prefix}
+		if len(word) >= 5 {
+			fuzzy := bleve.NewFuzzyQuery(word)
+			fuzzy.SetField("Parsed")
+			fuzzy.SetFuzziness(1)
+			disjuncts = append(disjuncts, fuzzy)
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(disjuncts...))
+	}
+	for field, value := range filter.fields() {
+		term := bleve.NewTermQuery(value)
+		term.SetField(field)
+		conjuncts = append(conjuncts, term)
+	}
+	for _, tag := range filter.Tags {
+		term := bleve.NewTermQuery(tag)
+		term.SetField("Tags")
+		conjuncts = append(conjuncts, term)
+	}
+	if len(conjuncts) == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+	return bleve.NewConjunctionQuery(conjuncts...)
+}
+
+func fieldString(field interface{}) string {
+	str, _ := field.(string)
+	return str
+}
+
+// fieldStrings reads a stored list, which Bleve returns as a single value if it has one element.
+func fieldStrings(field interface{}) []string {
+	switch casted := field.(type) {
+	case string:
+		return []string{casted}
+	case []interface{}:
+		strs := make([]string, 0, len(casted))
+		for _, value := range casted {
+			if str, ok := value.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
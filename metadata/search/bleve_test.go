@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestBleve(t *testing.T, path string) *Bleve {
+	searcher, err := NewBleve(&BleveParams{Path: path})
+	if err != nil {
+		t.Fatalf("Failed to open index: %s", err)
+	}
+	return searcher
+}
+
+func resultNames(results []ResourceDoc) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+	}
+	return names
+}
+
+func TestBleveSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.bleve")
+	searcher := newTestBleve(t, path)
+	resources := []ResourceDoc{
+		{Name: "hero-typesense-film", Variant: "default_variant", Type: "FEATURE_VARIANT", Owner: "alice", Status: "READY", Tags: []string{"movies"}},
+		{Name: "wine_sonoma_county", Variant: "second_variant", Type: "SOURCE_VARIANT", Owner: "bob", Status: "PENDING"},
+		{Name: "heroic", Variant: "default", Type: "FEATURE_VARIANT", Owner: "bob", Status: "READY", Tags: []string{"movies", "pii"}},
+		{Name: "juice-dataset-sonome", Variant: "third_variant_backup", Type: "LABEL_VARIANT", Owner: "alice", Status: "FAILED"},
+	}
+	for _, resource := range resources {
+		if err := searcher.Upsert(resource); err != nil {
+			t.Fatalf("Failed to upsert: %s", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		filter Filter
+		want   []string
+	}{
+		{"Word In Name", "film", Filter{}, []string{"hero-typesense-film"}},
+		{"Exact Before Prefix", "hero", Filter{}, []string{"hero-typesense-film", "heroic"}},
+		{"Typo", "sonoma", Filter{}, []string{"wine_sonoma_county", "juice-dataset-sonome"}},
+		{"Tag", "pii", Filter{}, []string{"heroic"}},
+		{"No Match", "zebra", Filter{}, []string{}},
+		{"Type Filter", "hero", Filter{Type: "FEATURE_VARIANT", Owner: "bob"}, []string{"heroic"}},
+		{"Status Filter", "", Filter{Status: "FAILED"}, []string{"juice-dataset-sonome"}},
+		{"Tag Filter", "", Filter{Tags: []string{"movies", "pii"}}, []string{"heroic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := searcher.RunSearchWithFilter(tt.query, tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to search: %s", err)
+			}
+			names := resultNames(results)
+			if len(names) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, names)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Fatalf("Expected %v, got %v", tt.want, names)
+				}
+			}
+		})
+	}
+
+	results, err := searcher.RunSearch("heroic")
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != 1 || results[0].Owner != "bob" || results[0].Status != "READY" || len(results[0].Tags) != 2 {
+		t.Fatalf("Expected the stored document back, got %#v", results)
+	}
+
+	if err := searcher.Delete(resources[2]); err != nil {
+		t.Fatalf("Failed to delete: %s", err)
+	}
+	if err := searcher.Close(); err != nil {
+		t.Fatalf("Failed to close: %s", err)
+	}
+	searcher = newTestBleve(t, path)
+	defer searcher.Close()
+	if results, err := searcher.RunSearch("hero"); err != nil || len(results) != 1 {
+		t.Fatalf("Expected the index to persist without the deleted document, got %v %v", resultNames(results), err)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to delete all: %s", err)
+	}
+	if results, err := searcher.RunSearch(""); err != nil || len(results) != 0 {
+		t.Fatalf("Expected an empty index, got %v %v", resultNames(results), err)
+	}
+	if err := searcher.Upsert(resources[0]); err != nil {
+		t.Fatalf("Failed to upsert after deleting all: %s", err)
+	}
+	docs, err := searcher.Documents()
+	if err != nil {
+		t.Fatalf("Failed to list documents: %s", err)
+	}
+	if len(docs) != 1 || docs[0].Name != resources[0].Name || docs[0].Variant != resources[0].Variant {
+		t.Fatalf("Expected only the upserted document, got %#v", docs)
+	}
+}
@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Command reindex brings the search index in line with the resources in etcd. It uses the same search backend
+// settings as the dashboard; an embedded index can't be synced while the dashboard has it open.
+package main
+
+import (
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/metadata/search"
+)
+
+func main() {
+	logger := logging.NewLogger("reindex")
+	searcher, err := search.NewSearcherFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to create searcher", "error", err)
+	}
+	storageProvider := metadata.EtcdStorageProvider{
+		Config: metadata.EtcdConfig{
+			Nodes: []metadata.EtcdNode{
+				{Host: help.GetEnv("ETCD_HOST", "localhost"), Port: help.GetEnv("ETCD_PORT", "2379")},
+			},
+		},
+	}
+	lookup, err := storageProvider.GetResourceLookup()
+	if err != nil {
+		logger.Panicw("Failed to connect to etcd", "error", err)
+	}
+	indexed, err := metadata.Reindex(searcher, lookup)
+	if err != nil {
+		logger.Panicw("Failed to reindex", "error", err)
+	}
+	if embedded, ok := searcher.(*search.Bleve); ok {
+		if err := embedded.Close(); err != nil {
+			logger.Panicw("Failed to close index", "error", err)
+		}
+	}
+	logger.Infow("Reindexed search", "resources", indexed)
+}
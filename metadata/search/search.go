@@ -6,19 +6,91 @@ package search
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	re "github.com/avast/retry-go/v4"
+	help "github.com/featureform/helpers"
 	ms "github.com/meilisearch/meilisearch-go"
 )
 
 type Searcher interface {
 	Upsert(ResourceDoc) error
 	RunSearch(q string) ([]ResourceDoc, error)
+	RunSearchWithFilter(q string, filter Filter) ([]ResourceDoc, error)
+	Delete(ResourceDoc) error
 	DeleteAll() error
 }
 
+// DocumentLister is implemented by searchers that can list every document they hold, so an index can be
+// brought up to date by writing only what changed rather than being cleared and refilled.
+type DocumentLister interface {
+	Documents() ([]ResourceDoc, error)
+}
+
+// Filter narrows a search to the documents that match every field that's set.
+type Filter struct {
+	Type   string
+	Owner  string
+	Status string
+	// Tags must all be on a document for it to match.
+	Tags []string
+}
+
+func (filter Filter) fields() map[string]string {
+	fields := make(map[string]string)
+	for field, value := range map[string]string{"Type": filter.Type, "Owner": filter.Owner, "Status": filter.Status} {
+		if value != "" {
+			fields[field] = value
+		}
+	}
+	return fields
+}
+
+func (filter Filter) meilisearchExpression() string {
+	conditions := make([]string, 0)
+	for field, value := range filter.fields() {
+		conditions = append(conditions, fmt.Sprintf("%s = %s", field, strconv.Quote(value)))
+	}
+	sort.Strings(conditions)
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, fmt.Sprintf("Tags = %s", strconv.Quote(tag)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// BackendFromEnv returns SEARCH_BACKEND or, if it's unset, meilisearch if MEILISEARCH_HOST is set and
+// bleve otherwise.
+func BackendFromEnv() string {
+	backend := help.GetEnv("SEARCH_BACKEND", "")
+	if backend == "" && help.GetEnv("MEILISEARCH_HOST", "") != "" {
+		return "meilisearch"
+	}
+	if backend == "" {
+		return "bleve"
+	}
+	return backend
+}
+
+// NewSearcherFromEnv connects to Meilisearch if BackendFromEnv is meilisearch. Otherwise it opens an
+// embedded index stored in SEARCH_INDEX_PATH.
+func NewSearcherFromEnv() (Searcher, error) {
+	switch backend := BackendFromEnv(); backend {
+	case "meilisearch":
+		return NewMeilisearch(&MeilisearchParams{
+			Host:   help.GetEnv("MEILISEARCH_HOST", "localhost"),
+			Port:   help.GetEnv("MEILISEARCH_PORT", "7700"),
+			ApiKey: help.GetEnv("MEILISEARCH_APIKEY", ""),
+		})
+	case "bleve":
+		return NewBleve(&BleveParams{Path: help.GetEnv("SEARCH_INDEX_PATH", "search.bleve")})
+	default:
+		return nil, fmt.Errorf("unknown search backend %s", backend)
+	}
+}
+
 type MeilisearchParams struct {
 	Host   string
 	Port   string
@@ -77,6 +149,8 @@ type ResourceDoc struct {
 	Name    string
 	Variant string
 	Type    string
+	Owner   string
+	Status  string
 	Tags    []string
 }
 
@@ -113,16 +187,30 @@ func (s Search) initializeCollection() error {
 		return fmt.Errorf("could not create index: %v", err)
 	}
 
+	filterable := []string{"Type", "Owner", "Status", "Tags"}
+	settingsResp, err := s.client.Index("resources").UpdateFilterableAttributes(&filterable)
+	if err != nil {
+		return fmt.Errorf("filterable attributes request failed: %v", err)
+	}
+	if err := s.waitForSync(settingsResp.TaskUID); err != nil {
+		return fmt.Errorf("could not set filterable attributes: %v", err)
+	}
 	return nil
 }
 
+func docID(doc ResourceDoc) string {
+	return strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), " ", "")
+}
+
 func (s Search) Upsert(doc ResourceDoc) error {
 	document := map[string]interface{}{
-		"ID":      strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), " ", ""),
+		"ID":      docID(doc),
 		"Parsed":  strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_", " "),
 		"Name":    doc.Name,
 		"Type":    doc.Type,
 		"Variant": doc.Variant,
+		"Owner":   doc.Owner,
+		"Status":  doc.Status,
 		"Tags":    doc.Tags,
 	}
 	resp, err := s.client.Index("resources").UpdateDocuments(document)
@@ -135,16 +223,39 @@ func (s Search) Upsert(doc ResourceDoc) error {
 	return nil
 }
 
+func (s Search) Delete(doc ResourceDoc) error {
+	resp, err := s.client.Index("resources").DeleteDocument(docID(doc))
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		fmt.Printf("Could not Delete %#v: %v", doc, err)
+	}
+	return nil
+}
+
+// DeleteAll deletes the index and creates it again, so that it keeps its settings.
 func (s Search) DeleteAll() error {
-	_, err := s.client.DeleteIndex("resources")
+	resp, err := s.client.DeleteIndex("resources")
 	if err != nil {
 		return fmt.Errorf("failed to delete index: %v", err)
 	}
-	return nil
+	if err := s.waitForSync(resp.TaskUID); err != nil && err.Error() != "index_not_found" {
+		return fmt.Errorf("could not delete index: %v", err)
+	}
+	return s.initializeCollection()
 }
 
 func (s Search) RunSearch(q string) ([]ResourceDoc, error) {
-	results, err := s.client.Index("resources").Search(q, &ms.SearchRequest{})
+	return s.RunSearchWithFilter(q, Filter{})
+}
+
+func (s Search) RunSearchWithFilter(q string, filter Filter) ([]ResourceDoc, error) {
+	request := &ms.SearchRequest{}
+	if expression := filter.meilisearchExpression(); expression != "" {
+		request.Filter = expression
+	}
+	results, err := s.client.Index("resources").Search(q, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %v", err)
 	}
@@ -162,10 +273,14 @@ func (s Search) RunSearch(q string) ([]ResourceDoc, error) {
 				}
 			}
 		}
+		owner, _ := doc["Owner"].(string)
+		status, _ := doc["Status"].(string)
 		searchResults = append(searchResults, ResourceDoc{
 			Name:    doc["Name"].(string),
 			Type:    doc["Type"].(string),
 			Variant: doc["Variant"].(string),
+			Owner:   owner,
+			Status:  status,
 			Tags:    tags,
 		})
 
@@ -180,6 +295,10 @@ func (s SearchMock) Upsert(doc ResourceDoc) error {
 	return nil
 }
 
+func (s SearchMock) Delete(doc ResourceDoc) error {
+	return nil
+}
+
 func (s SearchMock) DeleteAll() error {
 	return nil
 }
@@ -187,3 +306,7 @@ func (s SearchMock) DeleteAll() error {
 func (s SearchMock) RunSearch(q string) ([]ResourceDoc, error) {
 	return nil, nil
 }
+
+func (s SearchMock) RunSearchWithFilter(q string, filter Filter) ([]ResourceDoc, error) {
+	return nil, nil
+}
@@ -22,19 +22,25 @@ func main() {
 	logger := logging.NewLogger("metadata")
 	addr := help.GetEnv("METADATA_PORT", "8080")
 	enableSearch := help.GetEnv("ENABLE_SEARCH", "true")
-	storageProvider := metadata.EtcdStorageProvider{
+	boltPath := help.GetEnv("METADATA_BOLT_PATH", "")
+	var storageProvider metadata.StorageProvider = metadata.EtcdStorageProvider{
 		metadata.EtcdConfig{
 			Nodes: []metadata.EtcdNode{
 				{etcdHost, etcdPort},
 			},
 		},
 	}
+	if boltPath != "" {
+		logger.Infow("Using local bolt file for metadata", "path", boltPath)
+		storageProvider = metadata.BoltStorageProvider{Path: boltPath}
+	}
 	config := &metadata.Config{
 		Logger:          logger,
 		Address:         fmt.Sprintf(":%s", addr),
 		StorageProvider: storageProvider,
 	}
-	if enableSearch == "true" {
+	// An embedded index is kept up to date by the dashboard that serves it, as only one process can open it.
+	if enableSearch == "true" && search.BackendFromEnv() == "meilisearch" {
 		logger.Infow("Connecting to search", "host", os.Getenv("MEILISEARCH_HOST"), "port", os.Getenv("MEILISEARCH_PORT"))
 		config.SearchParams = &search.MeilisearchParams{
 			Port:   help.GetEnv("MEILISEARCH_PORT", "7700"),
@@ -42,6 +48,16 @@ func main() {
 			ApiKey: help.GetEnv("MEILISEARCH_APIKEY", ""),
 		}
 	}
+	rbac, err := metadata.RBACConfigFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to configure access control", "Err", err)
+	}
+	config.RBAC = rbac
+	credentials, err := help.ServerTLSFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to load TLS credentials", "Err", err)
+	}
+	config.Credentials = credentials
 	server, err := metadata.NewMetadataServer(config)
 	if err != nil {
 		logger.Panicw("Failed to create metadata server", "Err", err)
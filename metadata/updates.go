@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"slices"
+
 	pb "github.com/featureform/metadata/proto"
 )
 
@@ -55,3 +57,43 @@ func mergeProperties(destination, source *pb.Properties) *pb.Properties {
 	}
 	return destination
 }
+
+func removeNameVariant(destination []*pb.NameVariant, target *pb.NameVariant) []*pb.NameVariant {
+	filtered := make([]*pb.NameVariant, 0, len(destination))
+	for _, nameVariant := range destination {
+		if nameVariant.Name == target.Name && nameVariant.Variant == target.Variant {
+			continue
+		}
+		filtered = append(filtered, nameVariant)
+	}
+	return filtered
+}
+
+func removeVariant(variants []string, target string) []string {
+	filtered := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		if variant != target {
+			filtered = append(filtered, variant)
+		}
+	}
+	return filtered
+}
+
+// updateNameVariants adds target to a resource's list of name variants, or removes it on delete.
+func updateNameVariants(destination []*pb.NameVariant, op operation, target *pb.NameVariant) []*pb.NameVariant {
+	if op == delete_op {
+		return removeNameVariant(destination, target)
+	}
+	return unionNameVariants(destination, []*pb.NameVariant{target})
+}
+
+// updateModels adds model to a resource's list of models, or removes it on delete.
+func updateModels(models []string, op operation, model string) []string {
+	if op == delete_op {
+		return removeVariant(models, model)
+	}
+	if slices.Contains(models, model) {
+		return models
+	}
+	return append(models, model)
+}
@@ -22,6 +22,8 @@ const (
 	ONLINE_ROW_SERVE               = "online_row_serve"
 	ERROR                          = "error"
 	SUCCESS                        = "success"
+	CACHE_HIT                      = "cache_hit"
+	CACHE_MISS                     = "cache_miss"
 )
 
 // generic interfaces exposed to the user
@@ -37,6 +39,12 @@ type FeatureObserver interface {
 	Finish()
 }
 
+// CacheObserver is implemented by observers that count reads from the feature server's value cache.
+type CacheObserver interface {
+	CacheHits(n int)
+	CacheMisses(n int)
+}
+
 type PromMetricsHandler struct {
 	Hist  *prometheus.HistogramVec
 	Count *prometheus.CounterVec
@@ -140,6 +148,14 @@ func (p PromFeatureObserver) Finish() {
 	p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(SUCCESS)).Inc()
 }
 
+func (p PromFeatureObserver) CacheHits(n int) {
+	p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(CACHE_HIT)).Add(float64(n))
+}
+
+func (p PromFeatureObserver) CacheMisses(n int) {
+	p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(CACHE_MISS)).Add(float64(n))
+}
+
 func (p PromFeatureObserver) GetObservedRowCount() (int, error) {
 	var m = &dto.Metric{}
 	if err := p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(ONLINE_ROW_SERVE)).Write(m); err != nil {
@@ -156,6 +172,22 @@ func (p PromFeatureObserver) GetObservedErrorCount() (int, error) {
 	return int(m.Counter.GetValue()), nil
 }
 
+func (p PromFeatureObserver) GetObservedCacheHitCount() (int, error) {
+	var m = &dto.Metric{}
+	if err := p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(CACHE_HIT)).Write(m); err != nil {
+		return 0, err
+	}
+	return int(m.Counter.GetValue()), nil
+}
+
+func (p PromFeatureObserver) GetObservedCacheMissCount() (int, error) {
+	var m = &dto.Metric{}
+	if err := p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(CACHE_MISS)).Write(m); err != nil {
+		return 0, err
+	}
+	return int(m.Counter.GetValue()), nil
+}
+
 func (p TrainingDataObserver) SetError() {
 	p.Status = string(ERROR)
 	p.Timer.ObserveDuration()
@@ -112,6 +112,18 @@ func TestMetrics(t *testing.T) {
 		t.Fatalf("Could not fetch value: %v", err)
 	}
 	assert.Equal(t, trainingErrorCounterValueInt, trainingNum, "5 training data errors should be recorded")
+	servingObserver.CacheHits(3)
+	servingObserver.CacheMisses(2)
+	cacheHits, err := servingObserver.GetObservedCacheHitCount()
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 3, cacheHits, "3 cache hits should be recorded")
+	cacheMisses, err := servingObserver.GetObservedCacheMissCount()
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 2, cacheMisses, "2 cache misses should be recorded")
 	latencyCounterValue, err := GetHistogramValue(promMetrics.Hist, instanceName, featureName, featureVariant, "")
 	if err != nil {
 		t.Fatalf("Could not fetch value: %v", err)
@@ -452,7 +452,7 @@ func (q defaultBQQueries) transformationCreate(name string, query string) string
 }
 
 func (q defaultBQQueries) getColumns(client *bigquery.Client, name string) ([]TableColumn, error) {
-	qry := fmt.Sprintf("SELECT column_name FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE table_name=\"%s\" ORDER BY ordinal_position", q.getTablePrefix(), name)
+	qry := fmt.Sprintf("SELECT column_name, data_type FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE table_name=\"%s\" ORDER BY ordinal_position", q.getTablePrefix(), name)
 
 	bqQ := client.Query(qry)
 	it, err := bqQ.Read(q.getContext())
@@ -473,7 +473,7 @@ func (q defaultBQQueries) getColumns(client *bigquery.Client, name string) ([]Ta
 			wrapped.AddDetail("table_name", name)
 			return nil, wrapped
 		}
-		columnNames = append(columnNames, TableColumn{Name: column[0].(string)})
+		columnNames = append(columnNames, TableColumn{Name: column[0].(string), NativeType: column[1].(string)})
 	}
 
 	return columnNames, nil
@@ -537,19 +537,42 @@ func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSe
 		columns = append(columns, santizedName)
 		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS `%s`, ts, RANK() OVER (ORDER BY ts DESC, insert_ts DESC) AS %s_rnk FROM `%s` ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
 			query, santizedName, tableJoinAlias, q.getTableName(tableName), tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
-		}
 	}
+	// Each lag join is resolved to a single row per label entity and timestamp up front,
+	// so it doesn't need to take part in the rank based deduplication below.
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := strings.Replace(lagFeatureColumnName(tableName, lagFeature), "-", "_", -1)
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i+1)
+		columns = append(columns, lagColumnName)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT lbl.entity, lbl.ts, f.value AS `%s`, ROW_NUMBER() OVER (PARTITION BY lbl.entity, lbl.ts ORDER BY f.ts DESC, f.insert_ts DESC) AS %s_rn "+
+			"FROM (SELECT DISTINCT entity, ts FROM `%s`) AS lbl JOIN `%s` AS f ON (f.entity=lbl.entity AND TIMESTAMP_ADD(f.ts, INTERVAL %d MICROSECOND) <= lbl.ts)) AS %s "+
+			"ON (%s.entity=t0.entity AND %s.ts=t0.ts AND %s_rn=1)",
+			query, lagColumnName, tableJoinAlias, q.getTableName(labelName), q.getTableName(tableName), lagFeature.LagDelta.Microseconds(), tableJoinAlias,
+			tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	query = fmt.Sprintf("%s )) WHERE rn=1", query)
 	columnStr := strings.Join(columns, ", ")
-	selectColumnStr := strings.Join(selectColumns, ", ")
+	// Lag features aren't ranked, so the rank columns are only added when there are regular features.
+	rankSelectStr, rankOrderStr := "", ""
+	if len(selectColumns) > 0 {
+		rankSelectStr = ", " + strings.Join(selectColumns, ", ")
+		rankOrderStr = fmt.Sprintf(", %s DESC", strings.Join(selectColumns, ", "))
+	}
+	labelSource, err := q.trainingSetLabelSource(def, labelName)
+	if err != nil {
+		return err
+	}
 
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
 			"CREATE TABLE `%s` AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM `%s` AS t0 %s )",
-			q.getTableName(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
+				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\"%s) AS rn FROM ( "+
+				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s%s FROM %s AS t0 %s )",
+			q.getTableName(tableName), columnStr, rankOrderStr, columnStr, rankSelectStr, labelSource, query)
 
 		bqQ := store.client.Query(fullQuery)
 		job, err := bqQ.Run(store.query.getContext())
@@ -563,14 +586,37 @@ func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSe
 		tempTable := fmt.Sprintf("tmp_%s", tableName)
 		fullQuery := fmt.Sprintf(
 			"CREATE TABLE `%s` AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s desc) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM `%s` AS t0 %s )",
-			q.getTableName(tempTable), columnStr, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
+				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\"%s) AS rn FROM ( "+
+				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s%s FROM %s AS t0 %s )",
+			q.getTableName(tempTable), columnStr, rankOrderStr, columnStr, rankSelectStr, labelSource, query)
 		err := q.atomicUpdate(store.client, tableName, tempTable, fullQuery)
 		return err
 	}
 }
 
+// trainingSetLabelSource returns what a training set query should select its label
+// rows from, applying the definition's time window and entity filter if it has any.
+func (q defaultBQQueries) trainingSetLabelSource(def TrainingSetDef, labelName string) (string, error) {
+	if !def.hasLabelFilter() {
+		return fmt.Sprintf("`%s`", q.getTableName(labelName)), nil
+	}
+	conditions := make([]string, 0)
+	if !def.StartTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("ts >= TIMESTAMP '%s'", def.StartTime.UTC().Format("2006-01-02 15:04:05.999999-07:00")))
+	}
+	if !def.EndTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("ts < TIMESTAMP '%s'", def.EndTime.UTC().Format("2006-01-02 15:04:05.999999-07:00")))
+	}
+	if def.EntityFilter != nil {
+		filterTable, err := GetPrimaryTableName(def.EntityFilter.Source)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, fmt.Sprintf("entity IN (SELECT `%s` FROM `%s`)", def.EntityFilter.EntityColumn, q.getTableName(filterTable)))
+	}
+	return fmt.Sprintf("(SELECT * FROM `%s` WHERE %s)", q.getTableName(labelName), strings.Join(conditions, " AND ")), nil
+}
+
 func (q defaultBQQueries) trainingRowSelect(columns string, trainingSetName string) string {
 	return fmt.Sprintf("SELECT %s FROM `%s`", columns, q.getTableName(trainingSetName))
 }
@@ -993,6 +1039,22 @@ func (store *bqOfflineStore) CreatePrimaryTable(id ResourceID, schema TableSchem
 	return table, nil
 }
 
+func (store *bqOfflineStore) GetSourceSchema(id ResourceID) ([]TableColumn, error) {
+	if err := id.check(Primary, Transformation); err != nil {
+		return nil, err
+	}
+	name, err := GetPrimaryTableName(id)
+	if err != nil {
+		return nil, err
+	}
+	if exists, err := store.tableExists(id); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	return store.query.getColumns(store.client, name)
+}
+
 func (store *bqOfflineStore) GetPrimaryTable(id ResourceID) (PrimaryTable, error) {
 	name, err := GetPrimaryTableName(id)
 	if err != nil {
@@ -1311,11 +1373,15 @@ func (store *bqOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 }
 
 func (store *bqOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
-	return nil, fmt.Errorf("not Implemented")
+	return nil, unsupportedTrainTestSplitError(store.Type())
 }
 
 func (store *bqOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
-	return nil, nil, fmt.Errorf("not Implemented")
+	return nil, nil, unsupportedTrainTestSplitError(store.Type())
+}
+
+func (store *bqOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	return nil, unsupportedTrainTestSplitError(store.Type())
 }
 
 func (store *bqOfflineStore) CheckHealth() (bool, error) {
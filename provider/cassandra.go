@@ -221,26 +221,13 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 	key := table.key
 	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
 
-	var ptr interface{}
-	switch table.valueType {
-	case types.Int:
-		ptr = new(int)
-	case types.Int64:
-		ptr = new(int64)
-	case types.Float32:
-		ptr = new(float32)
-	case types.Float64:
-		ptr = new(float64)
-	case types.Bool:
-		ptr = new(bool)
-	case types.String, types.NilType:
-		ptr = new(string)
-	default:
-		return nil, fferr.NewDataTypeNotFoundErrorf(table.valueType, "could not determine column type")
+	ptr, err := table.valuePtr()
+	if err != nil {
+		return nil, err
 	}
 
 	query := fmt.Sprintf("SELECT value FROM %s WHERE entity = '%s'", tableName, entity)
-	err := table.session.Query(query).WithContext(context.TODO()).Scan(ptr)
+	err = table.session.Query(query).WithContext(context.TODO()).Scan(ptr)
 	if err == gocql.ErrNotFound {
 		wrapped := fferr.NewEntityNotFoundError(key.Feature, key.Variant, entity, nil)
 		wrapped.AddDetail("table_name", tableName)
@@ -251,24 +238,94 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 		wrapped.AddDetail("table_name", tableName)
 		return nil, wrapped
 	}
+	return table.derefValue(ptr)
+}
+
+// maxCassandraBatchGetSize limits the number of keys in each IN query, since large IN queries put
+// pressure on the coordinator node.
+const maxCassandraBatchGetSize = 100
+
+// BatchGet reads entities with IN queries of up to maxCassandraBatchGetSize keys.
+func (table cassandraOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	key := table.key
+	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
+	query := fmt.Sprintf("SELECT entity, value FROM %s WHERE entity IN ?", tableName)
+	found := make(map[string]interface{}, len(entities))
+	for start := 0; start < len(entities); start += maxCassandraBatchGetSize {
+		end := start + maxCassandraBatchGetSize
+		if end > len(entities) {
+			end = len(entities)
+		}
+		iter := table.session.Query(query, entities[start:end]).WithContext(context.TODO()).Iter()
+		for {
+			var entity string
+			ptr, err := table.valuePtr()
+			if err != nil {
+				iter.Close()
+				return nil, err
+			}
+			if !iter.Scan(&entity, ptr) {
+				break
+			}
+			if found[entity], err = table.derefValue(ptr); err != nil {
+				iter.Close()
+				return nil, err
+			}
+		}
+		if err := iter.Close(); err != nil {
+			wrapped := fferr.NewExecutionError(pt.CassandraOnline.String(), err)
+			wrapped.AddDetail("table_name", tableName)
+			return nil, wrapped
+		}
+	}
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		val, has := found[entity]
+		if !has {
+			wrapped := fferr.NewEntityNotFoundError(key.Feature, key.Variant, entity, nil)
+			wrapped.AddDetail("table_name", tableName)
+			return nil, wrapped
+		}
+		items[i] = GetItem{Entity: entity, Value: val}
+	}
+	return items, nil
+}
+
+// valuePtr returns a pointer to scan one of the table's values into.
+func (table cassandraOnlineTable) valuePtr() (interface{}, error) {
+	switch table.valueType {
+	case types.Int:
+		return new(int), nil
+	case types.Int64:
+		return new(int64), nil
+	case types.Float32:
+		return new(float32), nil
+	case types.Float64:
+		return new(float64), nil
+	case types.Bool:
+		return new(bool), nil
+	case types.String, types.NilType:
+		return new(string), nil
+	default:
+		return nil, fferr.NewDataTypeNotFoundErrorf(table.valueType, "could not determine column type")
+	}
+}
 
-	var val interface{}
+func (table cassandraOnlineTable) derefValue(ptr interface{}) (interface{}, error) {
 	switch casted := ptr.(type) {
 	case *int:
-		val = *casted
+		return *casted, nil
 	case *int64:
-		val = *casted
+		return *casted, nil
 	case *float32:
-		val = *casted
+		return *casted, nil
 	case *float64:
-		val = *casted
+		return *casted, nil
 	case *bool:
-		val = *casted
+		return *casted, nil
 	case *string:
-		val = *casted
+		return *casted, nil
 	default:
 		return nil, fferr.NewDataTypeNotFoundErrorf(table.valueType, "could not determine column type")
 	}
-	return val, nil
-
 }
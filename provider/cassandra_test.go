@@ -39,8 +39,9 @@ func TestOnlineStoreCassandra(t *testing.T) {
 	}
 
 	test := OnlineStoreTest{
-		t:     t,
-		store: store,
+		t:            t,
+		store:        store,
+		testBatchGet: true,
 	}
 	test.Run()
 }
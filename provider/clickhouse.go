@@ -999,6 +999,10 @@ func (store *clickHouseOfflineStore) prepareTrainingSetQuery(id ResourceID) (*Tr
 	}
 	features := make([]string, 0)
 	for _, name := range columnNames {
+		// The label entity and timestamp are only kept for train test splits
+		if name.Name == "_entity" || name.Name == "_ts" {
+			continue
+		}
 		features = append(features, sanitizeCH(name.Name))
 	}
 	columns := strings.Join(features, ", ")
@@ -1029,40 +1033,32 @@ func (store *clickHouseOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetI
 }
 
 func (store *clickHouseOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
+	if err := def.check(); err != nil {
+		return nil, err
+	}
 	prep, err := store.prepareTrainingSetQuery(ResourceID{Name: def.TrainingSetName, Variant: def.TrainingSetVariant})
 	if err != nil {
 		return nil, err
 	}
 	trainTestSplitTableName := store.getTrainTestSplitTableName(prep.TrainingSetName, def)
 
-	// Generate ORDER BY clause for shuffling if needed
-	orderByClause := ""
 	randomState := def.RandomState
-	if def.Shuffle {
-		if def.RandomState == 0 {
-			randomState = rand.Int() // Ensure a random state if 0 is provided
-		}
-		// Use ClickHouse's cityHash64 for deterministic shuffling, rowNumberInAllBlocks is a unique identifier for each row
-		orderByClause = fmt.Sprintf("ORDER BY cityHash64(concat(toString(_row), toString(%d)))", randomState)
+	if def.Shuffle && def.RandomState == 0 {
+		randomState = rand.Int() // Ensure a random state if 0 is provided
 	}
-
-	// Calculate the number of test rows based on the testSize parameter
-	var totalRows int
-	err = store.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", sanitizeCH(prep.TrainingSetName))).Scan(&totalRows)
+	splitExpr, err := store.splitGroupExpression(prep.TrainingSetName, def, randomState)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get table size: %v", err)
+		return nil, err
 	}
-	testRows := int(float32(totalRows) * def.TestSize)
 
-	// Create the final view with an 'is_test' column
+	// Create the final view with a 'split' column holding each row's splitGroup
 	createViewQuery := fmt.Sprintf(`
 			CREATE VIEW IF NOT EXISTS %s AS
-			SELECT *, IF(row_number() OVER (%s) <= %d, 1, 0) AS is_test
+			SELECT *, %s AS split
 			FROM %s
     	`,
 		sanitizeCH(trainTestSplitTableName),
-		orderByClause,
-		testRows,
+		splitExpr,
 		sanitizeCH(prep.TrainingSetName),
 	)
 
@@ -1084,41 +1080,103 @@ func (store *clickHouseOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef)
 	return dropFunc, nil
 }
 
+// splitGroupExpression returns the expression that assigns each row of the training set
+// to a splitGroup according to the definition's strategy.
+func (store *clickHouseOfflineStore) splitGroupExpression(trainingSetName string, def TrainTestSplitDef, randomState int) (string, error) {
+	// Use ClickHouse's cityHash64 for deterministic shuffling, _row is a unique identifier for each row
+	rowHash := fmt.Sprintf("cityHash64(concat(toString(_row), toString(%d)))", randomState)
+	switch def.Strategy {
+	case RandomSplit:
+		orderByClause := ""
+		if def.Shuffle {
+			orderByClause = fmt.Sprintf("ORDER BY %s", rowHash)
+		}
+		// Calculate the number of test and validation rows based on their sizes
+		var totalRows int
+		err := store.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", sanitizeCH(trainingSetName))).Scan(&totalRows)
+		if err != nil {
+			return "", fmt.Errorf("failed to get table size: %v", err)
+		}
+		testRows := int(float32(totalRows) * def.TestSize)
+		validationRows := int(float32(totalRows) * def.ValidationSize)
+		return fmt.Sprintf("multiIf(row_number() OVER (%s) <= %d, %d, row_number() OVER (%s) <= %d, %d, %d)",
+			orderByClause, testRows, testSplitGroup, orderByClause, testRows+validationRows, validationSplitGroup, trainSplitGroup), nil
+	case StratifiedSplit:
+		orderByClause := ""
+		if def.Shuffle {
+			orderByClause = fmt.Sprintf("ORDER BY %s", rowHash)
+		}
+		// Each label value is split on its own so the splits keep the label distribution
+		rowNumber := fmt.Sprintf("row_number() OVER (PARTITION BY label %s)", orderByClause)
+		labelRows := "count() OVER (PARTITION BY label)"
+		return fmt.Sprintf("multiIf(%s <= floor(%s * %v), %d, %s <= floor(%s * %v), %d, %d)",
+			rowNumber, labelRows, def.TestSize, testSplitGroup, rowNumber, labelRows, def.TestSize+def.ValidationSize, validationSplitGroup, trainSplitGroup), nil
+	case EntityHashSplit:
+		// Bucket entities into 10000 buckets so the same entity always lands in the same split
+		bucket := fmt.Sprintf("cityHash64(concat(toString(_entity), toString(%d))) %% 10000", def.RandomState)
+		testBuckets := int(def.TestSize * 10000)
+		validationBuckets := int((def.TestSize + def.ValidationSize) * 10000)
+		return fmt.Sprintf("multiIf(%s < %d, %d, %s < %d, %d, %d)",
+			bucket, testBuckets, testSplitGroup, bucket, validationBuckets, validationSplitGroup, trainSplitGroup), nil
+	case TimeSplit:
+		testCutoff := store.query.timestampLiteral(def.TestCutoff)
+		if def.ValidationCutoff.IsZero() {
+			return fmt.Sprintf("if(_ts >= %s, %d, %d)", testCutoff, testSplitGroup, trainSplitGroup), nil
+		}
+		return fmt.Sprintf("multiIf(_ts >= %s, %d, _ts >= %s, %d, %d)",
+			testCutoff, testSplitGroup, store.query.timestampLiteral(def.ValidationCutoff), validationSplitGroup, trainSplitGroup), nil
+	default:
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("unknown train test split strategy %s", def.Strategy))
+	}
+}
+
 func (store *clickHouseOfflineStore) getTrainTestSplitTableName(trainingSetTable string, def TrainTestSplitDef) string {
 	// Generate unique suffix for the view names
-	tableNameSuffix := fmt.Sprintf("%s_%d_%t_%d", trainingSetTable, int(def.TestSize*100), def.Shuffle, def.RandomState)
+	tableNameSuffix := fmt.Sprintf("%s_%d_%t_%d_%s_%d", trainingSetTable, int(def.TestSize*100), def.Shuffle, def.RandomState, def.Strategy, int(def.ValidationSize*100))
+	if def.Strategy == TimeSplit {
+		tableNameSuffix = fmt.Sprintf("%s_%d_%d", tableNameSuffix, def.TestCutoff.UnixMicro(), def.ValidationCutoff.UnixMicro())
+	}
 	trainTestSplitViewName := fmt.Sprintf("%s_split", tableNameSuffix)
 	return trainTestSplitViewName
 }
 
+func (store *clickHouseOfflineStore) getSplitIterator(prep *TrainingSetPreparation, trainTestSplitTableName string, group splitGroup) (TrainingSetIterator, error) {
+	query := store.query.trainingRowSplitSelect(prep.Columns, sanitizeCH(trainTestSplitTableName), group)
+	rows, err := store.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s set: %v", group, err)
+	}
+	colTypes, err := store.getValueColumnTypes(trainTestSplitTableName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get column types: %v", err)
+	}
+	return store.newsqlTrainingSetIterator(rows, colTypes), nil
+}
+
 func (store *clickHouseOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
 	prep, err := store.prepareTrainingSetQuery(ResourceID{Name: def.TrainingSetName, Variant: def.TrainingSetVariant})
 	if err != nil {
 		return nil, nil, err
 	}
 	trainTestSplitTableName := store.getTrainTestSplitTableName(prep.TrainingSetName, def)
-	train, test := store.query.trainingRowSplitSelect(prep.Columns, sanitizeCH(trainTestSplitTableName))
-
-	fmt.Printf("Training Set Query: %s\n", train)
-	fmt.Printf("Test Set Query: %s\n", test)
-
-	testRows, err := store.db.Query(test)
+	testIter, err := store.getSplitIterator(prep, trainTestSplitTableName, testSplitGroup)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not query test set: %v", err)
+		return nil, nil, err
 	}
-	trainRows, err := store.db.Query(train)
+	trainIter, err := store.getSplitIterator(prep, trainTestSplitTableName, trainSplitGroup)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not query train set: %v", err)
-
+		return nil, nil, err
 	}
+	return trainIter, testIter, nil
+}
 
-	colTypes, err := store.getValueColumnTypes(trainTestSplitTableName)
+func (store *clickHouseOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	prep, err := store.prepareTrainingSetQuery(ResourceID{Name: def.TrainingSetName, Variant: def.TrainingSetVariant})
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not get column types: %v", err)
+		return nil, err
 	}
-
-	return store.newsqlTrainingSetIterator(trainRows, colTypes), store.newsqlTrainingSetIterator(testRows, colTypes), nil
-
+	trainTestSplitTableName := store.getTrainTestSplitTableName(prep.TrainingSetName, def)
+	return store.getSplitIterator(prep, trainTestSplitTableName, validationSplitGroup)
 }
 
 func (store *clickHouseOfflineStore) Close() error {
@@ -1146,11 +1204,8 @@ func (q clickhouseSQLQueries) trainingRowSelect(columns string, trainingSetName
 	return fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s ORDER BY _row ASC)", columns, sanitizeCH(trainingSetName))
 }
 
-func (q clickhouseSQLQueries) trainingRowSplitSelect(columns string, trainingSetSplitName string) (string, string) {
-	testSplitQuery := fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE `is_test` = 1 ORDER BY _row ASC)", columns, trainingSetSplitName)
-	trainSplitQuery := fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE `is_test` = 0 ORDER BY _row ASC)", columns, trainingSetSplitName)
-
-	return trainSplitQuery, testSplitQuery
+func (q clickhouseSQLQueries) trainingRowSplitSelect(columns string, trainingSetSplitName string, group splitGroup) string {
+	return fmt.Sprintf("SELECT * EXCEPT _row FROM (SELECT %s FROM %s WHERE `split` = %d ORDER BY _row ASC)", columns, trainingSetSplitName, group)
 }
 
 func (q clickhouseSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
@@ -1286,6 +1341,10 @@ func (q clickhouseSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def Trai
 	return q.trainingSetQuery(store, def, tableName, labelName, true)
 }
 
+func (q clickhouseSQLQueries) timestampLiteral(t time.Time) string {
+	return fmt.Sprintf("toDateTime64('%s', 6, 'UTC')", t.UTC().Format("2006-01-02 15:04:05.999999"))
+}
+
 func buildTrainingSelect(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) (string, error) {
 	columns := make([]string, 0)
 	query := ""
@@ -1300,9 +1359,24 @@ func buildTrainingSelect(store *sqlOfflineStore, def TrainingSetDef, tableName s
 		query = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts FROM %s) AS %s ON (%s.entity = l.entity) AND (%s.ts <= l.ts)",
 			query, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
 	}
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return "", err
+		}
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, sanitizeCH(lagFeatureColumnName(tableName, lagFeature))))
+		// Shift the feature timestamps forward by the lag so the ASOF join matches values at least LagDelta older than the label
+		query = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, addMicroseconds(ts, %d) AS lag_ts FROM %s) AS %s ON (%s.entity = l.entity) AND (%s.lag_ts <= l.ts)",
+			query, lagFeature.LagDelta.Microseconds(), sanitizeCH(tableName), tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
 	columnStr := strings.Join(columns, ", ")
-	// rand gives us a UInt32 to ensure random order
-	query = fmt.Sprintf("SELECT %s, l.value as label, rand() as _row FROM %s AS l %s", columnStr, sanitizeCH(labelName), query)
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitizeCH)
+	if err != nil {
+		return "", err
+	}
+	// rand gives us a UInt32 to ensure random order, the label entity and timestamp are kept for train test splits
+	query = fmt.Sprintf("SELECT %s, l.value as label, rand() as _row, l.entity as _entity, l.ts as _ts FROM %s AS l %s", columnStr, labelSource, query)
 	return query, nil
 }
 
@@ -1481,7 +1555,7 @@ func (q clickhouseSQLQueries) transformationExists() string {
 }
 
 func (q clickhouseSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableColumn, error) {
-	qry := "SELECT name FROM system.columns WHERE table = ?"
+	qry := "SELECT name, type FROM system.columns WHERE table = ?"
 	rows, err := db.Query(qry, tableName)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
@@ -1491,13 +1565,13 @@ func (q clickhouseSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableC
 	defer rows.Close()
 	columnNames := make([]TableColumn, 0)
 	for rows.Next() {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+		var column, nativeType string
+		if err := rows.Scan(&column, &nativeType); err != nil {
 			wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
 			wrapped.AddDetail("table_name", tableName)
 			return nil, wrapped
 		}
-		columnNames = append(columnNames, TableColumn{Name: column})
+		columnNames = append(columnNames, TableColumn{Name: column, NativeType: nativeType})
 	}
 	return columnNames, nil
 }
@@ -1511,6 +1585,11 @@ func (q clickhouseSQLQueries) materializationIterateSegment(tableName string) st
 	return fmt.Sprintf("SELECT entity, value, ts FROM (SELECT * FROM %s WHERE row_number>%s AND row_number<=%s) t1", sanitizeCH(tableName), bind.Next(), bind.Next())
 }
 
+func (q clickhouseSQLQueries) materializationIterateSegmentSince(tableName string) string {
+	bind := q.newVariableBindingIterator()
+	return fmt.Sprintf("SELECT entity, value, ts FROM (SELECT * FROM %s WHERE row_number>%s AND row_number<=%s AND ts>=%s) t1", sanitizeCH(tableName), bind.Next(), bind.Next(), bind.Next())
+}
+
 type clickHouseMaterialization struct {
 	id        MaterializationID
 	db        *sql.DB
@@ -1542,9 +1621,31 @@ func (mat *clickHouseMaterialization) NumRows() (int64, error) {
 	return intVar, nil
 }
 
+func (mat *clickHouseMaterialization) MaxTimestamp() (time.Time, error) {
+	var max interface{}
+	query := fmt.Sprintf("SELECT max(ts) FROM %s", sanitizeCH(mat.tableName))
+	if err := mat.db.QueryRow(query).Scan(&max); err != nil {
+		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
+		wrapped.AddDetail("table_name", mat.tableName)
+		return time.Time{}, wrapped
+	}
+	return parseMaxTimestamp(max)
+}
+
 func (mat *clickHouseMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
-	query := mat.query.materializationIterateSegment(mat.tableName)
-	rows, err := mat.db.Query(query, start, end)
+	return mat.iterate(mat.query.materializationIterateSegment(mat.tableName), start, end)
+}
+
+func (mat *clickHouseMaterialization) IterateChunkSince(idx int, watermark time.Time) (FeatureIterator, error) {
+	start, end, err := genericChunkBounds(mat, defaultRowsPerChunk, idx)
+	if err != nil {
+		return nil, err
+	}
+	return mat.iterate(mat.query.materializationIterateSegmentSince(mat.tableName), start, end, watermark)
+}
+
+func (mat *clickHouseMaterialization) iterate(query string, args ...interface{}) (FeatureIterator, error) {
+	rows, err := mat.db.Query(query, args...)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(pt.ClickHouseOffline.String(), err)
 		wrapped.AddDetail("table_name", mat.tableName)
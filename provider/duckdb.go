@@ -0,0 +1,374 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marcboeker/go-duckdb"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/filestore"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+type duckdbColumnType string
+
+const (
+	duckdbInteger     duckdbColumnType = "INTEGER"
+	duckdbBigInt      duckdbColumnType = "BIGINT"
+	duckdbFloat       duckdbColumnType = "FLOAT"
+	duckdbDouble      duckdbColumnType = "DOUBLE"
+	duckdbDecimal     duckdbColumnType = "DECIMAL"
+	duckdbVarchar     duckdbColumnType = "VARCHAR"
+	duckdbBool        duckdbColumnType = "BOOLEAN"
+	duckdbTimestamp   duckdbColumnType = "TIMESTAMP"
+	duckdbTimestampTZ duckdbColumnType = "TIMESTAMPTZ"
+)
+
+func duckdbOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	store, err := NewDuckDBOfflineStore(config)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// duckdbOfflineStore runs the SQL offline store in process, on an in-memory database or a
+// database file. Primary tables can also be registered from parquet and CSV files in its
+// local filestore.
+type duckdbOfflineStore struct {
+	sqlOfflineStore
+	fileStore FileStore
+	dirPath   string
+}
+
+func NewDuckDBOfflineStore(config pc.SerializedConfig) (*duckdbOfflineStore, error) {
+	dc := pc.DuckDBConfig{}
+	if err := dc.Deserialize(config); err != nil {
+		return nil, err
+	}
+	queries := duckdbSQLQueries{}
+	queries.setVariableBinding(PostgresBindingStyle)
+	sgConfig := SQLOfflineStoreConfig{
+		Config:        config,
+		ConnectionURL: dc.Path,
+		Driver:        "duckdb",
+		ProviderType:  pt.DuckDBOffline,
+		QueryImpl:     &queries,
+	}
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	duckdbStore := &duckdbOfflineStore{sqlOfflineStore: *store}
+	if dc.DirPath != "" {
+		fsConfig := pc.LocalFileStoreConfig{DirPath: dc.DirPath}
+		serialized, err := fsConfig.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		fileStore, err := NewLocalFileStore(serialized)
+		if err != nil {
+			return nil, err
+		}
+		duckdbStore.fileStore = fileStore
+		duckdbStore.dirPath = strings.TrimPrefix(dc.DirPath, filestore.FileSystemPrefix)
+	}
+	return duckdbStore, nil
+}
+
+func (store *duckdbOfflineStore) AsOfflineStore() (OfflineStore, error) {
+	return store, nil
+}
+
+// RegisterPrimaryFromSourceTable registers a view over sourceName. Sources with a parquet or csv
+// extension, or a file:// URI, are read from the local filestore; anything else is a table or view.
+func (store *duckdbOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sourceName string) (PrimaryTable, error) {
+	if !isDuckDBFileSource(sourceName) {
+		return store.sqlOfflineStore.RegisterPrimaryFromSourceTable(id, sourceName)
+	}
+	if err := id.check(Primary); err != nil {
+		return nil, err
+	}
+	if exists, err := store.tableExistsForResourceId(id); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fferr.NewDatasetAlreadyExistsError(id.Name, id.Variant, nil)
+	}
+	tableName, err := GetPrimaryTableName(id)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := store.fileReader(id, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), reader)
+	if _, err := store.db.Exec(query); err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.DuckDBOffline.String(), id.Name, id.Variant, fferr.ResourceType(id.Type.String()), err)
+		wrapped.AddDetail("source", sourceName)
+		return nil, wrapped
+	}
+	columnNames, err := store.query.getColumns(store.db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlPrimaryTable{
+		db:           store.db,
+		name:         tableName,
+		schema:       TableSchema{Columns: columnNames},
+		query:        store.query,
+		providerType: store.Type(),
+	}, nil
+}
+
+func isDuckDBFileSource(sourceName string) bool {
+	if strings.HasPrefix(sourceName, filestore.FileSystemPrefix) {
+		return true
+	}
+	switch filestore.FileType(strings.TrimPrefix(filepath.Ext(sourceName), ".")) {
+	case filestore.Parquet, filestore.CSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// fileReader returns the table function that reads sourceName, which is either a path relative
+// to the filestore's root or a file:// URI within it.
+func (store *duckdbOfflineStore) fileReader(id ResourceID, sourceName string) (string, error) {
+	if store.fileStore == nil {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("cannot read %s: DuckDB provider has no DirPath", sourceName))
+	}
+	key := sourceName
+	if strings.HasPrefix(sourceName, filestore.FileSystemPrefix) {
+		rel, err := filepath.Rel(store.dirPath, strings.TrimPrefix(sourceName, filestore.FileSystemPrefix))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", fferr.NewInvalidArgumentError(fmt.Errorf("source %s is outside of the filestore %s", sourceName, store.dirPath))
+		}
+		key = rel
+	}
+	path, err := store.fileStore.CreateFilePath(key, false)
+	if err != nil {
+		return "", err
+	}
+	if exists, err := store.fileStore.Exists(path); err != nil {
+		return "", err
+	} else if !exists {
+		return "", fferr.NewDatasetNotFoundError(id.Name, id.Variant, fmt.Errorf("source file '%s' does not exist", sourceName))
+	}
+	localPath := strings.ReplaceAll(filepath.Join(store.dirPath, key), "'", "''")
+	switch path.Ext() {
+	case filestore.Parquet:
+		return fmt.Sprintf("read_parquet('%s')", localPath), nil
+	case filestore.CSV:
+		return fmt.Sprintf("read_csv_auto('%s', header=true)", localPath), nil
+	default:
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("unsupported file type %q; expected parquet or csv", path.Ext()))
+	}
+}
+
+type duckdbSQLQueries struct {
+	defaultOfflineSQLQueries
+}
+
+func (q duckdbSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	var query string
+	if timestamp {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable))
+	} else {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp(0) as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.SourceTable))
+	}
+	if _, err := db.Exec(query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DuckDBOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
+
+func (q duckdbSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sanitize(sourceName))
+}
+
+// materializationQuery numbers rows by entity, since DuckDB's parallel window functions don't keep
+// insertion order and segments must be stable.
+func (q duckdbSQLQueries) materializationQuery(create string, tableName string, sourceName string) string {
+	return fmt.Sprintf(
+		"%s %s AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
+			"AS rn FROM %s) t WHERE rn=1)", create, sanitize(tableName), sanitize(sourceName))
+}
+
+func (q duckdbSQLQueries) materializationCreate(tableName string, sourceName string) []string {
+	return []string{q.materializationQuery("CREATE TABLE IF NOT EXISTS", tableName, sourceName)}
+}
+
+func (q duckdbSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	query := q.materializationQuery("CREATE OR REPLACE TABLE", tableName, sourceName)
+	if _, err := db.Exec(query); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DuckDBOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		wrapped.AddDetail("source_name", sourceName)
+		return wrapped
+	}
+	return nil
+}
+
+func (q duckdbSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
+}
+
+func (q duckdbSQLQueries) determineColumnType(valueType types.ValueType) (string, error) {
+	switch valueType {
+	case types.Int, types.Int32:
+		return string(duckdbInteger), nil
+	case types.Int64:
+		return string(duckdbBigInt), nil
+	case types.Float32, types.Float64:
+		return string(duckdbDouble), nil
+	case types.String, types.NilType:
+		return string(duckdbVarchar), nil
+	case types.Bool:
+		return string(duckdbBool), nil
+	case types.Timestamp:
+		return string(duckdbTimestampTZ), nil
+	default:
+		return "", fferr.NewDataTypeNotFoundErrorf(valueType, "could not determine column type")
+	}
+}
+
+func (q duckdbSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	return fmt.Sprintf("CREATE TABLE %s (entity VARCHAR, value %s, ts TIMESTAMPTZ, UNIQUE (entity, ts))", sanitize(name), columnType)
+}
+
+func (q duckdbSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+func (q duckdbSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, true)
+}
+
+// trainingSetQuery uses ASOF joins to find the latest value of each feature at or before each label's
+// timestamp. Timestamps are compared as UTC TIMESTAMPs since sources may use either timestamp type.
+func (q duckdbSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	columns := make([]string, 0)
+	joins := make([]string, 0)
+	for i, feature := range def.Features {
+		featureTable, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		alias := fmt.Sprintf("t%d", i)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", alias, sanitize(featureTable)))
+		joins = append(joins, fmt.Sprintf("ASOF LEFT JOIN %s %s ON l.entity = %s.entity AND l.ts::TIMESTAMP >= %s.ts::TIMESTAMP",
+			sanitize(featureTable), alias, alias, alias))
+	}
+	for i, lagFeature := range def.LagFeatures {
+		featureTable, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeatureColumnName(featureTable, lagFeature))
+		alias := fmt.Sprintf("t%d", len(def.Features)+i)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", alias, lagColumnName))
+		joins = append(joins, fmt.Sprintf("ASOF LEFT JOIN %s %s ON l.entity = %s.entity AND l.ts::TIMESTAMP - INTERVAL (%d) MICROSECOND >= %s.ts::TIMESTAMP",
+			sanitize(featureTable), alias, alias, lagFeature.LagDelta.Microseconds(), alias))
+	}
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitize)
+	if err != nil {
+		return err
+	}
+	create := "CREATE TABLE"
+	if isUpdate {
+		create = "CREATE OR REPLACE TABLE"
+	}
+	query := fmt.Sprintf("%s %s AS SELECT %s, l.value AS label FROM %s l %s",
+		create, sanitize(tableName), strings.Join(columns, ", "), labelSource, strings.Join(joins, " "))
+	if _, err := store.db.Exec(query); err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.DuckDBOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
+		wrapped.AddDetail("table_name", tableName)
+		wrapped.AddDetail("label_name", labelName)
+		return wrapped
+	}
+	return nil
+}
+
+func (q duckdbSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	switch t {
+	case duckdbInteger, duckdbBigInt:
+		switch i := v.(type) {
+		case int32:
+			return int(i)
+		case int64:
+			return int(i)
+		}
+	case duckdbFloat, duckdbDouble:
+		switch f := v.(type) {
+		case float32:
+			return float64(f)
+		case float64:
+			return f
+		}
+	case duckdbDecimal:
+		if d, ok := v.(duckdb.Decimal); ok {
+			return d.Float64()
+		}
+	case duckdbTimestamp, duckdbTimestampTZ:
+		if ts, ok := v.(time.Time); ok {
+			return ts.UTC()
+		}
+	}
+	return v
+}
+
+func (q duckdbSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	name := t.DatabaseTypeName()
+	switch {
+	case strings.HasPrefix(name, string(duckdbDecimal)):
+		return duckdbDecimal
+	case name == "TIMESTAMP WITH TIME ZONE":
+		return duckdbTimestampTZ
+	}
+	switch duckdbColumnType(name) {
+	case duckdbInteger, duckdbBigInt, duckdbFloat, duckdbDouble, duckdbBool, duckdbTimestamp, duckdbTimestampTZ:
+		return duckdbColumnType(name)
+	case "TINYINT", "SMALLINT":
+		return duckdbInteger
+	}
+	return duckdbVarchar
+}
+
+func (q duckdbSQLQueries) numRows(n interface{}) (int64, error) {
+	return n.(int64), nil
+}
+
+func (q duckdbSQLQueries) transformationCreate(name string, query string) []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(name), query),
+	}
+}
+
+func (q duckdbSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	if _, err := db.Exec(fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", sanitize(tableName), query)); err != nil {
+		wrapped := fferr.NewExecutionError(pt.DuckDBOffline.String(), err)
+		wrapped.AddDetail("table_name", tableName)
+		return wrapped
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+func TestOfflineStoreDuckDB(t *testing.T) {
+	config := pc.DuckDBConfig{}
+	store, err := GetOfflineStore(pt.DuckDBOffline, config.Serialize())
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+
+	test := OfflineStoreTest{
+		t:     t,
+		store: store,
+	}
+	test.Run()
+	test.RunSQL()
+}
+
+func TestDuckDBLagFeatures(t *testing.T) {
+	config := pc.DuckDBConfig{}
+	store, err := GetOfflineStore(pt.DuckDBOffline, config.Serialize())
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+	defer store.Close()
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	featureID := randomFeatureID()
+	featureTable, err := store.CreateResourceTable(featureID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %s", err)
+	}
+	labelID := randomLabelID()
+	labelTable, err := store.CreateResourceTable(labelID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create label table: %s", err)
+	}
+	for i := 1; i <= 4; i++ {
+		ts := time.UnixMilli(int64(i)).UTC()
+		if err := featureTable.Write(ResourceRecord{Entity: "a", Value: i, TS: ts}); err != nil {
+			t.Fatalf("Failed to write feature: %s", err)
+		}
+		if err := labelTable.Write(ResourceRecord{Entity: "a", Value: i * 10, TS: ts}); err != nil {
+			t.Fatalf("Failed to write label: %s", err)
+		}
+	}
+	def := TrainingSetDef{
+		ID:       randomID(TrainingSet),
+		Label:    labelID,
+		Features: []ResourceID{featureID},
+		LagFeatures: []LagFeatureDef{
+			{FeatureName: featureID.Name, FeatureVariant: featureID.Variant, LagName: "lag_1ms", LagDelta: time.Millisecond},
+			{FeatureName: featureID.Name, FeatureVariant: featureID.Variant, LagDelta: 2 * time.Millisecond},
+		},
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	iter, err := store.GetTrainingSet(def.ID)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	expected := map[interface{}][]interface{}{
+		10: {1, nil, nil},
+		20: {2, 1, nil},
+		30: {3, 2, 1},
+		40: {4, 3, 2},
+	}
+	for iter.Next() {
+		features, has := expected[iter.Label()]
+		if !has {
+			t.Fatalf("Unexpected label %v", iter.Label())
+		}
+		if !reflect.DeepEqual(features, iter.Features()) {
+			t.Fatalf("Expected features %v for label %v, got %v", features, iter.Label(), iter.Features())
+		}
+		delete(expected, iter.Label())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Iteration failed: %s", err)
+	}
+	if len(expected) != 0 {
+		t.Fatalf("Missing training rows for labels %v", expected)
+	}
+}
+
+func TestDuckDBFileSources(t *testing.T) {
+	dir := t.TempDir()
+	csv := "entity,value\na,1\nb,2\nc,3\n"
+	if err := os.WriteFile(filepath.Join(dir, "source.csv"), []byte(csv), 0644); err != nil {
+		t.Fatalf("Failed to write csv: %s", err)
+	}
+	config := pc.DuckDBConfig{DirPath: fmt.Sprintf("file://%s", dir)}
+	store, err := GetOfflineStore(pt.DuckDBOffline, config.Serialize())
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+	defer store.Close()
+	copyQuery := fmt.Sprintf("COPY (SELECT * FROM read_csv_auto('%s')) TO '%s' (FORMAT PARQUET)",
+		filepath.Join(dir, "source.csv"), filepath.Join(dir, "source.parquet"))
+	if _, err := store.(*duckdbOfflineStore).db.Exec(copyQuery); err != nil {
+		t.Fatalf("Failed to write parquet: %s", err)
+	}
+
+	sources := []string{
+		"source.csv",
+		"source.parquet",
+		fmt.Sprintf("file://%s", filepath.Join(dir, "source.parquet")),
+	}
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			id := ResourceID{Name: createUUID(), Variant: createUUID(), Type: Primary}
+			table, err := store.RegisterPrimaryFromSourceTable(id, source)
+			if err != nil {
+				t.Fatalf("Failed to register %s: %s", source, err)
+			}
+			if numRows, err := table.NumRows(); err != nil {
+				t.Fatalf("Failed to get num rows: %s", err)
+			} else if numRows != 3 {
+				t.Fatalf("Expected 3 rows, got %d", numRows)
+			}
+			iter, err := table.IterateSegment(10)
+			if err != nil {
+				t.Fatalf("Failed to iterate: %s", err)
+			}
+			entities := make([]interface{}, 0)
+			for iter.Next() {
+				entities = append(entities, iter.Values()[0])
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatalf("Iteration failed: %s", err)
+			}
+			if !reflect.DeepEqual([]interface{}{"a", "b", "c"}, entities) {
+				t.Fatalf("Unexpected entities %v", entities)
+			}
+		})
+	}
+
+	invalid := []string{
+		"missing.parquet",
+		"file:///not/in/the/filestore.parquet",
+	}
+	for _, source := range invalid {
+		id := ResourceID{Name: createUUID(), Variant: createUUID(), Type: Primary}
+		if _, err := store.RegisterPrimaryFromSourceTable(id, source); err == nil {
+			t.Fatalf("Succeeded to register invalid source %s", source)
+		}
+	}
+}
@@ -444,6 +444,9 @@ func (store *dynamodbOnlineStore) GetImport(id ImportID) (Import, error) {
 	return S3Import{id: id, status: string(output.ImportTableDescription.ImportStatus), errorMessage: errorMessage}, nil
 }
 
+// dynamoTimestampAttribute holds the event time of a feature value, if it was written with one.
+const dynamoTimestampAttribute = "FeatureTimestamp"
+
 // maxDynamoBatchSize is the max amount of items that can be written to Dynamo at once. It's a dynamo set limitation.
 const maxDynamoBatchSize = 25
 
@@ -462,6 +465,9 @@ func (table dynamodbOnlineTable) BatchSet(items []SetItem) error {
 			table.key.Feature: &types.AttributeValueMemberS{Value: item.Entity},
 			"FeatureValue":    dynamoValue,
 		}
+		if !item.TS.IsZero() {
+			serialized[i][dynamoTimestampAttribute] = &types.AttributeValueMemberS{Value: item.TS.UTC().Format(time.RFC3339Nano)}
+		}
 	}
 	reqs := make([]types.WriteRequest, len(serialized))
 	for i, serItem := range serialized {
@@ -483,6 +489,14 @@ func (table dynamodbOnlineTable) MaxBatchSize() (int, error) {
 }
 
 func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
+	return table.set(entity, value, time.Time{})
+}
+
+func (table dynamodbOnlineTable) SetWithTimestamp(entity string, value interface{}, ts time.Time) error {
+	return table.set(entity, value, ts)
+}
+
+func (table dynamodbOnlineTable) set(entity string, value interface{}, ts time.Time) error {
 	dynamoValue, err := serializers[table.version].Serialize(table.valueType, value)
 	if err != nil {
 		wrap := fferr.NewInternalError(err)
@@ -490,17 +504,24 @@ func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
 		wrap.AddDetail("value", fmt.Sprintf("%v", value))
 		return wrap
 	}
+	attrValues := map[string]types.AttributeValue{
+		":val": dynamoValue,
+	}
+	// A value written without a timestamp replaces any previous one, so its old timestamp is dropped.
+	updateExpr := fmt.Sprintf("set FeatureValue = :val remove %s", dynamoTimestampAttribute)
+	if !ts.IsZero() {
+		attrValues[":ts"] = &types.AttributeValueMemberS{Value: ts.UTC().Format(time.RFC3339Nano)}
+		updateExpr = fmt.Sprintf("set FeatureValue = :val, %s = :ts", dynamoTimestampAttribute)
+	}
 	input := &dynamodb.UpdateItemInput{
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":val": dynamoValue,
-		},
-		TableName: aws.String(formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)),
+		ExpressionAttributeValues: attrValues,
+		TableName:                 aws.String(formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)),
 		Key: map[string]types.AttributeValue{
 			table.key.Feature: &types.AttributeValueMemberS{
 				Value: entity,
 			},
 		},
-		UpdateExpression: aws.String("set FeatureValue = :val"),
+		UpdateExpression: aws.String(updateExpr),
 	}
 	if _, err := table.client.UpdateItem(context.TODO(), input); err != nil {
 		wrapped := fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, "FEATURE_VARIANT", fmt.Errorf("error setting entity: %w", err))
@@ -512,6 +533,11 @@ func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
 }
 
 func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
+	val, _, err := table.GetWithTimestamp(entity)
+	return val, err
+}
+
+func (table dynamodbOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(formatDynamoTableName(table.key.Prefix, table.key.Feature, table.key.Variant)),
 		Key: map[string]types.AttributeValue{
@@ -521,22 +547,109 @@ func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
 		},
 	}
 	output_val, err := table.client.GetItem(context.TODO(), input)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
 	if len(output_val.Item) == 0 {
 		wrapped := fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
 		wrapped.AddDetail("entity", entity)
-		return nil, wrapped
-	}
-	if err != nil {
-		return nil, err
+		return nil, time.Time{}, wrapped
 	}
-	item := output_val.Item
+	return table.deserializeItem(entity, output_val.Item)
+}
+
+func (table dynamodbOnlineTable) deserializeItem(entity string, item map[string]types.AttributeValue) (interface{}, time.Time, error) {
 	value, ok := item["FeatureValue"]
 	if !ok {
 		wrapped := fferr.NewInternalErrorf("dynamoDB item does not have FeatureValue column")
 		wrapped.AddDetail("entity", entity)
-		return nil, wrapped
+		return nil, time.Time{}, wrapped
+	}
+	deserialized, err := serializers[table.version].Deserialize(table.valueType, value)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var ts time.Time
+	if tsAttr, ok := item[dynamoTimestampAttribute].(*types.AttributeValueMemberS); ok {
+		ts, err = time.Parse(time.RFC3339Nano, tsAttr.Value)
+		if err != nil {
+			wrapped := fferr.NewInternalErrorf("could not parse %s: %v", dynamoTimestampAttribute, err)
+			wrapped.AddDetail("entity", entity)
+			return nil, time.Time{}, wrapped
+		}
+	}
+	return deserialized, ts, nil
+}
+
+const (
+	// maxDynamoBatchGetSize is the max amount of keys that can be read from Dynamo at once. It's a dynamo get limitation.
+	maxDynamoBatchGetSize = 100
+	// maxDynamoBatchGetRetries bounds how many times unprocessed keys are retried before BatchGet gives up.
+	maxDynamoBatchGetRetries = 5
+)
+
+// BatchGet reads entities with BatchGetItem, maxDynamoBatchGetSize keys at a time. Keys that Dynamo
+// doesn't process due to throttling are retried up to maxDynamoBatchGetRetries times.
+func (table dynamodbOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	tableName := table.key.ToTableName()
+	unique := make([]string, 0, len(entities))
+	found := make(map[string]map[string]types.AttributeValue, len(entities))
+	for _, entity := range entities {
+		// Dynamo rejects batches with duplicate keys.
+		if _, has := found[entity]; !has {
+			found[entity] = nil
+			unique = append(unique, entity)
+		}
+	}
+	for start := 0; start < len(unique); start += maxDynamoBatchGetSize {
+		end := start + maxDynamoBatchGetSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, entity := range unique[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				table.key.Feature: &types.AttributeValueMemberS{Value: entity},
+			})
+		}
+		request := map[string]types.KeysAndAttributes{
+			tableName: {Keys: keys},
+		}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt > maxDynamoBatchGetRetries {
+				err := fmt.Errorf("%d keys were still unprocessed after %d retries", len(request[tableName].Keys), maxDynamoBatchGetRetries)
+				return nil, fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, fferr.FEATURE_VARIANT, err)
+			}
+			if attempt > 0 {
+				time.Sleep(time.Duration(attempt) * 50 * time.Millisecond)
+			}
+			output, err := table.client.BatchGetItem(context.TODO(), &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, fferr.NewResourceExecutionError(pt.DynamoDBOnline.String(), table.key.Feature, table.key.Variant, fferr.FEATURE_VARIANT, err)
+			}
+			for _, item := range output.Responses[tableName] {
+				if entity, ok := item[table.key.Feature].(*types.AttributeValueMemberS); ok {
+					found[entity.Value] = item
+				}
+			}
+			request = output.UnprocessedKeys
+		}
+	}
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		item := found[entity]
+		if len(item) == 0 {
+			wrapped := fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
+			wrapped.AddDetail("entity", entity)
+			return nil, wrapped
+		}
+		val, ts, err := table.deserializeItem(entity, item)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = GetItem{Entity: entity, Value: val, TS: ts}
 	}
-	return serializers[table.version].Deserialize(table.valueType, value)
+	return items, nil
 }
 
 // waitForDynamoDB waits for DynamoDB to return a valid response with exponential backoff.
@@ -17,11 +17,13 @@ func TestOnlineStoreDynamoDB(t *testing.T) {
 	}
 	store := GetTestingDynamoDB(t)
 	test := OnlineStoreTest{
-		t:            t,
-		store:        store,
-		testNil:      true,
-		testFloatVec: true,
-		testBatch:    true,
+		t:               t,
+		store:           store,
+		testNil:         true,
+		testFloatVec:    true,
+		testBatch:       true,
+		testTimestamped: true,
+		testBatchGet:    true,
 	}
 	test.Run()
 }
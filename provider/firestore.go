@@ -194,6 +194,27 @@ func (table firestoreOnlineTable) Get(entity string) (interface{}, error) {
 		wrapped.AddDetail("entity", entity)
 		return nil, wrapped
 	}
+	return table.entityValue(dataSnap, entity)
+}
+
+// BatchGet reads the table's document once, since it holds the values of every entity.
+func (table firestoreOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	dataSnap, err := table.document.Get(context.TODO())
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.FirestoreOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+	}
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		value, err := table.entityValue(dataSnap, entity)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = GetItem{Entity: entity, Value: value}
+	}
+	return items, nil
+}
+
+func (table firestoreOnlineTable) entityValue(dataSnap *firestore.DocumentSnapshot, entity string) (interface{}, error) {
 	value, err := dataSnap.DataAt(entity)
 	if err != nil {
 		return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, err)
@@ -50,8 +50,9 @@ func TestOnlineStoreFirestore(t *testing.T) {
 	}
 
 	test := OnlineStoreTest{
-		t:     t,
-		store: store,
+		t:            t,
+		store:        store,
+		testBatchGet: true,
 	}
 	test.Run()
 }
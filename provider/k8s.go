@@ -84,7 +84,11 @@ func (q pandasOfflineQueries) trainingSetCreate(def TrainingSetDef, featureSchem
 	} else {
 		labelWindowQuery = fmt.Sprintf("SELECT %s AS entity, %s AS value, %s AS label_ts FROM source_0", labelSchema.Entity, labelSchema.Value, labelSchema.TS)
 	}
-	labelPartitionQuery := fmt.Sprintf("(SELECT * FROM (SELECT entity, value, label_ts FROM (%s) t ) t0)", labelWindowQuery)
+	// Timestamps are stored as text by pandasql, so they're compared by their Julian day
+	labelFilter := pythonTrainingSetLabelFilter(def, func(op string, t time.Time) string {
+		return fmt.Sprintf("julianday(label_ts) %s julianday('%s')", op, t.UTC().Format("2006-01-02 15:04:05.999999"))
+	})
+	labelPartitionQuery := fmt.Sprintf("(SELECT * FROM (SELECT entity, value, label_ts FROM (%s) t%s ) t0)", labelWindowQuery, labelFilter)
 	labelJoinQuery := fmt.Sprintf("%s %s", labelPartitionQuery, joinQueryString)
 
 	timeStamps := strings.Join(featureTimestamps, ", ")
@@ -1388,6 +1392,14 @@ func (k8s *K8sOfflineStore) trainingSet(def TrainingSetDef, isUpdate bool) error
 		sourcePaths = append(sourcePaths, featureFilepath.ToURI())
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
+	if def.EntityFilter != nil {
+		filterSourcePath, err := k8s.getSourcePath(entityFilterSourceName(def.EntityFilter))
+		if err != nil {
+			k8s.logger.Errorw("Could not get entity filter source path", "source", def.EntityFilter.Source, "error", err)
+			return err
+		}
+		sourcePaths = append(sourcePaths, filterSourcePath)
+	}
 	trainingSetQuery := k8s.query.trainingSetCreate(def, featureSchemas, labelSchema)
 	k8s.logger.Debugw("Source List", "SourceFiles", sourcePaths)
 	k8s.logger.Debugw("Training Set Query", "list", trainingSetQuery)
@@ -1408,11 +1420,15 @@ func (k8s *K8sOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 }
 
 func (k8s *K8sOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
-	return nil, fmt.Errorf("not Implemented")
+	return nil, unsupportedTrainTestSplitError(k8s.Type())
 }
 
 func (k8s *K8sOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
-	return nil, nil, fmt.Errorf("not Implemented")
+	return nil, nil, unsupportedTrainTestSplitError(k8s.Type())
+}
+
+func (k8s *K8sOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	return nil, unsupportedTrainTestSplitError(k8s.Type())
 }
 
 func (k8s *K8sOfflineStore) CheckHealth() (bool, error) {
@@ -1,8 +1,14 @@
 package provider
 
 import (
-	pt "github.com/featureform/provider/provider_type"
+	"errors"
+	"fmt"
+	"sort"
 	"testing"
+	"time"
+
+	"github.com/featureform/fferr"
+	pt "github.com/featureform/provider/provider_type"
 )
 
 func TestOfflineStoreMemory(t *testing.T) {
@@ -17,3 +23,198 @@ func TestOfflineStoreMemory(t *testing.T) {
 	}
 	test.Run()
 }
+
+// memorySplitTrainingSet creates a training set with one row per entity, where entity i
+// has feature i, the label i%2 == 0, and a timestamp i hours after the epoch.
+func memorySplitTrainingSet(t *testing.T, rows int) (*memoryOfflineStore, ResourceID) {
+	store := NewMemoryOfflineStore()
+	labelID := ResourceID{Name: "label", Variant: "v", Type: Label}
+	featureID := ResourceID{Name: "feature", Variant: "v", Type: Feature}
+	labels, err := store.CreateResourceTable(labelID, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create label table: %s", err)
+	}
+	features, err := store.CreateResourceTable(featureID, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %s", err)
+	}
+	for i := 0; i < rows; i++ {
+		entity := fmt.Sprintf("entity-%d", i)
+		ts := time.Unix(0, 0).UTC().Add(time.Duration(i) * time.Hour)
+		if err := labels.Write(ResourceRecord{Entity: entity, Value: i%2 == 0, TS: ts}); err != nil {
+			t.Fatalf("Failed to write label: %s", err)
+		}
+		if err := features.Write(ResourceRecord{Entity: entity, Value: i, TS: ts}); err != nil {
+			t.Fatalf("Failed to write feature: %s", err)
+		}
+	}
+	id := ResourceID{Name: "training-set", Variant: "v", Type: TrainingSet}
+	if err := store.CreateTrainingSet(TrainingSetDef{ID: id, Label: labelID, Features: []ResourceID{featureID}}); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	return store, id
+}
+
+type memorySplit struct {
+	train, test, validation []int
+	// labels counts the true labels in each split.
+	labels map[string]int
+}
+
+func getMemorySplit(t *testing.T, store *memoryOfflineStore, def TrainTestSplitDef) memorySplit {
+	drop, err := store.CreateTrainTestSplit(def)
+	if err != nil {
+		t.Fatalf("Failed to create split: %s", err)
+	}
+	defer drop()
+	train, test, err := store.GetTrainTestSplit(def)
+	if err != nil {
+		t.Fatalf("Failed to get split: %s", err)
+	}
+	validation, err := store.GetValidationSplit(def)
+	if err != nil {
+		t.Fatalf("Failed to get validation split: %s", err)
+	}
+	split := memorySplit{labels: make(map[string]int)}
+	read := func(name string, iter TrainingSetIterator) []int {
+		features := make([]int, 0)
+		for iter.Next() {
+			features = append(features, iter.Features()[0].(int))
+			if iter.Label().(bool) {
+				split.labels[name]++
+			}
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("Failed to read %s split: %s", name, err)
+		}
+		// The memory store doesn't order training set rows, so compare them sorted.
+		sort.Ints(features)
+		return features
+	}
+	split.train = read("train", train)
+	split.test = read("test", test)
+	split.validation = read("validation", validation)
+	return split
+}
+
+func TestMemoryTrainTestSplit(t *testing.T) {
+	store, id := memorySplitTrainingSet(t, 20)
+	base := TrainTestSplitDef{TrainingSetName: id.Name, TrainingSetVariant: id.Variant}
+
+	t.Run("Random", func(t *testing.T) {
+		def := base
+		def.TestSize = 0.25
+		def.ValidationSize = 0.25
+		split := getMemorySplit(t, store, def)
+		if len(split.train) != 10 || len(split.test) != 5 || len(split.validation) != 5 {
+			t.Fatalf("Wrong split sizes: train %v test %v validation %v", split.train, split.test, split.validation)
+		}
+		rows := append(append(append([]int{}, split.train...), split.test...), split.validation...)
+		sort.Ints(rows)
+		for i, row := range rows {
+			if row != i {
+				t.Fatalf("Rows were lost or duplicated: train %v test %v validation %v", split.train, split.test, split.validation)
+			}
+		}
+	})
+
+	t.Run("RandomShuffleIsSeeded", func(t *testing.T) {
+		def := base
+		def.TestSize = 0.5
+		def.Shuffle = true
+		def.RandomState = 7
+		first := getMemorySplit(t, store, def)
+		second := getMemorySplit(t, store, def)
+		if fmt.Sprint(first.test) != fmt.Sprint(second.test) {
+			t.Fatalf("Same random state gave different splits: %v %v", first.test, second.test)
+		}
+	})
+
+	t.Run("Stratified", func(t *testing.T) {
+		def := base
+		def.Strategy = StratifiedSplit
+		def.TestSize = 0.2
+		def.ValidationSize = 0.2
+		def.Shuffle = true
+		def.RandomState = 3
+		split := getMemorySplit(t, store, def)
+		if split.labels["test"] != 2 || len(split.test) != 4 {
+			t.Fatalf("Test split doesn't keep the label distribution: %v", split.test)
+		}
+		if split.labels["validation"] != 2 || len(split.validation) != 4 {
+			t.Fatalf("Validation split doesn't keep the label distribution: %v", split.validation)
+		}
+		if split.labels["train"] != 6 || len(split.train) != 12 {
+			t.Fatalf("Train split doesn't keep the label distribution: %v", split.train)
+		}
+	})
+
+	t.Run("EntityHash", func(t *testing.T) {
+		def := base
+		def.Strategy = EntityHashSplit
+		def.TestSize = 0.3
+		def.ValidationSize = 0.3
+		def.RandomState = 1
+		first := getMemorySplit(t, store, def)
+		if len(first.train)+len(first.test)+len(first.validation) != 20 {
+			t.Fatalf("Rows were lost: %v %v %v", first.train, first.test, first.validation)
+		}
+		second := getMemorySplit(t, store, def)
+		if fmt.Sprint(first) != fmt.Sprint(second) {
+			t.Fatalf("Entity hash split isn't deterministic:\n%v\n%v", first, second)
+		}
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		def := base
+		def.Strategy = TimeSplit
+		def.ValidationCutoff = time.Unix(0, 0).UTC().Add(12 * time.Hour)
+		def.TestCutoff = time.Unix(0, 0).UTC().Add(16 * time.Hour)
+		split := getMemorySplit(t, store, def)
+		if len(split.train) != 12 || split.train[0] != 0 || split.train[11] != 11 {
+			t.Fatalf("Wrong train split: %v", split.train)
+		}
+		if fmt.Sprint(split.validation) != "[12 13 14 15]" {
+			t.Fatalf("Wrong validation split: %v", split.validation)
+		}
+		if fmt.Sprint(split.test) != "[16 17 18 19]" {
+			t.Fatalf("Wrong test split: %v", split.test)
+		}
+	})
+}
+
+func TestMemoryTrainTestSplitErrors(t *testing.T) {
+	store, id := memorySplitTrainingSet(t, 4)
+	def := TrainTestSplitDef{TrainingSetName: id.Name, TrainingSetVariant: id.Variant, TestSize: 0.5}
+	if _, err := store.GetValidationSplit(def); err == nil {
+		t.Fatalf("Expected an error getting a split that was never created")
+	}
+	drop, err := store.CreateTrainTestSplit(def)
+	if err != nil {
+		t.Fatalf("Failed to create split: %s", err)
+	}
+	if err := drop(); err != nil {
+		t.Fatalf("Failed to drop split: %s", err)
+	}
+	if _, _, err := store.GetTrainTestSplit(def); err == nil {
+		t.Fatalf("Expected an error getting a dropped split")
+	}
+	missing := def
+	missing.TrainingSetName = "missing"
+	if _, err := store.CreateTrainTestSplit(missing); err == nil {
+		t.Fatalf("Expected an error splitting a missing training set")
+	}
+	invalid := def
+	invalid.TestSize = 2
+	if _, err := store.CreateTrainTestSplit(invalid); err == nil {
+		t.Fatalf("Expected an error for an invalid split")
+	}
+}
+
+func TestUnsupportedTrainTestSplit(t *testing.T) {
+	err := unsupportedTrainTestSplitError(pt.PostgresOffline)
+	var unsupported *fferr.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected an UnsupportedError, got %T", err)
+	}
+}
@@ -150,7 +150,11 @@ func (q mySQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetD
 
 func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
 	columns := make([]string, 0)
-	query := fmt.Sprintf("(SELECT entity, value , ts from %s ) l", sanitize(labelName))
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitize)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("(SELECT entity, value , ts from %s ) l", labelSource)
 	for i, feature := range def.Features {
 		tableName, err := store.getResourceTableName(feature)
 		if err != nil {
@@ -162,10 +166,20 @@ func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDe
 		query = fmt.Sprintf("%s LEFT JOIN (SELECT entity, value AS %s, ts FROM %s "+
 			"WHERE entity=l.entity AND ts <= l.ts ORDER BY ts DESC LIMIT 1) AS %s ON %s.entity=l.entity",
 			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )", query)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
 		}
+		lagColumnName := sanitize(lagFeatureColumnName(tableName, lagFeature))
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i)
+		columns = append(columns, lagColumnName)
+		query = fmt.Sprintf("%s LEFT JOIN (SELECT entity, value AS %s, ts FROM %s "+
+			"WHERE entity=l.entity AND ts <= l.ts - INTERVAL %d MICROSECOND ORDER BY ts DESC LIMIT 1) AS %s ON %s.entity=l.entity",
+			query, lagColumnName, sanitize(tableName), lagFeature.LagDelta.Microseconds(), tableJoinAlias, tableJoinAlias)
 	}
+	query = fmt.Sprintf("%s )", query)
 	columnStr := strings.Join(columns, ", ")
 
 	if isUpdate {
@@ -188,6 +202,11 @@ func (q mySQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDe
 	return nil
 }
 
+// timestampLiteral omits the UTC offset, which older MySQL versions don't accept in literals.
+func (q mySQLQueries) timestampLiteral(t time.Time) string {
+	return fmt.Sprintf("'%s'", t.UTC().Format("2006-01-02 15:04:05.999999"))
+}
+
 func (q mySQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -253,7 +272,7 @@ func (q mySQLQueries) transformationExists() string {
 }
 
 func (q mySQLQueries) getColumns(db *sql.DB, tableName string) ([]TableColumn, error) {
-	rows, err := db.Query("SELECT column_name FROM information_schema.columns WHERE table_name = ?", tableName)
+	rows, err := db.Query("SELECT column_name, data_type FROM information_schema.columns WHERE table_name = ?", tableName)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(pt.MySqlOffline.String(), err)
 		wrapped.AddDetail("table_name", tableName)
@@ -262,13 +281,13 @@ func (q mySQLQueries) getColumns(db *sql.DB, tableName string) ([]TableColumn, e
 	defer rows.Close()
 	columnNames := make([]TableColumn, 0)
 	for rows.Next() {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+		var column, nativeType string
+		if err := rows.Scan(&column, &nativeType); err != nil {
 			wrapped := fferr.NewExecutionError(pt.MySqlOffline.String(), err)
 			wrapped.AddDetail("table_name", tableName)
 			return nil, wrapped
 		}
-		columnNames = append(columnNames, TableColumn{Name: column})
+		columnNames = append(columnNames, TableColumn{Name: column, NativeType: nativeType})
 	}
 	return columnNames, nil
 }
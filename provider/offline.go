@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"reflect"
 	"sort"
 	"strings"
@@ -150,11 +152,40 @@ type LagFeatureDef struct {
 	LagDelta       time.Duration
 }
 
+// EntityFilterDef restricts a training set to the entities found in a column
+// of a primary or transformation table.
+type EntityFilterDef struct {
+	Source       ResourceID
+	EntityColumn string
+}
+
 type TrainingSetDef struct {
 	ID          ResourceID
 	Label       ResourceID
 	Features    []ResourceID
 	LagFeatures []LagFeatureDef
+	// StartTime and EndTime bound the label timestamps included in the training
+	// set to [StartTime, EndTime). A zero value leaves that side unbounded.
+	StartTime    time.Time
+	EndTime      time.Time
+	EntityFilter *EntityFilterDef
+}
+
+// hasLabelFilter returns true if the definition restricts which label rows
+// are included in the training set.
+func (def *TrainingSetDef) hasLabelFilter() bool {
+	return !def.StartTime.IsZero() || !def.EndTime.IsZero() || def.EntityFilter != nil
+}
+
+// inTimeWindow returns true if ts falls within the definition's time bounds.
+func (def *TrainingSetDef) inTimeWindow(ts time.Time) bool {
+	if !def.StartTime.IsZero() && ts.Before(def.StartTime) {
+		return false
+	}
+	if !def.EndTime.IsZero() && !ts.Before(def.EndTime) {
+		return false
+	}
+	return true
 }
 
 func (def *TrainingSetDef) check() error {
@@ -174,6 +205,18 @@ func (def *TrainingSetDef) check() error {
 			return err
 		}
 	}
+	if !def.StartTime.IsZero() && !def.EndTime.IsZero() && !def.EndTime.After(def.StartTime) {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("training set end time %s must be after start time %s", def.EndTime, def.StartTime))
+	}
+	if def.EntityFilter != nil {
+		sourceType := def.EntityFilter.Source.Type
+		if sourceType != Primary && sourceType != Transformation {
+			return fferr.NewInvalidArgumentError(fmt.Errorf("entity filter source must be a primary or transformation, not %s", sourceType))
+		}
+		if def.EntityFilter.EntityColumn == "" {
+			return fferr.NewInvalidArgumentError(errors.New("entity filter must specify an entity column"))
+		}
+	}
 	return nil
 }
 
@@ -269,12 +312,79 @@ func (m *TransformationConfig) decodeArgs(t metadata.TransformationArgType, argM
 	return nil
 }
 
+type TrainTestSplitStrategy int
+
+const (
+	// RandomSplit assigns rows to splits by row order, shuffled if requested.
+	RandomSplit TrainTestSplitStrategy = iota
+	// TimeSplit assigns rows to splits by comparing the label timestamp to cutoffs,
+	// so that no training row is newer than a validation or test row.
+	TimeSplit
+	// StratifiedSplit assigns rows to splits separately for each label value,
+	// so that every split has the same label distribution.
+	StratifiedSplit
+	// EntityHashSplit assigns rows to splits by a hash of the label entity,
+	// so that an entity's rows never land in more than one split.
+	EntityHashSplit
+)
+
+func (s TrainTestSplitStrategy) String() string {
+	switch s {
+	case RandomSplit:
+		return "random"
+	case TimeSplit:
+		return "time"
+	case StratifiedSplit:
+		return "stratified"
+	case EntityHashSplit:
+		return "entity_hash"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
 type TrainTestSplitDef struct {
 	TrainingSetName    string
 	TrainingSetVariant string
 	TestSize           float32
 	Shuffle            bool
 	RandomState        int
+	Strategy           TrainTestSplitStrategy
+	// ValidationSize is the fraction of rows held out for a validation split
+	// in addition to the test split. It's ignored by TimeSplit.
+	ValidationSize float32
+	// TestCutoff and ValidationCutoff are only used by TimeSplit. Rows with a label
+	// timestamp at or after TestCutoff are test rows, and rows at or after
+	// ValidationCutoff but before TestCutoff are validation rows.
+	TestCutoff       time.Time
+	ValidationCutoff time.Time
+}
+
+// unsupportedTrainTestSplitError is returned by offline stores that can't split training sets.
+func unsupportedTrainTestSplitError(providerType pt.Type) error {
+	return fferr.NewUnsupportedError(fmt.Errorf("train test splits are not supported by %s", providerType))
+}
+
+func (def *TrainTestSplitDef) check() error {
+	if def.TestSize < 0 || def.TestSize > 1 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("test size must be between 0 and 1, got %v", def.TestSize))
+	}
+	if def.ValidationSize < 0 || def.TestSize+def.ValidationSize > 1 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("validation size must be between 0 and %v, got %v", 1-def.TestSize, def.ValidationSize))
+	}
+	switch def.Strategy {
+	case RandomSplit, StratifiedSplit, EntityHashSplit:
+	case TimeSplit:
+		if def.TestCutoff.IsZero() {
+			return fferr.NewInvalidArgumentError(errors.New("time split requires a test cutoff"))
+		}
+		if !def.ValidationCutoff.IsZero() && !def.ValidationCutoff.Before(def.TestCutoff) {
+			return fferr.NewInvalidArgumentError(fmt.Errorf("validation cutoff %s must be before test cutoff %s", def.ValidationCutoff, def.TestCutoff))
+		}
+	default:
+		return fferr.NewInvalidArgumentError(fmt.Errorf("unknown train test split strategy %s", def.Strategy))
+	}
+	return nil
 }
 
 type MaterializationOptions interface {
@@ -303,13 +413,37 @@ type OfflineStore interface {
 	GetTrainingSet(id ResourceID) (TrainingSetIterator, error)
 	CreateTrainTestSplit(TrainTestSplitDef) (func() error, error)
 	GetTrainTestSplit(TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error)
+	GetValidationSplit(TrainTestSplitDef) (TrainingSetIterator, error)
 	Close() error
 	ResourceLocation(id ResourceID) (string, error)
 	Provider
 }
 
+// SourceSchemaOfflineStore is implemented by offline stores that can read back the columns of a registered
+// primary or transformation table, including the type the store holds each one as.
+type SourceSchemaOfflineStore interface {
+	GetSourceSchema(id ResourceID) ([]TableColumn, error)
+}
+
+// ResourceTableOfflineStore is implemented by offline stores that can drop the table backing a feature or
+// label resource, a primary or transformation table, or a training set.
+type ResourceTableOfflineStore interface {
+	DeleteResourceTable(id ResourceID) error
+}
+
 type MaterializationID string
 
+// MaterializationIDForResource returns the ID that store assigns to the materialization of a feature variant,
+// which allows it to be looked up or deleted without holding the Materialization returned on creation.
+func MaterializationIDForResource(store OfflineStore, id ResourceID) MaterializationID {
+	switch store.Type() {
+	case pt.SparkOffline, pt.K8sOffline:
+		return MaterializationID(fmt.Sprintf("%s/%s/%s", FeatureMaterialization, id.Name, id.Variant))
+	default:
+		return MaterializationID(fmt.Sprintf("%s__%s", id.Name, id.Variant))
+	}
+}
+
 type TrainingSetIterator interface {
 	Next() bool
 	Features() []interface{}
@@ -333,6 +467,18 @@ type Materialization interface {
 	IterateChunk(idx int) (FeatureIterator, error)
 }
 
+// IncrementalMaterialization is implemented by materializations that can report the latest event
+// time they contain, which lets scheduled runs copy only the rows added since the last sync.
+type IncrementalMaterialization interface {
+	Materialization
+	MaxTimestamp() (time.Time, error)
+	// IterateChunkSince iterates over the rows of a chunk with an event time at or after watermark,
+	// leaving the rest of the chunk in the store. Rows at the watermark are included since more can
+	// arrive with the same timestamp after a sync; copying them again is safe because online Set is
+	// an upsert.
+	IterateChunkSince(idx int, watermark time.Time) (FeatureIterator, error)
+}
+
 type Chunks interface {
 	Size() int
 	ChunkIterator(idx int) (FeatureIterator, error)
@@ -604,12 +750,17 @@ type TableColumnJSONWrapper struct {
 type TableColumn struct {
 	Name string
 	types.ValueType
+	// NativeType is the column's type as reported by the offline store, e.g. BIGINT. It's only set on
+	// columns read back from a registered table.
+	NativeType string
 }
 
 type memoryOfflineStore struct {
 	tables           syncmap.Map
 	materializations syncmap.Map
 	trainingSets     syncmap.Map
+	// trainTestSplits holds each created split's rows by splitGroup, keyed by its TrainTestSplitDef.
+	trainTestSplits syncmap.Map
 	BaseProvider
 }
 
@@ -622,6 +773,7 @@ func NewMemoryOfflineStore() *memoryOfflineStore {
 		tables:           syncmap.Map{},
 		materializations: syncmap.Map{},
 		trainingSets:     syncmap.Map{},
+		trainTestSplits:  syncmap.Map{},
 		BaseProvider: BaseProvider{
 			ProviderType:   pt.MemoryOffline,
 			ProviderConfig: []byte{},
@@ -755,6 +907,18 @@ func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) err
 	return nil
 }
 
+func (store *memoryOfflineStore) DeleteResourceTable(id ResourceID) error {
+	tables := &store.tables
+	if id.Type == TrainingSet {
+		tables = &store.trainingSets
+	}
+	if _, has := tables.Load(id); !has {
+		return fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	tables.Delete(id)
+	return nil
+}
+
 func latestRecord(recs []ResourceRecord) ResourceRecord {
 	latest := recs[0]
 	for _, rec := range recs {
@@ -781,18 +945,26 @@ func (store *memoryOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 		}
 		features[i] = feature
 	}
+	if def.EntityFilter != nil {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("entity filters are not supported by the memory offline store"))
+	}
 	labelRecs := label.records()
-	trainingData := make(trainingRows, len(labelRecs))
-	for i, rec := range labelRecs {
+	trainingData := make(trainingRows, 0, len(labelRecs))
+	for _, rec := range labelRecs {
+		if !def.inTimeWindow(rec.TS) {
+			continue
+		}
 		featureVals := make([]interface{}, len(features))
 		for i, feature := range features {
 			featureVals[i] = feature.getLastValueBefore(rec.Entity, rec.TS)
 		}
 		labelVal := rec.Value
-		trainingData[i] = trainingRow{
+		trainingData = append(trainingData, trainingRow{
 			Features: featureVals,
 			Label:    labelVal,
-		}
+			entity:   rec.Entity,
+			ts:       rec.TS,
+		})
 	}
 	store.trainingSets.Store(def.ID, trainingData)
 	return nil
@@ -814,25 +986,44 @@ func (store *memoryOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetItera
 }
 
 func (store *memoryOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
-	// TODO properly implement this
+	if err := def.check(); err != nil {
+		return nil, err
+	}
+	id := ResourceID{Name: def.TrainingSetName, Variant: def.TrainingSetVariant, Type: TrainingSet}
+	data, has := store.trainingSets.Load(id)
+	if !has {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	store.trainTestSplits.Store(def, data.(trainingRows).split(def))
 	dropFunc := func() error {
+		store.trainTestSplits.Delete(def)
 		return nil
 	}
 	return dropFunc, nil
 }
 
-func (store *memoryOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
-	// TODO properly implement this
-	trainingSetResourceId := ResourceID{
-		Name:    def.TrainingSetName,
-		Variant: def.TrainingSetVariant,
+func (store *memoryOfflineStore) getTrainTestSplit(def TrainTestSplitDef) (map[splitGroup]trainingRows, error) {
+	split, has := store.trainTestSplits.Load(def)
+	if !has {
+		return nil, fferr.NewDatasetNotFoundError(def.TrainingSetName, def.TrainingSetVariant, fmt.Errorf("train test split has not been created"))
 	}
-	trainingSet, err := store.GetTrainingSet(trainingSetResourceId)
+	return split.(map[splitGroup]trainingRows), nil
+}
+
+func (store *memoryOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
+	split, err := store.getTrainTestSplit(def)
 	if err != nil {
 		return nil, nil, err
 	}
-	return trainingSet, trainingSet, nil
+	return split[trainSplitGroup].Iterator(), split[testSplitGroup].Iterator(), nil
+}
 
+func (store *memoryOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	split, err := store.getTrainTestSplit(def)
+	if err != nil {
+		return nil, err
+	}
+	return split[validationSplitGroup].Iterator(), nil
 }
 
 func (store *memoryOfflineStore) Close() error {
@@ -852,6 +1043,101 @@ func (rows trainingRows) Iterator() TrainingSetIterator {
 type trainingRow struct {
 	Features []interface{}
 	Label    interface{}
+	// entity and ts are the label row's, and are only used to split the training set.
+	entity string
+	ts     time.Time
+}
+
+// split assigns each row to a splitGroup the same way the ClickHouse store's split expressions do.
+func (rows trainingRows) split(def TrainTestSplitDef) map[splitGroup]trainingRows {
+	seed := int64(def.RandomState)
+	if def.Shuffle && seed == 0 {
+		seed = rand.Int63()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	groups := make(map[splitGroup]trainingRows)
+	switch def.Strategy {
+	case RandomSplit:
+		ordered := rows.ordered(def.Shuffle, rng)
+		testRows := int(float32(len(ordered)) * def.TestSize)
+		validationRows := int(float32(len(ordered)) * def.ValidationSize)
+		for i, row := range ordered {
+			group := splitGroupByPosition(i, testRows, testRows+validationRows)
+			groups[group] = append(groups[group], row)
+		}
+	case StratifiedSplit:
+		// Each label value is split on its own so the splits keep the label distribution.
+		labels := make([]string, 0)
+		byLabel := make(map[string]trainingRows)
+		for _, row := range rows {
+			label := fmt.Sprint(row.Label)
+			if _, has := byLabel[label]; !has {
+				labels = append(labels, label)
+			}
+			byLabel[label] = append(byLabel[label], row)
+		}
+		for _, label := range labels {
+			ordered := byLabel[label].ordered(def.Shuffle, rng)
+			testRows := int(float32(len(ordered)) * def.TestSize)
+			testAndValidationRows := int(float32(len(ordered)) * (def.TestSize + def.ValidationSize))
+			for i, row := range ordered {
+				group := splitGroupByPosition(i, testRows, testAndValidationRows)
+				groups[group] = append(groups[group], row)
+			}
+		}
+	case EntityHashSplit:
+		// Entities are hashed into 10000 buckets so the same entity always lands in the same split.
+		testBuckets := uint64(def.TestSize * 10000)
+		validationBuckets := uint64((def.TestSize + def.ValidationSize) * 10000)
+		for _, row := range rows {
+			hash := fnv.New64a()
+			hash.Write([]byte(fmt.Sprintf("%s%d", row.entity, def.RandomState)))
+			bucket := hash.Sum64() % 10000
+			group := trainSplitGroup
+			if bucket < testBuckets {
+				group = testSplitGroup
+			} else if bucket < validationBuckets {
+				group = validationSplitGroup
+			}
+			groups[group] = append(groups[group], row)
+		}
+	case TimeSplit:
+		for _, row := range rows {
+			group := trainSplitGroup
+			if !row.ts.Before(def.TestCutoff) {
+				group = testSplitGroup
+			} else if !def.ValidationCutoff.IsZero() && !row.ts.Before(def.ValidationCutoff) {
+				group = validationSplitGroup
+			}
+			groups[group] = append(groups[group], row)
+		}
+	}
+	return groups
+}
+
+// ordered returns a copy of the rows, shuffled if shuffle is set.
+func (rows trainingRows) ordered(shuffle bool, rng *rand.Rand) trainingRows {
+	ordered := make(trainingRows, len(rows))
+	copy(ordered, rows)
+	if shuffle {
+		rng.Shuffle(len(ordered), func(i, j int) {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		})
+	}
+	return ordered
+}
+
+// splitGroupByPosition puts the first testRows rows in the test split and the rows after them,
+// up to testAndValidationRows, in the validation split.
+func splitGroupByPosition(i, testRows, testAndValidationRows int) splitGroup {
+	switch {
+	case i < testRows:
+		return testSplitGroup
+	case i < testAndValidationRows:
+		return validationSplitGroup
+	default:
+		return trainSplitGroup
+	}
 }
 
 type memoryTrainingRowsIterator struct {
@@ -1003,6 +1289,33 @@ func (mat *MemoryMaterialization) IterateChunk(idx int) (FeatureIterator, error)
 	return genericIterateChunk(mat, mat.RowsPerChunk, idx)
 }
 
+func (mat *MemoryMaterialization) MaxTimestamp() (time.Time, error) {
+	var max time.Time
+	for _, rec := range mat.Data {
+		if rec.TS.After(max) {
+			max = rec.TS
+		}
+	}
+	return max, nil
+}
+
+func (mat *MemoryMaterialization) IterateChunkSince(idx int, watermark time.Time) (FeatureIterator, error) {
+	if mat.RowsPerChunk == 0 {
+		mat.RowsPerChunk = defaultRowsPerChunk
+	}
+	start, end, err := genericChunkBounds(mat, mat.RowsPerChunk, idx)
+	if err != nil {
+		return nil, err
+	}
+	newer := make([]ResourceRecord, 0)
+	for _, rec := range mat.Data[start:end] {
+		if !rec.TS.Before(watermark) {
+			newer = append(newer, rec)
+		}
+	}
+	return newMemoryFeatureIterator(newer), nil
+}
+
 type memoryFeatureIterator struct {
 	data []ResourceRecord
 	idx  int64
@@ -1086,12 +1399,21 @@ func getNumRowsAndChunks(mat Materialization, rowsPerChunk int64) (int64, int, e
 }
 
 func genericIterateChunk(mat Materialization, rowsPerChunk int64, idx int) (FeatureIterator, error) {
-	rows, chunks, err := getNumRowsAndChunks(mat, rowsPerChunk)
+	start, end, err := genericChunkBounds(mat, rowsPerChunk, idx)
 	if err != nil {
 		return nil, err
 	}
+	return mat.IterateSegment(start, end)
+}
+
+// genericChunkBounds returns the segment of rows that makes up chunk idx.
+func genericChunkBounds(mat Materialization, rowsPerChunk int64, idx int) (int64, int64, error) {
+	rows, chunks, err := getNumRowsAndChunks(mat, rowsPerChunk)
+	if err != nil {
+		return 0, 0, err
+	}
 	if idx > chunks {
-		return nil, fferr.NewInternalErrorf("Chunk out of range\nIdx: %d\nTotal: %d", idx, chunks)
+		return 0, 0, fferr.NewInternalErrorf("Chunk out of range\nIdx: %d\nTotal: %d", idx, chunks)
 	}
 	start := int64(idx) * rowsPerChunk
 	end := (int64(idx) + 1) * rowsPerChunk
@@ -1101,5 +1423,5 @@ func genericIterateChunk(mat Materialization, rowsPerChunk int64, idx int) (Feat
 	if end > rows {
 		end = rows
 	}
-	return mat.IterateSegment(start, end)
+	return start, end, nil
 }
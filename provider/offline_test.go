@@ -43,6 +43,7 @@ func (test *OfflineStoreTest) Run() {
 		"InvalidResourceIDs":      testInvalidResourceIDs,
 		"Materializations":        testMaterializations,
 		"MaterializationUpdate":   testMaterializationUpdate,
+		"IncrementalChunks":       testIncrementalMaterializationChunks,
 		"InvalidResourceRecord":   testWriteInvalidResourceRecord,
 		"InvalidMaterialization":  testInvalidMaterialization,
 		"MaterializeUnknown":      testMaterializeUnknown,
@@ -92,6 +93,9 @@ func (test *OfflineStoreTest) RunSQL() {
 		"CreatePrimaryFromSource":            testCreatePrimaryFromSource,
 		"CreatePrimaryFromNonExistentSource": testCreatePrimaryFromNonExistentSource,
 		"TrainTestSplit":                     testTrainTestSplit,
+		"TrainingSetNamedLag":                testNamedLagFeaturesTrainingSet,
+		"TrainingSetFilters":                 testTrainingSetFilters,
+		"SourceSchema":                       testSourceSchema,
 	}
 
 	for name, fn := range testFns {
@@ -462,6 +466,54 @@ func testMaterializations(t *testing.T, store OfflineStore) {
 
 }
 
+func testIncrementalMaterializationChunks(t *testing.T, store OfflineStore) {
+	id := randomID(Feature)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	watermark := time.UnixMilli(2000).UTC()
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: watermark.Add(-time.Second)},
+		{Entity: "b", Value: 2, TS: watermark},
+		{Entity: "c", Value: 3, TS: watermark.Add(time.Second)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record %v: %s", rec, err)
+		}
+	}
+	mat, err := store.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	incremental, ok := mat.(IncrementalMaterialization)
+	if !ok {
+		t.Skipf("%s materializations are not incremental", store.Type())
+	}
+	iter, err := incremental.IterateChunkSince(0, watermark)
+	if err != nil {
+		t.Fatalf("Failed to iterate chunk: %s", err)
+	}
+	newer := make([]string, 0)
+	for iter.Next() {
+		newer = append(newer, iter.Value().Entity)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Iteration failed: %s", err)
+	}
+	if !reflect.DeepEqual(newer, []string{"b", "c"}) {
+		t.Fatalf("Expected only rows at or after the watermark, got %v", newer)
+	}
+}
+
 func testMaterializationUpdate(t *testing.T, store OfflineStore) {
 	type TestCase struct {
 		WriteRecords                           []ResourceRecord
@@ -1630,6 +1682,30 @@ func testInvalidTrainingSetDefs(t *testing.T, store OfflineStore) {
 			Label:    randomID(Label),
 			Features: []ResourceID{},
 		},
+		"EndBeforeStart": TrainingSetDef{
+			ID:        randomID(TrainingSet),
+			Label:     randomID(Label),
+			Features:  []ResourceID{randomID(Feature)},
+			StartTime: time.UnixMilli(2),
+			EndTime:   time.UnixMilli(1),
+		},
+		"WrongEntityFilterType": TrainingSetDef{
+			ID:       randomID(TrainingSet),
+			Label:    randomID(Label),
+			Features: []ResourceID{randomID(Feature)},
+			EntityFilter: &EntityFilterDef{
+				Source:       randomID(Feature),
+				EntityColumn: "entity",
+			},
+		},
+		"NoEntityFilterColumn": TrainingSetDef{
+			ID:       randomID(TrainingSet),
+			Label:    randomID(Label),
+			Features: []ResourceID{randomID(Feature)},
+			EntityFilter: &EntityFilterDef{
+				Source: randomID(Primary),
+			},
+		},
 	}
 	for name, def := range invalidDefs {
 		nameConst := name
@@ -3242,6 +3318,49 @@ func testCreatePrimaryFromSource(t *testing.T, store OfflineStore) {
 	}
 }
 
+func testSourceSchema(t *testing.T, store OfflineStore) {
+	schemaStore, ok := store.(SourceSchemaOfflineStore)
+	if !ok {
+		t.Skipf("%s does not implement SourceSchemaOfflineStore", store.Type())
+	}
+	primaryID := ResourceID{
+		Name:    createUUID(),
+		Variant: createUUID(),
+		Type:    Primary,
+	}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	if _, err := store.CreatePrimaryTable(primaryID, schema); err != nil {
+		t.Fatalf("Could not create primary table: %v", err)
+	}
+	columns, err := schemaStore.GetSourceSchema(primaryID)
+	if err != nil {
+		t.Fatalf("Could not get source schema: %v", err)
+	}
+	if len(columns) != len(schema.Columns) {
+		t.Fatalf("Expected %d columns, got %v", len(schema.Columns), columns)
+	}
+	for i, column := range columns {
+		if column.Name != schema.Columns[i].Name {
+			t.Fatalf("Expected column %d to be %s, got %s", i, schema.Columns[i].Name, column.Name)
+		}
+		if column.NativeType == "" {
+			t.Fatalf("Expected column %s to have a native type", column.Name)
+		}
+	}
+	if columns[0].NativeType == columns[1].NativeType {
+		t.Fatalf("Expected string and int columns to have different native types, got %s", columns[0].NativeType)
+	}
+	if _, err := schemaStore.GetSourceSchema(ResourceID{Name: createUUID(), Variant: createUUID(), Type: Primary}); err == nil {
+		t.Fatalf("Expected getting the schema of a missing table to fail")
+	}
+}
+
 func Test_snowflakeOfflineTable_checkTimestamp(t *testing.T) {
 	type fields struct {
 		db   *sql.DB
@@ -3268,6 +3387,35 @@ func Test_snowflakeOfflineTable_checkTimestamp(t *testing.T) {
 	}
 }
 
+func TestTrainTestSplitDefCheck(t *testing.T) {
+	tests := map[string]struct {
+		def     TrainTestSplitDef
+		isValid bool
+	}{
+		"Random":               {TrainTestSplitDef{TestSize: 0.2, ValidationSize: 0.2}, true},
+		"Stratified":           {TrainTestSplitDef{TestSize: 0.2, Strategy: StratifiedSplit}, true},
+		"EntityHash":           {TrainTestSplitDef{TestSize: 0.2, Strategy: EntityHashSplit}, true},
+		"Time":                 {TrainTestSplitDef{Strategy: TimeSplit, TestCutoff: time.UnixMilli(2), ValidationCutoff: time.UnixMilli(1)}, true},
+		"TestSizeTooLarge":     {TrainTestSplitDef{TestSize: 1.5}, false},
+		"SplitsTooLarge":       {TrainTestSplitDef{TestSize: 0.6, ValidationSize: 0.6}, false},
+		"NegativeValidation":   {TrainTestSplitDef{TestSize: 0.5, ValidationSize: -0.1}, false},
+		"TimeWithoutCutoff":    {TrainTestSplitDef{Strategy: TimeSplit}, false},
+		"ValidationAfterTest":  {TrainTestSplitDef{Strategy: TimeSplit, TestCutoff: time.UnixMilli(1), ValidationCutoff: time.UnixMilli(2)}, false},
+		"UnknownSplitStrategy": {TrainTestSplitDef{Strategy: TrainTestSplitStrategy(100)}, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.def.check()
+			if test.isValid && err != nil {
+				t.Fatalf("Expected valid def, got: %v", err)
+			}
+			if !test.isValid && err == nil {
+				t.Fatalf("Expected invalid def to fail")
+			}
+		})
+	}
+}
+
 func TestReplaceSourceName(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -3402,7 +3550,7 @@ func modifyTransformationConfig(t *testing.T, testName, tableName string, provid
 		// In contrast to the SQL provider, that only needed change is the table name to perform the required transformation configuration,
 		// The Spark implementation needs to update the source mappings to ensure the source file is used in the transformation query.
 		config.SourceMapping[0].Source = tableName
-	case pt.MemoryOffline, pt.BigQueryOffline, pt.PostgresOffline, pt.MySqlOffline, pt.SnowflakeOffline, pt.ClickHouseOffline, pt.RedshiftOffline:
+	case pt.MemoryOffline, pt.BigQueryOffline, pt.PostgresOffline, pt.DuckDBOffline, pt.MySqlOffline, pt.SnowflakeOffline, pt.ClickHouseOffline, pt.RedshiftOffline:
 		tableName := getTableName(string(providerType), tableName)
 		config.Query = strings.Replace(config.Query, "tb", tableName, 1)
 	default:
@@ -3471,6 +3619,168 @@ func TestBigQueryConfig_Deserialize(t *testing.T) {
 	}
 }
 
+func testTrainingSetFilters(t *testing.T, store OfflineStore) {
+	type expectedTrainingRow struct {
+		Features []interface{}
+		Label    interface{}
+	}
+	type TestCase struct {
+		StartTime    time.Time
+		EndTime      time.Time
+		FilterByID   bool
+		ExpectedRows []expectedTrainingRow
+	}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	featureRecords := []ResourceRecord{
+		{Entity: "a", Value: 10, TS: time.UnixMilli(0)},
+		{Entity: "b", Value: 20, TS: time.UnixMilli(0)},
+		{Entity: "c", Value: 30, TS: time.UnixMilli(0)},
+	}
+	labelRecords := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: time.UnixMilli(1)},
+		{Entity: "b", Value: 2, TS: time.UnixMilli(2)},
+		{Entity: "c", Value: 3, TS: time.UnixMilli(3)},
+		{Entity: "a", Value: 4, TS: time.UnixMilli(4)},
+	}
+	// Entities used by the entity filter, stored in a column that isn't named entity.
+	filterSchema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "id", ValueType: types.String},
+		},
+	}
+	filterRecords := []GenericRecord{
+		{"a"},
+		{"c"},
+	}
+
+	tests := map[string]TestCase{
+		"StartTime": {
+			StartTime: time.UnixMilli(2),
+			ExpectedRows: []expectedTrainingRow{
+				{Features: []interface{}{20}, Label: 2},
+				{Features: []interface{}{30}, Label: 3},
+				{Features: []interface{}{10}, Label: 4},
+			},
+		},
+		"EndTime": {
+			EndTime: time.UnixMilli(3),
+			ExpectedRows: []expectedTrainingRow{
+				{Features: []interface{}{10}, Label: 1},
+				{Features: []interface{}{20}, Label: 2},
+			},
+		},
+		"TimeWindow": {
+			StartTime: time.UnixMilli(2),
+			EndTime:   time.UnixMilli(4),
+			ExpectedRows: []expectedTrainingRow{
+				{Features: []interface{}{20}, Label: 2},
+				{Features: []interface{}{30}, Label: 3},
+			},
+		},
+		"EntityFilter": {
+			FilterByID: true,
+			ExpectedRows: []expectedTrainingRow{
+				{Features: []interface{}{10}, Label: 1},
+				{Features: []interface{}{30}, Label: 3},
+				{Features: []interface{}{10}, Label: 4},
+			},
+		},
+		"TimeWindowAndEntityFilter": {
+			StartTime:  time.UnixMilli(2),
+			FilterByID: true,
+			ExpectedRows: []expectedTrainingRow{
+				{Features: []interface{}{30}, Label: 3},
+				{Features: []interface{}{10}, Label: 4},
+			},
+		},
+	}
+	runTestCase := func(t *testing.T, test TestCase) {
+		featureID := randomID(Feature)
+		featureTable, err := store.CreateResourceTable(featureID, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := featureTable.WriteBatch(featureRecords); err != nil {
+			t.Fatalf("Failed to write batch: %v", err)
+		}
+		labelID := randomID(Label)
+		labelTable, err := store.CreateResourceTable(labelID, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := labelTable.WriteBatch(labelRecords); err != nil {
+			t.Fatalf("Failed to write batch: %v", err)
+		}
+		def := TrainingSetDef{
+			ID:        randomID(TrainingSet),
+			Label:     labelID,
+			Features:  []ResourceID{featureID},
+			StartTime: test.StartTime,
+			EndTime:   test.EndTime,
+		}
+		if test.FilterByID {
+			filterID := randomID(Primary)
+			filterTable, err := store.CreatePrimaryTable(filterID, filterSchema)
+			if err != nil {
+				t.Fatalf("Failed to create primary table: %s", err)
+			}
+			if err := filterTable.WriteBatch(filterRecords); err != nil {
+				t.Fatalf("Failed to write batch: %v", err)
+			}
+			def.EntityFilter = &EntityFilterDef{Source: filterID, EntityColumn: "id"}
+		}
+		if err := store.CreateTrainingSet(def); err != nil {
+			t.Fatalf("Failed to create training set: %s", err)
+		}
+		iter, err := store.GetTrainingSet(def.ID)
+		if err != nil {
+			t.Fatalf("Failed to get training set: %s", err)
+		}
+		expectedRows := test.ExpectedRows
+		i := 0
+		for iter.Next() {
+			realRow := expectedTrainingRow{
+				Features: iter.Features(),
+				Label:    iter.Label(),
+			}
+			found := false
+			for j, expRow := range expectedRows {
+				if reflect.DeepEqual(realRow, expRow) {
+					found = true
+					expectedRows = append(expectedRows[:j:j], expectedRows[j+1:]...)
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("Unexpected training row: %v, expected %v", realRow, expectedRows)
+			}
+			i++
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("Failed to iterate training set: %s", err)
+		}
+		if len(test.ExpectedRows) != i {
+			t.Fatalf("Training set has different number of rows %d %d", len(test.ExpectedRows), i)
+		}
+	}
+	for name, test := range tests {
+		nameConst := name
+		testConst := test
+		t.Run(nameConst, func(t *testing.T) {
+			if store.Type() != pt.MemoryOffline {
+				t.Parallel()
+			}
+			runTestCase(t, testConst)
+		})
+	}
+}
+
 func testLagFeaturesTrainingSet(t *testing.T, store OfflineStore) {
 	type expectedTrainingRow struct {
 		Features []interface{}
@@ -3714,6 +4024,82 @@ func testLagFeaturesTrainingSet(t *testing.T, store OfflineStore) {
 	}
 }
 
+// testNamedLagFeaturesTrainingSet checks that a named lag looks back from each label's own
+// timestamp and entity, and is null when the entity has no earlier feature value.
+func testNamedLagFeaturesTrainingSet(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	start := time.UnixMilli(0)
+	featureID := randomID(Feature)
+	featureTable, err := store.CreateResourceTable(featureID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	featureRecords := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: start},
+		{Entity: "a", Value: 2, TS: start.Add(time.Hour)},
+		{Entity: "b", Value: 10, TS: start},
+		{Entity: "b", Value: 20, TS: start.Add(2 * time.Hour)},
+	}
+	if err := featureTable.WriteBatch(featureRecords); err != nil {
+		t.Fatalf("Failed to write batch: %v", err)
+	}
+	labelID := randomID(Label)
+	labelTable, err := store.CreateResourceTable(labelID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	labelRecords := []ResourceRecord{
+		{Entity: "a", Value: 100, TS: start.Add(time.Hour)},
+		{Entity: "b", Value: 200, TS: start.Add(2 * time.Hour)},
+		{Entity: "c", Value: 300, TS: start.Add(time.Hour)},
+	}
+	if err := labelTable.WriteBatch(labelRecords); err != nil {
+		t.Fatalf("Failed to write batch: %v", err)
+	}
+	def := TrainingSetDef{
+		ID:       randomID(TrainingSet),
+		Label:    labelID,
+		Features: []ResourceID{featureID},
+		LagFeatures: []LagFeatureDef{
+			{FeatureName: featureID.Name, FeatureVariant: featureID.Variant, LagName: "previous_hour", LagDelta: time.Hour},
+		},
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	iter, err := store.GetTrainingSet(def.ID)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	expected := map[interface{}][]interface{}{
+		100: {2, 1},
+		200: {20, 10},
+		300: {nil, nil},
+	}
+	for iter.Next() {
+		features, has := expected[iter.Label()]
+		if !has {
+			t.Fatalf("Unexpected label %v", iter.Label())
+		}
+		if !reflect.DeepEqual(features, iter.Features()) {
+			t.Fatalf("Expected features %v for label %v, got %v", features, iter.Label(), iter.Features())
+		}
+		delete(expected, iter.Label())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Failed to iterate training set: %s", err)
+	}
+	if len(expected) != 0 {
+		t.Fatalf("Missing training rows for labels %v", expected)
+	}
+}
+
 func TestTableSchemaValue(t *testing.T) {
 	tableSchema := TableSchema{
 		Columns: []TableColumn{
@@ -4436,12 +4822,16 @@ func testTrainTestSplit(t *testing.T, store OfflineStore) {
 	}
 
 	type TestParameters struct {
-		TestSize          float32
-		Shuffle           bool
-		RandomState       int
-		RandomState2      int
-		ExpectedTestRows  int
-		IterShouldBeEqual bool
+		TestSize               float32
+		Shuffle                bool
+		RandomState            int
+		RandomState2           int
+		ExpectedTestRows       int
+		IterShouldBeEqual      bool
+		Strategy               TrainTestSplitStrategy
+		ValidationSize         float32
+		ExpectedValidationRows int
+		ExpectedTestLabels     map[interface{}]int
 	}
 
 	type TestCase struct {
@@ -4592,6 +4982,124 @@ func testTrainTestSplit(t *testing.T, store OfflineStore) {
 		}
 	}
 
+	countRows := func(t *testing.T, iter TrainingSetIterator) (int, map[interface{}]int) {
+		rows := 0
+		labels := make(map[interface{}]int)
+		for iter.Next() {
+			rows++
+			labels[iter.Label()]++
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("Failed to iterate split: %s", err)
+		}
+		return rows, labels
+	}
+
+	testStrategySplit := func(t *testing.T, store OfflineStore, params TestParameters) {
+		id := setupTable(t, store)
+		def := TrainTestSplitDef{
+			TrainingSetName:    id.Name,
+			TrainingSetVariant: id.Variant,
+			TestSize:           params.TestSize,
+			Shuffle:            params.Shuffle,
+			RandomState:        params.RandomState,
+			Strategy:           params.Strategy,
+			ValidationSize:     params.ValidationSize,
+		}
+		cleanupFunc, err := store.CreateTrainTestSplit(def)
+		if err != nil {
+			t.Fatalf("failed to create train test split: %v", err)
+		}
+		defer cleanupFunc()
+		trainIter, testIter, err := store.GetTrainTestSplit(def)
+		if err != nil {
+			t.Fatalf("failed to fetch train test split iterators: %v", err)
+		}
+		validationIter, err := store.GetValidationSplit(def)
+		if err != nil {
+			t.Fatalf("failed to fetch validation split iterator: %v", err)
+		}
+		trainRows, _ := countRows(t, trainIter)
+		testRows, testLabels := countRows(t, testIter)
+		validationRows, _ := countRows(t, validationIter)
+		if trainRows+testRows+validationRows != 10 {
+			t.Fatalf("Expected 10 rows across splits, got: %d train %d test %d validation", trainRows, testRows, validationRows)
+		}
+		if params.ExpectedTestRows >= 0 && params.ExpectedTestRows != testRows {
+			t.Fatalf("Expected %d test rows, got: %d", params.ExpectedTestRows, testRows)
+		}
+		if params.ExpectedValidationRows >= 0 && params.ExpectedValidationRows != validationRows {
+			t.Fatalf("Expected %d validation rows, got: %d", params.ExpectedValidationRows, validationRows)
+		}
+		if params.ExpectedTestLabels != nil && !reflect.DeepEqual(params.ExpectedTestLabels, testLabels) {
+			t.Fatalf("Expected test labels %v, got: %v", params.ExpectedTestLabels, testLabels)
+		}
+	}
+
+	testTimeSplit := func(t *testing.T, store OfflineStore, params TestParameters) {
+		schema := TableSchema{
+			Columns: []TableColumn{
+				{Name: "entity", ValueType: types.String},
+				{Name: "value", ValueType: types.Int},
+				{Name: "ts", ValueType: types.Timestamp},
+			},
+		}
+		featureID := randomID(Feature)
+		labelID := randomID(Label)
+		featureRecs := make([]ResourceRecord, 0)
+		labelRecs := make([]ResourceRecord, 0)
+		for i := 1; i <= 10; i++ {
+			featureRecs = append(featureRecs, ResourceRecord{Entity: "a", Value: i, TS: time.UnixMilli(int64(i))})
+			labelRecs = append(labelRecs, ResourceRecord{Entity: "a", Value: i * 10, TS: time.UnixMilli(int64(i))})
+		}
+		for id, recs := range map[ResourceID][]ResourceRecord{featureID: featureRecs, labelID: labelRecs} {
+			table, err := store.CreateResourceTable(id, schema)
+			if err != nil {
+				t.Fatalf("Failed to create table: %s", err)
+			}
+			if err := table.WriteBatch(recs); err != nil {
+				t.Fatalf("Failed to write batch: %v", err)
+			}
+		}
+		tsID := randomID(TrainingSet)
+		if err := store.CreateTrainingSet(TrainingSetDef{ID: tsID, Label: labelID, Features: []ResourceID{featureID}}); err != nil {
+			t.Fatalf("Failed to create training set: %s", err)
+		}
+		def := TrainTestSplitDef{
+			TrainingSetName:    tsID.Name,
+			TrainingSetVariant: tsID.Variant,
+			Strategy:           TimeSplit,
+			TestCutoff:         time.UnixMilli(9),
+			ValidationCutoff:   time.UnixMilli(7),
+		}
+		cleanupFunc, err := store.CreateTrainTestSplit(def)
+		if err != nil {
+			t.Fatalf("failed to create train test split: %v", err)
+		}
+		defer cleanupFunc()
+		trainIter, testIter, err := store.GetTrainTestSplit(def)
+		if err != nil {
+			t.Fatalf("failed to fetch train test split iterators: %v", err)
+		}
+		validationIter, err := store.GetValidationSplit(def)
+		if err != nil {
+			t.Fatalf("failed to fetch validation split iterator: %v", err)
+		}
+		expected := map[string]struct {
+			iter   TrainingSetIterator
+			labels map[interface{}]int
+		}{
+			"train":      {trainIter, map[interface{}]int{10: 1, 20: 1, 30: 1, 40: 1, 50: 1, 60: 1}},
+			"validation": {validationIter, map[interface{}]int{70: 1, 80: 1}},
+			"test":       {testIter, map[interface{}]int{90: 1, 100: 1}},
+		}
+		for name, exp := range expected {
+			if _, labels := countRows(t, exp.iter); !reflect.DeepEqual(exp.labels, labels) {
+				t.Fatalf("Expected %s labels %v, got: %v", name, exp.labels, labels)
+			}
+		}
+	}
+
 	tests := map[string]TestCase{
 		"Even Rows": {
 			TestParameters: TestParameters{
@@ -4664,6 +5172,44 @@ func testTrainTestSplit(t *testing.T, store OfflineStore) {
 			},
 			TestFunction: testShuffle,
 		},
+		"Validation Split": {
+			TestParameters: TestParameters{
+				TestSize:               0.3,
+				ValidationSize:         0.2,
+				Shuffle:                true,
+				RandomState:            1,
+				ExpectedTestRows:       3,
+				ExpectedValidationRows: 2,
+			},
+			TestFunction: testStrategySplit,
+		},
+		"Stratified": {
+			TestParameters: TestParameters{
+				TestSize:               0.5,
+				Shuffle:                true,
+				RandomState:            1,
+				Strategy:               StratifiedSplit,
+				ExpectedTestRows:       4,
+				ExpectedValidationRows: 0,
+				// 7 true and 3 false labels, each split in half and rounded down
+				ExpectedTestLabels: map[interface{}]int{true: 3, false: 1},
+			},
+			TestFunction: testStrategySplit,
+		},
+		"Entity Hash": {
+			TestParameters: TestParameters{
+				TestSize:    0.5,
+				RandomState: 1,
+				Strategy:    EntityHashSplit,
+				// The number of rows per split depends on the hash of each entity
+				ExpectedTestRows:       -1,
+				ExpectedValidationRows: 0,
+			},
+			TestFunction: testStrategySplit,
+		},
+		"Time Split": {
+			TestFunction: testTimeSplit,
+		},
 	}
 
 	for name, test := range tests {
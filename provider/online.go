@@ -6,6 +6,7 @@ package provider
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/featureform/fferr"
 	fs "github.com/featureform/filestore"
@@ -66,6 +67,14 @@ type OnlineStoreTable interface {
 	Get(entity string) (interface{}, error)
 }
 
+// TimestampedOnlineStoreTable is implemented by online tables that can store the event time of a value
+// alongside it. Values written with Set have no event time, which GetWithTimestamp reports as the zero time.
+type TimestampedOnlineStoreTable interface {
+	OnlineStoreTable
+	SetWithTimestamp(entity string, value interface{}, ts time.Time) error
+	GetWithTimestamp(entity string) (interface{}, time.Time, error)
+}
+
 type VectorStore interface {
 	CreateIndex(feature, variant string, vectorType types.VectorType) (VectorStoreTable, error)
 	DeleteIndex(feature, variant string) error
@@ -83,9 +92,28 @@ type BatchOnlineTable interface {
 	MaxBatchSize() (int, error)
 }
 
+// BatchGetOnlineTable is implemented by online tables that can read many entities in a few round trips.
+// Items are returned in the same order as the requested entities. Like Get, it fails with an
+// EntityNotFoundError if any entity is missing.
+type BatchGetOnlineTable interface {
+	OnlineStoreTable
+	BatchGet(entities []string) ([]GetItem, error)
+}
+
+type GetItem struct {
+	Entity string
+	Value  interface{}
+	// TS is the event time of the value. It's zero if the table doesn't track event times
+	// or the value was written without one.
+	TS time.Time
+}
+
 type SetItem struct {
 	Entity string
 	Value  interface{}
+	// TS is the event time of the value. Tables that implement TimestampedOnlineStoreTable
+	// store it if it's set.
+	TS time.Time
 }
 
 type tableKey struct {
@@ -149,17 +177,44 @@ func (store *localOnlineStore) CheckHealth() (bool, error) {
 	return false, fmt.Errorf("provider health check not implemented")
 }
 
-type localOnlineTable map[string]interface{}
+type localOnlineValue struct {
+	value interface{}
+	ts    time.Time
+}
+
+type localOnlineTable map[string]localOnlineValue
 
 func (table localOnlineTable) Set(entity string, value interface{}) error {
-	table[entity] = value
+	table[entity] = localOnlineValue{value: value}
 	return nil
 }
 
 func (table localOnlineTable) Get(entity string) (interface{}, error) {
+	val, _, err := table.GetWithTimestamp(entity)
+	return val, err
+}
+
+func (table localOnlineTable) SetWithTimestamp(entity string, value interface{}, ts time.Time) error {
+	table[entity] = localOnlineValue{value: value, ts: ts}
+	return nil
+}
+
+func (table localOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
 	val, has := table[entity]
 	if !has {
-		return nil, fferr.NewEntityNotFoundError("", "", entity, nil)
+		return nil, time.Time{}, fferr.NewEntityNotFoundError("", "", entity, nil)
+	}
+	return val.value, val.ts, nil
+}
+
+func (table localOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		val, ts, err := table.GetWithTimestamp(entity)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = GetItem{Entity: entity, Value: val, TS: ts}
 	}
-	return val, nil
+	return items, nil
 }
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
@@ -26,9 +27,11 @@ type OnlineStoreTest struct {
 	t     *testing.T
 	store OnlineStore
 	// TODO(simba) remove once we implement for all providers
-	testNil      bool
-	testFloatVec bool
-	testBatch    bool
+	testNil         bool
+	testFloatVec    bool
+	testBatch       bool
+	testTimestamped bool
+	testBatchGet    bool
 }
 
 func (test *OnlineStoreTest) Run() {
@@ -56,6 +59,14 @@ func (test *OnlineStoreTest) Run() {
 		testFns["BatchSetGetEntity"] = testBatchSetGetEntity
 	}
 
+	if test.testTimestamped {
+		testFns["SetGetTimestamp"] = testSetGetTimestamp
+	}
+
+	if test.testBatchGet {
+		testFns["BatchGetEntity"] = testBatchGetEntity
+	}
+
 	store := test.store
 	for name, fn := range testFns {
 		testName := fmt.Sprintf("%s_%s", name, store.Type())
@@ -68,6 +79,17 @@ func (test *OnlineStoreTest) Run() {
 	}
 }
 
+func TestOnlineStoreLocal(t *testing.T) {
+	test := OnlineStoreTest{
+		t:               t,
+		store:           NewLocalOnlineStore(),
+		testNil:         true,
+		testTimestamped: true,
+		testBatchGet:    true,
+	}
+	test.Run()
+}
+
 func randomFeatureVariant() (string, string) {
 	return uuid.NewString(), uuid.NewString()
 }
@@ -129,6 +151,53 @@ func testSetGetEntity(t *testing.T, store OnlineStore) {
 	}
 }
 
+func testSetGetTimestamp(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, types.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	tsTable, ok := tab.(TimestampedOnlineStoreTable)
+	if !ok {
+		t.Fatalf("Table is not a TimestampedOnlineStoreTable")
+	}
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	if err := tsTable.SetWithTimestamp("a", "val", ts); err != nil {
+		t.Fatalf("Failed to set entity with timestamp: %s", err)
+	}
+	if err := tsTable.Set("b", "val"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	gotVal, gotTS, err := tsTable.GetWithTimestamp("a")
+	if err != nil {
+		t.Fatalf("Failed to get entity with timestamp: %s", err)
+	}
+	if gotVal != "val" || !gotTS.Equal(ts) {
+		t.Fatalf("Wrong value or timestamp: %v %v\nExpected: %v %v", gotVal, gotTS, "val", ts)
+	}
+	if gotVal, err := tsTable.Get("a"); err != nil || gotVal != "val" {
+		t.Fatalf("Failed to get timestamped entity without timestamp: %v %s", gotVal, err)
+	}
+	_, gotTS, err = tsTable.GetWithTimestamp("b")
+	if err != nil {
+		t.Fatalf("Failed to get entity with timestamp: %s", err)
+	}
+	if !gotTS.IsZero() {
+		t.Fatalf("Expected zero timestamp for value set without one, got %v", gotTS)
+	}
+	if err := tsTable.Set("a", "newer"); err != nil {
+		t.Fatalf("Failed to overwrite entity: %s", err)
+	}
+	gotVal, gotTS, err = tsTable.GetWithTimestamp("a")
+	if err != nil {
+		t.Fatalf("Failed to get entity with timestamp: %s", err)
+	}
+	if gotVal != "newer" || !gotTS.IsZero() {
+		t.Fatalf("Expected overwriting without a timestamp to clear it, got %v %v", gotVal, gotTS)
+	}
+}
+
 func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 	mockFeature, mockVariant := randomFeatureVariant()
 	defer store.DeleteTable(mockFeature, mockVariant)
@@ -146,7 +215,7 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 	}
 	singleEnt := "e"
 	singleVal := "val"
-	singleSet := []SetItem{{singleEnt, singleVal}}
+	singleSet := []SetItem{{Entity: singleEnt, Value: singleVal}}
 	if err := batchTable.BatchSet(singleSet); err != nil {
 		t.Fatalf("Failed to set single entity: %s", err)
 	}
@@ -161,7 +230,7 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 	for i := 0; i < maxNum; i++ {
 		entity := fmt.Sprintf("entity_%d", i)
 		value := fmt.Sprintf("value_%d", i)
-		maxSet[i] = SetItem{entity, value}
+		maxSet[i] = SetItem{Entity: entity, Value: value}
 	}
 	if err := batchTable.BatchSet(maxSet); err != nil {
 		t.Fatalf("Failed to set multi entity: %s", err)
@@ -176,12 +245,56 @@ func testBatchSetGetEntity(t *testing.T, store OnlineStore) {
 			t.Fatalf("Values are not the same %v %v", val, gotVal)
 		}
 	}
-	overSizedSet := append(maxSet, SetItem{"a", "b"})
+	overSizedSet := append(maxSet, SetItem{Entity: "a", Value: "b"})
 	if err := batchTable.BatchSet(overSizedSet); err == nil {
 		t.Fatalf("Succeeded to batch set over max size")
 	}
 }
 
+func testBatchGetEntity(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, types.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	batchTable, ok := tab.(BatchGetOnlineTable)
+	if !ok {
+		t.Fatalf("Table does not implement batch get interface.")
+	}
+	// Enough entities to span multiple requests in stores that limit batch sizes.
+	entities := make([]string, 250)
+	for i := range entities {
+		entities[i] = fmt.Sprintf("entity_%d", i)
+		if err := tab.Set(entities[i], fmt.Sprintf("value_%d", i)); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+	}
+	// Duplicates and reordering must be preserved in the results.
+	requested := append([]string{entities[10], entities[10]}, entities...)
+	items, err := batchTable.BatchGet(requested)
+	if err != nil {
+		t.Fatalf("Failed to batch get entities: %s", err)
+	}
+	if len(items) != len(requested) {
+		t.Fatalf("Expected %d items, got %d", len(requested), len(items))
+	}
+	for i, item := range items {
+		expected, err := tab.Get(requested[i])
+		if err != nil {
+			t.Fatalf("Failed to get entity: %s", err)
+		}
+		if item.Entity != requested[i] || !reflect.DeepEqual(expected, item.Value) {
+			t.Fatalf("Wrong item at %d: %+v\nExpected: %s %v", i, item, requested[i], expected)
+		}
+	}
+	if _, err := batchTable.BatchGet([]string{entities[0], "missing"}); err == nil {
+		t.Fatalf("succeeded in batch getting non-existent entity")
+	} else if _, valid := err.(*fferr.EntityNotFoundError); !valid {
+		t.Fatalf("Wrong error for entity not found: %T", err)
+	}
+}
+
 func testEntityNotFound(t *testing.T, store OnlineStore) {
 	mockFeature, mockVariant := uuid.NewString(), "v"
 	entity := "e"
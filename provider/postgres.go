@@ -154,7 +154,11 @@ func (q postgresSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def Traini
 
 func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
 	columns := make([]string, 0)
-	query := fmt.Sprintf(" (SELECT entity, value , ts from %s ) l ", sanitize(labelName))
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitize)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(" (SELECT entity, value , ts from %s ) l ", labelSource)
 	for i, feature := range def.Features {
 		tableName, err := store.getResourceTableName(feature)
 		if err != nil {
@@ -165,10 +169,19 @@ func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		columns = append(columns, santizedName)
 		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
 			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )", query)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
 		}
+		lagColumnName := sanitize(lagFeatureColumnName(tableName, lagFeature))
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i)
+		columns = append(columns, lagColumnName)
+		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts - INTERVAL '%d microseconds' ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
+			query, lagColumnName, sanitize(tableName), lagFeature.LagDelta.Microseconds(), tableJoinAlias, tableJoinAlias)
 	}
+	query = fmt.Sprintf("%s )", query)
 	columnStr := strings.Join(columns, ", ")
 
 	if !isUpdate {
@@ -193,6 +206,10 @@ func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 	return nil
 }
 
+func (q postgresSQLQueries) timestampLiteral(t time.Time) string {
+	return fmt.Sprintf("'%s'::timestamptz", t.UTC().Format("2006-01-02 15:04:05.999999-07:00"))
+}
+
 func (q postgresSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -254,7 +271,7 @@ func (q postgresSQLQueries) transformationExists() string {
 }
 
 func (q postgresSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableColumn, error) {
-	qry := fmt.Sprintf("SELECT attname AS column_name FROM   pg_attribute WHERE  attrelid = 'public.%s'::regclass AND    attnum > 0 ORDER  BY attnum", tableName)
+	qry := fmt.Sprintf("SELECT attname AS column_name, format_type(atttypid, atttypmod) AS data_type FROM   pg_attribute WHERE  attrelid = 'public.%s'::regclass AND    attnum > 0 ORDER  BY attnum", tableName)
 	rows, err := db.Query(qry)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(pt.PostgresOffline.String(), err)
@@ -264,13 +281,13 @@ func (q postgresSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableCol
 	defer rows.Close()
 	columnNames := make([]TableColumn, 0)
 	for rows.Next() {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+		var column, nativeType string
+		if err := rows.Scan(&column, &nativeType); err != nil {
 			wrapped := fferr.NewExecutionError(pt.PostgresOffline.String(), err)
 			wrapped.AddDetail("table_name", tableName)
 			return nil, wrapped
 		}
-		columnNames = append(columnNames, TableColumn{Name: column})
+		columnNames = append(columnNames, TableColumn{Name: column, NativeType: nativeType})
 	}
 	return columnNames, nil
 }
@@ -0,0 +1,554 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/featureform/fferr"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+)
+
+// postgresOnlineTablesTable maps each feature variant to its table and value type.
+const postgresOnlineTablesTable = "featureform_online_tables"
+
+// maxPostgresBatchSize keeps batch inserts well under Postgres' limit of 65535 bind parameters.
+const maxPostgresBatchSize = 5000
+
+func postgresOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	postgresConfig := &pc.PostgresConfig{}
+	if err := postgresConfig.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewPostgresOnlineStore(postgresConfig)
+}
+
+type postgresOnlineStore struct {
+	db *sql.DB
+	BaseProvider
+}
+
+func NewPostgresOnlineStore(config *pc.PostgresConfig) (*postgresOnlineStore, error) {
+	db, err := sql.Open("postgres", postgresOnlineDSN(config))
+	if err != nil {
+		return nil, fferr.NewConnectionError(pt.PostgresOnline.String(), err)
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (feature TEXT, variant TEXT, table_name TEXT NOT NULL, value_type TEXT NOT NULL, PRIMARY KEY (feature, variant))", sanitize(postgresOnlineTablesTable))
+	if _, err := db.Exec(query); err != nil {
+		db.Close()
+		return nil, fferr.NewConnectionError(pt.PostgresOnline.String(), err)
+	}
+	return &postgresOnlineStore{
+		db: db,
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.PostgresOnline,
+			ProviderConfig: config.Serialize(),
+		},
+	}, nil
+}
+
+// postgresOnlineDSN builds the connection URL, escaping credentials that contain characters like @ or /.
+func postgresOnlineDSN(config *pc.PostgresConfig) string {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.Username, config.Password),
+		Host:     net.JoinHostPort(config.Host, config.Port),
+		Path:     "/" + config.Database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+func (store *postgresOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *postgresOnlineStore) Close() error {
+	if err := store.db.Close(); err != nil {
+		return fferr.NewExecutionError(pt.PostgresOnline.String(), err)
+	}
+	return nil
+}
+
+func (store *postgresOnlineStore) CheckHealth() (bool, error) {
+	if err := store.db.Ping(); err != nil {
+		return false, fferr.NewConnectionError(pt.PostgresOnline.String(), err)
+	}
+	return true, nil
+}
+
+// postgresOnlineTableName hashes the feature variant since Postgres truncates identifiers
+// longer than 63 bytes.
+func postgresOnlineTableName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s__%s", feature, variant)))
+	return fmt.Sprintf("featureform_online_%s", hex.EncodeToString(hash[:12]))
+}
+
+func postgresOnlineColumnType(valueType types.ValueType) (string, error) {
+	if vecType, ok := valueType.(types.VectorType); ok {
+		if vecType.ScalarType != types.Float32 {
+			return "", fferr.NewDataTypeNotFoundErrorf(valueType, "pgvector only supports float32 vectors")
+		}
+		return fmt.Sprintf("vector(%d)", vecType.Dimension), nil
+	}
+	switch valueType {
+	case types.Int8, types.Int16, types.Int32, types.UInt8, types.UInt16:
+		return "integer", nil
+	case types.Int, types.Int64, types.UInt32:
+		return "bigint", nil
+	case types.UInt64:
+		// bigint is signed, so values above math.MaxInt64 need numeric.
+		return "numeric(20)", nil
+	case types.Float32:
+		return "real", nil
+	case types.Float64:
+		return "double precision", nil
+	case types.String, types.NilType:
+		return "text", nil
+	case types.Bool:
+		return "boolean", nil
+	case types.Timestamp, types.Datetime:
+		return "timestamptz", nil
+	default:
+		return "", fferr.NewDataTypeNotFoundErrorf(valueType, "could not determine column type")
+	}
+}
+
+func (store *postgresOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	query := fmt.Sprintf("SELECT table_name, value_type FROM %s WHERE feature = $1 AND variant = $2", sanitize(postgresOnlineTablesTable))
+	var tableName, serializedType string
+	err := store.db.QueryRow(query, feature, variant).Scan(&tableName, &serializedType)
+	if err == sql.ErrNoRows {
+		wrapped := fferr.NewDatasetNotFoundError(feature, variant, nil)
+		wrapped.AddDetail("provider", store.ProviderType.String())
+		return nil, wrapped
+	} else if err != nil {
+		return nil, fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	valueType, err := types.DeserializeType(serializedType)
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("value_type", serializedType)
+		return nil, wrapped
+	}
+	return &postgresOnlineTable{
+		db:        store.db,
+		feature:   feature,
+		variant:   variant,
+		tableName: tableName,
+		valueType: valueType,
+	}, nil
+}
+
+func (store *postgresOnlineStore) CreateTable(feature, variant string, valueType types.ValueType) (OnlineStoreTable, error) {
+	return store.createTable(feature, variant, valueType, false)
+}
+
+// CreateIndex creates a table with an HNSW index on its vectors. It requires the pgvector
+// extension to be available on the server.
+func (store *postgresOnlineStore) CreateIndex(feature, variant string, vectorType types.VectorType) (VectorStoreTable, error) {
+	table, err := store.createTable(feature, variant, vectorType, true)
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (store *postgresOnlineStore) createTable(feature, variant string, valueType types.ValueType, index bool) (*postgresOnlineTable, error) {
+	if _, err := store.GetTable(feature, variant); err == nil {
+		return nil, fferr.NewDatasetAlreadyExistsError(feature, variant, nil)
+	} else if _, notFound := err.(*fferr.DatasetNotFoundError); !notFound {
+		return nil, err
+	}
+	columnType, err := postgresOnlineColumnType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	tableName := postgresOnlineTableName(feature, variant)
+	queries := make([]string, 0)
+	if valueType.IsVector() {
+		queries = append(queries, "CREATE EXTENSION IF NOT EXISTS vector")
+	}
+	queries = append(queries, fmt.Sprintf("CREATE TABLE %s (entity TEXT PRIMARY KEY, value %s, ts TIMESTAMPTZ)", sanitize(tableName), columnType))
+	if index {
+		// Cosine distance matches the Redis vector indexes.
+		queries = append(queries, fmt.Sprintf("CREATE INDEX ON %s USING hnsw (value vector_cosine_ops)", sanitize(tableName)))
+	}
+	queries = append(queries, fmt.Sprintf("INSERT INTO %s (feature, variant, table_name, value_type) VALUES ($1, $2, $3, $4)", sanitize(postgresOnlineTablesTable)))
+	tx, err := store.db.Begin()
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	defer tx.Rollback()
+	for i, query := range queries {
+		var args []interface{}
+		if i == len(queries)-1 {
+			args = []interface{}{feature, variant, tableName, types.SerializeType(valueType)}
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			wrapped := fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+			wrapped.AddDetail("table_name", tableName)
+			return nil, wrapped
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	return &postgresOnlineTable{
+		db:        store.db,
+		feature:   feature,
+		variant:   variant,
+		tableName: tableName,
+		valueType: valueType,
+	}, nil
+}
+
+func (store *postgresOnlineStore) DeleteTable(feature, variant string) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	defer tx.Rollback()
+	queries := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", sanitize(postgresOnlineTableName(feature, variant))),
+		fmt.Sprintf("DELETE FROM %s WHERE feature = $1 AND variant = $2", sanitize(postgresOnlineTablesTable)),
+	}
+	if _, err := tx.Exec(queries[0]); err != nil {
+		return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	if _, err := tx.Exec(queries[1], feature, variant); err != nil {
+		return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	return nil
+}
+
+func (store *postgresOnlineStore) DeleteIndex(feature, variant string) error {
+	return store.DeleteTable(feature, variant)
+}
+
+type postgresOnlineTable struct {
+	db        *sql.DB
+	feature   string
+	variant   string
+	tableName string
+	valueType types.ValueType
+}
+
+func (table *postgresOnlineTable) upsertQuery(rows int) string {
+	placeholders := make([]string, rows)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
+	}
+	return fmt.Sprintf(
+		"INSERT INTO %s (entity, value, ts) VALUES %s ON CONFLICT (entity) DO UPDATE SET value = EXCLUDED.value, ts = EXCLUDED.ts",
+		sanitize(table.tableName), strings.Join(placeholders, ", "),
+	)
+}
+
+func (table *postgresOnlineTable) serializeValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if table.valueType.IsVector() {
+		vec, ok := value.([]float32)
+		if !ok {
+			return nil, fferr.NewDataTypeNotFoundErrorf(value, "expected []float32 for vector table")
+		}
+		return formatPgVector(vec), nil
+	}
+	switch typed := value.(type) {
+	case uint64:
+		// database/sql rejects uint64 values with the high bit set, so send them as numeric text.
+		return strconv.FormatUint(typed, 10), nil
+	case int, int8, int16, int32, int64, uint8, uint16, uint32, float32, float64, string, bool:
+		return typed, nil
+	case time.Time:
+		return typed.UTC(), nil
+	default:
+		return nil, fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
+	}
+}
+
+func (table *postgresOnlineTable) set(entity string, value interface{}, ts time.Time) error {
+	serialized, err := table.serializeValue(value)
+	if err != nil {
+		return err
+	}
+	var tsArg interface{}
+	if !ts.IsZero() {
+		tsArg = ts.UTC()
+	}
+	if _, err := table.db.Exec(table.upsertQuery(1), entity, serialized, tsArg); err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return wrapped
+	}
+	return nil
+}
+
+func (table *postgresOnlineTable) Set(entity string, value interface{}) error {
+	return table.set(entity, value, time.Time{})
+}
+
+func (table *postgresOnlineTable) SetWithTimestamp(entity string, value interface{}, ts time.Time) error {
+	return table.set(entity, value, ts)
+}
+
+func (table *postgresOnlineTable) BatchSet(items []SetItem) error {
+	if len(items) > maxPostgresBatchSize {
+		return fferr.NewInternalErrorf("Cannot batch write %d items.\nMax: %d\n", len(items), maxPostgresBatchSize)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	// An upsert can't touch the same row twice, so only the last value of each entity is written.
+	last := make(map[string]int, len(items))
+	for i, item := range items {
+		last[item.Entity] = i
+	}
+	args := make([]interface{}, 0, len(last)*3)
+	for i, item := range items {
+		if last[item.Entity] != i {
+			continue
+		}
+		serialized, err := table.serializeValue(item.Value)
+		if err != nil {
+			return err
+		}
+		var tsArg interface{}
+		if !item.TS.IsZero() {
+			tsArg = item.TS.UTC()
+		}
+		args = append(args, item.Entity, serialized, tsArg)
+	}
+	if _, err := table.db.Exec(table.upsertQuery(len(args)/3), args...); err != nil {
+		return fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.FEATURE_VARIANT, err)
+	}
+	return nil
+}
+
+func (table *postgresOnlineTable) MaxBatchSize() (int, error) {
+	return maxPostgresBatchSize, nil
+}
+
+func (table *postgresOnlineTable) Get(entity string) (interface{}, error) {
+	val, _, err := table.GetWithTimestamp(entity)
+	return val, err
+}
+
+func (table *postgresOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	query := fmt.Sprintf("SELECT %s, ts FROM %s WHERE entity = $1", table.valueColumn(), sanitize(table.tableName))
+	var raw interface{}
+	var ts sql.NullTime
+	err := table.db.QueryRow(query, entity).Scan(&raw, &ts)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, fferr.NewEntityNotFoundError(table.feature, table.variant, entity, nil)
+	} else if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return nil, time.Time{}, wrapped
+	}
+	val, err := table.deserializeValue(raw)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return val, ts.Time, nil
+}
+
+func (table *postgresOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	query := fmt.Sprintf("SELECT entity, %s, ts FROM %s WHERE entity = ANY($1)", table.valueColumn(), sanitize(table.tableName))
+	rows, err := table.db.Query(query, pq.Array(entities))
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.FEATURE_VARIANT, err)
+	}
+	defer rows.Close()
+	found := make(map[string]GetItem, len(entities))
+	for rows.Next() {
+		var entity string
+		var raw interface{}
+		var ts sql.NullTime
+		if err := rows.Scan(&entity, &raw, &ts); err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.FEATURE_VARIANT, err)
+		}
+		val, err := table.deserializeValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		found[entity] = GetItem{Entity: entity, Value: val, TS: ts.Time}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), table.feature, table.variant, fferr.FEATURE_VARIANT, err)
+	}
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		item, has := found[entity]
+		if !has {
+			return nil, fferr.NewEntityNotFoundError(table.feature, table.variant, entity, nil)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// valueColumn selects vectors as text, since database/sql has no representation of the pgvector type.
+func (table *postgresOnlineTable) valueColumn() string {
+	if table.valueType.IsVector() {
+		return "value::text"
+	}
+	return "value"
+}
+
+// deserializeValue casts a scanned value to the Go type of the table's value type.
+func (table *postgresOnlineTable) deserializeValue(raw interface{}) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if table.valueType.IsVector() {
+		return parsePgVector(postgresOnlineString(raw))
+	}
+	var err error
+	var result interface{}
+	switch table.valueType {
+	case types.Int:
+		var i int64
+		if i, err = postgresOnlineInt(raw); err == nil {
+			result = int(i)
+		}
+	case types.Int8, types.Int16, types.Int32:
+		var i int64
+		if i, err = postgresOnlineInt(raw); err == nil {
+			result = int32(i)
+		}
+	case types.Int64:
+		result, err = postgresOnlineInt(raw)
+	case types.UInt8, types.UInt16, types.UInt32:
+		var i int64
+		if i, err = postgresOnlineInt(raw); err == nil {
+			result = uint64(i)
+		}
+	case types.UInt64:
+		result, err = strconv.ParseUint(postgresOnlineString(raw), 10, 64)
+	case types.Float32:
+		var f float64
+		if f, err = postgresOnlineFloat(raw); err == nil {
+			result = float32(f)
+		}
+	case types.Float64:
+		result, err = postgresOnlineFloat(raw)
+	case types.Bool:
+		if b, ok := raw.(bool); ok {
+			result = b
+		} else {
+			result, err = strconv.ParseBool(postgresOnlineString(raw))
+		}
+	case types.Timestamp, types.Datetime:
+		ts, ok := raw.(time.Time)
+		if !ok {
+			err = fmt.Errorf("expected timestamp, got %T", raw)
+		}
+		result = ts.UTC()
+	default:
+		result = postgresOnlineString(raw)
+	}
+	if err != nil {
+		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", raw, table.valueType, err))
+		wrapped.AddDetail("feature", table.feature)
+		wrapped.AddDetail("variant", table.variant)
+		return nil, wrapped
+	}
+	return result, nil
+}
+
+func postgresOnlineString(raw interface{}) string {
+	if b, ok := raw.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", raw)
+}
+
+func postgresOnlineInt(raw interface{}) (int64, error) {
+	if i, ok := raw.(int64); ok {
+		return i, nil
+	}
+	return strconv.ParseInt(postgresOnlineString(raw), 10, 64)
+}
+
+func postgresOnlineFloat(raw interface{}) (float64, error) {
+	if f, ok := raw.(float64); ok {
+		return f, nil
+	}
+	return strconv.ParseFloat(postgresOnlineString(raw), 64)
+}
+
+// Nearest returns the k entities whose vectors have the smallest cosine distance to vector.
+func (table *postgresOnlineTable) Nearest(feature, variant string, vector []float32, k int32) ([]string, error) {
+	query := fmt.Sprintf("SELECT entity FROM %s ORDER BY value <=> $1::vector LIMIT $2", sanitize(table.tableName))
+	rows, err := table.db.Query(query, formatPgVector(vector), k)
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	defer rows.Close()
+	entities := make([]string, 0, k)
+	for rows.Next() {
+		var entity string
+		if err := rows.Scan(&entity); err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+		}
+		entities = append(entities, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.PostgresOnline.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	return entities, nil
+}
+
+// formatPgVector writes a vector in pgvector's text format, e.g. [1,2.5,3].
+func formatPgVector(vec []float32) string {
+	parts := make([]string, len(vec))
+	for i, v := range vec {
+		parts[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(parts, ","))
+}
+
+func parsePgVector(text string) ([]float32, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return nil, fferr.NewInternalError(fmt.Errorf("invalid vector: %s", text))
+	}
+	trimmed = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	if trimmed == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(trimmed, ",")
+	vec := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fferr.NewInternalError(fmt.Errorf("invalid vector %s: %w", text, err))
+		}
+		vec[i] = float32(f)
+	}
+	return vec, nil
+}
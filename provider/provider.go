@@ -10,6 +10,7 @@ import (
 	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/secrets"
 )
 
 func init() {
@@ -21,10 +22,12 @@ func init() {
 		pt.DynamoDBOnline:    dynamodbOnlineStoreFactory,
 		pt.PineconeOnline:    pineconeOnlineStoreFactory,
 		pt.QdrantOnline:      qdrantOnlineStoreFactory,
+		pt.PostgresOnline:    postgresOnlineStoreFactory,
 		pt.MemoryOffline:     memoryOfflineStoreFactory,
 		pt.MySqlOffline:      mySqlOfflineStoreFactory,
 		pt.PostgresOffline:   postgresOfflineStoreFactory,
 		pt.ClickHouseOffline: clickhouseOfflineStoreFactory,
+		pt.DuckDBOffline:     duckdbOfflineStoreFactory,
 		pt.SnowflakeOffline:  snowflakeOfflineStoreFactory,
 		pt.RedshiftOffline:   redshiftOfflineStoreFactory,
 		pt.BigQueryOffline:   bigQueryOfflineStoreFactory,
@@ -88,10 +91,16 @@ func RegisterFactory(t pt.Type, f Factory) error {
 	return nil
 }
 
+// Get creates a provider from its config, resolving any secret references in it first. The provider's
+// Config holds the resolved secrets, so it must not be written back to metadata.
 func Get(t pt.Type, config pc.SerializedConfig) (Provider, error) {
 	f, has := factories[t]
 	if !has {
 		return nil, fferr.NewInternalError(fmt.Errorf("no provider of type: %s", t))
 	}
-	return f(config)
+	resolved, err := secrets.ResolveConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return f(resolved)
 }
@@ -0,0 +1,42 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	"github.com/featureform/fferr"
+	ss "github.com/featureform/helpers/string_set"
+)
+
+type DuckDBConfig struct {
+	// Path is the database file. The database is kept in memory if it's empty.
+	Path string `json:"Path"`
+	// DirPath is the root of the local filestore that parquet and CSV sources are read from,
+	// e.g. file:///data/featureform.
+	DirPath string `json:"DirPath"`
+}
+
+func (d *DuckDBConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, d)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func (d *DuckDBConfig) Serialize() []byte {
+	conf, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+func (d DuckDBConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"DirPath": true,
+	}
+}
+
+func (a DuckDBConfig) DifferingFields(b DuckDBConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
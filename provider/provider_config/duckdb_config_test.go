@@ -0,0 +1,75 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestDuckDBConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"DirPath": true,
+	}
+
+	config := DuckDBConfig{
+		Path:    "/tmp/featureform.duckdb",
+		DirPath: "file:///tmp/featureform",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestDuckDBConfigDifferingFields(t *testing.T) {
+	type args struct {
+		a DuckDBConfig
+		b DuckDBConfig
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", args{
+			a: DuckDBConfig{
+				Path:    "/tmp/featureform.duckdb",
+				DirPath: "file:///tmp/featureform",
+			},
+			b: DuckDBConfig{
+				Path:    "/tmp/featureform.duckdb",
+				DirPath: "file:///tmp/featureform",
+			},
+		}, ss.StringSet{}},
+		{"Differing Fields", args{
+			a: DuckDBConfig{
+				Path:    "/tmp/featureform.duckdb",
+				DirPath: "file:///tmp/featureform",
+			},
+			b: DuckDBConfig{
+				Path:    "",
+				DirPath: "file:///data",
+			},
+		}, ss.StringSet{
+			"Path":    true,
+			"DirPath": true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.a.DifferingFields(tt.args.b)
+
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -20,8 +20,10 @@ var providerMap = map[string]string{
 	"BLOB_ONLINE":        "OnlineBlobConfig",
 	"MONGODB_ONLINE":     "MongoDbConfig",
 	"PINECONE_ONLINE":    "PineconeConfig",
+	"POSTGRES_ONLINE":    "PostgresConfig",
 	"POSTGRES_OFFLINE":   "PostgresConfig",
 	"CLICKHOUSE_OFFLINE": "ClickHouseConfig",
+	"DUCKDB_OFFLINE":     "DuckDBConfig",
 	"MYSQL_OFFLINE":      "MySqlConfig",
 	"SNOWFLAKE_OFFLINE":  "SnowflakeConfig",
 	"REDSHIFT_OFFLINE":   "RedshiftConfig",
@@ -259,6 +261,28 @@ func TestPostgres(t *testing.T) {
 	assert.NotNil(t, instance)
 }
 
+func TestDuckDB(t *testing.T) {
+	connectionConfigs, err := getConnectionConfigs()
+	if err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	var jsonDict map[string]interface{}
+	if err = json.Unmarshal(connectionConfigs, &jsonDict); err != nil {
+		println(err)
+		t.FailNow()
+	}
+
+	config := jsonDict["DuckDBConfig"].(map[string]interface{})
+	instance := DuckDBConfig{
+		Path:    config["Path"].(string),
+		DirPath: config["DirPath"].(string),
+	}
+
+	assert.NotNil(t, instance)
+}
+
 func TestSnowflake(t *testing.T) {
 	connectionConfigs, err := getConnectionConfigs()
 	if err != nil {
@@ -17,12 +17,14 @@ const (
 	MongoDBOnline   Type = "MONGODB_ONLINE"
 	PineconeOnline  Type = "PINECONE_ONLINE"
 	QdrantOnline    Type = "QDRANT_ONLINE"
+	PostgresOnline  Type = "POSTGRES_ONLINE"
 
 	// Offline
 	MemoryOffline     Type = "MEMORY_OFFLINE"
 	MySqlOffline      Type = "MYSQL_OFFLINE"
 	PostgresOffline   Type = "POSTGRES_OFFLINE"
 	ClickHouseOffline Type = "CLICKHOUSE_OFFLINE"
+	DuckDBOffline     Type = "DUCKDB_OFFLINE"
 	SnowflakeOffline  Type = "SNOWFLAKE_OFFLINE"
 	RedshiftOffline   Type = "REDSHIFT_OFFLINE"
 	SparkOffline      Type = "SPARK_OFFLINE"
@@ -49,8 +51,10 @@ var AllProviderTypes = []Type{
 	MySqlOffline,
 	PineconeOnline,
 	QdrantOnline,
+	PostgresOnline,
 	PostgresOffline,
 	ClickHouseOffline,
+	DuckDBOffline,
 	SnowflakeOffline,
 	RedshiftOffline,
 	SparkOffline,
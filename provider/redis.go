@@ -191,8 +191,27 @@ func (store *redisOnlineStore) CreateTable(feature, variant string, valueType ty
 	return table, nil
 }
 
-// TODO: Implement table deletion
+// DeleteTable removes a table's values and event times and unregisters it.
 func (store *redisOnlineStore) DeleteTable(feature, variant string) error {
+	key := redisTableKey{store.prefix, feature, variant}
+	tablesKey := fmt.Sprintf("%s__tables", store.prefix)
+	exists, err := store.client.Do(context.TODO(), store.client.B().Hexists().Key(tablesKey).Field(key.String()).Build()).AsBool()
+	if err != nil {
+		return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, err)
+	}
+	if !exists {
+		return fferr.NewDatasetNotFoundError(feature, variant, nil)
+	}
+	table := redisOnlineTable{key: key}
+	cmds := rueidis.Commands{
+		store.client.B().Del().Key(key.String(), table.timestampsKey()).Build(),
+		store.client.B().Hdel().Key(tablesKey).Field(key.String()).Build(),
+	}
+	for _, resp := range store.client.DoMulti(context.TODO(), cmds...) {
+		if resp.Error() != nil {
+			return fferr.NewResourceExecutionError(store.ProviderType.String(), feature, variant, fferr.FEATURE_VARIANT, resp.Error())
+		}
+	}
 	return nil
 }
 
@@ -256,7 +275,32 @@ type redisOnlineTable struct {
 	valueType types.ValueType
 }
 
+// Set writes a value without an event time, clearing the event time of an earlier SetWithTimestamp so
+// that it isn't returned with the new value.
 func (table redisOnlineTable) Set(entity string, value interface{}) error {
+	serialized, err := serializeRedisValue(value)
+	if err != nil {
+		return err
+	}
+	return table.doEntity(entity,
+		table.client.B().Hset().Key(table.key.String()).FieldValue().FieldValue(entity, serialized).Build(),
+		table.client.B().Hdel().Key(table.timestampsKey()).Field(entity).Build(),
+	)
+}
+
+// doEntity runs the commands that write an entity in a single round trip.
+func (table redisOnlineTable) doEntity(entity string, cmds ...rueidis.Completed) error {
+	for _, res := range table.client.DoMulti(context.TODO(), cmds...) {
+		if res.Error() != nil {
+			wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, res.Error())
+			wrapped.AddDetail("entity", entity)
+			return wrapped
+		}
+	}
+	return nil
+}
+
+func serializeRedisValue(value interface{}) (string, error) {
 	switch v := value.(type) {
 	case nil:
 		value = "nil"
@@ -286,21 +330,9 @@ func (table redisOnlineTable) Set(entity string, value interface{}) error {
 	case []float32:
 		value = rueidis.VectorString32(v)
 	default:
-		return fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
+		return "", fferr.NewDataTypeNotFoundErrorf(value, "unsupported data type")
 	}
-	cmd := table.client.B().
-		Hset().
-		Key(table.key.String()).
-		FieldValue().
-		FieldValue(entity, value.(string)).
-		Build()
-	res := table.client.Do(context.TODO(), cmd)
-	if res.Error() != nil {
-		wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, res.Error())
-		wrapped.AddDetail("entity", entity)
-		return wrapped
-	}
-	return nil
+	return value.(string), nil
 }
 
 func (table redisOnlineTable) Get(entity string) (interface{}, error) {
@@ -313,12 +345,17 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 	if resp.Error() != nil {
 		return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, resp.Error())
 	}
-	var err error
-	var result interface{}
 	val, err := resp.ToString()
 	if err != nil {
 		return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
 	}
+	return table.parseValue(entity, val)
+}
+
+// parseValue casts a value stored as a string back to the table's type.
+func (table redisOnlineTable) parseValue(entity, val string) (interface{}, error) {
+	var err error
+	var result interface{}
 	if table.valueType.IsVector() {
 		return rueidis.ToVector32(val), nil
 	}
@@ -349,13 +386,105 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 		result, err = val, nil
 	}
 	if err != nil {
-		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", resp, table.valueType, err))
+		wrapped := fferr.NewInternalError(fmt.Errorf("could not cast value: %v to %s: %w", val, table.valueType, err))
 		wrapped.AddDetail("entity", entity)
 		return nil, wrapped
 	}
 	return result, nil
 }
 
+// timestampsKey is the hash that stores each entity's event time, kept separate from the
+// value hash so that tables written before timestamps were supported remain readable.
+func (table redisOnlineTable) timestampsKey() string {
+	return fmt.Sprintf("%s__timestamps", table.key.String())
+}
+
+func (table redisOnlineTable) SetWithTimestamp(entity string, value interface{}, ts time.Time) error {
+	serialized, err := serializeRedisValue(value)
+	if err != nil {
+		return err
+	}
+	return table.doEntity(entity,
+		table.client.B().Hset().Key(table.key.String()).FieldValue().FieldValue(entity, serialized).Build(),
+		table.client.B().Hset().Key(table.timestampsKey()).FieldValue().FieldValue(entity, ts.UTC().Format(time.RFC3339Nano)).Build(),
+	)
+}
+
+func (table redisOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	val, err := table.Get(entity)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	cmd := table.client.B().
+		Hget().
+		Key(table.timestampsKey()).
+		Field(entity).
+		Build()
+	raw, err := table.client.Do(context.TODO(), cmd).ToString()
+	if rueidis.IsRedisNil(err) {
+		// The value was written without an event time.
+		return val, time.Time{}, nil
+	} else if err != nil {
+		wrapped := fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		wrapped.AddDetail("entity", entity)
+		return nil, time.Time{}, wrapped
+	}
+	ts, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		wrapped := fferr.NewInternalError(fmt.Errorf("could not parse timestamp %s: %w", raw, err))
+		wrapped.AddDetail("entity", entity)
+		return nil, time.Time{}, wrapped
+	}
+	return val, ts, nil
+}
+
+// BatchGet reads the values and event times of all entities with a single pipelined pair of HMGETs.
+func (table redisOnlineTable) BatchGet(entities []string) ([]GetItem, error) {
+	if len(entities) == 0 {
+		return []GetItem{}, nil
+	}
+	cmds := rueidis.Commands{
+		table.client.B().Hmget().Key(table.key.String()).Field(entities...).Build(),
+		table.client.B().Hmget().Key(table.timestampsKey()).Field(entities...).Build(),
+	}
+	resps := table.client.DoMulti(context.TODO(), cmds...)
+	vals, err := resps[0].ToArray()
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+	}
+	timestamps, err := resps[1].ToArray()
+	if err != nil {
+		return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+	}
+	items := make([]GetItem, len(entities))
+	for i, entity := range entities {
+		raw, err := vals[i].ToString()
+		if rueidis.IsRedisNil(err) {
+			return nil, fferr.NewEntityNotFoundError(table.key.Feature, table.key.Variant, entity, nil)
+		} else if err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		}
+		val, err := table.parseValue(entity, raw)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = GetItem{Entity: entity, Value: val}
+		rawTS, err := timestamps[i].ToString()
+		if rueidis.IsRedisNil(err) {
+			// The value was written without an event time.
+			continue
+		} else if err != nil {
+			return nil, fferr.NewResourceExecutionError(pt.RedisOnline.String(), table.key.Feature, table.key.Variant, fferr.ENTITY, err)
+		}
+		if items[i].TS, err = time.Parse(time.RFC3339Nano, rawTS); err != nil {
+			wrapped := fferr.NewInternalError(fmt.Errorf("could not parse timestamp %s: %w", rawTS, err))
+			wrapped.AddDetail("entity", entity)
+			return nil, wrapped
+		}
+	}
+	return items, nil
+}
+
 type redisOnlineIndex struct {
 	client    rueidis.Client
 	key       redisIndexKey
@@ -46,11 +46,47 @@ func TestOnlineStoreRedisMock(t *testing.T) {
 		t:     t,
 		store: store,
 		// TODO(simba) make this work.
-		testNil: false,
+		testNil:         false,
+		testTimestamped: true,
+		testBatchGet:    true,
 	}
 	test.Run()
 }
 
+func TestRedisDeleteTable(t *testing.T) {
+	mRedis := mockRedis()
+	defer mRedis.Close()
+	redisMockConfig := &pc.RedisConfig{
+		Addr: mRedis.Addr(),
+	}
+	store, err := GetOnlineStore(pt.RedisOnline, redisMockConfig.Serialized())
+	if err != nil {
+		t.Fatalf("could not initialize store: %s\n", err)
+	}
+	defer store.Close()
+	table, err := store.CreateTable("feature", "variant", types.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.(TimestampedOnlineStoreTable).SetWithTimestamp("a", "val", time.Now()); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	for _, key := range mRedis.Keys() {
+		if key != "Featureform_table____tables" {
+			t.Fatalf("Expected the table's values and event times to be deleted, found %s", key)
+		}
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected deleted table to be unregistered")
+	}
+	if err := store.DeleteTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected deleting a missing table to fail")
+	}
+}
+
 func TestOnlineStoreRedisInsecure(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration tests")
@@ -180,19 +180,42 @@ func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		columns = append(columns, santizedName)
 		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
 			query, santizedName, tableJoinAlias, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
+	}
+	// Each lag join is resolved to a single row per label entity and timestamp up front,
+	// so it doesn't need to take part in the rank based deduplication below.
+	for i, lagFeature := range def.LagFeatures {
+		tableName, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
 		}
+		lagColumnName := sanitize(lagFeatureColumnName(tableName, lagFeature))
+		tableJoinAlias := fmt.Sprintf("t%d", len(def.Features)+i+1)
+		columns = append(columns, lagColumnName)
+		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT lbl.entity, lbl.ts, f.value AS %s, ROW_NUMBER() OVER (PARTITION BY lbl.entity, lbl.ts ORDER BY f.ts DESC) AS %s_rn "+
+			"FROM (SELECT DISTINCT entity, ts FROM %s) AS lbl JOIN %s AS f ON (f.entity=lbl.entity AND f.ts + INTERVAL '%d microseconds' <= lbl.ts)) AS %s "+
+			"ON (%s.entity=t0.entity AND %s.ts=t0.ts AND %s_rn=1)",
+			query, lagColumnName, tableJoinAlias, sanitize(labelName), sanitize(tableName), lagFeature.LagDelta.Microseconds(), tableJoinAlias,
+			tableJoinAlias, tableJoinAlias, tableJoinAlias)
 	}
+	query = fmt.Sprintf("%s )) WHERE rn=1", query)
 	columnStr := strings.Join(columns, ", ")
-	selectColumnStr := strings.Join(selectColumns, ", ")
+	// Lag features aren't ranked, so the rank columns are only added when there are regular features.
+	rankSelectStr, rankOrderStr := "", ""
+	if len(selectColumns) > 0 {
+		rankSelectStr = ", " + strings.Join(selectColumns, ", ")
+		rankOrderStr = fmt.Sprintf(", %s DESC", strings.Join(selectColumns, ", "))
+	}
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitize)
+	if err != nil {
+		return err
+	}
 
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
 			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			sanitize(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
+				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\"%s) AS rn FROM ( "+
+				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s%s FROM %s AS t0 %s )",
+			sanitize(tableName), columnStr, rankOrderStr, columnStr, rankSelectStr, labelSource, query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewResourceExecutionError(pt.RedshiftOffline.String(), def.ID.Name, def.ID.Variant, fferr.ResourceType(def.ID.Type.String()), err)
 			wrapped.AddDetail("table_name", tableName)
@@ -203,9 +226,9 @@ func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def Trainin
 		tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
 		fullQuery := fmt.Sprintf(
 			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s desc) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			tempTable, columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
+				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\"%s) AS rn FROM ( "+
+				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s%s FROM %s AS t0 %s )",
+			tempTable, columnStr, rankOrderStr, columnStr, rankSelectStr, labelSource, query)
 
 		if err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery); err != nil {
 			return err
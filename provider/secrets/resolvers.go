@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/featureform/fferr"
+	"golang.org/x/exp/slices"
+)
+
+// EnvResolver resolves secret://env/NAME to the environment variable NAME. Only variables that start with
+// Prefix or are listed in Names can be referenced, so configs can't read the rest of the environment.
+type EnvResolver struct {
+	Prefix string
+	Names  []string
+}
+
+func (resolver EnvResolver) allows(name string) bool {
+	if resolver.Prefix != "" && strings.HasPrefix(name, resolver.Prefix) {
+		return true
+	}
+	return slices.Contains(resolver.Names, name)
+}
+
+func (resolver EnvResolver) Resolve(ref Reference) (string, error) {
+	if !resolver.allows(ref.Path) {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("environment variable %s can't be referenced as a secret", ref.Path))
+	}
+	secret, has := os.LookupEnv(ref.Path)
+	if !has {
+		return "", fferr.NewKeyNotFoundError(ref.String(), fmt.Errorf("environment variable %s is not set", ref.Path))
+	}
+	return secretKey(ref, secret)
+}
+
+// FileResolver resolves secret://file/path to the contents of the file at path under Dir, without the
+// trailing newline, so secrets mounted as files in Kubernetes can be referenced. Paths that lead out of
+// Dir, including through symlinks, are rejected.
+type FileResolver struct {
+	Dir string
+}
+
+func (resolver FileResolver) Resolve(ref Reference) (string, error) {
+	if resolver.Dir == "" {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("no secrets directory is configured to resolve %s", ref))
+	}
+	if !filepath.IsLocal(ref.Path) {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s is outside the secrets directory", ref))
+	}
+	path, err := resolver.securePath(ref)
+	if err != nil {
+		return "", err
+	}
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fferr.NewKeyNotFoundError(ref.String(), err)
+	} else if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	return secretKey(ref, strings.TrimRight(string(contents), "\r\n"))
+}
+
+// securePath follows the symlinks in the path of ref, such as those Kubernetes uses to mount secrets, and
+// checks that it still ends up in Dir.
+func (resolver FileResolver) securePath(ref Reference) (string, error) {
+	dir, err := filepath.EvalSymlinks(resolver.Dir)
+	if err != nil {
+		return "", fferr.NewInternalError(fmt.Errorf("could not open secrets directory %s: %v", resolver.Dir, err))
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, ref.Path))
+	if os.IsNotExist(err) {
+		return "", fferr.NewKeyNotFoundError(ref.String(), err)
+	} else if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s is outside the secrets directory", ref))
+	}
+	return path, nil
+}
+
+// VaultResolver resolves secret://vault/path#key to a key of the secret at path in Vault, or a server
+// with the same HTTP API. Paths are API paths, so secrets in a KV version 2 engine are read from paths
+// like secret/data/snowflake. Only paths under one of Prefixes can be read, so configs can't read every
+// secret that Token has access to. Address and Token default to VAULT_ADDR and VAULT_TOKEN.
+type VaultResolver struct {
+	Address  string
+	Token    string
+	Prefixes []string
+	Client   *http.Client
+}
+
+// securePath cleans the path of ref and checks that it's under one of the resolver's prefixes.
+func (resolver *VaultResolver) securePath(ref Reference) (string, error) {
+	cleaned := path.Clean(ref.Path)
+	if path.IsAbs(ref.Path) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s is not a relative vault path", ref))
+	}
+	for _, prefix := range resolver.Prefixes {
+		prefix = strings.Trim(path.Clean(prefix), "/")
+		if prefix != "" && prefix != "." && (cleaned == prefix || strings.HasPrefix(cleaned, prefix+"/")) {
+			return cleaned, nil
+		}
+	}
+	return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s is outside the allowed vault paths", ref))
+}
+
+// vaultResponse is a read from Vault. A KV version 2 engine nests the secret and its metadata in data.
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+func (resolver *VaultResolver) Resolve(ref Reference) (string, error) {
+	secretPath, err := resolver.securePath(ref)
+	if err != nil {
+		return "", err
+	}
+	address := resolver.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("no vault address is configured to resolve %s", ref))
+	}
+	token := resolver.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	client := resolver.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	// The path is escaped so it can't add a query or encoded dots that the server would decode.
+	endpoint := strings.TrimSuffix(address, "/") + (&url.URL{Path: "/v1/" + secretPath}).EscapedPath()
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fferr.NewInvalidArgumentError(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fferr.NewConnectionError("vault", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fferr.NewConnectionError("vault", err)
+	}
+	var read vaultResponse
+	if err := json.Unmarshal(body, &read); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fferr.NewInternalError(fmt.Errorf("could not parse vault response for %s: %v", ref, err))
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fferr.NewKeyNotFoundError(ref.String(), fmt.Errorf("no secret at %s", ref.Path))
+	case resp.StatusCode != http.StatusOK:
+		return "", fferr.NewConnectionError("vault", fmt.Errorf("reading %s failed with status %d: %s", ref, resp.StatusCode, strings.Join(read.Errors, ", ")))
+	}
+	data := read.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	if ref.Key == "" {
+		if len(data) != 1 {
+			return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s has %d keys, pick one with #key", ref, len(data)))
+		}
+		for key := range data {
+			ref.Key = key
+		}
+	}
+	return fieldString(ref, data)
+}
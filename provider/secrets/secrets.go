@@ -0,0 +1,199 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package secrets resolves references to secrets that are kept out of provider configs. A config field can
+// hold a reference like secret://env/FEATUREFORM_SECRET_SNOWFLAKE_PASSWORD, secret://file/redis-password or
+// secret://vault/secret/data/snowflake#password instead of the secret itself. Metadata only ever stores the
+// reference; it's resolved when the provider is created.
+//
+// Deployments pick the resolvers that are enabled with SECRET_RESOLVERS, a comma separated list of schemes
+// that defaults to env,file. The env resolver only reads variables that start with SECRET_ENV_PREFIX
+// (FEATUREFORM_SECRET_ by default) or are listed in SECRET_ENV_NAMES, and the file resolver only reads files
+// in SECRETS_DIR (/etc/featureform/secrets by default). The vault resolver has to be enabled explicitly; it
+// reads from VAULT_ADDR and only reads paths under the comma separated VAULT_SECRET_PREFIXES.
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/featureform/fferr"
+	help "github.com/featureform/helpers"
+)
+
+const Prefix = "secret://"
+
+// Reference points at a secret held by the resolver registered for Scheme. If Key is set, the secret is a
+// JSON object and the reference is to one of its fields.
+type Reference struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+func (ref Reference) String() string {
+	str := fmt.Sprintf("%s%s/%s", Prefix, ref.Scheme, ref.Path)
+	if ref.Key != "" {
+		str += "#" + ref.Key
+	}
+	return str
+}
+
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, fferr.NewInvalidArgumentError(fmt.Errorf("%s is not a secret reference", value))
+	}
+	location, key, _ := strings.Cut(strings.TrimPrefix(value, Prefix), "#")
+	scheme, path, _ := strings.Cut(location, "/")
+	if scheme == "" || path == "" {
+		return Reference{}, fferr.NewInvalidArgumentError(fmt.Errorf("secret reference %s must look like %s<resolver>/<path>[#key]", value, Prefix))
+	}
+	return Reference{Scheme: scheme, Path: path, Key: key}, nil
+}
+
+type SecretResolver interface {
+	Resolve(ref Reference) (string, error)
+}
+
+var (
+	resolversMtx sync.RWMutex
+	resolvers    = ResolversFromEnv()
+)
+
+// ResolversFromEnv returns the built-in resolvers enabled by SECRET_RESOLVERS, keyed by scheme.
+func ResolversFromEnv() map[string]SecretResolver {
+	builtin := map[string]SecretResolver{
+		"env": EnvResolver{
+			Prefix: help.GetEnv("SECRET_ENV_PREFIX", "FEATUREFORM_SECRET_"),
+			Names:  splitList(help.GetEnv("SECRET_ENV_NAMES", "")),
+		},
+		"file":  FileResolver{Dir: help.GetEnv("SECRETS_DIR", "/etc/featureform/secrets")},
+		"vault": &VaultResolver{Prefixes: splitList(help.GetEnv("VAULT_SECRET_PREFIXES", ""))},
+	}
+	enabled := make(map[string]SecretResolver)
+	for _, scheme := range splitList(help.GetEnv("SECRET_RESOLVERS", "env,file")) {
+		if resolver, has := builtin[scheme]; has {
+			enabled[scheme] = resolver
+		}
+	}
+	return enabled
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// RegisterResolver makes references with the given scheme resolve through resolver, replacing the resolver
+// that was registered for it.
+func RegisterResolver(scheme string, resolver SecretResolver) {
+	resolversMtx.Lock()
+	defer resolversMtx.Unlock()
+	resolvers[scheme] = resolver
+}
+
+func Resolve(value string) (string, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+	resolversMtx.RLock()
+	resolver, has := resolvers[ref.Scheme]
+	resolversMtx.RUnlock()
+	if !has {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("no secret resolver is enabled for %s", ref))
+	}
+	return resolver.Resolve(ref)
+}
+
+// ResolveConfig replaces every secret reference in a serialized config with the secret it points at,
+// including references nested in executor and store configs. Configs without references are returned as is.
+func ResolveConfig(config []byte) ([]byte, error) {
+	if !bytes.Contains(config, []byte(Prefix)) {
+		return config, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		// References are only recognized in JSON configs.
+		return config, nil
+	}
+	resolved, err := resolveValue(parsed)
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return serialized, nil
+}
+
+func resolveValue(value interface{}) (interface{}, error) {
+	switch casted := value.(type) {
+	case string:
+		if !IsReference(casted) {
+			return casted, nil
+		}
+		return Resolve(casted)
+	case map[string]interface{}:
+		for key, val := range casted {
+			resolved, err := resolveValue(val)
+			if err != nil {
+				return nil, err
+			}
+			casted[key] = resolved
+		}
+	case []interface{}:
+		for i, val := range casted {
+			resolved, err := resolveValue(val)
+			if err != nil {
+				return nil, err
+			}
+			casted[i] = resolved
+		}
+	}
+	return value, nil
+}
+
+// secretKey returns the field of a JSON object secret that ref points at, or the whole secret if ref has
+// no key.
+func secretKey(ref Reference, secret string) (string, error) {
+	if ref.Key == "" {
+		return secret, nil
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fferr.NewInvalidArgumentError(fmt.Errorf("secret %s is not a JSON object: %v", ref, err))
+	}
+	return fieldString(ref, fields)
+}
+
+func fieldString(ref Reference, fields map[string]interface{}) (string, error) {
+	field, has := fields[ref.Key]
+	if !has {
+		return "", fferr.NewKeyNotFoundError(ref.String(), fmt.Errorf("secret has no key %s", ref.Key))
+	}
+	if str, ok := field.(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(field)
+	if err != nil {
+		return "", fferr.NewInternalError(err)
+	}
+	return string(encoded), nil
+}
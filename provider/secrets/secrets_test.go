@@ -0,0 +1,257 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Reference
+		valid bool
+	}{
+		{"secret://env/SNOWFLAKE_PASSWORD", Reference{Scheme: "env", Path: "SNOWFLAKE_PASSWORD"}, true},
+		{"secret://file/etc/featureform/password", Reference{Scheme: "file", Path: "etc/featureform/password"}, true},
+		{"secret://vault/secret/data/redis#password", Reference{Scheme: "vault", Path: "secret/data/redis", Key: "password"}, true},
+		{"secret://env", Reference{}, false},
+		{"secret:///path", Reference{}, false},
+		{"password", Reference{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, err := ParseReference(tt.value)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Expected valid to be %v, got error %v", tt.valid, err)
+			}
+			if ref != tt.want {
+				t.Fatalf("Expected %#v, got %#v", tt.want, ref)
+			}
+			if tt.valid && ref.String() != tt.value {
+				t.Fatalf("Expected %s to round trip, got %s", tt.value, ref)
+			}
+		})
+	}
+}
+
+// newVaultServer serves secrets like a Vault dev server with a KV version 2 engine at secret/ and a
+// version 1 engine at kv/.
+func newVaultServer(t *testing.T, token string) *httptest.Server {
+	responses := map[string]interface{}{
+		"/v1/secret/data/snowflake": map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"password": "sf-password", "account": "sf-account"},
+				"metadata": map[string]interface{}{"version": 1},
+			},
+		},
+		"/v1/kv/redis": map[string]interface{}{
+			"data": map[string]interface{}{"password": "redis-password"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+		response, has := responses[r.URL.Path]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{}})
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolvers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "password"), []byte("file-password\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "creds.json"), []byte(`{"user": "admin", "port": 5432}`), 0600); err != nil {
+		t.Fatalf("Failed to write secret: %s", err)
+	}
+	outside := filepath.Join(t.TempDir(), "outside")
+	if err := os.WriteFile(outside, []byte("outside-password"), 0600); err != nil {
+		t.Fatalf("Failed to write secret: %s", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Fatalf("Failed to link secret: %s", err)
+	}
+	if err := os.Symlink("password", filepath.Join(dir, "linked")); err != nil {
+		t.Fatalf("Failed to link secret: %s", err)
+	}
+	t.Setenv("FF_TEST_PASSWORD", "env-password")
+	t.Setenv("OTHER_PASSWORD", "other-password")
+	vault := newVaultServer(t, "root")
+	t.Setenv("VAULT_ADDR", "")
+	newVault := func(token string) *VaultResolver {
+		return &VaultResolver{Address: vault.URL, Token: token, Prefixes: []string{"secret/data/", "kv"}}
+	}
+	kv2Only := &VaultResolver{Address: vault.URL, Token: "root", Prefixes: []string{"secret/data"}}
+	env := EnvResolver{Prefix: "FF_TEST_", Names: []string{"NAMED_PASSWORD"}}
+	t.Setenv("NAMED_PASSWORD", "named-password")
+
+	tests := []struct {
+		name     string
+		resolver SecretResolver
+		value    string
+		want     string
+		valid    bool
+	}{
+		{"Env", env, "secret://env/FF_TEST_PASSWORD", "env-password", true},
+		{"Env Named", env, "secret://env/NAMED_PASSWORD", "named-password", true},
+		{"Env Not Set", env, "secret://env/FF_TEST_MISSING", "", false},
+		{"Env Not Allowed", env, "secret://env/OTHER_PASSWORD", "", false},
+		{"Env Nothing Allowed", EnvResolver{}, "secret://env/FF_TEST_PASSWORD", "", false},
+		{"File", FileResolver{Dir: dir}, "secret://file/password", "file-password", true},
+		{"File Key", FileResolver{Dir: dir}, "secret://file/creds.json#user", "admin", true},
+		{"File Number Key", FileResolver{Dir: dir}, "secret://file/creds.json#port", "5432", true},
+		{"File Missing Key", FileResolver{Dir: dir}, "secret://file/creds.json#password", "", false},
+		{"File Not Found", FileResolver{Dir: dir}, "secret://file/missing", "", false},
+		{"File Linked", FileResolver{Dir: dir}, "secret://file/linked", "file-password", true},
+		{"File Parent", FileResolver{Dir: dir}, "secret://file/../outside", "", false},
+		{"File Absolute", FileResolver{Dir: dir}, "secret://file/" + outside, "", false},
+		{"File Linked Outside", FileResolver{Dir: dir}, "secret://file/escape", "", false},
+		{"File No Dir", FileResolver{}, "secret://file/password", "", false},
+		{"Vault KV2", newVault("root"), "secret://vault/secret/data/snowflake#password", "sf-password", true},
+		{"Vault KV1", newVault("root"), "secret://vault/kv/redis#password", "redis-password", true},
+		{"Vault Single Key", newVault("root"), "secret://vault/kv/redis", "redis-password", true},
+		{"Vault Ambiguous Key", newVault("root"), "secret://vault/secret/data/snowflake", "", false},
+		{"Vault Not Found", newVault("root"), "secret://vault/secret/data/missing#password", "", false},
+		{"Vault Bad Token", newVault("wrong"), "secret://vault/kv/redis#password", "", false},
+		{"Vault Outside Prefixes", kv2Only, "secret://vault/kv/redis#password", "", false},
+		{"Vault Prefix Boundary", newVault("root"), "secret://vault/kv2/redis#password", "", false},
+		{"Vault Parent", kv2Only, "secret://vault/secret/data/../../kv/redis#password", "", false},
+		{"Vault Absolute", newVault("root"), "secret://vault//kv/redis#password", "", false},
+		{"Vault Query", newVault("root"), "secret://vault/kv/redis?list=true#password", "", false},
+		{"Vault No Prefixes", &VaultResolver{Address: vault.URL, Token: "root"}, "secret://vault/kv/redis#password", "", false},
+		{"Vault No Address", &VaultResolver{Token: "root", Prefixes: []string{"kv"}}, "secret://vault/kv/redis#password", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.value)
+			if err != nil {
+				t.Fatalf("Failed to parse reference: %s", err)
+			}
+			secret, err := tt.resolver.Resolve(ref)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Expected valid to be %v, got error %v", tt.valid, err)
+			}
+			if secret != tt.want {
+				t.Fatalf("Expected %q, got %q", tt.want, secret)
+			}
+		})
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	t.Setenv("FEATUREFORM_SECRET_TEST_PASSWORD", "env-password")
+	t.Setenv("VAULT_ADDR", newVaultServer(t, "root").URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	if _, err := ResolveConfig([]byte(`{"Password": "secret://vault/secret/data/snowflake#password"}`)); err == nil {
+		t.Fatalf("Expected the vault resolver to be disabled by default")
+	}
+	RegisterResolver("vault", &VaultResolver{Prefixes: []string{"secret"}})
+
+	plain := []byte(`{"Addr": "localhost:6379", "Password": "password", "DB": 0}`)
+	resolved, err := ResolveConfig(plain)
+	if err != nil {
+		t.Fatalf("Failed to resolve: %s", err)
+	}
+	if string(resolved) != string(plain) {
+		t.Fatalf("Expected a config without references to be unchanged, got %s", resolved)
+	}
+
+	config := []byte(`{
+		"ExecutorType": "EMR",
+		"ExecutorConfig": {"Credentials": {"AWSAccessKeyId": "id", "AWSSecretKey": "secret://env/FEATUREFORM_SECRET_TEST_PASSWORD"}},
+		"StoreConfig": {"Passwords": ["secret://vault/secret/data/snowflake#password", "plain"]},
+		"Port": 12345678901234567890
+	}`)
+	resolved, err = ResolveConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to resolve: %s", err)
+	}
+	var parsed struct {
+		ExecutorConfig struct {
+			Credentials map[string]string
+		}
+		StoreConfig struct {
+			Passwords []string
+		}
+		Port json.Number
+	}
+	if err := json.Unmarshal(resolved, &parsed); err != nil {
+		t.Fatalf("Failed to parse resolved config %s: %s", resolved, err)
+	}
+	if parsed.ExecutorConfig.Credentials["AWSSecretKey"] != "env-password" || parsed.ExecutorConfig.Credentials["AWSAccessKeyId"] != "id" {
+		t.Fatalf("Expected the nested env reference to resolve, got %s", resolved)
+	}
+	if parsed.StoreConfig.Passwords[0] != "sf-password" || parsed.StoreConfig.Passwords[1] != "plain" {
+		t.Fatalf("Expected the vault reference in the list to resolve, got %s", resolved)
+	}
+	if parsed.Port != "12345678901234567890" {
+		t.Fatalf("Expected numbers to be kept exactly, got %s", parsed.Port)
+	}
+
+	if _, err := ResolveConfig([]byte(`{"Password": "secret://unknown/path"}`)); err == nil {
+		t.Fatalf("Expected a reference without a resolver to fail")
+	}
+	if _, err := ResolveConfig([]byte(`{"Password": "secret://env/FEATUREFORM_SECRET_TEST_MISSING"}`)); err == nil {
+		t.Fatalf("Expected a missing secret to fail")
+	}
+
+	RegisterResolver("static", staticResolver("registered"))
+	resolved, err = ResolveConfig([]byte(`{"Password": "secret://static/anything"}`))
+	if err != nil || string(resolved) != `{"Password":"registered"}` {
+		t.Fatalf("Expected a registered resolver to be used, got %s %v", resolved, err)
+	}
+}
+
+func TestResolversFromEnv(t *testing.T) {
+	if resolvers := ResolversFromEnv(); len(resolvers) != 2 || resolvers["vault"] != nil {
+		t.Fatalf("Expected only env and file to be enabled by default, got %v", resolvers)
+	}
+	t.Setenv("SECRET_RESOLVERS", "vault, file")
+	t.Setenv("SECRETS_DIR", "/var/secrets")
+	t.Setenv("VAULT_SECRET_PREFIXES", "secret/data/featureform, kv/featureform")
+	resolvers := ResolversFromEnv()
+	if _, has := resolvers["env"]; has || len(resolvers) != 2 {
+		t.Fatalf("Expected only vault and file to be enabled, got %v", resolvers)
+	}
+	if file := resolvers["file"].(FileResolver); file.Dir != "/var/secrets" {
+		t.Fatalf("Expected files to be read from SECRETS_DIR, got %s", file.Dir)
+	}
+	if vault := resolvers["vault"].(*VaultResolver); len(vault.Prefixes) != 2 || vault.Prefixes[1] != "kv/featureform" {
+		t.Fatalf("Expected vault paths to be limited to VAULT_SECRET_PREFIXES, got %v", vault.Prefixes)
+	}
+	t.Setenv("SECRET_RESOLVERS", "env")
+	t.Setenv("SECRET_ENV_PREFIX", "SECRET_")
+	t.Setenv("SECRET_ENV_NAMES", "DB_PASSWORD,API_KEY")
+	env := ResolversFromEnv()["env"].(EnvResolver)
+	if env.Prefix != "SECRET_" || len(env.Names) != 2 || env.Names[1] != "API_KEY" {
+		t.Fatalf("Expected the env resolver to be limited by SECRET_ENV_PREFIX and SECRET_ENV_NAMES, got %#v", env)
+	}
+	t.Setenv("SECRET_RESOLVERS", "")
+	if resolvers := ResolversFromEnv(); len(resolvers) != 0 {
+		t.Fatalf("Expected no resolvers to be enabled, got %v", resolvers)
+	}
+}
+
+type staticResolver string
+
+func (secret staticResolver) Resolve(ref Reference) (string, error) {
+	return string(secret), nil
+}
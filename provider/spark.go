@@ -628,6 +628,32 @@ func createQuotedIdentifier(id ResourceID) string {
 	return fmt.Sprintf("`%s__%s__%s`", id.Type, id.Name, id.Variant)
 }
 
+// pythonTrainingSetLabelFilter returns the WHERE clause that applies a training set's time window
+// and entity filter to the label window of a Spark or Pandas training set query. The entity filter's
+// source is expected to be passed to the job as the source following the label and features.
+func pythonTrainingSetLabelFilter(def TrainingSetDef, timeCondition func(op string, t time.Time) string) string {
+	if !def.hasLabelFilter() {
+		return ""
+	}
+	conditions := make([]string, 0)
+	if !def.StartTime.IsZero() {
+		conditions = append(conditions, timeCondition(">=", def.StartTime))
+	}
+	if !def.EndTime.IsZero() {
+		conditions = append(conditions, timeCondition("<", def.EndTime))
+	}
+	if def.EntityFilter != nil {
+		conditions = append(conditions, fmt.Sprintf("entity IN (SELECT `%s` FROM source_%d)", def.EntityFilter.EntityColumn, len(def.Features)+1))
+	}
+	return fmt.Sprintf(" WHERE %s", strings.Join(conditions, " AND "))
+}
+
+// entityFilterSourceName returns the name of the entity filter's source in the form
+// accepted by the file store offline stores' getSourcePath.
+func entityFilterSourceName(filter *EntityFilterDef) string {
+	return fmt.Sprintf("featureform_%s__%s__%s", strings.ToLower(filter.Source.Type.String()), filter.Source.Name, filter.Source.Variant)
+}
+
 func (q defaultPythonOfflineQueries) trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchema ResourceSchema) string {
 	columns := make([]string, 0)
 	joinQueries := make([]string, 0)
@@ -677,7 +703,10 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(def TrainingSetDef, featu
 	} else {
 		labelWindowQuery = fmt.Sprintf("SELECT %s AS entity, %s AS value, %s AS label_ts FROM source_0", labelSchema.Entity, labelSchema.Value, labelSchema.TS)
 	}
-	labelPartitionQuery := fmt.Sprintf("(SELECT * FROM (SELECT entity, value, label_ts FROM (%s) t ) t0)", labelWindowQuery)
+	labelFilter := pythonTrainingSetLabelFilter(def, func(op string, t time.Time) string {
+		return fmt.Sprintf("label_ts %s TIMESTAMP '%s UTC'", op, t.UTC().Format("2006-01-02 15:04:05.999999"))
+	})
+	labelPartitionQuery := fmt.Sprintf("(SELECT * FROM (SELECT entity, value, label_ts FROM (%s) t%s ) t0)", labelWindowQuery, labelFilter)
 	labelJoinQuery := fmt.Sprintf("%s %s", labelPartitionQuery, joinQueryString)
 
 	timeStamps := strings.Join(feature_timestamps, ", ")
@@ -2233,6 +2262,14 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		sourcePaths = append(sourcePaths, featureSourcePath)
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
+	if def.EntityFilter != nil {
+		filterSourcePath, err := spark.getSourcePath(entityFilterSourceName(def.EntityFilter))
+		if err != nil {
+			spark.Logger.Errorw("Could not get entity filter source path", "source", def.EntityFilter.Source, "error", err)
+			return err
+		}
+		sourcePaths = append(sourcePaths, filterSourcePath)
+	}
 	trainingSetQuery := spark.query.trainingSetCreate(def, featureSchemas, labelSchema)
 
 	sparkArgs, err := spark.Executor.SparkSubmitArgs(destinationPath, trainingSetQuery, sourcePaths, CreateTrainingSet, spark.Store)
@@ -2272,11 +2309,15 @@ func (spark *SparkOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterat
 }
 
 func (spark *SparkOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
-	return nil, fmt.Errorf("not Implemented")
+	return nil, unsupportedTrainTestSplitError(spark.Type())
 }
 
 func (spark *SparkOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
-	return nil, nil, fmt.Errorf("not Implemented")
+	return nil, nil, unsupportedTrainTestSplitError(spark.Type())
+}
+
+func (spark *SparkOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	return nil, unsupportedTrainTestSplitError(spark.Type())
 }
 
 func sanitizeSparkSQL(name string) string {
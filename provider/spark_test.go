@@ -1647,6 +1647,30 @@ func TestTrainingSetCreate(t *testing.T) {
 	}
 }
 
+func TestPythonTrainingSetLabelFilter(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{"test_training_set", "default", TrainingSet},
+		Features: []ResourceID{{"test_feature_1", "default", Feature}},
+		Label:    ResourceID{"test_label", "default", Label},
+	}
+	timeCondition := func(op string, ts time.Time) string {
+		return fmt.Sprintf("label_ts %s %d", op, ts.UnixMilli())
+	}
+	if filter := pythonTrainingSetLabelFilter(def, timeCondition); filter != "" {
+		t.Fatalf("expected no filter, got %s", filter)
+	}
+	def.StartTime = time.UnixMilli(1)
+	def.EndTime = time.UnixMilli(2)
+	def.EntityFilter = &EntityFilterDef{Source: ResourceID{"users", "default", Primary}, EntityColumn: "user_id"}
+	expected := " WHERE label_ts >= 1 AND label_ts < 2 AND entity IN (SELECT `user_id` FROM source_2)"
+	if filter := pythonTrainingSetLabelFilter(def, timeCondition); filter != expected {
+		t.Fatalf("label filter not correct, got %s, expected %s", filter, expected)
+	}
+	if name := entityFilterSourceName(def.EntityFilter); name != "featureform_primary__users__default" {
+		t.Fatalf("entity filter source name not correct, got %s", name)
+	}
+}
+
 // func TestCompareStructsFail(t *testing.T) {
 // 	t.Parallel()
 // 	type testStruct struct {
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/araddon/dateparse"
 	"github.com/featureform/fferr"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -51,7 +52,9 @@ type OfflineTableQueries interface {
 	materializationDrop(tableName string) string
 	getTable() string
 	dropTable(tableName string) string
+	dropView(viewName string) string
 	materializationIterateSegment(tableName string) string
+	materializationIterateSegmentSince(tableName string) string
 	newSQLOfflineTable(name string, columnType string) string
 	writeUpdate(table string) string
 	writeInserts(table string) string
@@ -60,7 +63,8 @@ type OfflineTableQueries interface {
 	trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
 	trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
 	trainingRowSelect(columns string, trainingSetName string) string
-	trainingRowSplitSelect(columns string, trainingSetSplitName string) (string, string)
+	timestampLiteral(t time.Time) string
+	trainingRowSplitSelect(columns string, trainingSetSplitName string, group splitGroup) string
 	castTableItemType(v interface{}, t interface{}) interface{}
 	getValueColumnType(t *sql.ColumnType) interface{}
 	numRows(n interface{}) (int64, error)
@@ -69,6 +73,28 @@ type OfflineTableQueries interface {
 	transformationExists() string
 }
 
+// splitGroup identifies which split of a train test split a training set row belongs to.
+type splitGroup int
+
+const (
+	trainSplitGroup splitGroup = iota
+	testSplitGroup
+	validationSplitGroup
+)
+
+func (g splitGroup) String() string {
+	switch g {
+	case trainSplitGroup:
+		return "Train"
+	case testSplitGroup:
+		return "Test"
+	case validationSplitGroup:
+		return "Validation"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(g))
+	}
+}
+
 type sqlOfflineStore struct {
 	db     *sql.DB
 	parent SQLOfflineStoreConfig
@@ -163,19 +189,43 @@ func (store *sqlOfflineStore) tableExists(tableName string) (bool, error) {
 }
 
 func (store *sqlOfflineStore) tableExistsForResourceId(id ResourceID) (bool, error) {
-	var tableName string
-	var err error
+	tableName, err := store.tableNameForResourceId(id)
+	if err != nil {
+		return false, err
+	}
+	return store.tableExists(tableName)
+}
+
+func (store *sqlOfflineStore) tableNameForResourceId(id ResourceID) (string, error) {
 	if id.check(Feature, Label) == nil {
-		tableName, err = store.getResourceTableName(id)
+		return store.getResourceTableName(id)
 	} else if id.check(TrainingSet) == nil {
-		tableName, err = store.getTrainingSetName(id)
+		return store.getTrainingSetName(id)
 	} else if id.check(Primary) == nil || id.check(Transformation) == nil {
-		tableName, err = GetPrimaryTableName(id)
+		return GetPrimaryTableName(id)
 	}
+	return "", fferr.NewInvalidResourceTypeError(id.Name, id.Variant, fferr.ResourceType(id.Type.String()), nil)
+}
+
+// DeleteResourceTable drops the table or view backing id. Registered resources and primaries are views
+// while transformations and training sets are tables, and not every dialect can tell the two apart, so a
+// view is dropped if dropping a table fails.
+func (store *sqlOfflineStore) DeleteResourceTable(id ResourceID) error {
+	tableName, err := store.tableNameForResourceId(id)
 	if err != nil {
-		return false, err
+		return err
 	}
-	return store.tableExists(tableName)
+	if exists, err := store.tableExists(tableName); err != nil {
+		return err
+	} else if !exists {
+		return fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	if _, err := store.db.Exec(store.query.dropTable(tableName)); err != nil {
+		if _, viewErr := store.db.Exec(store.query.dropView(tableName)); viewErr != nil {
+			return fferr.NewExecutionError(store.Type().String(), err)
+		}
+	}
+	return nil
 }
 
 func (store *sqlOfflineStore) AsOfflineStore() (OfflineStore, error) {
@@ -349,6 +399,22 @@ func (store *sqlOfflineStore) GetPrimaryTable(id ResourceID) (PrimaryTable, erro
 	}, nil
 }
 
+func (store *sqlOfflineStore) GetSourceSchema(id ResourceID) ([]TableColumn, error) {
+	if err := id.check(Primary, Transformation); err != nil {
+		return nil, err
+	}
+	name, err := GetPrimaryTableName(id)
+	if err != nil {
+		return nil, err
+	}
+	if exists, err := store.tableExistsForResourceId(id); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, nil)
+	}
+	return store.query.getColumns(store.db, name)
+}
+
 func (store *sqlOfflineStore) GetTransformationTable(id ResourceID) (TransformationTable, error) {
 	name, err := GetPrimaryTableName(id)
 	if err != nil {
@@ -480,8 +546,19 @@ func (mat *sqlMaterialization) NumRows() (int64, error) {
 }
 
 func (mat *sqlMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
-	query := mat.query.materializationIterateSegment(mat.tableName)
-	rows, err := mat.db.Query(query, start, end)
+	return mat.iterate(mat.query.materializationIterateSegment(mat.tableName), start, end)
+}
+
+func (mat *sqlMaterialization) IterateChunkSince(idx int, watermark time.Time) (FeatureIterator, error) {
+	start, end, err := genericChunkBounds(mat, defaultRowsPerChunk, idx)
+	if err != nil {
+		return nil, err
+	}
+	return mat.iterate(mat.query.materializationIterateSegmentSince(mat.tableName), start, end, watermark)
+}
+
+func (mat *sqlMaterialization) iterate(query string, args ...interface{}) (FeatureIterator, error) {
+	rows, err := mat.db.Query(query, args...)
 	if err != nil {
 		wrapped := fferr.NewExecutionError(mat.providerType.String(), err)
 		wrapped.AddDetail("table_name", mat.tableName)
@@ -508,6 +585,38 @@ func (mat *sqlMaterialization) IterateChunk(idx int) (FeatureIterator, error) {
 	return genericIterateChunk(mat, defaultRowsPerChunk, idx)
 }
 
+func (mat *sqlMaterialization) MaxTimestamp() (time.Time, error) {
+	var max interface{}
+	query := fmt.Sprintf("SELECT MAX(ts) FROM %s", sanitize(mat.tableName))
+	if err := mat.db.QueryRow(query).Scan(&max); err != nil {
+		wrapped := fferr.NewExecutionError(mat.providerType.String(), err)
+		wrapped.AddDetail("table_name", mat.tableName)
+		return time.Time{}, wrapped
+	}
+	return parseMaxTimestamp(max)
+}
+
+// parseMaxTimestamp converts the result of a MAX(ts) query into a time. Drivers differ in whether
+// they return timestamps as times or strings, and an empty table returns NULL.
+func parseMaxTimestamp(val interface{}) (time.Time, error) {
+	switch casted := val.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return casted, nil
+	case []byte:
+		return parseMaxTimestamp(string(casted))
+	case string:
+		ts, err := dateparse.ParseAny(casted)
+		if err != nil {
+			return time.Time{}, fferr.NewDataTypeNotFoundErrorf(casted, "could not parse timestamp: %v", err)
+		}
+		return ts, nil
+	default:
+		return time.Time{}, fferr.NewDataTypeNotFoundErrorf(val, "unsupported timestamp type %T", val)
+	}
+}
+
 type sqlFeatureIterator struct {
 	rows         *sql.Rows
 	err          error
@@ -915,11 +1024,15 @@ func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator
 }
 
 func (store *sqlOfflineStore) CreateTrainTestSplit(def TrainTestSplitDef) (func() error, error) {
-	return nil, fmt.Errorf("not Implemented")
+	return nil, unsupportedTrainTestSplitError(store.Type())
 }
 
 func (store *sqlOfflineStore) GetTrainTestSplit(def TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
-	return nil, nil, fmt.Errorf("not Implemented")
+	return nil, nil, unsupportedTrainTestSplitError(store.Type())
+}
+
+func (store *sqlOfflineStore) GetValidationSplit(def TrainTestSplitDef) (TrainingSetIterator, error) {
+	return nil, unsupportedTrainTestSplitError(store.Type())
 }
 
 // getValueColumnTypes returns a list of column types. Columns consist of feature and label values
@@ -1014,6 +1127,11 @@ func (it *sqlTrainingRowsIterator) Next() bool {
 	return true
 }
 
+// Close releases the rows of an iterator that's abandoned before it's exhausted.
+func (it *sqlTrainingRowsIterator) Close() error {
+	return it.rows.Close()
+}
+
 func (it *sqlTrainingRowsIterator) Err() error {
 	return it.err
 }
@@ -1452,7 +1570,7 @@ func (q defaultOfflineSQLQueries) primaryTableRegister(tableName string, sourceN
 
 func (q defaultOfflineSQLQueries) getColumns(db *sql.DB, name string) ([]TableColumn, error) {
 	bind := q.newVariableBindingIterator()
-	qry := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_name = %s order by ordinal_position", bind.Next())
+	qry := fmt.Sprintf("SELECT column_name, data_type FROM information_schema.columns WHERE table_name = %s order by ordinal_position", bind.Next())
 	rows, err := db.Query(qry, name)
 	if err != nil {
 		wrapped := fferr.NewExecutionError("SQL", err)
@@ -1462,13 +1580,13 @@ func (q defaultOfflineSQLQueries) getColumns(db *sql.DB, name string) ([]TableCo
 	defer rows.Close()
 	columnNames := make([]TableColumn, 0)
 	for rows.Next() {
-		var column string
-		if err := rows.Scan(&column); err != nil {
+		var column, nativeType string
+		if err := rows.Scan(&column, &nativeType); err != nil {
 			wrapped := fferr.NewExecutionError("SQL", err)
 			wrapped.AddDetail("table_name", name)
 			return nil, wrapped
 		}
-		columnNames = append(columnNames, TableColumn{Name: column})
+		columnNames = append(columnNames, TableColumn{Name: column, NativeType: nativeType})
 	}
 	return columnNames, nil
 }
@@ -1528,13 +1646,17 @@ func (q defaultOfflineSQLQueries) dropTable(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
 
+func (q defaultOfflineSQLQueries) dropView(viewName string) string {
+	return fmt.Sprintf("DROP VIEW %s", sanitize(viewName))
+}
+
 func (q defaultOfflineSQLQueries) trainingRowSelect(columns string, trainingSetName string) string {
 	return fmt.Sprintf("SELECT %s FROM %s", columns, sanitize(trainingSetName))
 }
 
-func (q defaultOfflineSQLQueries) trainingRowSplitSelect(columns string, trainingSetSplitName string) (string, string) {
+func (q defaultOfflineSQLQueries) trainingRowSplitSelect(columns string, trainingSetSplitName string, group splitGroup) string {
 	// throw unimiplemented error
-	return "", ""
+	return ""
 }
 func (q defaultOfflineSQLQueries) getValueColumnTypes(tableName string) string {
 	return fmt.Sprintf("SELECT * FROM %s", sanitize(tableName))
@@ -1585,6 +1707,11 @@ func (q defaultOfflineSQLQueries) materializationIterateSegment(tableName string
 	return fmt.Sprintf("SELECT entity, value, ts FROM ( SELECT * FROM %s WHERE row_number>%s AND row_number<=%s)t1", sanitize(tableName), bind.Next(), bind.Next())
 }
 
+func (q defaultOfflineSQLQueries) materializationIterateSegmentSince(tableName string) string {
+	bind := q.newVariableBindingIterator()
+	return fmt.Sprintf("SELECT entity, value, ts FROM ( SELECT * FROM %s WHERE row_number>%s AND row_number<=%s AND ts>=%s)t1", sanitize(tableName), bind.Next(), bind.Next(), bind.Next())
+}
+
 func (q defaultOfflineSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
 	placeholders := make([]string, 0)
 	for _ = range columns {
@@ -1593,6 +1720,40 @@ func (q defaultOfflineSQLQueries) createValuePlaceholderString(columns []TableCo
 	return strings.Join(placeholders, ", ")
 }
 
+// trainingSetLabelSource returns what a training set query should select its label
+// rows from. If the definition has a time window or entity filter, the label table is
+// wrapped in a subquery that applies them; otherwise the quoted label table is returned.
+func (store *sqlOfflineStore) trainingSetLabelSource(def TrainingSetDef, labelName string, quote func(string) string) (string, error) {
+	if !def.hasLabelFilter() {
+		return quote(labelName), nil
+	}
+	conditions := make([]string, 0)
+	if !def.StartTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("ts >= %s", store.query.timestampLiteral(def.StartTime)))
+	}
+	if !def.EndTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("ts < %s", store.query.timestampLiteral(def.EndTime)))
+	}
+	if def.EntityFilter != nil {
+		filterTable, err := GetPrimaryTableName(def.EntityFilter.Source)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, fmt.Sprintf("entity IN (SELECT %s FROM %s)", quote(def.EntityFilter.EntityColumn), quote(filterTable)))
+	}
+	return fmt.Sprintf("(SELECT * FROM %s WHERE %s)", quote(labelName), strings.Join(conditions, " AND ")), nil
+}
+
+// lagFeatureColumnName returns the unsanitized training set column name for a
+// lag feature. If the lag isn't explicitly named, it's derived from the feature's
+// table name and the lag delta.
+func lagFeatureColumnName(tableName string, lagFeature LagFeatureDef) string {
+	if lagFeature.LagName != "" {
+		return lagFeature.LagName
+	}
+	return fmt.Sprintf("%s_lag_%s", tableName, lagFeature.LagDelta)
+}
+
 func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
 	columns := make([]string, 0)
 	query := ""
@@ -1614,10 +1775,7 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 		if err != nil {
 			return err
 		}
-		lagColumnName := sanitize(lagFeature.LagName)
-		if lagFeature.LagName == "" {
-			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", tableName, lagFeature.LagDelta))
-		}
+		lagColumnName := sanitize(lagFeatureColumnName(tableName, lagFeature))
 		columns = append(columns, lagColumnName)
 		sanitizedName := sanitize(tableName)
 		tableJoinAlias := fmt.Sprintf("t%d", lagFeaturesOffset+i+1)
@@ -1628,12 +1786,16 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 
 	query = fmt.Sprintf("%s )) WHERE rn=1", query)
 	columnStr := strings.Join(columns, ", ")
+	labelSource, err := store.trainingSetLabelSource(def, labelName, sanitize)
+	if err != nil {
+		return err
+	}
 	if !isUpdate {
 		fullQuery := fmt.Sprintf(
 			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
 				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			sanitize(tableName), columnStr, columnStr, sanitize(labelName), query)
+			sanitize(tableName), columnStr, columnStr, labelSource, query)
 		if _, err := store.db.Exec(fullQuery); err != nil {
 			wrapped := fferr.NewExecutionError("SQL", err)
 			wrapped.AddDetail("table_name", tableName)
@@ -1645,13 +1807,17 @@ func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def T
 			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
 				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
 				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			tempTable, columnStr, columnStr, sanitize(labelName), query)
+			tempTable, columnStr, columnStr, labelSource, query)
 		err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery)
 		return err
 	}
 	return nil
 }
 
+func (q defaultOfflineSQLQueries) timestampLiteral(t time.Time) string {
+	return fmt.Sprintf("'%s'", t.UTC().Format("2006-01-02 15:04:05.999999-07:00"))
+}
+
 func (q defaultOfflineSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
 	sanitizedTable := sanitize(tableName)
 	transaction := fmt.Sprintf(
@@ -303,3 +303,7 @@ func (m MockUnitTestOfflineStore) CreateTrainTestSplit(TrainTestSplitDef) (func(
 func (m MockUnitTestOfflineStore) GetTrainTestSplit(TrainTestSplitDef) (TrainingSetIterator, TrainingSetIterator, error) {
 	return nil, nil, nil
 }
+
+func (m MockUnitTestOfflineStore) GetValidationSplit(TrainTestSplitDef) (TrainingSetIterator, error) {
+	return nil, nil
+}
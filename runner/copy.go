@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/metadata"
@@ -41,6 +42,8 @@ type MaterializedChunkRunner struct {
 	Table        provider.OnlineStoreTable
 	Store        provider.OnlineStore
 	ChunkIdx     int
+	// Watermark skips rows with an event time at or before it. A zero Watermark copies every row.
+	Watermark time.Time
 }
 
 type ResultSync struct {
@@ -64,7 +67,7 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 		DoneChannel: done,
 	}
 	go func() {
-		it, err := m.Materialized.IterateChunk(m.ChunkIdx)
+		it, err := m.iterateChunk()
 		if err != nil {
 			jobWatcher.EndWatch(err)
 			return
@@ -114,7 +117,7 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 						}
 						buffer = buffer[:0]
 					} else {
-						buffer = append(buffer, provider.SetItem{record.Entity, record.Value})
+						buffer = append(buffer, provider.SetItem{Entity: record.Entity, Value: record.Value, TS: record.TS})
 					}
 				}
 				// Clear the buffer
@@ -129,10 +132,17 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 				}
 			}
 		} else {
+			tsTable, supportsTS := m.Table.(provider.TimestampedOnlineStoreTable)
 			setterFn = func() {
 				defer wg.Done()
 				for record := range ch {
-					if err := m.Table.Set(record.Entity, record.Value); err != nil {
+					var err error
+					if supportsTS && !record.TS.IsZero() {
+						err = tsTable.SetWithTimestamp(record.Entity, record.Value, record.TS)
+					} else {
+						err = m.Table.Set(record.Entity, record.Value)
+					}
+					if err != nil {
 						select {
 						case errCh <- err:
 						default:
@@ -147,9 +157,10 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 		}
 		var chanErr error
 		for it.Next() {
+			record := it.Value()
 			select {
 			case chanErr = <-errCh:
-			case ch <- it.Value():
+			case ch <- record:
 			default:
 			}
 			if chanErr != nil {
@@ -194,6 +205,19 @@ func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
 	return jobWatcher, nil
 }
 
+// iterateChunk iterates over the runner's chunk. With a watermark, the store skips the rows that were
+// already copied so that they're never read.
+func (m *MaterializedChunkRunner) iterateChunk() (provider.FeatureIterator, error) {
+	if m.Watermark.IsZero() {
+		return m.Materialized.IterateChunk(m.ChunkIdx)
+	}
+	incremental, ok := m.Materialized.(provider.IncrementalMaterialization)
+	if !ok {
+		return nil, fferr.NewInternalError(fmt.Errorf("materialization %s does not support incremental copies", m.Materialized.ID()))
+	}
+	return incremental.IterateChunkSince(m.ChunkIdx, m.Watermark)
+}
+
 func (m *MaterializedChunkRunner) SetIndex(index int) error {
 	m.ChunkIdx = index
 	return nil
@@ -264,6 +288,7 @@ type MaterializedChunkRunnerConfig struct {
 	IsUpdate       bool
 	Logger         *zap.SugaredLogger
 	SkipCache      bool
+	Watermark      time.Time
 }
 
 func (m *MaterializedChunkRunnerConfig) Serialize() (Config, error) {
@@ -349,5 +374,6 @@ func MaterializedChunkRunnerFactory(config Config) (types.Runner, error) {
 		Table:        table,
 		Store:        onlineStore,
 		ChunkIdx:     runnerConfig.ChunkIdx,
+		Watermark:    runnerConfig.Watermark,
 	}, nil
 }
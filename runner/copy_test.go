@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
@@ -428,6 +429,10 @@ func (b BrokenNumChunksOfflineStore) GetTrainTestSplit(def provider.TrainTestSpl
 	return nil, nil, fmt.Errorf("not Implemented")
 }
 
+func (b BrokenNumChunksOfflineStore) GetValidationSplit(def provider.TrainTestSplitDef) (provider.TrainingSetIterator, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
 func (b BrokenNumChunksOfflineStore) GetBatchFeatures(tables []provider.ResourceID) (provider.BatchFeatureIterator, error) {
 	return nil, nil
 }
@@ -728,6 +733,10 @@ func (m MockOfflineStore) GetTrainTestSplit(def provider.TrainTestSplitDef) (pro
 
 }
 
+func (m MockOfflineStore) GetValidationSplit(def provider.TrainTestSplitDef) (provider.TrainingSetIterator, error) {
+	return nil, fmt.Errorf("not Implemented")
+}
+
 type MockOnlineStoreTable struct{}
 
 func NewMockOnlineStore() *MockOnlineStore {
@@ -929,6 +938,42 @@ func TestChunkRunnerFactory(t *testing.T) {
 	}
 }
 
+func TestChunkRunnerWatermark(t *testing.T) {
+	watermark := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	materialization := provider.MemoryMaterialization{
+		Id: provider.MaterializationID(uuid.NewString()),
+		Data: []provider.ResourceRecord{
+			{Entity: "old", Value: 1, TS: watermark.Add(-time.Hour)},
+			{Entity: "synced", Value: 2, TS: watermark},
+			{Entity: "new", Value: 3, TS: watermark.Add(time.Hour)},
+		},
+	}
+	table := &MockOnlineTable{}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialization,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		Watermark:    watermark,
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if val, err := table.Get("new"); err != nil || val != 3 {
+		t.Fatalf("Row newer than watermark not copied: %v %v", val, err)
+	}
+	// Rows at the watermark may have arrived after the last sync, so they're copied again.
+	if val, err := table.Get("synced"); err != nil || val != 2 {
+		t.Fatalf("Row at watermark not copied: %v %v", val, err)
+	}
+	if _, err := table.Get("old"); err == nil {
+		t.Fatalf("Row before watermark was copied")
+	}
+}
+
 func TestRunnerConfigDeserializeFails(t *testing.T) {
 	failConfig := []byte("this should fail when attempted to be deserialized")
 	config := &MaterializedChunkRunnerConfig{}
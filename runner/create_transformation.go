@@ -34,6 +34,13 @@ func (c *CreateTransformationRunner) Run() (types.CompletionWatcher, error) {
 				return
 			}
 		}
+		if c.Schema != nil {
+			id := metadata.NameVariant{Name: c.TransformationConfig.TargetTableID.Name, Variant: c.TransformationConfig.TargetTableID.Variant}
+			if err := RecordSourceSchema(c.Schema, id, c.Offline); err != nil {
+				transformationWatcher.EndWatch(err)
+				return
+			}
+		}
 		transformationWatcher.EndWatch(nil)
 	}()
 	return transformationWatcher, nil
@@ -44,6 +51,8 @@ type CreateTransformationConfig struct {
 	OfflineConfig        pc.SerializedConfig
 	TransformationConfig provider.TransformationConfig
 	IsUpdate             bool
+	// MetadataAddress is where the transformation's schema is checked and recorded after every run.
+	MetadataAddress string
 }
 
 func (c *CreateTransformationConfig) Serialize() (Config, error) {
@@ -66,6 +75,8 @@ type CreateTransformationRunner struct {
 	Offline              provider.OfflineStore
 	TransformationConfig provider.TransformationConfig
 	IsUpdate             bool
+	// Schema is nil if the transformation's schema isn't checked.
+	Schema SourceSchemaClient
 }
 
 func (c CreateTransformationRunner) Resource() metadata.ResourceID {
@@ -97,10 +108,15 @@ func CreateTransformationRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	schema, err := newSourceSchemaClient(transformationConfig.MetadataAddress)
+	if err != nil {
+		return nil, err
+	}
 	return &CreateTransformationRunner{
 		Offline:              offlineStore,
 		TransformationConfig: transformationConfig.TransformationConfig,
 		IsUpdate:             transformationConfig.IsUpdate,
+		Schema:               schema,
 	}, nil
 
 }
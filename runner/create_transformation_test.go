@@ -46,6 +46,10 @@ func (m MockOfflineCreateTransformationFail) GetTrainTestSplit(provider.TrainTes
 	return nil, nil, nil
 }
 
+func (m MockOfflineCreateTransformationFail) GetValidationSplit(provider.TrainTestSplitDef) (provider.TrainingSetIterator, error) {
+	return nil, nil
+}
+
 func (m MockOfflineCreateTransformationFail) GetBatchFeatures(tables []provider.ResourceID) (provider.BatchFeatureIterator, error) {
 	return nil, nil
 }
@@ -102,6 +106,7 @@ func TestRun(t *testing.T) {
 		MockOfflineStore{},
 		provider.TransformationConfig{},
 		false,
+		nil,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
@@ -117,6 +122,7 @@ func TestFail(t *testing.T) {
 		MockOfflineCreateTransformationFail{},
 		provider.TransformationConfig{},
 		false,
+		nil,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
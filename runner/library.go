@@ -9,6 +9,7 @@ import (
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/types"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -25,12 +26,18 @@ func registerFactories() {
 	if err := RegisterFactory(CREATE_TRANSFORMATION, CreateTransformationRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register 'Create Transformation' factory: %w", err))
 	}
+	if err := RegisterFactory(REGISTER_SOURCE, RegisterSourceRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register 'Register Source' factory: %w", err))
+	}
 	if err := RegisterFactory(CREATE_TRAINING_SET, TrainingSetRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register 'Create Training Set' factory: %w", err))
 	}
 	if err := RegisterFactory(S3_IMPORT_DYNAMODB, S3ImportDynamoDBRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register S3 import to DynamoDB factory: %v", err))
 	}
+	if err := RegisterFactory(MONITOR_FEATURE, MonitorRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register 'Monitor Feature' factory: %w", err))
+	}
 }
 
 type RunnerName string
@@ -46,6 +53,7 @@ const (
 	CREATE_TRANSFORMATION RunnerName = "Create transformation"
 	MATERIALIZE           RunnerName = "Materialize"
 	S3_IMPORT_DYNAMODB    RunnerName = "S3 import to DynamoDB"
+	MONITOR_FEATURE       RunnerName = "Monitor feature"
 )
 
 type Config []byte
@@ -57,6 +65,13 @@ type RunnerConfig interface {
 
 type RunnerFactory func(config Config) (types.Runner, error)
 
+// LoggingRunner is implemented by runners that log their progress, so callers that run them in their own
+// process can collect the output of each job.
+type LoggingRunner interface {
+	types.Runner
+	SetLogger(logger *zap.SugaredLogger)
+}
+
 var factoryMap = make(map[RunnerName]RunnerFactory)
 
 // Don't use this in testing, it affects global state and can break other tests or cause race conditions.
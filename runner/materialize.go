@@ -7,6 +7,7 @@ package runner
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -40,6 +41,10 @@ type MaterializeRunner struct {
 	IsUpdate bool
 	Cloud    JobCloud
 	Logger   *zap.SugaredLogger
+	// Incremental copies only the rows at or after the watermark in Watermarks, if both are set
+	// and the materialization supports it.
+	Incremental bool
+	Watermarks  WatermarkStore
 }
 
 func (m MaterializeRunner) Resource() metadata.ResourceID {
@@ -54,6 +59,39 @@ func (m MaterializeRunner) IsUpdateJob() bool {
 	return m.IsUpdate
 }
 
+func (m *MaterializeRunner) SetLogger(logger *zap.SugaredLogger) {
+	m.Logger = logger
+}
+
+func (m *MaterializeRunner) SetWatermarkStore(store WatermarkStore) error {
+	m.Watermarks = store
+	return nil
+}
+
+// incrementalWindow returns the watermark to copy from and the watermark to record once the copy
+// succeeds. Both are zero if the runner should do a full copy without tracking a watermark. Rows at
+// the recorded watermark are copied again on the next run, since rows with the same timestamp can
+// arrive after it's recorded.
+func (m MaterializeRunner) incrementalWindow(materialization provider.Materialization) (time.Time, time.Time, error) {
+	if !m.Incremental || m.Watermarks == nil {
+		return time.Time{}, time.Time{}, nil
+	}
+	incremental, ok := materialization.(provider.IncrementalMaterialization)
+	if !ok {
+		m.Logger.Infow("Materialization does not support incremental copies, copying all rows", "name", m.ID.Name, "variant", m.ID.Variant)
+		return time.Time{}, time.Time{}, nil
+	}
+	from, err := m.Watermarks.GetWatermark(m.Resource())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := incremental.MaxTimestamp()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
 type WatcherMultiplex struct {
 	CompletionList []types.CompletionWatcher
 }
@@ -146,6 +184,15 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		// Otherwise it was an exists error, but was an update, so should be ignored.
 	}
 
+	from, to, err := m.incrementalWindow(materialization)
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() && !to.After(from) {
+		m.Logger.Infow("No rows newer than watermark, skipping copy", "name", m.ID.Name, "variant", m.ID.Variant, "watermark", from)
+		return completedWatcher(), nil
+	}
+
 	m.Logger.Infow("Getting number of chunks", "name", m.ID.Name, "variant", m.ID.Variant)
 	numChunks, err := materialization.NumChunks()
 	if err != nil {
@@ -160,6 +207,7 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 		MaterializedID: materialization.ID(),
 		ResourceID:     m.ID,
 		Logger:         m.Logger,
+		Watermark:      from,
 	}
 	var cloudWatcher types.CompletionWatcher
 	switch m.Cloud {
@@ -219,6 +267,13 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 			materializeWatcher.EndWatch(err)
 			return
 		}
+		if !to.IsZero() {
+			m.Logger.Infow("Updating watermark", "name", m.ID.Name, "variant", m.ID.Variant, "watermark", to)
+			if err := m.Watermarks.SetWatermark(m.Resource(), to); err != nil {
+				materializeWatcher.EndWatch(err)
+				return
+			}
+		}
 		materializeWatcher.EndWatch(nil)
 	}()
 	return materializeWatcher, nil
@@ -226,15 +281,20 @@ func (m MaterializeRunner) MaterializeToOnline(materialization provider.Material
 
 func (m MaterializeRunner) handleNoOnlineStore() (types.CompletionWatcher, error) {
 	m.Logger.Infow("No Online Store, skipping materialization", "name", m.ID.Name, "variant", m.ID.Variant)
+	return completedWatcher(), nil
+}
+
+// completedWatcher returns a watcher for a job that had nothing to do.
+func completedWatcher() types.CompletionWatcher {
 	done := make(chan interface{})
-	materializeWatcher := &SyncWatcher{
+	watcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
 	go func() {
-		materializeWatcher.EndWatch(nil)
+		watcher.EndWatch(nil)
 	}()
-	return materializeWatcher, nil
+	return watcher
 }
 
 type MaterializedRunnerConfig struct {
@@ -246,6 +306,7 @@ type MaterializedRunnerConfig struct {
 	VType         vt.ValueTypeJSONWrapper
 	Cloud         JobCloud
 	IsUpdate      bool
+	Incremental   bool
 }
 
 func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
@@ -289,12 +350,13 @@ func MaterializeRunnerFactory(config Config) (types.Runner, error) {
 		return nil, err
 	}
 	return &MaterializeRunner{
-		Online:   onlineStore, // This can be nil if onlineProvider is nil
-		Offline:  offlineStore,
-		ID:       runnerConfig.ResourceID,
-		VType:    runnerConfig.VType.ValueType,
-		IsUpdate: runnerConfig.IsUpdate,
-		Cloud:    runnerConfig.Cloud,
-		Logger:   logging.NewLogger("materializer").SugaredLogger,
+		Online:      onlineStore, // This can be nil if onlineProvider is nil
+		Offline:     offlineStore,
+		ID:          runnerConfig.ResourceID,
+		VType:       runnerConfig.VType.ValueType,
+		IsUpdate:    runnerConfig.IsUpdate,
+		Cloud:       runnerConfig.Cloud,
+		Logger:      logging.NewLogger("materializer").SugaredLogger,
+		Incremental: runnerConfig.Incremental,
 	}, nil
 }
@@ -7,6 +7,7 @@ package runner
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
@@ -111,6 +112,61 @@ func createMaterialization(
 	return id, mat
 }
 
+type memoryWatermarkStore map[metadata.ResourceID]time.Time
+
+func (s memoryWatermarkStore) GetWatermark(id metadata.ResourceID) (time.Time, error) {
+	return s[id], nil
+}
+
+func (s memoryWatermarkStore) SetWatermark(id metadata.ResourceID, ts time.Time) error {
+	s[id] = ts
+	return nil
+}
+
+func TestMaterializeRunnerIncrementalWindow(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	watermark := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	latest := watermark.Add(time.Hour)
+	mat := &provider.MemoryMaterialization{
+		Data: []provider.ResourceRecord{
+			{Entity: "a", Value: 1, TS: watermark},
+			{Entity: "b", Value: 2, TS: latest},
+		},
+	}
+	store := memoryWatermarkStore{}
+	job := &MaterializeRunner{ID: id, Logger: logger, Incremental: true}
+	if err := job.SetWatermarkStore(store); err != nil {
+		t.Fatalf("Failed to set watermark store: %v", err)
+	}
+
+	from, to, err := job.incrementalWindow(mat)
+	if err != nil {
+		t.Fatalf("Failed to get window: %v", err)
+	}
+	if !from.IsZero() || !to.Equal(latest) {
+		t.Fatalf("Wrong window without a watermark: %v %v", from, to)
+	}
+
+	store[job.Resource()] = watermark
+	from, to, err = job.incrementalWindow(mat)
+	if err != nil {
+		t.Fatalf("Failed to get window: %v", err)
+	}
+	if !from.Equal(watermark) || !to.Equal(latest) {
+		t.Fatalf("Wrong window with a watermark: %v %v", from, to)
+	}
+
+	job.Incremental = false
+	from, to, err = job.incrementalWindow(mat)
+	if err != nil {
+		t.Fatalf("Failed to get window: %v", err)
+	}
+	if !from.IsZero() || !to.IsZero() {
+		t.Fatalf("Non-incremental runner should do a full copy: %v %v", from, to)
+	}
+}
+
 type mockChunkRunner struct{}
 
 func (m mockChunkRunner) Run() (types.CompletionWatcher, error) {
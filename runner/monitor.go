@@ -0,0 +1,234 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/types"
+)
+
+// StatsStore persists the statistics computed by the last monitoring run of each feature variant.
+type StatsStore interface {
+	// GetStats returns false if the feature hasn't been monitored yet.
+	GetStats(id metadata.ResourceID) (FeatureStats, bool, error)
+	SetStats(id metadata.ResourceID, stats FeatureStats) error
+}
+
+// StatsRunner is implemented by runners that need somewhere to store feature statistics.
+type StatsRunner interface {
+	types.Runner
+	SetStatsStore(store StatsStore) error
+}
+
+// StatusSetter updates the status of a resource in metadata. It's implemented by metadata.Client.
+type StatusSetter interface {
+	SetStatus(ctx context.Context, resID metadata.ResourceID, status metadata.ResourceStatus, errorMessage string) error
+}
+
+// EtcdStatsStore stores feature statistics in the metadata store next to the resource's coordinator job.
+type EtcdStatsStore struct {
+	Storage metadata.KVStorage
+}
+
+func (s EtcdStatsStore) GetStats(id metadata.ResourceID) (FeatureStats, bool, error) {
+	key := metadata.GetStatsKey(id)
+	val, err := s.Storage.Get(key)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return FeatureStats{}, false, nil
+	} else if err != nil {
+		return FeatureStats{}, false, err
+	}
+	stats := FeatureStats{}
+	if err := json.Unmarshal(val, &stats); err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return FeatureStats{}, false, wrapped
+	}
+	return stats, true, nil
+}
+
+func (s EtcdStatsStore) SetStats(id metadata.ResourceID, stats FeatureStats) error {
+	serialized, err := json.Marshal(stats)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return s.Storage.Put(metadata.GetStatsKey(id), string(serialized))
+}
+
+// MonitorRunner computes statistics over a feature's materialization and compares them to the
+// previous run's. If the drift score passes the threshold, the feature variant's status is set
+// to WARNING. It goes back to READY once a later run no longer detects drift.
+type MonitorRunner struct {
+	Offline        provider.OfflineStore
+	ID             provider.ResourceID
+	DriftMetric    DriftMetric
+	DriftThreshold float64
+	Stats          StatsStore
+	Status         StatusSetter
+	Logger         *zap.SugaredLogger
+}
+
+func (m *MonitorRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    m.ID.Name,
+		Variant: m.ID.Variant,
+		Type:    provider.ProviderToMetadataResourceType[m.ID.Type],
+	}
+}
+
+func (m *MonitorRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (m *MonitorRunner) SetLogger(logger *zap.SugaredLogger) {
+	m.Logger = logger
+}
+
+func (m *MonitorRunner) SetStatsStore(store StatsStore) error {
+	m.Stats = store
+	return nil
+}
+
+func (m *MonitorRunner) threshold() float64 {
+	if m.DriftThreshold > 0 {
+		return m.DriftThreshold
+	}
+	return m.DriftMetric.defaultThreshold()
+}
+
+func (m *MonitorRunner) Run() (types.CompletionWatcher, error) {
+	if m.Stats == nil {
+		return nil, fferr.NewInternalError(fmt.Errorf("monitor runner has no stats store"))
+	}
+	done := make(chan interface{})
+	watcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		watcher.EndWatch(m.monitor())
+	}()
+	return watcher, nil
+}
+
+func (m *MonitorRunner) monitor() error {
+	m.Logger.Infow("Computing feature statistics", "name", m.ID.Name, "variant", m.ID.Variant)
+	mat, err := m.Offline.GetMaterialization(provider.MaterializationIDForResource(m.Offline, m.ID))
+	if err != nil {
+		return err
+	}
+	previous, hasPrevious, err := m.Stats.GetStats(m.Resource())
+	if err != nil {
+		return err
+	}
+	var prevPtr *FeatureStats
+	if hasPrevious {
+		prevPtr = &previous
+	}
+	stats, err := computeStats(mat, prevPtr)
+	if err != nil {
+		return err
+	}
+	stats.Computed = time.Now().UTC()
+	stats.DriftMetric = m.DriftMetric
+	if hasPrevious && previous.Numeric && stats.Numeric {
+		stats.DriftScore, err = driftScore(m.DriftMetric, previous.Histogram, stats.Histogram)
+		if err != nil {
+			return err
+		}
+		stats.Drifted = stats.DriftScore > m.threshold()
+	}
+	m.Logger.Infow("Computed feature statistics", "name", m.ID.Name, "variant", m.ID.Variant, "drift_score", stats.DriftScore, "drifted", stats.Drifted)
+	if err := m.Stats.SetStats(m.Resource(), stats); err != nil {
+		return err
+	}
+	if m.Status == nil {
+		return nil
+	}
+	if stats.Drifted {
+		msg := fmt.Sprintf("%s drift score %.4f exceeds threshold %.4f", m.DriftMetric, stats.DriftScore, m.threshold())
+		return m.Status.SetStatus(context.Background(), m.Resource(), metadata.WARNING, msg)
+	}
+	if previous.Drifted {
+		return m.Status.SetStatus(context.Background(), m.Resource(), metadata.READY, "")
+	}
+	return nil
+}
+
+type MonitorRunnerConfig struct {
+	OfflineType     pt.Type
+	OfflineConfig   pc.SerializedConfig
+	ResourceID      provider.ResourceID
+	DriftMetric     DriftMetric
+	DriftThreshold  float64
+	MetadataAddress string
+}
+
+func (m *MonitorRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(m)
+	if err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return config, nil
+}
+
+func (m *MonitorRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, m)
+	if err != nil {
+		return fferr.NewInternalError(err)
+	}
+	return nil
+}
+
+func MonitorRunnerFactory(config Config) (types.Runner, error) {
+	runnerConfig := &MonitorRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, err
+	}
+	switch runnerConfig.DriftMetric {
+	case "":
+		runnerConfig.DriftMetric = PSIDrift
+	case PSIDrift, KSDrift:
+	default:
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("unknown drift metric: %s", runnerConfig.DriftMetric))
+	}
+	offlineProvider, err := provider.Get(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	if err != nil {
+		return nil, err
+	}
+	offlineStore, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, err
+	}
+	logger := logging.NewLogger("monitor")
+	var status StatusSetter
+	if runnerConfig.MetadataAddress != "" {
+		client, err := metadata.NewClient(runnerConfig.MetadataAddress, logger)
+		if err != nil {
+			return nil, err
+		}
+		status = client
+	}
+	return &MonitorRunner{
+		Offline:        offlineStore,
+		ID:             runnerConfig.ResourceID,
+		DriftMetric:    runnerConfig.DriftMetric,
+		DriftThreshold: runnerConfig.DriftThreshold,
+		Status:         status,
+		Logger:         logger.SugaredLogger,
+	}, nil
+}
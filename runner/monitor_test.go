@@ -0,0 +1,177 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type memoryStatsStore map[metadata.ResourceID]FeatureStats
+
+func (s memoryStatsStore) GetStats(id metadata.ResourceID) (FeatureStats, bool, error) {
+	stats, has := s[id]
+	return stats, has, nil
+}
+
+func (s memoryStatsStore) SetStats(id metadata.ResourceID, stats FeatureStats) error {
+	s[id] = stats
+	return nil
+}
+
+type mockStatusSetter struct {
+	statuses []metadata.ResourceStatus
+}
+
+func (s *mockStatusSetter) SetStatus(ctx context.Context, id metadata.ResourceID, status metadata.ResourceStatus, msg string) error {
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+type monitorOfflineStore struct {
+	provider.OfflineStore
+	mat provider.Materialization
+}
+
+func (store monitorOfflineStore) Type() pt.Type {
+	return pt.MemoryOffline
+}
+
+func (store monitorOfflineStore) GetMaterialization(id provider.MaterializationID) (provider.Materialization, error) {
+	return store.mat, nil
+}
+
+func monitorMaterialization(values []interface{}) provider.Materialization {
+	records := make([]provider.ResourceRecord, len(values))
+	for i, val := range values {
+		records[i] = provider.ResourceRecord{Entity: strconv.Itoa(i), Value: val}
+	}
+	// A small chunk size makes sure that stats are combined across chunks.
+	return &provider.MemoryMaterialization{Data: records, RowsPerChunk: 7}
+}
+
+func TestComputeStats(t *testing.T) {
+	values := []interface{}{nil}
+	for i := 0; i < 20; i++ {
+		values = append(values, float32(i%10))
+	}
+	stats, err := computeStats(monitorMaterialization(values), nil)
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %s", err)
+	}
+	if stats.Count != 21 || stats.NullCount != 1 {
+		t.Fatalf("Expected 21 values with 1 null, got %d with %d", stats.Count, stats.NullCount)
+	}
+	if !stats.Numeric || stats.Min != 0 || stats.Max != 9 || stats.Mean != 4.5 {
+		t.Fatalf("Wrong numeric stats: %+v", stats)
+	}
+	if stats.DistinctCount != 10 {
+		t.Fatalf("Expected 10 distinct values, got %d", stats.DistinctCount)
+	}
+	var total int64
+	for _, bucket := range stats.Histogram {
+		total += bucket.Count
+	}
+	if len(stats.Histogram) != defaultHistogramBuckets || total != 20 {
+		t.Fatalf("Expected %d buckets holding 20 values: %+v", defaultHistogramBuckets, stats.Histogram)
+	}
+
+	strStats, err := computeStats(monitorMaterialization([]interface{}{"a", "b", "a"}), nil)
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %s", err)
+	}
+	if strStats.Numeric || strStats.Histogram != nil || strStats.DistinctCount != 2 {
+		t.Fatalf("Wrong string stats: %+v", strStats)
+	}
+}
+
+func TestDriftScore(t *testing.T) {
+	expected := []HistogramBucket{{Count: 50}, {Count: 50}}
+	same := []HistogramBucket{{Count: 5}, {Count: 5}}
+	shifted := []HistogramBucket{{Count: 10}, {Count: 90}}
+	for _, metric := range []DriftMetric{PSIDrift, KSDrift} {
+		score, err := driftScore(metric, expected, same)
+		if err != nil {
+			t.Fatalf("Failed to compute %s: %s", metric, err)
+		}
+		if score > 1e-9 {
+			t.Fatalf("Expected no %s drift for identical distributions, got %f", metric, score)
+		}
+		score, err = driftScore(metric, expected, shifted)
+		if err != nil {
+			t.Fatalf("Failed to compute %s: %s", metric, err)
+		}
+		if score <= metric.defaultThreshold() {
+			t.Fatalf("Expected %s drift above %f, got %f", metric, metric.defaultThreshold(), score)
+		}
+	}
+	ks, _ := driftScore(KSDrift, expected, shifted)
+	if math.Abs(ks-0.4) > 1e-9 {
+		t.Fatalf("Expected KS of 0.4, got %f", ks)
+	}
+	if _, err := driftScore(PSIDrift, expected, shifted[:1]); err == nil {
+		t.Fatalf("Expected error comparing histograms with different buckets")
+	}
+}
+
+func TestMonitorRunnerDrift(t *testing.T) {
+	id := provider.ResourceID{Name: "feature", Variant: "v1", Type: provider.Feature}
+	stats := make(memoryStatsStore)
+	status := &mockStatusSetter{}
+	offline := &monitorOfflineStore{}
+	runner := &MonitorRunner{
+		Offline:     offline,
+		ID:          id,
+		DriftMetric: PSIDrift,
+		Stats:       stats,
+		Status:      status,
+		Logger:      zaptest.NewLogger(t).Sugar(),
+	}
+	run := func(values []interface{}) FeatureStats {
+		offline.mat = monitorMaterialization(values)
+		watcher, err := runner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Run failed: %s", err)
+		}
+		return stats[runner.Resource()]
+	}
+	uniform := make([]interface{}, 100)
+	skewed := make([]interface{}, 100)
+	for i := range uniform {
+		uniform[i] = float64(i)
+		skewed[i] = float64(i * i / 100)
+	}
+
+	if first := run(uniform); first.Drifted || first.DriftScore != 0 {
+		t.Fatalf("First run should not detect drift: %+v", first)
+	}
+	if second := run(uniform); second.Drifted {
+		t.Fatalf("Unchanged values should not drift: %+v", second)
+	}
+	if len(status.statuses) != 0 {
+		t.Fatalf("Status should not change without drift: %v", status.statuses)
+	}
+	if third := run(skewed); !third.Drifted {
+		t.Fatalf("Skewed values should drift: %+v", third)
+	}
+	if fourth := run(skewed); fourth.Drifted {
+		t.Fatalf("Unchanged values should not drift: %+v", fourth)
+	}
+	expected := []metadata.ResourceStatus{metadata.WARNING, metadata.READY}
+	if len(status.statuses) != len(expected) || status.statuses[0] != expected[0] || status.statuses[1] != expected[1] {
+		t.Fatalf("Expected statuses %v, got %v", expected, status.statuses)
+	}
+}
@@ -26,6 +26,13 @@ func (m *RegisterSourceRunner) Run() (types.CompletionWatcher, error) {
 			registerFileWatcher.EndWatch(err)
 			return
 		}
+		if m.Schema != nil {
+			id := metadata.NameVariant{Name: m.ResourceID.Name, Variant: m.ResourceID.Variant}
+			if err := RecordSourceSchema(m.Schema, id, m.Offline); err != nil {
+				registerFileWatcher.EndWatch(err)
+				return
+			}
+		}
 		registerFileWatcher.EndWatch(nil)
 	}()
 	return registerFileWatcher, nil
@@ -36,12 +43,16 @@ type RegisterSourceConfig struct {
 	OfflineConfig   pc.SerializedConfig
 	ResourceID      provider.ResourceID
 	SourceTableName string
+	// MetadataAddress is where the source's schema is checked and recorded after every run.
+	MetadataAddress string
 }
 
 type RegisterSourceRunner struct {
 	Offline         provider.OfflineStore
 	ResourceID      provider.ResourceID
 	SourceTableName string
+	// Schema is nil if the source's schema isn't checked.
+	Schema SourceSchemaClient
 }
 
 func (r RegisterSourceRunner) Resource() metadata.ResourceID {
@@ -85,10 +96,15 @@ func RegisterSourceRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	schema, err := newSourceSchemaClient(registerConfig.MetadataAddress)
+	if err != nil {
+		return nil, err
+	}
 	return &RegisterSourceRunner{
 		Offline:         offlineStore,
 		ResourceID:      registerConfig.ResourceID,
 		SourceTableName: registerConfig.SourceTableName,
+		Schema:          schema,
 	}, nil
 
 }
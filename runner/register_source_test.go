@@ -49,6 +49,10 @@ func (m MockOfflineRegisterSourceFail) GetTrainTestSplit(provider.TrainTestSplit
 	return nil, nil, nil
 }
 
+func (m MockOfflineRegisterSourceFail) GetValidationSplit(provider.TrainTestSplitDef) (provider.TrainingSetIterator, error) {
+	return nil, nil
+}
+
 func (m MockOfflineRegisterSourceFail) CreatePrimaryTable(id provider.ResourceID, schema provider.TableSchema) (provider.PrimaryTable, error) {
 	return nil, nil
 }
@@ -101,6 +105,7 @@ func TestRunRegisterResource(t *testing.T) {
 		MockOfflineStore{},
 		provider.ResourceID{},
 		"",
+		nil,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
@@ -116,6 +121,7 @@ func TestFailRegisterResource(t *testing.T) {
 		MockOfflineRegisterSourceFail{},
 		provider.ResourceID{},
 		"",
+		nil,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
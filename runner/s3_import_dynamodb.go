@@ -59,6 +59,10 @@ func (r S3ImportDynamoDBRunner) IsUpdateJob() bool {
 	return r.IsUpdate
 }
 
+func (r *S3ImportDynamoDBRunner) SetLogger(logger *zap.SugaredLogger) {
+	r.Logger = logger
+}
+
 func (r S3ImportDynamoDBRunner) Run() (types.CompletionWatcher, error) {
 	r.Logger.Infow("Staring S3 import to DynamoDB materialization runner", "name", r.ID.Name, "variant", r.ID.Variant)
 
@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// SourceSchemaClient reads a source variant and the features and labels built on it, and records its schema.
+// It's implemented by metadata.Client.
+type SourceSchemaClient interface {
+	GetSourceVariant(ctx context.Context, id metadata.NameVariant) (*metadata.SourceVariant, error)
+	GetFeatureVariants(ctx context.Context, ids []metadata.NameVariant) ([]*metadata.FeatureVariant, error)
+	GetLabelVariants(ctx context.Context, ids []metadata.NameVariant) ([]*metadata.LabelVariant, error)
+	SetSourceSchema(ctx context.Context, id metadata.NameVariant, columns []metadata.SourceColumn) error
+}
+
+// newSourceSchemaClient connects to the metadata server at address. Source runners configured without an
+// address don't check or record schemas.
+func newSourceSchemaClient(address string) (SourceSchemaClient, error) {
+	if address == "" {
+		return nil, nil
+	}
+	client, err := metadata.NewClient(address, logging.NewLogger("source-schema"))
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// SourceTableID returns the ID that a source variant's table is registered under in its offline store.
+func SourceTableID(source *metadata.SourceVariant) provider.ResourceID {
+	id := provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Primary}
+	if source.IsSQLTransformation() || source.IsDFTransformation() {
+		id.Type = provider.Transformation
+	}
+	return id
+}
+
+// CheckSourceSchema reads the columns of a source variant's table and compares them against the schema
+// recorded the last time the source ran. It fails if a column that one of the source's features or labels
+// reads was dropped or changed type; otherwise it returns the current columns. Stores that can't read back
+// a table's columns are skipped and return no columns.
+func CheckSourceSchema(client SourceSchemaClient, source *metadata.SourceVariant, offlineStore provider.OfflineStore) ([]metadata.SourceColumn, error) {
+	schemaStore, ok := offlineStore.(provider.SourceSchemaOfflineStore)
+	if !ok {
+		return nil, nil
+	}
+	tableColumns, err := schemaStore.GetSourceSchema(SourceTableID(source))
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]metadata.SourceColumn, len(tableColumns))
+	for i, column := range tableColumns {
+		columns[i] = metadata.SourceColumn{Name: column.Name, Type: column.NativeType}
+	}
+	recorded := source.Schema()
+	if len(recorded) == 0 {
+		return columns, nil
+	}
+	dependents, err := sourceColumnDependents(client, source)
+	if err != nil {
+		return nil, err
+	}
+	if changes := incompatibleSchemaChanges(recorded, columns, dependents); len(changes) > 0 {
+		return nil, fferr.NewSourceSchemaChangedError(source.Name(), source.Variant(), changes, nil)
+	}
+	return columns, nil
+}
+
+// RecordSourceSchema checks the table of the source variant with the given ID against its recorded schema and,
+// if no dependent column was broken, records the table's current columns as the source's schema. The source
+// is read from metadata on every call so scheduled re-runs compare against the schema of the previous run.
+func RecordSourceSchema(client SourceSchemaClient, id metadata.NameVariant, offlineStore provider.OfflineStore) error {
+	source, err := client.GetSourceVariant(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	columns, err := CheckSourceSchema(client, source, offlineStore)
+	if err != nil {
+		return err
+	}
+	if columns == nil {
+		return nil
+	}
+	return client.SetSourceSchema(context.Background(), id, columns)
+}
+
+// sourceColumnDependents maps each source column that a feature or label variant reads to the variants reading it.
+func sourceColumnDependents(client SourceSchemaClient, source *metadata.SourceVariant) (map[string][]string, error) {
+	dependents := make(map[string][]string)
+	addColumns := func(resourceType string, name, variant string, location interface{}) {
+		columns, ok := location.(metadata.ResourceVariantColumns)
+		if !ok {
+			return
+		}
+		dependent := fmt.Sprintf("%s %s (%s)", resourceType, name, variant)
+		for _, column := range []string{columns.Entity, columns.Value, columns.TS} {
+			if column != "" {
+				dependents[strings.ToLower(column)] = append(dependents[strings.ToLower(column)], dependent)
+			}
+		}
+	}
+	if len(source.Features()) > 0 {
+		features, err := client.GetFeatureVariants(context.Background(), source.Features())
+		if err != nil {
+			return nil, err
+		}
+		for _, feature := range features {
+			addColumns("feature", feature.Name(), feature.Variant(), feature.LocationColumns())
+		}
+	}
+	if len(source.Labels()) > 0 {
+		labels, err := client.GetLabelVariants(context.Background(), source.Labels())
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			addColumns("label", label.Name(), label.Variant(), label.LocationColumns())
+		}
+	}
+	return dependents, nil
+}
+
+// incompatibleSchemaChanges describes every recorded column with dependents that's missing from current or
+// has a different type there. Column names are compared case-insensitively, as most offline stores do.
+func incompatibleSchemaChanges(recorded, current []metadata.SourceColumn, dependents map[string][]string) []string {
+	currentTypes := make(map[string]string, len(current))
+	for _, column := range current {
+		currentTypes[strings.ToLower(column.Name)] = column.Type
+	}
+	changes := make([]string, 0)
+	for _, column := range recorded {
+		name := strings.ToLower(column.Name)
+		users, isUsed := dependents[name]
+		if !isUsed {
+			continue
+		}
+		currentType, exists := currentTypes[name]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("column %s used by %s was dropped", column.Name, strings.Join(users, ", ")))
+		} else if !strings.EqualFold(currentType, column.Type) {
+			changes = append(changes, fmt.Sprintf("column %s used by %s changed type from %s to %s", column.Name, strings.Join(users, ", "), column.Type, currentType))
+		}
+	}
+	return changes
+}
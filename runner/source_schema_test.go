@@ -0,0 +1,259 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+// schemaOfflineStore reports columns as the schema of every table.
+type schemaOfflineStore struct {
+	MockOfflineStore
+	columns *[]provider.TableColumn
+	// readIDs holds every ID whose schema was read.
+	readIDs *[]provider.ResourceID
+}
+
+func (store schemaOfflineStore) GetSourceSchema(id provider.ResourceID) ([]provider.TableColumn, error) {
+	*store.readIDs = append(*store.readIDs, id)
+	return *store.columns, nil
+}
+
+func startSchemaMetadata(t *testing.T) *metadata.Client {
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	serv, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:          logger,
+		StorageProvider: metadata.LocalStorageProvider{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go serv.ServeOnListener(lis)
+	t.Cleanup(func() { serv.Stop() })
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create metadata client: %s", err)
+	}
+	defs := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "offline", Type: "MOCK_OFFLINE"},
+		metadata.EntityDef{Name: "user"},
+		metadata.SourceDef{
+			Name:       "transactions",
+			Variant:    "v1",
+			Owner:      "Featureform",
+			Provider:   "offline",
+			Definition: metadata.PrimaryDataSource{Location: metadata.SQLTable{Name: "transactions"}},
+		},
+		metadata.SourceDef{
+			Name:     "large_transactions",
+			Variant:  "v1",
+			Owner:    "Featureform",
+			Provider: "offline",
+			Definition: metadata.TransformationSource{TransformationType: metadata.SQLTransformationType{
+				Query:   "SELECT * FROM {{transactions.v1}} WHERE amount > 100",
+				Sources: metadata.NameVariants{{Name: "transactions", Variant: "v1"}},
+			}},
+		},
+		metadata.FeatureDef{
+			Name:     "avg_amount",
+			Variant:  "v1",
+			Provider: "offline",
+			Entity:   "user",
+			Source:   metadata.NameVariant{Name: "transactions", Variant: "v1"},
+			Owner:    "Featureform",
+			Location: metadata.ResourceVariantColumns{Entity: "user_id", Value: "amount", TS: "ts"},
+			Mode:     metadata.PRECOMPUTED,
+		},
+		metadata.FeatureDef{
+			Name:     "large_amount",
+			Variant:  "v1",
+			Provider: "offline",
+			Entity:   "user",
+			Source:   metadata.NameVariant{Name: "large_transactions", Variant: "v1"},
+			Owner:    "Featureform",
+			Location: metadata.ResourceVariantColumns{Entity: "user_id", Value: "amount"},
+			Mode:     metadata.PRECOMPUTED,
+		},
+	}
+	if err := client.CreateAll(context.Background(), defs); err != nil {
+		t.Fatalf("Failed to create metadata entries: %s", err)
+	}
+	return client
+}
+
+func runSourceRunner(t *testing.T, runner types.Runner) error {
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run source runner: %s", err)
+	}
+	return watcher.Wait()
+}
+
+func TestSourceRunnersRecordSchemaOnEveryRun(t *testing.T) {
+	client := startSchemaMetadata(t)
+	columns := []provider.TableColumn{
+		{Name: "user_id", NativeType: "VARCHAR"},
+		{Name: "amount", NativeType: "BIGINT"},
+		{Name: "ts", NativeType: "TIMESTAMP"},
+		{Name: "comment", NativeType: "VARCHAR"},
+	}
+	readIDs := make([]provider.ResourceID, 0)
+	store := schemaOfflineStore{columns: &columns, readIDs: &readIDs}
+
+	primaryID := provider.ResourceID{Name: "transactions", Variant: "v1", Type: provider.Primary}
+	register := &RegisterSourceRunner{Offline: store, ResourceID: primaryID, SourceTableName: "transactions", Schema: client}
+	if err := runSourceRunner(t, register); err != nil {
+		t.Fatalf("First run failed: %s", err)
+	}
+	expected := []metadata.SourceColumn{
+		{Name: "user_id", Type: "VARCHAR"},
+		{Name: "amount", Type: "BIGINT"},
+		{Name: "ts", Type: "TIMESTAMP"},
+		{Name: "comment", Type: "VARCHAR"},
+	}
+	source, err := client.GetSourceVariant(context.Background(), metadata.NameVariant{Name: "transactions", Variant: "v1"})
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if !reflect.DeepEqual(expected, source.Schema()) {
+		t.Fatalf("Wrong recorded schema\nExpected: %v\nFound: %v", expected, source.Schema())
+	}
+
+	// Dropping a column that nothing reads is fine.
+	columns = columns[:3]
+	if err := runSourceRunner(t, register); err != nil {
+		t.Fatalf("Run after dropping an unused column failed: %s", err)
+	}
+
+	columns = []provider.TableColumn{
+		{Name: "user_id", NativeType: "VARCHAR"},
+		{Name: "amount", NativeType: "VARCHAR"},
+		{Name: "ts", NativeType: "TIMESTAMP"},
+	}
+	err = runSourceRunner(t, register)
+	var changed *fferr.SourceSchemaChangedError
+	if !errors.As(err, &changed) {
+		t.Fatalf("Expected a SourceSchemaChangedError after retyping a used column, got %v", err)
+	}
+
+	// A scheduled update of a transformation runs the same check against its own table.
+	transformationID := provider.ResourceID{Name: "large_transactions", Variant: "v1", Type: provider.Transformation}
+	update := &CreateTransformationRunner{
+		Offline:              store,
+		TransformationConfig: provider.TransformationConfig{TargetTableID: transformationID},
+		IsUpdate:             true,
+		Schema:               client,
+	}
+	if err := runSourceRunner(t, update); err != nil {
+		t.Fatalf("First transformation run failed: %s", err)
+	}
+	columns = columns[1:]
+	if err := runSourceRunner(t, update); !errors.As(err, &changed) {
+		t.Fatalf("Expected a SourceSchemaChangedError after dropping a used column, got %v", err)
+	}
+	if last := readIDs[len(readIDs)-1]; last != transformationID {
+		t.Fatalf("Expected the transformation table's schema to be read, got %v", last)
+	}
+}
+
+func TestSourceRunnersWithoutMetadataSkipSchema(t *testing.T) {
+	columns := []provider.TableColumn{}
+	readIDs := make([]provider.ResourceID, 0)
+	store := schemaOfflineStore{columns: &columns, readIDs: &readIDs}
+	register := &RegisterSourceRunner{Offline: store, ResourceID: provider.ResourceID{Name: "source", Type: provider.Primary}}
+	if err := runSourceRunner(t, register); err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	if len(readIDs) != 0 {
+		t.Fatalf("Expected the schema not to be read without a metadata client")
+	}
+}
+
+func TestIncompatibleSchemaChanges(t *testing.T) {
+	recorded := []metadata.SourceColumn{
+		{Name: "user_id", Type: "VARCHAR"},
+		{Name: "amount", Type: "BIGINT"},
+		{Name: "ts", Type: "TIMESTAMP"},
+		{Name: "comment", Type: "VARCHAR"},
+	}
+	dependents := map[string][]string{
+		"user_id": {"feature avg_amount (v1)", "label fraud (v1)"},
+		"amount":  {"feature avg_amount (v1)"},
+		"ts":      {"feature avg_amount (v1)"},
+	}
+	type testCase struct {
+		name     string
+		current  []metadata.SourceColumn
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			name:     "Unchanged",
+			current:  recorded,
+			expected: []string{},
+		},
+		{
+			name: "Unused Column Dropped And Columns Added",
+			current: []metadata.SourceColumn{
+				{Name: "user_id", Type: "VARCHAR"},
+				{Name: "amount", Type: "BIGINT"},
+				{Name: "ts", Type: "TIMESTAMP"},
+				{Name: "country", Type: "VARCHAR"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "Different Case",
+			current: []metadata.SourceColumn{
+				{Name: "USER_ID", Type: "varchar"},
+				{Name: "AMOUNT", Type: "bigint"},
+				{Name: "TS", Type: "timestamp"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "Dropped Column",
+			current: []metadata.SourceColumn{
+				{Name: "amount", Type: "BIGINT"},
+				{Name: "ts", Type: "TIMESTAMP"},
+			},
+			expected: []string{"column user_id used by feature avg_amount (v1), label fraud (v1) was dropped"},
+		},
+		{
+			name: "Retyped Column",
+			current: []metadata.SourceColumn{
+				{Name: "user_id", Type: "VARCHAR"},
+				{Name: "amount", Type: "VARCHAR"},
+				{Name: "ts", Type: "TIMESTAMP"},
+			},
+			expected: []string{"column amount used by feature avg_amount (v1) changed type from BIGINT to VARCHAR"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changes := incompatibleSchemaChanges(recorded, tc.current, dependents)
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Fatalf("Expected changes %v, got %v", tc.expected, changes)
+			}
+		})
+	}
+}
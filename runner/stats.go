@@ -0,0 +1,270 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/provider"
+)
+
+const defaultHistogramBuckets = 10
+
+// Bucket proportions are floored to this when computing PSI so that empty buckets don't divide by zero.
+const psiEpsilon = 1e-4
+
+type DriftMetric string
+
+const (
+	PSIDrift DriftMetric = "PSI"
+	KSDrift  DriftMetric = "KS"
+)
+
+// defaultThreshold returns the commonly used cutoff for significant drift.
+func (m DriftMetric) defaultThreshold() float64 {
+	if m == KSDrift {
+		return 0.1
+	}
+	return 0.2
+}
+
+type HistogramBucket struct {
+	Lower float64
+	Upper float64
+	Count int64
+}
+
+// FeatureStats summarizes the values of a materialization. Min, Max, Mean and Histogram are only
+// set for numeric features.
+type FeatureStats struct {
+	Count         int64
+	NullCount     int64
+	NullRate      float64
+	Numeric       bool
+	Min           float64
+	Max           float64
+	Mean          float64
+	DistinctCount int64
+	Histogram     []HistogramBucket
+	// DriftScore compares Histogram to the previous run's. It's zero on the first run.
+	DriftMetric DriftMetric
+	DriftScore  float64
+	Drifted     bool
+	Computed    time.Time
+}
+
+// statsAccumulator computes everything but the histogram in a single pass over the values.
+type statsAccumulator struct {
+	count, nulls, numeric int64
+	nonNumeric            bool
+	min, max, sum         float64
+	distinct              map[string]struct{}
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{
+		min:      math.Inf(1),
+		max:      math.Inf(-1),
+		distinct: make(map[string]struct{}),
+	}
+}
+
+func (acc *statsAccumulator) add(value interface{}) {
+	acc.count++
+	if value == nil {
+		acc.nulls++
+		return
+	}
+	acc.distinct[fmt.Sprintf("%v", value)] = struct{}{}
+	num, ok := numericValue(value)
+	if !ok {
+		acc.nonNumeric = true
+		return
+	}
+	acc.numeric++
+	acc.sum += num
+	acc.min = math.Min(acc.min, num)
+	acc.max = math.Max(acc.max, num)
+}
+
+func (acc *statsAccumulator) stats() FeatureStats {
+	stats := FeatureStats{
+		Count:         acc.count,
+		NullCount:     acc.nulls,
+		DistinctCount: int64(len(acc.distinct)),
+	}
+	if acc.count > 0 {
+		stats.NullRate = float64(acc.nulls) / float64(acc.count)
+	}
+	if acc.numeric > 0 && !acc.nonNumeric {
+		stats.Numeric = true
+		stats.Min = acc.min
+		stats.Max = acc.max
+		stats.Mean = acc.sum / float64(acc.numeric)
+	}
+	return stats
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	switch casted := value.(type) {
+	case int:
+		return float64(casted), true
+	case int8:
+		return float64(casted), true
+	case int16:
+		return float64(casted), true
+	case int32:
+		return float64(casted), true
+	case int64:
+		return float64(casted), true
+	case uint:
+		return float64(casted), true
+	case uint8:
+		return float64(casted), true
+	case uint16:
+		return float64(casted), true
+	case uint32:
+		return float64(casted), true
+	case uint64:
+		return float64(casted), true
+	case float32:
+		return float64(casted), true
+	case float64:
+		return casted, true
+	default:
+		return 0, false
+	}
+}
+
+// histogramBuckets returns empty buckets to fill. The previous run's bucket edges are reused when
+// there are any so that the two histograms can be compared.
+func histogramBuckets(stats FeatureStats, previous []HistogramBucket) []HistogramBucket {
+	if len(previous) > 0 {
+		buckets := make([]HistogramBucket, len(previous))
+		for i, bucket := range previous {
+			buckets[i] = HistogramBucket{Lower: bucket.Lower, Upper: bucket.Upper}
+		}
+		return buckets
+	}
+	if stats.Min == stats.Max {
+		return []HistogramBucket{{Lower: stats.Min, Upper: stats.Max}}
+	}
+	width := (stats.Max - stats.Min) / defaultHistogramBuckets
+	buckets := make([]HistogramBucket, defaultHistogramBuckets)
+	for i := range buckets {
+		buckets[i] = HistogramBucket{Lower: stats.Min + float64(i)*width, Upper: stats.Min + float64(i+1)*width}
+	}
+	buckets[len(buckets)-1].Upper = stats.Max
+	return buckets
+}
+
+// addToHistogram counts the value in the bucket it falls in. Values outside of the buckets' range
+// are counted in the first or last bucket.
+func addToHistogram(buckets []HistogramBucket, value float64) {
+	for i := range buckets {
+		if value < buckets[i].Upper || i == len(buckets)-1 {
+			buckets[i].Count++
+			return
+		}
+	}
+}
+
+func bucketProportions(buckets []HistogramBucket) []float64 {
+	var total int64
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	proportions := make([]float64, len(buckets))
+	if total == 0 {
+		return proportions
+	}
+	for i, bucket := range buckets {
+		proportions[i] = float64(bucket.Count) / float64(total)
+	}
+	return proportions
+}
+
+// driftScore compares two histograms with the same bucket edges.
+func driftScore(metric DriftMetric, expected, actual []HistogramBucket) (float64, error) {
+	if len(expected) != len(actual) {
+		return 0, fferr.NewInternalError(fmt.Errorf("cannot compare histograms with %d and %d buckets", len(expected), len(actual)))
+	}
+	exp, act := bucketProportions(expected), bucketProportions(actual)
+	switch metric {
+	case PSIDrift, "":
+		var psi float64
+		for i := range exp {
+			e, a := math.Max(exp[i], psiEpsilon), math.Max(act[i], psiEpsilon)
+			psi += (a - e) * math.Log(a/e)
+		}
+		return psi, nil
+	case KSDrift:
+		// The KS statistic is approximated from the bucketed CDFs.
+		var expCDF, actCDF, ks float64
+		for i := range exp {
+			expCDF += exp[i]
+			actCDF += act[i]
+			ks = math.Max(ks, math.Abs(expCDF-actCDF))
+		}
+		return ks, nil
+	default:
+		return 0, fferr.NewInvalidArgumentError(fmt.Errorf("unknown drift metric: %s", metric))
+	}
+}
+
+// computeStats iterates over every chunk of the materialization, twice for numeric features since
+// the histogram's buckets depend on the range of the values.
+func computeStats(mat provider.Materialization, previous *FeatureStats) (FeatureStats, error) {
+	numChunks, err := mat.NumChunks()
+	if err != nil {
+		return FeatureStats{}, err
+	}
+	acc := newStatsAccumulator()
+	err = forEachValue(mat, numChunks, acc.add)
+	if err != nil {
+		return FeatureStats{}, err
+	}
+	stats := acc.stats()
+	if !stats.Numeric {
+		return stats, nil
+	}
+	var previousBuckets []HistogramBucket
+	if previous != nil && previous.Numeric {
+		previousBuckets = previous.Histogram
+	}
+	stats.Histogram = histogramBuckets(stats, previousBuckets)
+	err = forEachValue(mat, numChunks, func(value interface{}) {
+		if num, ok := numericValue(value); ok {
+			addToHistogram(stats.Histogram, num)
+		}
+	})
+	if err != nil {
+		return FeatureStats{}, err
+	}
+	return stats, nil
+}
+
+func forEachValue(mat provider.Materialization, numChunks int, fn func(interface{})) error {
+	for i := 0; i < numChunks; i++ {
+		it, err := mat.IterateChunk(i)
+		if err != nil {
+			return err
+		}
+		for it.Next() {
+			fn(it.Value().Value)
+		}
+		if err := it.Err(); err != nil {
+			it.Close()
+			return err
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -49,6 +49,10 @@ func (m MockOfflineCreateTrainingSetFail) GetTrainTestSplit(def provider.TrainTe
 	return nil, nil, nil
 }
 
+func (m MockOfflineCreateTrainingSetFail) GetValidationSplit(def provider.TrainTestSplitDef) (provider.TrainingSetIterator, error) {
+	return nil, nil
+}
+
 func (m MockOfflineCreateTrainingSetFail) GetBatchFeatures(ids []provider.ResourceID) (provider.BatchFeatureIterator, error) {
 	return nil, nil
 }
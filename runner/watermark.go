@@ -0,0 +1,52 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	"github.com/featureform/types"
+)
+
+// WatermarkStore persists the latest event time that a materialization has copied to its
+// online store. GetWatermark returns the zero time if nothing has been copied yet.
+type WatermarkStore interface {
+	GetWatermark(id metadata.ResourceID) (time.Time, error)
+	SetWatermark(id metadata.ResourceID, ts time.Time) error
+}
+
+// WatermarkRunner is implemented by runners that can copy incrementally from a watermark.
+type WatermarkRunner interface {
+	types.Runner
+	SetWatermarkStore(store WatermarkStore) error
+}
+
+// EtcdWatermarkStore stores watermarks in the metadata store next to the resource's coordinator job.
+type EtcdWatermarkStore struct {
+	Storage metadata.KVStorage
+}
+
+func (s EtcdWatermarkStore) GetWatermark(id metadata.ResourceID) (time.Time, error) {
+	key := metadata.GetWatermarkKey(id)
+	val, err := s.Storage.Get(key)
+	if _, isNotFound := err.(*fferr.KeyNotFoundError); isNotFound {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := time.Parse(time.RFC3339Nano, string(val))
+	if err != nil {
+		wrapped := fferr.NewInternalError(err)
+		wrapped.AddDetail("key", key)
+		return time.Time{}, wrapped
+	}
+	return ts, nil
+}
+
+func (s EtcdWatermarkStore) SetWatermark(id metadata.ResourceID, ts time.Time) error {
+	return s.Storage.Put(metadata.GetWatermarkKey(id), ts.UTC().Format(time.RFC3339Nano))
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/featureform/coordinator"
+	"github.com/featureform/metadata"
 	"github.com/featureform/runner"
 	"github.com/google/uuid"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -43,12 +44,35 @@ func CreateAndRun() error {
 		return err
 	}
 	logger.Infof("Starting job for resource: %v", jobRunner.Resource())
-	if jobRunner.IsUpdateJob() {
-		logger.Info("This is an update job")
+	var cli *clientv3.Client
+	statsRunner, isStatsRunner := jobRunner.(runner.StatsRunner)
+	if jobRunner.IsUpdateJob() || isStatsRunner {
+		logger.Info("This job needs etcd")
 		etcdConf, ok = os.LookupEnv("ETCD_CONFIG")
 		if !ok {
 			return errors.New("ETCD_CONFIG not set")
 		}
+		etcdConfig := &coordinator.ETCDConfig{}
+		err := etcdConfig.Deserialize(coordinator.Config(etcdConf))
+		if err != nil {
+			return err
+		}
+		cli, err = clientv3.New(clientv3.Config{Endpoints: etcdConfig.Endpoints, Username: etcdConfig.Username, Password: etcdConfig.Password, DialTimeout: time.Second * 5})
+		if err != nil {
+			return err
+		}
+		if watermarkRunner, isWatermarkRunner := jobRunner.(runner.WatermarkRunner); isWatermarkRunner {
+			store := runner.EtcdWatermarkStore{Storage: metadata.EtcdStorage{Client: cli}}
+			if err := watermarkRunner.SetWatermarkStore(store); err != nil {
+				return err
+			}
+		}
+		if isStatsRunner {
+			store := runner.EtcdStatsStore{Storage: metadata.EtcdStorage{Client: cli}}
+			if err := statsRunner.SetStatsStore(store); err != nil {
+				return err
+			}
+		}
 	}
 	indexString, hasIndexEnv := os.LookupEnv("JOB_COMPLETION_INDEX")
 	indexRunner, isIndexRunner := jobRunner.(runner.IndexRunner)
@@ -79,15 +103,6 @@ func CreateAndRun() error {
 	if jobRunner.IsUpdateJob() {
 		jobResource := jobRunner.Resource()
 		logger.Infof("Logging update success in etcd for job: %v", jobResource)
-		etcdConfig := &coordinator.ETCDConfig{}
-		err := etcdConfig.Deserialize(coordinator.Config(etcdConf))
-		if err != nil {
-			return err
-		}
-		cli, err := clientv3.New(clientv3.Config{Endpoints: etcdConfig.Endpoints, Username: etcdConfig.Username, Password: etcdConfig.Password, DialTimeout: time.Second * 5})
-		if err != nil {
-			return err
-		}
 		resourceID := jobRunner.Resource()
 		timeCompleted := time.Now()
 		updatedEvent := &coordinator.ResourceUpdatedEvent{
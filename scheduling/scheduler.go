@@ -0,0 +1,364 @@
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/featureform/logging"
+	sp "github.com/featureform/scheduling/storage_providers"
+)
+
+// Executor does the work of a task run. Execute must return once ctx is done; the scheduler cancels
+// it when the run is cancelled or its attempt times out.
+type Executor interface {
+	Execute(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error
+}
+
+type runKey struct {
+	taskID TaskID
+	runID  TaskRunID
+}
+
+// execution is an attempt at a run that's being executed by this scheduler.
+type execution struct {
+	cancel context.CancelFunc
+}
+
+// Scheduler periodically checks the triggers stored by the TaskManager and creates a task run
+// for each one that has fired. Triggers live in the TaskManager's storage, so they survive
+// restarts and are shared by every scheduler using the same storage.
+//
+// If the scheduler has an Executor, it also executes the latest run of each task with a Job once
+// it's due, including runs that failed and are waiting to be retried, and stops executions whose
+// run is cancelled or times out.
+type Scheduler struct {
+	manager  *TaskManager
+	executor Executor
+	interval time.Duration
+	logger   logging.Logger
+	mu       sync.Mutex
+	running  map[runKey]*execution
+	// ticking makes concurrent calls to Tick run one at a time, so a trigger doesn't fire twice.
+	ticking sync.Mutex
+}
+
+// NewScheduler creates a scheduler. The executor can be nil if runs are executed elsewhere.
+func NewScheduler(manager *TaskManager, executor Executor, interval time.Duration, logger logging.Logger) *Scheduler {
+	return &Scheduler{
+		manager:  manager,
+		executor: executor,
+		interval: interval,
+		logger:   logger,
+		running:  make(map[runKey]*execution),
+	}
+}
+
+// Tasks returns the TaskManager the scheduler stores triggers and runs in.
+func (s *Scheduler) Tasks() *TaskManager {
+	return s.manager
+}
+
+// AddTrigger stores a ScheduleTrigger or DependencyTrigger for a task, replacing the task's
+// trigger with the same name.
+func (s *Scheduler) AddTrigger(taskID TaskID, trigger Trigger) error {
+	if _, err := s.manager.GetTaskByID(taskID); err != nil {
+		return err
+	}
+	switch casted := trigger.(type) {
+	case ScheduleTrigger:
+		if _, err := casted.Next(time.Now()); err != nil {
+			return err
+		}
+	case DependencyTrigger:
+		if len(casted.Upstream) == 0 {
+			return fmt.Errorf("dependency trigger %s has no upstream tasks", casted.Name())
+		}
+		for _, upstream := range casted.Upstream {
+			if upstream == taskID {
+				return fmt.Errorf("task %d cannot depend on itself", taskID)
+			}
+			if _, err := s.manager.GetTaskByID(upstream); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("trigger type %s cannot be scheduled", trigger.Type())
+	}
+	_, err := s.manager.SetTrigger(taskID, trigger)
+	return err
+}
+
+// Start runs Tick every interval until the context is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Tick(now.UTC()); err != nil {
+				s.logger.Errorw("Failed to schedule task runs", "error", err)
+			}
+		}
+	}
+}
+
+// Tick fails runs whose attempt has timed out, executes runs that are due, and creates a task
+// run for each trigger that has fired as of now. A failing run or trigger doesn't stop the
+// others from being checked; all errors are returned together. It's safe to call while the
+// scheduler is started, to check on runs sooner.
+func (s *Scheduler) Tick(now time.Time) error {
+	s.ticking.Lock()
+	defer s.ticking.Unlock()
+	var errs []error
+	if err := s.checkRuns(now); err != nil {
+		errs = append(errs, err)
+	}
+
+	triggers, err := s.manager.GetAllTriggers()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, scheduled := range triggers {
+		if err := s.runIfFired(scheduled, now); err != nil {
+			errs = append(errs, fmt.Errorf("task %d trigger %s: %w", scheduled.TaskID, scheduled.Trigger.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) runIfFired(scheduled TaskTrigger, now time.Time) error {
+	lastRun, hasRun, err := s.manager.GetLatestRun(scheduled.TaskID)
+	if err != nil {
+		return err
+	}
+	// Don't start another run while the previous one is still going or waiting to be retried.
+	if hasRun && !lastRun.Status.IsFinished() {
+		return nil
+	}
+	var fired bool
+	switch trigger := scheduled.Trigger.(type) {
+	case ScheduleTrigger:
+		since := scheduled.Added
+		if hasRun {
+			since = lastRun.StartTime
+		}
+		next, err := trigger.Next(since)
+		if err != nil {
+			return err
+		}
+		fired = !next.After(now)
+	case DependencyTrigger:
+		since := scheduled.Added
+		if hasRun {
+			since = lastRun.StartTime
+		}
+		fired, err = s.upstreamSucceededSince(trigger.Upstream, since)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("trigger type %s cannot be scheduled", scheduled.Trigger.Type())
+	}
+	if !fired {
+		return nil
+	}
+	task, err := s.manager.GetTaskByID(scheduled.TaskID)
+	if err != nil {
+		return err
+	}
+	run, err := s.manager.CreateTaskRun(task.Name, scheduled.TaskID, scheduled.Trigger)
+	if err != nil {
+		return err
+	}
+	s.logger.Infow("Created task run", "task", scheduled.TaskID, "run", run.ID, "trigger", scheduled.Trigger.Name())
+	return nil
+}
+
+// checkRuns looks at the latest run of each task. Only the latest run can be unfinished, since
+// triggers don't fire while it is.
+func (s *Scheduler) checkRuns(now time.Time) error {
+	tasks, err := s.manager.GetAllTasks()
+	if _, notFound := err.(*sp.KeyNotFoundError); notFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var errs []error
+	for _, task := range tasks {
+		run, hasRun, err := s.manager.GetLatestRun(task.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", task.ID, err))
+			continue
+		}
+		if !hasRun {
+			continue
+		}
+		switch {
+		case run.TimedOut(now):
+			s.stop(run)
+			err = s.failTimedOutRun(run)
+		case run.Status == Cancelled:
+			s.stop(run)
+		case s.executor != nil && len(task.Job) > 0 && run.Due(now):
+			err = s.execute(task, run, now)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d run %d: %w", task.ID, run.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// execute starts an attempt at a run that's due. The run is set to Running before the executor
+// starts, so other schedulers sharing the storage don't execute it too.
+func (s *Scheduler) execute(task TaskMetadata, run TaskRunMetadata, now time.Time) error {
+	key := runKey{taskID: run.TaskId, runID: run.ID}
+	s.mu.Lock()
+	_, isRunning := s.running[key]
+	s.mu.Unlock()
+	if isRunning {
+		return nil
+	}
+
+	lock, err := s.manager.LockTaskRun(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlock(run, lock)
+	run, err = s.manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+	if !run.Due(now) {
+		return nil
+	}
+	if err := s.manager.SetRunStatus(run.ID, run.TaskId, Running, nil, lock); err != nil {
+		return err
+	}
+	run, err = s.manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := &execution{cancel: cancel}
+	s.mu.Lock()
+	s.running[key] = exec
+	s.mu.Unlock()
+	s.logger.Infow("Executing task run", "task", run.TaskId, "run", run.ID, "attempt", len(run.Attempts))
+	go func() {
+		err := s.executor.Execute(ctx, task, run)
+		s.mu.Lock()
+		if s.running[key] == exec {
+			delete(s.running, key)
+		}
+		s.mu.Unlock()
+		// A cancelled context means the run was cancelled or timed out, and its status was already set.
+		if ctx.Err() != nil {
+			return
+		}
+		cancel()
+		if err := s.finish(run, err); err != nil {
+			s.logger.Errorw("Failed to set task run status", "task", run.TaskId, "run", run.ID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// finish records the result of an attempt. A failed attempt goes back to Pending if the task's
+// retry policy allows another one.
+func (s *Scheduler) finish(run TaskRunMetadata, runErr error) error {
+	lock, err := s.manager.LockTaskRun(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlock(run, lock)
+	current, err := s.manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+	// The run was cancelled or timed out by another scheduler while it was being executed.
+	if current.Status != Running {
+		return nil
+	}
+	status := Success
+	if runErr != nil {
+		status = Failed
+		s.logger.Infow("Task run attempt failed", "task", run.TaskId, "run", run.ID, "error", runErr)
+	}
+	return s.manager.SetRunStatus(run.ID, run.TaskId, status, runErr, lock)
+}
+
+// CancelRun cancels a run that hasn't finished and stops it if it's being executed. Runs
+// cancelled through the TaskManager directly are stopped on the next tick.
+func (s *Scheduler) CancelRun(taskID TaskID, runID TaskRunID) error {
+	lock, err := s.manager.LockTaskRun(taskID, runID)
+	if err != nil {
+		return err
+	}
+	run := TaskRunMetadata{ID: runID, TaskId: taskID}
+	defer s.unlock(run, lock)
+	if err := s.manager.CancelRun(runID, taskID, lock); err != nil {
+		return err
+	}
+	s.stop(run)
+	return nil
+}
+
+// stop cancels the execution of a run if this scheduler is executing it.
+func (s *Scheduler) stop(run TaskRunMetadata) {
+	key := runKey{taskID: run.TaskId, runID: run.ID}
+	s.mu.Lock()
+	exec, isRunning := s.running[key]
+	delete(s.running, key)
+	s.mu.Unlock()
+	if isRunning {
+		s.logger.Infow("Stopping task run", "task", run.TaskId, "run", run.ID)
+		exec.cancel()
+	}
+}
+
+func (s *Scheduler) unlock(run TaskRunMetadata, lock sp.LockObject) {
+	if err := s.manager.UnlockTaskRun(run.TaskId, run.ID, lock); err != nil {
+		s.logger.Errorw("Failed to unlock task run", "task", run.TaskId, "run", run.ID, "error", err)
+	}
+}
+
+func (s *Scheduler) failTimedOutRun(run TaskRunMetadata) error {
+	lock, err := s.manager.LockTaskRun(run.TaskId, run.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlock(run, lock)
+	timeoutErr := fmt.Errorf("attempt timed out at %s", run.Deadline.Format(time.RFC3339))
+	s.logger.Infow("Task run timed out", "task", run.TaskId, "run", run.ID, "deadline", run.Deadline)
+	return s.manager.SetRunStatus(run.ID, run.TaskId, Failed, timeoutErr, lock)
+}
+
+// upstreamSucceededSince returns true if the latest run of every upstream task succeeded and at
+// least one of them finished after since, so that each round of upstream runs fires only once.
+func (s *Scheduler) upstreamSucceededSince(upstream []TaskID, since time.Time) (bool, error) {
+	anyNew := false
+	for _, taskID := range upstream {
+		run, hasRun, err := s.manager.GetLatestRun(taskID)
+		if err != nil {
+			return false, err
+		}
+		if !hasRun || run.Status != Success {
+			return false, nil
+		}
+		finished := run.EndTime
+		if finished.IsZero() {
+			finished = run.StartTime
+		}
+		if finished.After(since) {
+			anyNew = true
+		}
+	}
+	return anyNew, nil
+}
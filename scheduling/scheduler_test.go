@@ -0,0 +1,470 @@
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/featureform/logging"
+	sp "github.com/featureform/scheduling/storage_providers"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestScheduler(t *testing.T) (*TaskManager, *Scheduler) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	return &manager, NewScheduler(&manager, nil, time.Minute, logger)
+}
+
+func createTestTask(t *testing.T, manager *TaskManager, name string) TaskMetadata {
+	task, err := manager.CreateTask(name, ResourceCreation, NameVariant{name, "variant"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	return task
+}
+
+func setTestRunStatus(t *testing.T, manager *TaskManager, run TaskRunMetadata, status Status) {
+	lock, err := manager.LockTaskRun(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("failed to lock run: %v", err)
+	}
+	defer manager.UnlockTaskRun(run.TaskId, run.ID, lock)
+	if err := manager.SetRunStatus(run.ID, run.TaskId, status, nil, lock); err != nil {
+		t.Fatalf("failed to set run status: %v", err)
+	}
+}
+
+func numRuns(t *testing.T, manager *TaskManager, taskID TaskID) TaskRunID {
+	run, hasRun, err := manager.GetLatestRun(taskID)
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	if !hasRun {
+		return 0
+	}
+	return run.ID
+}
+
+func TestSchedulerScheduleTrigger(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	task := createTestTask(t, manager, "scheduled")
+	if err := scheduler.AddTrigger(task.ID, ScheduleTrigger{TriggerName: "minutely", Schedule: "* * * * *"}); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+
+	now := time.Now().UTC()
+	if err := scheduler.Tick(now); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, task.ID); runs != 0 {
+		t.Fatalf("schedule fired before it was due: %d runs", runs)
+	}
+
+	later := now.Add(2 * time.Minute)
+	if err := scheduler.Tick(later); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, task.ID); runs != 1 {
+		t.Fatalf("expected 1 run, got %d", runs)
+	}
+
+	// The first run is still pending, so no new run should be created.
+	if err := scheduler.Tick(later.Add(2 * time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, task.ID); runs != 1 {
+		t.Fatalf("expected 1 run while the first is pending, got %d", runs)
+	}
+
+	run, _, err := manager.GetLatestRun(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	if run.TriggerType != ScheduleTriggerType {
+		t.Fatalf("wrong trigger type: %s", run.TriggerType)
+	}
+	setTestRunStatus(t, manager, run, Success)
+	if err := scheduler.Tick(later.Add(2 * time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, task.ID); runs != 2 {
+		t.Fatalf("expected 2 runs, got %d", runs)
+	}
+}
+
+func TestSchedulerDependencyTrigger(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	upstream := createTestTask(t, manager, "upstream")
+	downstream := createTestTask(t, manager, "downstream")
+	trigger := DependencyTrigger{TriggerName: "after_upstream", Upstream: []TaskID{upstream.ID}}
+	if err := scheduler.AddTrigger(downstream.ID, trigger); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 0 {
+		t.Fatalf("dependency fired before upstream ran: %d runs", runs)
+	}
+
+	upstreamRun, err := manager.CreateTaskRun(upstream.Name, upstream.ID, OneOffTrigger{TriggerName: "manual"})
+	if err != nil {
+		t.Fatalf("failed to create upstream run: %v", err)
+	}
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 0 {
+		t.Fatalf("dependency fired before upstream succeeded: %d runs", runs)
+	}
+
+	setTestRunStatus(t, manager, upstreamRun, Success)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 1 {
+		t.Fatalf("expected 1 downstream run, got %d", runs)
+	}
+
+	// The upstream run has already been consumed, so it shouldn't fire again.
+	downstreamRun, _, err := manager.GetLatestRun(downstream.ID)
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	setTestRunStatus(t, manager, downstreamRun, Success)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 1 {
+		t.Fatalf("expected 1 downstream run, got %d", runs)
+	}
+}
+
+func TestSchedulerInvalidTriggers(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	task := createTestTask(t, manager, "task")
+	testCases := []struct {
+		name    string
+		taskID  TaskID
+		trigger Trigger
+	}{
+		{"InvalidCron", task.ID, ScheduleTrigger{TriggerName: "bad", Schedule: "not a cron"}},
+		{"NoUpstream", task.ID, DependencyTrigger{TriggerName: "empty"}},
+		{"SelfDependency", task.ID, DependencyTrigger{TriggerName: "self", Upstream: []TaskID{task.ID}}},
+		{"UnknownUpstream", task.ID, DependencyTrigger{TriggerName: "unknown", Upstream: []TaskID{100}}},
+		{"OneOff", task.ID, OneOffTrigger{TriggerName: "oneoff"}},
+		{"UnknownTask", 100, ScheduleTrigger{TriggerName: "hourly", Schedule: "0 * * * *"}},
+	}
+	for _, currTest := range testCases {
+		t.Run(currTest.name, func(t *testing.T) {
+			if err := scheduler.AddTrigger(currTest.taskID, currTest.trigger); err == nil {
+				t.Fatalf("expected error adding trigger")
+			}
+		})
+	}
+}
+
+func TestSchedulerTimesOutRuns(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	task := createTestTask(t, manager, "slow")
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{}, time.Minute); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	if err := scheduler.AddTrigger(task.ID, ScheduleTrigger{TriggerName: "minutely", Schedule: "* * * * *"}); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+	if err := scheduler.Tick(time.Now().UTC().Add(2 * time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run, _, err := manager.GetLatestRun(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get latest run: %v", err)
+	}
+	setTestRunStatus(t, manager, run, Running)
+
+	if err := scheduler.Tick(time.Now().UTC().Add(5 * time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run, err = manager.GetRunByID(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if run.Status != Failed {
+		t.Fatalf("expected timed out run to fail, got %s", run.Status)
+	}
+	if len(run.Attempts) != 1 || run.Attempts[0].Error == "" {
+		t.Fatalf("expected failed attempt with timeout error, got %+v", run.Attempts)
+	}
+}
+
+func TestSchedulerTriggersPersist(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	upstream := createTestTask(t, manager, "upstream")
+	task := createTestTask(t, manager, "persisted")
+	if err := scheduler.AddTrigger(task.ID, ScheduleTrigger{TriggerName: "minutely", Schedule: "* * * * *"}); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+	if err := scheduler.AddTrigger(task.ID, DependencyTrigger{TriggerName: "after upstream", Upstream: []TaskID{upstream.ID}}); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+	triggers, err := manager.GetAllTriggers()
+	if err != nil {
+		t.Fatalf("failed to get triggers: %v", err)
+	}
+	if len(triggers) != 2 {
+		t.Fatalf("expected 2 stored triggers, got %+v", triggers)
+	}
+	added := triggers[1].Added
+
+	// Re-adding a trigger replaces it without resetting when it was added.
+	if err := scheduler.AddTrigger(task.ID, ScheduleTrigger{TriggerName: "minutely", Schedule: "*/5 * * * *"}); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+	triggers, err = manager.GetAllTriggers()
+	if err != nil {
+		t.Fatalf("failed to get triggers: %v", err)
+	}
+	if len(triggers) != 2 {
+		t.Fatalf("expected re-adding a trigger to replace it, got %+v", triggers)
+	}
+	schedule, ok := triggers[1].Trigger.(ScheduleTrigger)
+	if !ok || schedule.Schedule != "*/5 * * * *" || !triggers[1].Added.Equal(added) {
+		t.Fatalf("expected the replaced schedule with its original added time, got %+v", triggers[1])
+	}
+
+	// A scheduler created later, like one started after a restart, fires the stored triggers.
+	restarted := NewScheduler(manager, nil, time.Minute, logging.WrapZapLogger(zaptest.NewLogger(t).Sugar()))
+	if err := restarted.Tick(time.Now().UTC().Add(10 * time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, task.ID); runs != 1 {
+		t.Fatalf("expected the stored trigger to fire, got %d runs", runs)
+	}
+}
+
+type executorFunc func(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error
+
+func (fn executorFunc) Execute(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error {
+	return fn(ctx, task, run)
+}
+
+func newTestSchedulerWithExecutor(t *testing.T, executor Executor) (*TaskManager, *Scheduler) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	logger := logging.WrapZapLogger(zaptest.NewLogger(t).Sugar())
+	return &manager, NewScheduler(&manager, executor, time.Minute, logger)
+}
+
+// createTestJobTask creates a task with a job, so the scheduler's executor runs it.
+func createTestJobTask(t *testing.T, manager *TaskManager, name string) TaskMetadata {
+	task := createTestTask(t, manager, name)
+	task, err := manager.SetTaskJob(task.ID, []byte(name))
+	if err != nil {
+		t.Fatalf("failed to set task job: %v", err)
+	}
+	return task
+}
+
+func createTestRun(t *testing.T, manager *TaskManager, task TaskMetadata) TaskRunMetadata {
+	run, err := manager.CreateTaskRun(task.Name, task.ID, OneOffTrigger{TriggerName: "apply"})
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	return run
+}
+
+func waitForRunAttempts(t *testing.T, manager *TaskManager, run TaskRunMetadata, status Status, attempts int) TaskRunMetadata {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := manager.GetRunByID(run.TaskId, run.ID)
+		if err != nil {
+			t.Fatalf("failed to get run: %v", err)
+		}
+		if current.Status == status && (attempts == 0 || len(current.Attempts) == attempts) {
+			return current
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected run to be %s after %d attempts, got %s after %d", status, attempts, current.Status, len(current.Attempts))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func waitForRunStatus(t *testing.T, manager *TaskManager, run TaskRunMetadata, status Status) TaskRunMetadata {
+	return waitForRunAttempts(t, manager, run, status, 0)
+}
+
+func TestSchedulerRetriesFailedRuns(t *testing.T) {
+	var attempts int32
+	manager, scheduler := newTestSchedulerWithExecutor(t, executorFunc(func(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return fmt.Errorf("attempt %d failed", len(run.Attempts))
+		}
+		return nil
+	}))
+	task := createTestJobTask(t, manager, "flaky")
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}, 0); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	run := createTestRun(t, manager, task)
+
+	now := time.Now().UTC()
+	if err := scheduler.Tick(now); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run = waitForRunAttempts(t, manager, run, Pending, 1)
+	if run.RetryAt.IsZero() {
+		t.Fatalf("expected a failed attempt waiting to be retried, got %+v", run)
+	}
+
+	// The retry isn't executed until its backoff has passed.
+	if err := scheduler.Tick(now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if executed := atomic.LoadInt32(&attempts); executed != 1 {
+		t.Fatalf("expected the retry to wait for its backoff, got %d attempts", executed)
+	}
+
+	if err := scheduler.Tick(now.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	waitForRunAttempts(t, manager, run, Pending, 2)
+	if err := scheduler.Tick(now.Add(24 * time.Hour)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run = waitForRunStatus(t, manager, run, Success)
+	if len(run.Attempts) != 3 || run.EndTime.IsZero() {
+		t.Fatalf("expected the run to succeed on its third attempt, got %+v", run)
+	}
+}
+
+func TestSchedulerCancelStopsExecution(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	manager, scheduler := newTestSchedulerWithExecutor(t, executorFunc(func(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	}))
+	task := createTestJobTask(t, manager, "long")
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{MaxAttempts: 3}, 0); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	run := createTestRun(t, manager, task)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	<-started
+	waitForRunStatus(t, manager, run, Running)
+
+	if err := scheduler.CancelRun(run.TaskId, run.ID); err != nil {
+		t.Fatalf("failed to cancel run: %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected cancelling the run to stop its execution")
+	}
+	if err := scheduler.Tick(time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run = waitForRunStatus(t, manager, run, Cancelled)
+	if len(run.Attempts) != 1 {
+		t.Fatalf("expected a cancelled run not to be retried, got %+v", run.Attempts)
+	}
+}
+
+func TestSchedulerStopsTimedOutRuns(t *testing.T) {
+	var executions int32
+	stopped := make(chan error, 2)
+	manager, scheduler := newTestSchedulerWithExecutor(t, executorFunc(func(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error {
+		atomic.AddInt32(&executions, 1)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	}))
+	task := createTestJobTask(t, manager, "hanging")
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{MaxAttempts: 2}, time.Minute); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	run := createTestRun(t, manager, task)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	waitForRunStatus(t, manager, run, Running)
+
+	// The first attempt times out and the run is retried right away, since there's no backoff.
+	for i := 0; i < 2; i++ {
+		if err := scheduler.Tick(time.Now().UTC().Add(5 * time.Minute)); err != nil {
+			t.Fatalf("failed to tick: %v", err)
+		}
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the timed out attempt to be stopped")
+		}
+		if i == 0 {
+			if err := scheduler.Tick(time.Now().UTC()); err != nil {
+				t.Fatalf("failed to tick: %v", err)
+			}
+			waitForRunStatus(t, manager, run, Running)
+		}
+	}
+	run = waitForRunStatus(t, manager, run, Failed)
+	if len(run.Attempts) != 2 || atomic.LoadInt32(&executions) != 2 {
+		t.Fatalf("expected both attempts to time out, got %+v", run.Attempts)
+	}
+}
+
+func TestSchedulerSkipsTasksWithoutJob(t *testing.T) {
+	var executions int32
+	manager, scheduler := newTestSchedulerWithExecutor(t, executorFunc(func(ctx context.Context, task TaskMetadata, run TaskRunMetadata) error {
+		atomic.AddInt32(&executions, 1)
+		return nil
+	}))
+	task := createTestTask(t, manager, "external")
+	run := createTestRun(t, manager, task)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	run, err := manager.GetRunByID(run.TaskId, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if run.Status != Pending || atomic.LoadInt32(&executions) != 0 {
+		t.Fatalf("expected a run without a job to be left to its creator, got %s after %d executions", run.Status, executions)
+	}
+}
+
+func TestSchedulerDependencyTriggerIgnoresEarlierRuns(t *testing.T) {
+	manager, scheduler := newTestScheduler(t)
+	upstream := createTestTask(t, manager, "upstream")
+	downstream := createTestTask(t, manager, "downstream")
+	upstreamRun := createTestRun(t, manager, upstream)
+	setTestRunStatus(t, manager, upstreamRun, Success)
+
+	// The upstream run finished before the trigger was added, so it doesn't fire it.
+	trigger := DependencyTrigger{TriggerName: "after_upstream", Upstream: []TaskID{upstream.ID}}
+	if err := scheduler.AddTrigger(downstream.ID, trigger); err != nil {
+		t.Fatalf("failed to add trigger: %v", err)
+	}
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 0 {
+		t.Fatalf("dependency fired for an upstream run from before it was added: %d runs", runs)
+	}
+
+	upstreamRun = createTestRun(t, manager, upstream)
+	setTestRunStatus(t, manager, upstreamRun, Success)
+	if err := scheduler.Tick(time.Now().UTC()); err != nil {
+		t.Fatalf("failed to tick: %v", err)
+	}
+	if runs := numRuns(t, manager, downstream.ID); runs != 1 {
+		t.Fatalf("expected 1 downstream run, got %d", runs)
+	}
+}
@@ -0,0 +1,220 @@
+package scheduling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltStorageBucket = []byte("storage")
+	boltLocksBucket   = []byte("locks")
+)
+
+// BoltStorageProvider persists keys and locks in a single local bbolt file so that
+// single node deployments keep their state across restarts without running etcd.
+// bbolt holds an exclusive file lock on the database for as long as it's open, so
+// only one process can use the file at a time. Key locks are stored in the file
+// alongside the values and expire like the memory provider's if they aren't refreshed.
+type BoltStorageProvider struct {
+	db *bolt.DB
+}
+
+type boltLock struct {
+	ID   string
+	Date time.Time
+}
+
+func NewBoltStorageProvider(path string) (*BoltStorageProvider, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: ValidTimePeriod})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt file %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltStorageBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(boltLocksBucket); err != nil {
+			return err
+		}
+		// Locks can't be held across restarts since the goroutines that refresh them are gone.
+		return clearBucket(tx, boltLocksBucket)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize bolt file %s: %w", path, err)
+	}
+	return &BoltStorageProvider{db: db}, nil
+}
+
+func clearBucket(tx *bolt.Tx, name []byte) error {
+	if err := tx.DeleteBucket(name); err != nil {
+		return err
+	}
+	_, err := tx.CreateBucket(name)
+	return err
+}
+
+// Close releases the file lock on the underlying database.
+func (b *BoltStorageProvider) Close() error {
+	return b.db.Close()
+}
+
+func (b *BoltStorageProvider) Set(key string, value string, lock LockObject) error {
+	if key == "" {
+		return fmt.Errorf("key is empty")
+	}
+	if value == "" {
+		return fmt.Errorf("value is empty for key %s", key)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		currentLock, ok, err := getBoltLock(tx, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("key is not locked")
+		}
+		if currentLock.ID != lock.ID {
+			return fmt.Errorf("key %s is locked by another id: locked by: %s, unlock by: %s", key, currentLock.ID, lock.ID)
+		}
+		return tx.Bucket(boltStorageBucket).Put([]byte(key), []byte(value))
+	})
+}
+
+func (b *BoltStorageProvider) Get(key string, prefix bool) (map[string]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key is empty")
+	}
+
+	result := make(map[string]string)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltStorageBucket)
+		if !prefix {
+			if value := bucket.Get([]byte(key)); value != nil {
+				result[key] = string(value)
+			}
+			return nil
+		}
+		c := bucket.Cursor()
+		p := []byte(key)
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			result[string(k)] = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, &KeyNotFoundError{Key: key}
+	}
+	return result, nil
+}
+
+func (b *BoltStorageProvider) ListKeys(prefix string) ([]string, error) {
+	var result []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// Keys are stored in byte order so the cursor returns them already sorted.
+		c := tx.Bucket(boltStorageBucket).Cursor()
+		p := []byte(prefix)
+		for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+			result = append(result, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (b *BoltStorageProvider) Lock(key string) (LockObject, error) {
+	if key == "" {
+		return LockObject{}, fmt.Errorf("key is empty")
+	}
+
+	id := uuid.New().String()
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		keyLock, ok, err := getBoltLock(tx, key)
+		if err != nil {
+			return err
+		}
+		if ok && time.Since(keyLock.Date) < ValidTimePeriod {
+			return fmt.Errorf("key is already locked by: %s", keyLock.ID)
+		}
+		return putBoltLock(tx, key, boltLock{ID: id, Date: time.Now()})
+	})
+	if err != nil {
+		return LockObject{}, err
+	}
+
+	lockChannel := make(chan error)
+	go b.updateLockTime(id, key, lockChannel)
+	return LockObject{ID: id, Channel: &lockChannel}, nil
+}
+
+func (b *BoltStorageProvider) Unlock(key string, lock LockObject) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		keyLock, ok, err := getBoltLock(tx, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("key is not locked")
+		}
+		if keyLock.ID != lock.ID {
+			return fmt.Errorf("key is locked by another id: locked by: %s, unlock  by: %s", keyLock.ID, lock.ID)
+		}
+		return tx.Bucket(boltLocksBucket).Delete([]byte(key))
+	})
+}
+
+func (b *BoltStorageProvider) updateLockTime(id string, key string, lockChannel chan error) {
+	for {
+		time.Sleep(UpdateSleepTime)
+
+		select {
+		case <-lockChannel:
+			return
+		default:
+			stillHeld := false
+			err := b.db.Update(func(tx *bolt.Tx) error {
+				keyLock, ok, err := getBoltLock(tx, key)
+				if err != nil || !ok || keyLock.ID != id {
+					return err
+				}
+				stillHeld = true
+				return putBoltLock(tx, key, boltLock{ID: id, Date: time.Now()})
+			})
+			// The lock was released, taken over, or the database was closed.
+			if err != nil || !stillHeld {
+				return
+			}
+		}
+	}
+}
+
+func getBoltLock(tx *bolt.Tx, key string) (boltLock, bool, error) {
+	value := tx.Bucket(boltLocksBucket).Get([]byte(key))
+	if value == nil {
+		return boltLock{}, false, nil
+	}
+	var lock boltLock
+	if err := json.Unmarshal(value, &lock); err != nil {
+		return boltLock{}, false, fmt.Errorf("failed to parse lock for key %s: %w", key, err)
+	}
+	return lock, true, nil
+}
+
+func putBoltLock(tx *bolt.Tx, key string, lock boltLock) error {
+	serialized, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(boltLocksBucket).Put([]byte(key), serialized)
+}
@@ -0,0 +1,98 @@
+package scheduling
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestBoltStorageProvider(t *testing.T, path string) *BoltStorageProvider {
+	provider, err := NewBoltStorageProvider(path)
+	if err != nil {
+		t.Fatalf("could not open bolt storage provider: %v", err)
+	}
+	return provider
+}
+
+func TestBoltStorageProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	provider := newTestBoltStorageProvider(t, path)
+
+	keys := []string{"list/key1", "lost/key2", "list/key3"}
+	for _, key := range keys {
+		lock, err := provider.Lock(key)
+		if err != nil {
+			t.Fatalf("Lock(%s) failed: %v", key, err)
+		}
+		if err := provider.Set(key, "value", lock); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key, err)
+		}
+		if err := provider.Unlock(key, lock); err != nil {
+			t.Fatalf("Unlock(%s) failed: %v", key, err)
+		}
+	}
+	if err := provider.Set("list/key1", "value", LockObject{ID: "unlocked"}); err == nil {
+		t.Fatalf("Set without holding the lock should have failed")
+	}
+
+	// Values should survive reopening the file.
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	provider = newTestBoltStorageProvider(t, path)
+	defer provider.Close()
+
+	listed, err := provider.ListKeys("list")
+	if err != nil {
+		t.Fatalf("ListKeys failed: %v", err)
+	}
+	if !compareStringSlices(listed, []string{"list/key1", "list/key3"}) {
+		t.Fatalf("Expected List: %v, Got List: %v", []string{"list/key1", "list/key3"}, listed)
+	}
+	results, err := provider.Get("list/", true)
+	if err != nil {
+		t.Fatalf("Get with prefix failed: %v", err)
+	}
+	expected := map[string]string{"list/key1": "value", "list/key3": "value"}
+	if !compareMaps(results, expected) {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+	if _, err := provider.Get("missing", false); err == nil {
+		t.Fatalf("Get of a missing key should have failed")
+	} else if _, ok := err.(*KeyNotFoundError); !ok {
+		t.Fatalf("Expected KeyNotFoundError, got %T: %v", err, err)
+	}
+}
+
+func TestBoltLockAndUnlock(t *testing.T) {
+	provider := newTestBoltStorageProvider(t, filepath.Join(t.TempDir(), "storage.db"))
+	defer provider.Close()
+
+	key := "/tasks/metadata/task_id=1"
+	lock, err := provider.Lock(key)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	diffLock, err := provider.Lock(key)
+	if err == nil {
+		t.Fatalf("Locking using different id should have failed")
+	}
+	if err := provider.Unlock(key, diffLock); err == nil {
+		t.Fatalf("Unlocking using different id should have failed")
+	}
+	if err := provider.Unlock(key, lock); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := provider.Lock(key); err != nil {
+		t.Fatalf("Lock after unlock failed: %v", err)
+	}
+}
+
+func TestBoltFileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	provider := newTestBoltStorageProvider(t, path)
+	defer provider.Close()
+
+	if _, err := NewBoltStorageProvider(path); err == nil {
+		t.Fatalf("Opening a file that is already in use should have failed")
+	}
+}
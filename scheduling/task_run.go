@@ -4,23 +4,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/gorhill/cronexpr"
 )
 
 type TaskRunID int32
 type Status string
 
 const (
-	Success Status = "SUCCESS"
-	Failed  Status = "FAILED"
-	Pending Status = "PENDING"
-	Running Status = "RUNNING"
+	Success   Status = "SUCCESS"
+	Failed    Status = "FAILED"
+	Pending   Status = "PENDING"
+	Running   Status = "RUNNING"
+	Cancelled Status = "CANCELLED"
 )
 
+// IsFinished returns true if a run with this status will not be attempted again.
+func (s Status) IsFinished() bool {
+	return s == Success || s == Failed || s == Cancelled
+}
+
 type TriggerType string
 
 const (
-	OneOffTriggerType TriggerType = "OneOffTrigger"
-	DummyTriggerType  TriggerType = "DummyTrigger"
+	OneOffTriggerType     TriggerType = "OneOffTrigger"
+	DummyTriggerType      TriggerType = "DummyTrigger"
+	ScheduleTriggerType   TriggerType = "ScheduleTrigger"
+	DependencyTriggerType TriggerType = "DependencyTrigger"
 )
 
 type Trigger interface {
@@ -53,17 +63,137 @@ func (t DummyTrigger) Name() string {
 	return t.TriggerName
 }
 
-type TaskRunMetadata struct {
-	ID          TaskRunID   `json:"runId"`
-	TaskId      TaskID      `json:"taskId"`
-	Name        string      `json:"name"`
+// ScheduleTrigger fires on a cron schedule, e.g. "0 * * * *" for every hour.
+type ScheduleTrigger struct {
+	TriggerName string `json:"triggerName"`
+	Schedule    string `json:"schedule"`
+}
+
+func (t ScheduleTrigger) Type() TriggerType {
+	return ScheduleTriggerType
+}
+
+func (t ScheduleTrigger) Name() string {
+	return t.TriggerName
+}
+
+// Next returns the first time the schedule fires after the given time.
+func (t ScheduleTrigger) Next(after time.Time) (time.Time, error) {
+	expr, err := cronexpr.Parse(t.Schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron schedule %q: %w", t.Schedule, err)
+	}
+	return expr.Next(after), nil
+}
+
+// DependencyTrigger fires once the latest runs of all its upstream tasks have succeeded and at least
+// one of them finished after the task's last run started, or after the trigger was added if the task
+// hasn't run.
+type DependencyTrigger struct {
+	TriggerName string   `json:"triggerName"`
+	Upstream    []TaskID `json:"upstream"`
+}
+
+func (t DependencyTrigger) Type() TriggerType {
+	return DependencyTriggerType
+}
+
+func (t DependencyTrigger) Name() string {
+	return t.TriggerName
+}
+
+// TaskTrigger is a ScheduleTrigger or DependencyTrigger registered for a task. Added is when it was
+// registered; triggers of tasks that have never run fire relative to it.
+type TaskTrigger struct {
+	TaskID      TaskID      `json:"taskId"`
 	Trigger     Trigger     `json:"trigger"`
 	TriggerType TriggerType `json:"triggerType"`
-	Status      Status      `json:"status"`
-	StartTime   time.Time   `json:"startTime"`
-	EndTime     time.Time   `json:"endTime"`
-	Logs        []string    `json:"logs"`
-	Error       string      `json:"error"`
+	Added       time.Time   `json:"added"`
+}
+
+func (t *TaskTrigger) Marshal() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (t *TaskTrigger) Unmarshal(data []byte) error {
+	type tempConfig struct {
+		TaskID      TaskID          `json:"taskId"`
+		Trigger     json.RawMessage `json:"trigger"`
+		TriggerType TriggerType     `json:"triggerType"`
+		Added       time.Time       `json:"added"`
+	}
+
+	var temp tempConfig
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to deserialize task trigger: %w", err)
+	}
+	if temp.TaskID == 0 {
+		return fmt.Errorf("task trigger is missing TaskID")
+	}
+	trigger, err := unmarshalTrigger(temp.TriggerType, temp.Trigger)
+	if err != nil {
+		return err
+	}
+	t.TaskID = temp.TaskID
+	t.Trigger = trigger
+	t.TriggerType = temp.TriggerType
+	t.Added = temp.Added
+	return nil
+}
+
+// RunAttempt records one attempt at a task run, so the run history shows why it was retried.
+type RunAttempt struct {
+	Number    int       `json:"number"`
+	Status    Status    `json:"status"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Error     string    `json:"error"`
+}
+
+type TaskRunMetadata struct {
+	ID          TaskRunID    `json:"runId"`
+	TaskId      TaskID       `json:"taskId"`
+	Name        string       `json:"name"`
+	Trigger     Trigger      `json:"trigger"`
+	TriggerType TriggerType  `json:"triggerType"`
+	Status      Status       `json:"status"`
+	StartTime   time.Time    `json:"startTime"`
+	EndTime     time.Time    `json:"endTime"`
+	Logs        []string     `json:"logs"`
+	Error       string       `json:"error"`
+	Attempts    []RunAttempt `json:"attempts"`
+	// Deadline is when the current attempt times out. It's zero if the task has no timeout.
+	Deadline time.Time `json:"deadline"`
+	// RetryAt is the earliest time a failed run should be attempted again.
+	RetryAt time.Time `json:"retryAt"`
+}
+
+// Due returns true if the run is waiting to be executed and, if it's being retried, its backoff has passed.
+func (t *TaskRunMetadata) Due(now time.Time) bool {
+	return t.Status == Pending && !t.RetryAt.After(now)
+}
+
+// TimedOut returns true if the run's current attempt has passed its deadline.
+func (t *TaskRunMetadata) TimedOut(now time.Time) bool {
+	return t.Status == Running && !t.Deadline.IsZero() && now.After(t.Deadline)
+}
+
+// finishAttempt closes the current attempt with the given status. If no attempt is running,
+// one is recorded that starts and ends now.
+func (t *TaskRunMetadata) finishAttempt(status Status, err error, now time.Time) {
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	last := len(t.Attempts) - 1
+	if last < 0 || t.Attempts[last].Status != Running {
+		t.Attempts = append(t.Attempts, RunAttempt{Number: len(t.Attempts) + 1, StartTime: now})
+		last = len(t.Attempts) - 1
+	}
+	t.Attempts[last].Status = status
+	t.Attempts[last].EndTime = now
+	t.Attempts[last].Error = errMsg
+	t.Deadline = time.Time{}
 }
 
 // Formatting
@@ -82,6 +212,9 @@ func (t *TaskRunMetadata) Unmarshal(data []byte) error {
 		EndTime     time.Time       `json:"endTime"`
 		Logs        []string        `json:"logs"`
 		Error       string          `json:"error"`
+		Attempts    []RunAttempt    `json:"attempts"`
+		Deadline    time.Time       `json:"deadline"`
+		RetryAt     time.Time       `json:"retryAt"`
 	}
 
 	var temp tempConfig
@@ -116,27 +249,51 @@ func (t *TaskRunMetadata) Unmarshal(data []byte) error {
 	t.EndTime = temp.EndTime
 	t.Logs = temp.Logs
 	t.Error = temp.Error
+	t.Attempts = temp.Attempts
+	t.Deadline = temp.Deadline
+	t.RetryAt = temp.RetryAt
 
+	trigger, err := unmarshalTrigger(temp.TriggerType, temp.Trigger)
+	if err != nil {
+		return err
+	}
+	t.Trigger = trigger
+	return nil
+}
+
+// unmarshalTrigger deserializes a trigger stored as JSON into the concrete type for triggerType.
+func unmarshalTrigger(triggerType TriggerType, data json.RawMessage) (Trigger, error) {
 	triggerMap := make(map[string]interface{})
-	if err := json.Unmarshal(temp.Trigger, &triggerMap); err != nil {
-		return fmt.Errorf("failed to deserialize trigger data: %w", err)
+	if err := json.Unmarshal(data, &triggerMap); err != nil {
+		return nil, fmt.Errorf("failed to deserialize trigger data: %w", err)
 	}
 
-	switch temp.TriggerType {
+	switch triggerType {
 	case OneOffTriggerType:
 		var oneOffTrigger OneOffTrigger
-		if err := json.Unmarshal(temp.Trigger, &oneOffTrigger); err != nil {
-			return fmt.Errorf("failed to deserialize One Off Trigger data: %w", err)
+		if err := json.Unmarshal(data, &oneOffTrigger); err != nil {
+			return nil, fmt.Errorf("failed to deserialize One Off Trigger data: %w", err)
 		}
-		t.Trigger = oneOffTrigger
+		return oneOffTrigger, nil
 	case DummyTriggerType:
 		var dummyTrigger DummyTrigger
-		if err := json.Unmarshal(temp.Trigger, &dummyTrigger); err != nil {
-			return fmt.Errorf("failed to deserialize Dummy Trigger data: %w", err)
+		if err := json.Unmarshal(data, &dummyTrigger); err != nil {
+			return nil, fmt.Errorf("failed to deserialize Dummy Trigger data: %w", err)
 		}
-		t.Trigger = dummyTrigger
+		return dummyTrigger, nil
+	case ScheduleTriggerType:
+		var scheduleTrigger ScheduleTrigger
+		if err := json.Unmarshal(data, &scheduleTrigger); err != nil {
+			return nil, fmt.Errorf("failed to deserialize Schedule Trigger data: %w", err)
+		}
+		return scheduleTrigger, nil
+	case DependencyTriggerType:
+		var dependencyTrigger DependencyTrigger
+		if err := json.Unmarshal(data, &dependencyTrigger); err != nil {
+			return nil, fmt.Errorf("failed to deserialize Dependency Trigger data: %w", err)
+		}
+		return dependencyTrigger, nil
 	default:
-		return fmt.Errorf("unknown trigger type: %s", temp.TriggerType)
+		return nil, fmt.Errorf("unknown trigger type: %s", triggerType)
 	}
-	return nil
 }
@@ -141,6 +141,44 @@ func TestSerializeTaskRunMetadata(t *testing.T) {
 			},
 			triggerType: "DummyTrigger",
 		},
+		{
+			name: "WithScheduleTrigger",
+			task: TaskRunMetadata{
+				ID:     1,
+				TaskId: 12,
+				Name:   "schedule_taskrun",
+				Trigger: ScheduleTrigger{
+					TriggerName: "name3",
+					Schedule:    "0 * * * *",
+				},
+				TriggerType: ScheduleTriggerType,
+				Status:      Success,
+				StartTime:   time.Now().Truncate(0).UTC(),
+				EndTime:     time.Now().Truncate(0).UTC(),
+				Logs:        nil,
+				Error:       "",
+			},
+			triggerType: "ScheduleTrigger",
+		},
+		{
+			name: "WithDependencyTrigger",
+			task: TaskRunMetadata{
+				ID:     1,
+				TaskId: 12,
+				Name:   "dependency_taskrun",
+				Trigger: DependencyTrigger{
+					TriggerName: "name4",
+					Upstream:    []TaskID{10, 11},
+				},
+				TriggerType: DependencyTriggerType,
+				Status:      Pending,
+				StartTime:   time.Now().Truncate(0).UTC(),
+				EndTime:     time.Now().Truncate(0).UTC(),
+				Logs:        nil,
+				Error:       "",
+			},
+			triggerType: "DependencyTrigger",
+		},
 	}
 
 	for _, currTest := range testCases {
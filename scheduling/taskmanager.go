@@ -1,7 +1,9 @@
 package scheduling
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +58,18 @@ func (trmk TaskRunMetadataKey) String() string {
 	return key
 }
 
+type TaskTriggerKey struct {
+	taskID TaskID
+	name   string
+}
+
+func (ttk TaskTriggerKey) String() string {
+	if ttk.taskID == 0 {
+		return "/tasks/triggers/task_id="
+	}
+	return fmt.Sprintf("/tasks/triggers/task_id=%d/trigger=%s", ttk.taskID, ttk.name)
+}
+
 func NewTaskManager(storage sp.StorageProvider) TaskManager {
 	return TaskManager{storage: storage}
 }
@@ -186,6 +200,54 @@ func (tm *TaskManager) GetTaskByID(id TaskID) (TaskMetadata, error) {
 	return taskMetadata, nil
 }
 
+// SetTaskPolicy sets the retry policy and per-attempt timeout used by the task's runs.
+func (tm *TaskManager) SetTaskPolicy(id TaskID, policy RetryPolicy, timeout time.Duration) (TaskMetadata, error) {
+	if policy.MaxAttempts < 0 {
+		return TaskMetadata{}, fmt.Errorf("max attempts cannot be negative: %d", policy.MaxAttempts)
+	}
+	if timeout < 0 {
+		return TaskMetadata{}, fmt.Errorf("timeout cannot be negative: %v", timeout)
+	}
+	metadata, err := tm.GetTaskByID(id)
+	if err != nil {
+		return TaskMetadata{}, err
+	}
+	metadata.RetryPolicy = policy
+	metadata.Timeout = timeout
+	return metadata, tm.setTask(metadata)
+}
+
+// SetTaskJob sets the job the scheduler's Executor runs for the task.
+func (tm *TaskManager) SetTaskJob(id TaskID, job []byte) (TaskMetadata, error) {
+	metadata, err := tm.GetTaskByID(id)
+	if err != nil {
+		return TaskMetadata{}, err
+	}
+	metadata.Job = job
+	return metadata, tm.setTask(metadata)
+}
+
+func (tm *TaskManager) setTask(metadata TaskMetadata) error {
+	serializedMetadata, err := metadata.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %v", err)
+	}
+	key := TaskMetadataKey{taskID: metadata.ID}
+	taskLock, err := tm.storage.Lock(key.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock task key: %v", err)
+	}
+	err = tm.storage.Set(key.String(), string(serializedMetadata), taskLock)
+	if err != nil {
+		return err
+	}
+	err = tm.storage.Unlock(key.String(), taskLock)
+	if err != nil {
+		return fmt.Errorf("failed to unlock task key: %v", err)
+	}
+	return nil
+}
+
 func (tm *TaskManager) GetAllTasks() (TaskMetadataList, error) {
 	metadata, err := tm.storage.Get(TaskMetadataKey{}.String(), true)
 	if err != nil {
@@ -205,6 +267,71 @@ func (tm *TaskManager) GetAllTasks() (TaskMetadataList, error) {
 	return tml, nil
 }
 
+// Trigger Methods
+
+// SetTrigger stores a trigger for a task, replacing the task's trigger with the same name. A replaced
+// trigger keeps the time it was first added, so re-registering a schedule doesn't delay it.
+func (tm *TaskManager) SetTrigger(taskID TaskID, trigger Trigger) (TaskTrigger, error) {
+	key := TaskTriggerKey{taskID: taskID, name: trigger.Name()}.String()
+	lock, err := tm.storage.Lock(key)
+	if err != nil {
+		return TaskTrigger{}, fmt.Errorf("failed to lock trigger key: %v", err)
+	}
+	defer tm.storage.Unlock(key, lock)
+
+	taskTrigger := TaskTrigger{
+		TaskID:      taskID,
+		Trigger:     trigger,
+		TriggerType: trigger.Type(),
+		Added:       time.Now().UTC(),
+	}
+	existing, err := tm.storage.Get(key, false)
+	if err == nil {
+		previous := TaskTrigger{}
+		if err := previous.Unmarshal([]byte(existing[key])); err != nil {
+			return TaskTrigger{}, err
+		}
+		taskTrigger.Added = previous.Added
+	} else if _, notFound := err.(*sp.KeyNotFoundError); !notFound {
+		return TaskTrigger{}, err
+	}
+
+	serialized, err := taskTrigger.Marshal()
+	if err != nil {
+		return TaskTrigger{}, fmt.Errorf("failed to marshal trigger: %v", err)
+	}
+	if err := tm.storage.Set(key, string(serialized), lock); err != nil {
+		return TaskTrigger{}, err
+	}
+	return taskTrigger, nil
+}
+
+// GetAllTriggers returns the triggers stored for every task.
+func (tm *TaskManager) GetAllTriggers() ([]TaskTrigger, error) {
+	recs, err := tm.storage.Get(TaskTriggerKey{}.String(), true)
+	if _, notFound := err.(*sp.KeyNotFoundError); notFound {
+		return []TaskTrigger{}, nil
+	} else if err != nil {
+		return []TaskTrigger{}, err
+	}
+
+	triggers := make([]TaskTrigger, 0, len(recs))
+	for _, record := range recs {
+		taskTrigger := TaskTrigger{}
+		if err := taskTrigger.Unmarshal([]byte(record)); err != nil {
+			return []TaskTrigger{}, fmt.Errorf("failed to unmarshal trigger record: %v", err)
+		}
+		triggers = append(triggers, taskTrigger)
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		if triggers[i].TaskID != triggers[j].TaskID {
+			return triggers[i].TaskID < triggers[j].TaskID
+		}
+		return triggers[i].Trigger.Name() < triggers[j].Trigger.Name()
+	})
+	return triggers, nil
+}
+
 type TaskRunList []TaskRunMetadata
 
 func (trl *TaskRunList) ToJSON() string {
@@ -357,6 +484,34 @@ func (tm *TaskManager) GetRunByID(taskID TaskID, runID TaskRunID) (TaskRunMetada
 
 }
 
+// GetLatestRun returns the task's most recently created run. The bool is false if the task has no runs.
+func (tm *TaskManager) GetLatestRun(taskID TaskID) (TaskRunMetadata, bool, error) {
+	taskRunKey := TaskRunKey{taskID: taskID}
+	key, err := tm.storage.Get(taskRunKey.String(), false)
+	if err != nil {
+		return TaskRunMetadata{}, false, fmt.Errorf("failed to fetch task: %v", err)
+	}
+
+	runs := TaskRuns{}
+	err = runs.Unmarshal([]byte(key[taskRunKey.String()]))
+	if err != nil {
+		return TaskRunMetadata{}, false, err
+	}
+	if len(runs.Runs) == 0 {
+		return TaskRunMetadata{}, false, nil
+	}
+
+	latestID, err := getHighestRunID(runs)
+	if err != nil {
+		return TaskRunMetadata{}, false, err
+	}
+	run, err := tm.GetRunByID(taskID, latestID)
+	if err != nil {
+		return TaskRunMetadata{}, false, err
+	}
+	return run, true, nil
+}
+
 func (tm *TaskManager) GetRunsByDate(start time.Time, end time.Time) (TaskRunList, error) {
 	// the date range is inclusive
 	var runs []TaskRunMetadata
@@ -416,6 +571,11 @@ func (tm *TaskManager) GetAllTaskRuns() (TaskRunList, error) {
 }
 
 // Write Methods
+
+// SetRunStatus records a status change in the run's attempt history. Setting a run to Running starts a
+// new attempt. Setting it to Failed ends the attempt, and if the task's retry policy allows another
+// attempt the run goes back to Pending with RetryAt set to when it should be retried, unless err is a
+// NonRetryableError. The Scheduler executes the run again once RetryAt has passed.
 func (t *TaskManager) SetRunStatus(runID TaskRunID, taskID TaskID, status Status, err error, lock sp.LockObject) error {
 	if taskID <= 0 {
 		return fmt.Errorf("invalid run id: %d", taskID)
@@ -427,21 +587,70 @@ func (t *TaskManager) SetRunStatus(runID TaskRunID, taskID TaskID, status Status
 	if status == Failed && err == nil {
 		return fmt.Errorf("error is required for failed status")
 	}
+	task, e := t.GetTaskByID(taskID)
+	if e != nil {
+		return fmt.Errorf("failed to fetch task: %v", e)
+	}
+
+	now := time.Now().UTC()
+	switch status {
+	case Running:
+		metadata.Attempts = append(metadata.Attempts, RunAttempt{Number: len(metadata.Attempts) + 1, Status: Running, StartTime: now})
+		metadata.RetryAt = time.Time{}
+		if task.Timeout > 0 {
+			metadata.Deadline = now.Add(task.Timeout)
+		}
+	case Success, Failed, Cancelled:
+		metadata.finishAttempt(status, err, now)
+	}
+	var nonRetryable *NonRetryableError
+	if status == Failed && !errors.As(err, &nonRetryable) && task.RetryPolicy.CanAttempt(len(metadata.Attempts)) {
+		status = Pending
+		metadata.RetryAt = now.Add(task.RetryPolicy.Backoff(len(metadata.Attempts)))
+	}
+
 	metadata.Status = status
+	if status.IsFinished() {
+		metadata.EndTime = now
+	}
 	if err == nil {
 		metadata.Error = ""
 	} else {
 		metadata.Error = err.Error()
 	}
+	return t.setRun(metadata, lock)
+}
+
+// CancelRun stops a run that hasn't finished. It won't be retried.
+func (t *TaskManager) CancelRun(runID TaskRunID, taskID TaskID, lock sp.LockObject) error {
+	if taskID <= 0 {
+		return fmt.Errorf("invalid task id: %d", taskID)
+	}
+	metadata, e := t.GetRunByID(taskID, runID)
+	if e != nil {
+		return fmt.Errorf("failed to fetch run: %v", e)
+	}
+	if metadata.Status.IsFinished() {
+		return fmt.Errorf("cannot cancel run %d with status %s", runID, metadata.Status)
+	}
+	now := time.Now().UTC()
+	if metadata.Status == Running {
+		metadata.finishAttempt(Cancelled, nil, now)
+	}
+	metadata.Status = Cancelled
+	metadata.EndTime = now
+	metadata.RetryAt = time.Time{}
+	return t.setRun(metadata, lock)
+}
 
+func (t *TaskManager) setRun(metadata TaskRunMetadata, lock sp.LockObject) error {
 	serializedMetadata, e := metadata.Marshal()
 	if e != nil {
 		return fmt.Errorf("failed to marshal metadata: %v", e)
 	}
 
-	taskRunMetadataKey := TaskRunMetadataKey{taskID: taskID, runID: metadata.ID, date: metadata.StartTime}
-	e = t.storage.Set(taskRunMetadataKey.String(), string(serializedMetadata), lock)
-	return e
+	taskRunMetadataKey := TaskRunMetadataKey{taskID: metadata.TaskId, runID: metadata.ID, date: metadata.StartTime}
+	return t.storage.Set(taskRunMetadataKey.String(), string(serializedMetadata), lock)
 }
 
 func (t *TaskManager) SetRunEndTime(runID TaskRunID, taskID TaskID, time time.Time, lock sp.LockObject) error {
@@ -498,14 +707,22 @@ func (t *TaskManager) AppendRunLog(runID TaskRunID, taskID TaskID, log string, l
 }
 
 // Locking
+
 func (tm *TaskManager) LockTaskRun(taskID TaskID, runId TaskRunID) (sp.LockObject, error) {
-	date := time.Now().UTC()
-	key := TaskRunMetadataKey{taskID: taskID, runID: runId, date: date}.String()
-	return tm.storage.Lock(key)
+	return tm.storage.Lock(tm.runLockKey(taskID, runId))
 }
 
 func (tm *TaskManager) UnlockTaskRun(taskID TaskID, runId TaskRunID, lock sp.LockObject) error {
-	runDate := time.Now().UTC()
-	key := TaskRunMetadataKey{taskID: taskID, runID: runId, date: runDate}.String()
-	return tm.storage.Unlock(key, lock)
+	return tm.storage.Unlock(tm.runLockKey(taskID, runId), lock)
+}
+
+// runLockKey is the metadata key a run is stored at. The key includes the date the run started, so a
+// run retried on a later day is still locked under the key it's written to. Runs that can't be found
+// are locked under today's date.
+func (tm *TaskManager) runLockKey(taskID TaskID, runId TaskRunID) string {
+	date := time.Now().UTC()
+	if run, err := tm.GetRunByID(taskID, runId); err == nil {
+		date = run.StartTime
+	}
+	return TaskRunMetadataKey{taskID: taskID, runID: runId, date: date}.String()
 }
@@ -725,6 +725,136 @@ func TestSetStatusByRunID(t *testing.T) {
 	}
 }
 
+func TestRunRetries(t *testing.T) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	task, err := manager.CreateTask("retried", ResourceCreation, NameVariant{"name", "variant"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute}
+	if _, err := manager.SetTaskPolicy(task.ID, policy, time.Hour); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	run, err := manager.CreateTaskRun("run", task.ID, OneOffTrigger{TriggerName: "manual"})
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	lock, err := manager.LockTaskRun(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to lock task run: %v", err)
+	}
+	defer manager.UnlockTaskRun(task.ID, run.ID, lock)
+
+	setStatus := func(status Status, err error) TaskRunMetadata {
+		if e := manager.SetRunStatus(run.ID, task.ID, status, err, lock); e != nil {
+			t.Fatalf("failed to set status %s: %v", status, e)
+		}
+		got, e := manager.GetRunByID(task.ID, run.ID)
+		if e != nil {
+			t.Fatalf("failed to get run: %v", e)
+		}
+		return got
+	}
+
+	got := setStatus(Running, nil)
+	if got.Deadline.IsZero() {
+		t.Fatalf("expected deadline to be set from the task timeout")
+	}
+	got = setStatus(Failed, fmt.Errorf("first failure"))
+	if got.Status != Pending {
+		t.Fatalf("expected run to be pending a retry, got %s", got.Status)
+	}
+	if got.RetryAt.IsZero() || !got.Deadline.IsZero() {
+		t.Fatalf("expected retry time and no deadline, got %v %v", got.RetryAt, got.Deadline)
+	}
+
+	setStatus(Running, nil)
+	got = setStatus(Failed, fmt.Errorf("second failure"))
+	if got.Status != Failed {
+		t.Fatalf("expected run to fail after max attempts, got %s", got.Status)
+	}
+	if len(got.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(got.Attempts))
+	}
+	for i, expectedErr := range []string{"first failure", "second failure"} {
+		attempt := got.Attempts[i]
+		if attempt.Number != i+1 || attempt.Status != Failed || attempt.Error != expectedErr {
+			t.Fatalf("wrong attempt %d: %+v", i, attempt)
+		}
+	}
+}
+
+func TestRunNonRetryableError(t *testing.T) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	task, err := manager.CreateTask("not_retried", ResourceCreation, NameVariant{"name", "variant"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{MaxAttempts: 3}, 0); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	run, err := manager.CreateTaskRun("run", task.ID, OneOffTrigger{TriggerName: "manual"})
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	lock, err := manager.LockTaskRun(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to lock task run: %v", err)
+	}
+	defer manager.UnlockTaskRun(task.ID, run.ID, lock)
+	if err := manager.SetRunStatus(run.ID, task.ID, Running, nil, lock); err != nil {
+		t.Fatalf("failed to set status: %v", err)
+	}
+	failure := &NonRetryableError{Err: fmt.Errorf("resource already failed")}
+	if err := manager.SetRunStatus(run.ID, task.ID, Failed, fmt.Errorf("attempt failed: %w", failure), lock); err != nil {
+		t.Fatalf("failed to set status: %v", err)
+	}
+	got, err := manager.GetRunByID(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if got.Status != Failed || len(got.Attempts) != 1 || got.Error != "attempt failed: resource already failed" {
+		t.Fatalf("expected a non-retryable failure to fail the run, got %+v", got)
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	task, err := manager.CreateTask("cancelled", ResourceCreation, NameVariant{"name", "variant"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	run, err := manager.CreateTaskRun("run", task.ID, OneOffTrigger{TriggerName: "manual"})
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	lock, err := manager.LockTaskRun(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to lock task run: %v", err)
+	}
+	defer manager.UnlockTaskRun(task.ID, run.ID, lock)
+
+	if err := manager.SetRunStatus(run.ID, task.ID, Running, nil, lock); err != nil {
+		t.Fatalf("failed to set status: %v", err)
+	}
+	if err := manager.CancelRun(run.ID, task.ID, lock); err != nil {
+		t.Fatalf("failed to cancel run: %v", err)
+	}
+	got, err := manager.GetRunByID(task.ID, run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+	if got.Status != Cancelled || got.EndTime.IsZero() {
+		t.Fatalf("expected cancelled run with end time, got %s %v", got.Status, got.EndTime)
+	}
+	if len(got.Attempts) != 1 || got.Attempts[0].Status != Cancelled {
+		t.Fatalf("expected cancelled attempt, got %+v", got.Attempts)
+	}
+	if err := manager.CancelRun(run.ID, task.ID, lock); err == nil {
+		t.Fatalf("expected error cancelling a finished run")
+	}
+}
+
 func TestSetEndTimeByRunID(t *testing.T) {
 	type taskInfo struct {
 		Name   string
@@ -934,3 +1064,27 @@ func TestKeyPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTaskJob(t *testing.T) {
+	manager := NewTaskManager(sp.NewMemoryStorageProvider())
+	task, err := manager.CreateTask("task", ResourceCreation, NameVariant{"name", "variant"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := manager.SetTaskPolicy(task.ID, RetryPolicy{MaxAttempts: 2}, time.Minute); err != nil {
+		t.Fatalf("failed to set task policy: %v", err)
+	}
+	if _, err := manager.SetTaskJob(task.ID, []byte(`{"runner":"Materialize"}`)); err != nil {
+		t.Fatalf("failed to set task job: %v", err)
+	}
+	got, err := manager.GetTaskByID(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if string(got.Job) != `{"runner":"Materialize"}` || got.RetryPolicy.MaxAttempts != 2 || got.Timeout != time.Minute {
+		t.Fatalf("expected the job to be stored alongside the task's policy, got %+v", got)
+	}
+	if _, err := manager.SetTaskJob(100, nil); err == nil {
+		t.Fatalf("expected an error setting the job of an unknown task")
+	}
+}
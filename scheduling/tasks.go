@@ -43,13 +43,70 @@ type TaskTarget interface {
 	Type() TargetType
 }
 
+// RetryPolicy controls how many times a failed run is attempted and how long to wait
+// between attempts. The zero value makes a single attempt.
+type RetryPolicy struct {
+	MaxAttempts int `json:"maxAttempts"`
+	// InitialBackoff is the wait before the first retry. It doubles for each retry after
+	// that, up to MaxBackoff if it's set.
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"`
+}
+
+// CanAttempt returns true if another attempt is allowed after the given number of attempts.
+func (p RetryPolicy) CanAttempt(attempts int) bool {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return attempts < maxAttempts
+}
+
+// Backoff returns how long to wait after the given number of failed attempts.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	backoff := p.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// NonRetryableError is the error of an attempt that another attempt would fail the same way. A run
+// that fails with one isn't retried.
+type NonRetryableError struct {
+	Err error
+}
+
+func (e *NonRetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
 type TaskMetadata struct {
-	ID          TaskID     `json:"id"`
-	Name        string     `json:"name"`
-	TaskType    TaskType   `json:"type"`
-	Target      TaskTarget `json:"target"`
-	TargetType  TargetType `json:"targetType"`
-	DateCreated time.Time  `json:"dateCreated"`
+	ID          TaskID      `json:"id"`
+	Name        string      `json:"name"`
+	TaskType    TaskType    `json:"type"`
+	Target      TaskTarget  `json:"target"`
+	TargetType  TargetType  `json:"targetType"`
+	DateCreated time.Time   `json:"dateCreated"`
+	RetryPolicy RetryPolicy `json:"retryPolicy"`
+	// Timeout is how long an attempt can run before it's failed. Zero means no timeout.
+	Timeout time.Duration `json:"timeout"`
+	// Job describes what the scheduler's Executor does for the task's runs. The scheduler only
+	// executes runs of tasks that have one; other runs are executed by whoever created them.
+	Job []byte `json:"job,omitempty"`
 }
 
 func (t *TaskMetadata) Marshal() ([]byte, error) {
@@ -65,6 +122,9 @@ func (t *TaskMetadata) Unmarshal(data []byte) error {
 		Target      json.RawMessage `json:"target"`
 		TargetType  TargetType      `json:"targetType"`
 		DateCreated time.Time       `json:"dateCreated"`
+		RetryPolicy RetryPolicy     `json:"retryPolicy"`
+		Timeout     time.Duration   `json:"timeout"`
+		Job         []byte          `json:"job,omitempty"`
 	}
 
 	var temp tempConfig
@@ -92,6 +152,9 @@ func (t *TaskMetadata) Unmarshal(data []byte) error {
 	t.DateCreated = temp.DateCreated
 
 	t.TargetType = temp.TargetType
+	t.RetryPolicy = temp.RetryPolicy
+	t.Timeout = temp.Timeout
+	t.Job = temp.Job
 
 	targetMap := make(map[string]interface{})
 	if err := json.Unmarshal(temp.Target, &targetMap); err != nil {
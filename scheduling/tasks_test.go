@@ -41,6 +41,23 @@ func TestSerializeTaskMetadata(t *testing.T) {
 			},
 			targettype: "NameVariant",
 		},
+		{
+			name: "WithRetryPolicy",
+			task: TaskMetadata{
+				ID:       1,
+				Name:     "retry_task",
+				TaskType: ResourceCreation,
+				Target: NameVariant{
+					Name:    "transaction",
+					Variant: "default",
+				},
+				TargetType:  NameVariantTarget,
+				DateCreated: time.Now().Truncate(0).UTC(),
+				RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute},
+				Timeout:     time.Hour,
+			},
+			targettype: "NameVariant",
+		},
 	}
 
 	for _, currTest := range testCases {
@@ -65,6 +82,26 @@ func TestSerializeTaskMetadata(t *testing.T) {
 	}
 }
 
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}
+	canAttempt := []bool{true, true, true, true, false}
+	for attempts, expected := range canAttempt {
+		if got := policy.CanAttempt(attempts); got != expected {
+			t.Fatalf("CanAttempt(%d) = %v, expected %v", attempts, got, expected)
+		}
+	}
+	backoffs := []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for attempts, expected := range backoffs {
+		if got := policy.Backoff(attempts); got != expected {
+			t.Fatalf("Backoff(%d) = %v, expected %v", attempts, got, expected)
+		}
+	}
+	zero := RetryPolicy{}
+	if !zero.CanAttempt(0) || zero.CanAttempt(1) {
+		t.Fatalf("Zero policy should allow exactly one attempt")
+	}
+}
+
 func TestIncorrectTaskMetadata(t *testing.T) {
 	testCases := []struct {
 		name string
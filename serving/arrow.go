@@ -0,0 +1,388 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider/types"
+)
+
+const defaultArrowBatchSize = 10_000
+
+// TrainingDataArrow streams a training set as Arrow record batches, which clients can load into
+// columnar dataframes without deserializing a message per row. The schema is built from the
+// features' and label's value types and sent before any of the rows.
+func (serv *FeatureServer) TrainingDataArrow(req *pb.TrainingDataArrowRequest, stream pb.Feature_TrainingDataArrowServer) error {
+	ctx := stream.Context()
+	id := req.GetId()
+	name, variant := id.GetName(), id.GetVersion()
+	featureObserver := serv.Metrics.BeginObservingTrainingServe(name, variant)
+	defer featureObserver.Finish()
+	logger := serv.Logger.With("Name", name, "Variant", variant)
+	logger.Info("Serving training data as arrow")
+	batchSize := int(req.GetBatchSize())
+	if batchSize < 0 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("batch size cannot be negative: %d", batchSize))
+	} else if batchSize == 0 {
+		batchSize = defaultArrowBatchSize
+	}
+	schema, err := serv.trainingSetArrowSchema(ctx, name, variant)
+	if err != nil {
+		logger.Errorw("Failed to build arrow schema", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	iter, err := serv.getTrainingSetIterator(name, variant)
+	if err != nil {
+		logger.Errorw("Failed to get training set iterator", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	serializedSchema, err := serializeArrowRecords(schema)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.ArrowBatch{Schema: serializedSchema}); err != nil {
+		logger.Errorw("Failed to write to stream", "Error", err)
+		featureObserver.SetError()
+		return fferr.NewInternalError(err)
+	}
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	rows := 0
+	sendBatch := func() error {
+		record := builder.NewRecord()
+		defer record.Release()
+		serialized, err := serializeArrowRecords(schema, record)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.ArrowBatch{RecordBatch: serialized, NumRows: record.NumRows()}); err != nil {
+			logger.Errorw("Failed to write to stream", "Error", err)
+			return fferr.NewInternalError(err)
+		}
+		rows = 0
+		return nil
+	}
+	for iter.Next() {
+		// Copy the features so appending the label can't write into the iterator's slice.
+		features := iter.Features()
+		values := make([]interface{}, len(features), len(features)+1)
+		copy(values, features)
+		values = append(values, iter.Label())
+		if err := appendArrowRow(builder, values); err != nil {
+			featureObserver.SetError()
+			return err
+		}
+		featureObserver.ServeRow()
+		rows++
+		if rows == batchSize {
+			if err := sendBatch(); err != nil {
+				featureObserver.SetError()
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		logger.Errorw("Dataset error", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	if rows > 0 {
+		if err := sendBatch(); err != nil {
+			featureObserver.SetError()
+			return err
+		}
+	}
+	if model := req.GetModel(); model != nil {
+		trainingSets := []metadata.NameVariant{{Name: name, Variant: variant}}
+		err := serv.Metadata.CreateModel(ctx, metadata.ModelDef{Name: model.GetName(), Trainingsets: trainingSets})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trainingSetArrowSchema returns a schema with a column per feature, in the training set's order,
+// followed by the label. Columns are named the same as in TrainingDataColumns.
+func (serv *FeatureServer) trainingSetArrowSchema(ctx context.Context, name, variant string) (*arrow.Schema, error) {
+	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	featureIDs := ts.Features()
+	features, err := serv.Metadata.GetFeatureVariants(ctx, featureIDs)
+	if err != nil {
+		return nil, err
+	}
+	featureTypes := make(map[metadata.NameVariant]types.ValueType, len(features))
+	for _, feature := range features {
+		valueType, err := feature.Type()
+		if err != nil {
+			return nil, err
+		}
+		featureTypes[metadata.NameVariant{Name: feature.Name(), Variant: feature.Variant()}] = valueType
+	}
+	fields := make([]arrow.Field, 0, len(featureIDs)+1)
+	for _, id := range featureIDs {
+		valueType, has := featureTypes[id]
+		if !has {
+			return nil, fferr.NewDatasetNotFoundError(id.Name, id.Variant, fmt.Errorf("feature not found"))
+		}
+		field, err := arrowField(fmt.Sprintf("feature__%s__%s", id.Name, id.Variant), valueType)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	labelID := ts.Label()
+	label, err := serv.Metadata.GetLabelVariant(ctx, labelID)
+	if err != nil {
+		return nil, err
+	}
+	labelType, err := label.Type()
+	if err != nil {
+		return nil, err
+	}
+	labelField, err := arrowField(fmt.Sprintf("label__%s__%s", labelID.Name, labelID.Variant), labelType)
+	if err != nil {
+		return nil, err
+	}
+	fields = append(fields, labelField)
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func arrowField(name string, valueType types.ValueType) (arrow.Field, error) {
+	dataType, err := arrowDataType(valueType)
+	if err != nil {
+		return arrow.Field{}, err
+	}
+	return arrow.Field{Name: name, Type: dataType, Nullable: true}, nil
+}
+
+func arrowDataType(valueType types.ValueType) (arrow.DataType, error) {
+	if vector, isVector := valueType.(types.VectorType); isVector {
+		elem, err := arrowDataType(vector.ScalarType)
+		if err != nil {
+			return nil, err
+		}
+		return arrow.FixedSizeListOf(vector.Dimension, elem), nil
+	}
+	switch valueType.Scalar() {
+	case types.Int, types.Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case types.Int8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case types.Int16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case types.Int32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case types.UInt8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case types.UInt16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case types.UInt32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case types.UInt64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case types.Float32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case types.Float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case types.String:
+		return arrow.BinaryTypes.String, nil
+	case types.Bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case types.Timestamp, types.Datetime:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, nil
+	default:
+		return nil, fferr.NewDataTypeNotFoundErrorf(valueType, "no arrow type for value type")
+	}
+}
+
+// serializeArrowRecords writes the records to an Arrow IPC stream.
+func serializeArrowRecords(schema *arrow.Schema, records ...arrow.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			writer.Close()
+			return nil, fferr.NewInternalError(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fferr.NewInternalError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func appendArrowRow(builder *array.RecordBuilder, values []interface{}) error {
+	if len(values) != len(builder.Fields()) {
+		return fferr.NewInternalError(fmt.Errorf("row has %d values, schema has %d columns", len(values), len(builder.Fields())))
+	}
+	for i, value := range values {
+		if err := appendArrowValue(builder.Field(i), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendArrowValue(builder array.Builder, value interface{}) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+	switch b := builder.(type) {
+	case *array.Int8Builder:
+		v, err := arrowInt(value)
+		b.Append(int8(v))
+		return err
+	case *array.Int16Builder:
+		v, err := arrowInt(value)
+		b.Append(int16(v))
+		return err
+	case *array.Int32Builder:
+		v, err := arrowInt(value)
+		b.Append(int32(v))
+		return err
+	case *array.Int64Builder:
+		v, err := arrowInt(value)
+		b.Append(v)
+		return err
+	case *array.Uint8Builder:
+		v, err := arrowInt(value)
+		b.Append(uint8(v))
+		return err
+	case *array.Uint16Builder:
+		v, err := arrowInt(value)
+		b.Append(uint16(v))
+		return err
+	case *array.Uint32Builder:
+		v, err := arrowInt(value)
+		b.Append(uint32(v))
+		return err
+	case *array.Uint64Builder:
+		v, err := arrowInt(value)
+		b.Append(uint64(v))
+		return err
+	case *array.Float32Builder:
+		v, err := arrowFloat(value)
+		b.Append(float32(v))
+		return err
+	case *array.Float64Builder:
+		v, err := arrowFloat(value)
+		b.Append(v)
+		return err
+	case *array.StringBuilder:
+		b.Append(fmt.Sprintf("%v", value))
+		return nil
+	case *array.BooleanBuilder:
+		v, ok := value.(bool)
+		if !ok {
+			b.AppendNull()
+			return fferr.NewDataTypeNotFoundErrorf(value, "expected bool")
+		}
+		b.Append(v)
+		return nil
+	case *array.TimestampBuilder:
+		v, ok := value.(time.Time)
+		if !ok {
+			b.AppendNull()
+			return fferr.NewDataTypeNotFoundErrorf(value, "expected timestamp")
+		}
+		b.Append(arrow.Timestamp(v.UnixNano()))
+		return nil
+	case *array.FixedSizeListBuilder:
+		return appendArrowList(b, value)
+	default:
+		return fferr.NewInternalError(fmt.Errorf("unsupported arrow builder %T", builder))
+	}
+}
+
+func appendArrowList(builder *array.FixedSizeListBuilder, value interface{}) error {
+	var elems []interface{}
+	switch casted := value.(type) {
+	case []float32:
+		elems = make([]interface{}, len(casted))
+		for i, v := range casted {
+			elems[i] = v
+		}
+	case []float64:
+		elems = make([]interface{}, len(casted))
+		for i, v := range casted {
+			elems[i] = v
+		}
+	case []interface{}:
+		elems = casted
+	default:
+		builder.AppendNull()
+		return fferr.NewDataTypeNotFoundErrorf(value, "expected vector")
+	}
+	dimension := int(builder.Type().(*arrow.FixedSizeListType).Len())
+	if len(elems) != dimension {
+		builder.AppendNull()
+		return fferr.NewDataTypeNotFoundErrorf(value, "expected vector with %d dimensions, got %d", dimension, len(elems))
+	}
+	builder.Append(true)
+	for _, elem := range elems {
+		if err := appendArrowValue(builder.ValueBuilder(), elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func arrowInt(value interface{}) (int64, error) {
+	switch casted := value.(type) {
+	case int:
+		return int64(casted), nil
+	case int8:
+		return int64(casted), nil
+	case int16:
+		return int64(casted), nil
+	case int32:
+		return int64(casted), nil
+	case int64:
+		return casted, nil
+	case uint8:
+		return int64(casted), nil
+	case uint16:
+		return int64(casted), nil
+	case uint32:
+		return int64(casted), nil
+	case uint64:
+		return int64(casted), nil
+	default:
+		return 0, fferr.NewDataTypeNotFoundErrorf(value, "expected integer")
+	}
+}
+
+func arrowFloat(value interface{}) (float64, error) {
+	switch casted := value.(type) {
+	case float32:
+		return float64(casted), nil
+	case float64:
+		return casted, nil
+	default:
+		if i, err := arrowInt(value); err == nil {
+			return float64(i), nil
+		}
+		return 0, fferr.NewDataTypeNotFoundErrorf(value, "expected float")
+	}
+}
@@ -0,0 +1,173 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/ipc"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	grpcmeta "google.golang.org/grpc/metadata"
+
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	"github.com/featureform/provider/types"
+)
+
+type mockArrowStream struct {
+	Batches []*pb.ArrowBatch
+}
+
+func (stream *mockArrowStream) Send(batch *pb.ArrowBatch) error {
+	stream.Batches = append(stream.Batches, batch)
+	return nil
+}
+
+func (stream *mockArrowStream) Context() context.Context {
+	return context.Background()
+}
+
+func (stream *mockArrowStream) SetHeader(grpcmeta.MD) error {
+	return nil
+}
+
+func (stream *mockArrowStream) SendHeader(grpcmeta.MD) error {
+	return nil
+}
+
+func (stream *mockArrowStream) SetTrailer(grpcmeta.MD) {
+}
+
+func (stream *mockArrowStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func (stream *mockArrowStream) RecvMsg(interface{}) error {
+	return nil
+}
+
+func readArrowStream(t *testing.T, serialized []byte) (*arrow.Schema, []arrow.Record) {
+	reader, err := ipc.NewReader(bytes.NewReader(serialized), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatalf("Failed to read arrow stream: %s", err)
+	}
+	defer reader.Release()
+	records := make([]arrow.Record, 0)
+	for reader.Next() {
+		record := reader.Record()
+		record.Retain()
+		records = append(records, record)
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Failed to read arrow record: %s", err)
+	}
+	return reader.Schema(), records
+}
+
+func TestTrainingDataArrow(t *testing.T) {
+	defsFn := func(providerType string) []metadata.ResourceDef {
+		defs := simpleResourceDefsFn(providerType)
+		for i, def := range defs {
+			switch casted := def.(type) {
+			case metadata.FeatureDef:
+				casted.Type = types.Float64
+				defs[i] = casted
+			case metadata.LabelDef:
+				casted.Type = types.Bool
+				defs[i] = casted
+			}
+		}
+		return defs
+	}
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	labelId := provider.ResourceID{Name: "label", Variant: "variant", Type: provider.Label}
+	recs := map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: {{Entity: "a", Value: 12.5}, {Entity: "b", Value: 3.0}},
+		labelId:   {{Entity: "a", Value: true}, {Entity: "b", Value: false}},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: defsFn,
+		FactoryFn:      createMockOfflineStoreFactory(recs, simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.TrainingDataArrowRequest{
+		Id:        &pb.TrainingDataID{Name: "training-set", Version: "variant"},
+		BatchSize: 1,
+	}
+	stream := &mockArrowStream{}
+	if err := serv.TrainingDataArrow(req, stream); err != nil {
+		t.Fatalf("Failed to get arrow training data: %s", err)
+	}
+	if len(stream.Batches) != 3 {
+		t.Fatalf("Expected a schema and 2 batches, got %d messages", len(stream.Batches))
+	}
+	schema, records := readArrowStream(t, stream.Batches[0].Schema)
+	if len(records) != 0 {
+		t.Fatalf("Schema message should not have records: %v", records)
+	}
+	expectedSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "feature__feature__variant", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "label__label__variant", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	}, nil)
+	if !schema.Equal(expectedSchema) {
+		t.Fatalf("Wrong schema\nExpected: %s\nFound: %s", expectedSchema, schema)
+	}
+	type Row struct {
+		Feature float64
+		Label   bool
+	}
+	actual := make([]Row, 0)
+	for _, batch := range stream.Batches[1:] {
+		if batch.NumRows != 1 {
+			t.Fatalf("Expected 1 row per batch, got %d", batch.NumRows)
+		}
+		_, records := readArrowStream(t, batch.RecordBatch)
+		for _, record := range records {
+			features := record.Column(0).(*array.Float64)
+			labels := record.Column(1).(*array.Boolean)
+			for i := 0; i < int(record.NumRows()); i++ {
+				actual = append(actual, Row{features.Value(i), labels.Value(i)})
+			}
+			record.Release()
+		}
+	}
+	assert.ElementsMatch(t, []Row{{12.5, true}, {3.0, false}}, actual)
+}
+
+func TestAppendArrowValue(t *testing.T) {
+	vectorType := types.VectorType{ScalarType: types.Float32, Dimension: 2}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "int", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "vector", Type: arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Float32), Nullable: true},
+	}, nil)
+	dataType, err := arrowDataType(vectorType)
+	if err != nil {
+		t.Fatalf("Failed to get vector type: %s", err)
+	}
+	if !arrow.TypeEqual(dataType, schema.Field(1).Type) {
+		t.Fatalf("Wrong vector type: %s", dataType)
+	}
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	if err := appendArrowRow(builder, []interface{}{int64(7), []float32{1, 2}}); err != nil {
+		t.Fatalf("Failed to append row: %s", err)
+	}
+	if err := appendArrowRow(builder, []interface{}{nil, nil}); err != nil {
+		t.Fatalf("Failed to append nulls: %s", err)
+	}
+	if err := appendArrowRow(builder, []interface{}{"a", nil}); err == nil {
+		t.Fatalf("Appending a string to an int column should fail")
+	}
+	if err := appendArrowRow(builder, []interface{}{1, []float32{1}}); err == nil {
+		t.Fatalf("Appending a vector with the wrong dimension should fail")
+	}
+}
@@ -0,0 +1,170 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/featureform/coordinator"
+	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
+)
+
+// valueCache is a bounded LRU cache of a feature variant's online values, keyed by entity.
+type valueCache struct {
+	mtx        sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type cachedValue struct {
+	entity  string
+	value   interface{}
+	ts      time.Time
+	expires time.Time
+}
+
+func newValueCache(config metadata.ValueCacheConfig) *valueCache {
+	return &valueCache{
+		maxEntries: config.MaxEntries,
+		ttl:        config.TTL,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *valueCache) get(entity string, now time.Time) (cachedValue, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	elem, has := c.entries[entity]
+	if !has {
+		return cachedValue{}, false
+	}
+	cached := elem.Value.(cachedValue)
+	if !cached.expires.IsZero() && now.After(cached.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, entity)
+		return cachedValue{}, false
+	}
+	c.order.MoveToFront(elem)
+	return cached, true
+}
+
+func (c *valueCache) set(entity string, value interface{}, ts time.Time, now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cached := cachedValue{entity: entity, value: value, ts: ts}
+	if c.ttl > 0 {
+		cached.expires = now.Add(c.ttl)
+	}
+	if elem, has := c.entries[entity]; has {
+		elem.Value = cached
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[entity] = c.order.PushFront(cached)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cachedValue).entity)
+	}
+}
+
+func (c *valueCache) purge() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+func (serv *FeatureServer) getOrCacheValueCache(meta *metadata.FeatureVariant) *valueCache {
+	key := serv.getNVCacheKey(meta.Name(), meta.Variant())
+	cache, _ := serv.ValueCaches.LoadOrStore(key, newValueCache(meta.ValueCache()))
+	return cache.(*valueCache)
+}
+
+// getCachedEntityValues serves what it can from the feature variant's value cache and reads the
+// rest of the entities from the online table, caching them for later requests.
+func (serv *FeatureServer) getCachedEntityValues(ctx context.Context, entities []string, featureTable provider.OnlineStoreTable, meta *metadata.FeatureVariant) ([]indexedValue, error) {
+	cache := serv.getOrCacheValueCache(meta)
+	now := time.Now()
+	results := make([]indexedValue, len(entities))
+	missing := make([]string, 0)
+	missingIdx := make([]int, 0)
+	for i, entity := range entities {
+		if cached, has := cache.get(entity, now); has {
+			results[i] = indexedValue{index: i, value: cached.value, ts: cached.ts}
+		} else {
+			missing = append(missing, entity)
+			missingIdx = append(missingIdx, i)
+		}
+	}
+	if obs, ok := ctx.Value(observer{}).(metrics.CacheObserver); ok {
+		obs.CacheHits(len(entities) - len(missing))
+		obs.CacheMisses(len(missing))
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	fetched, err := serv.getEntityValues(ctx, missing, featureTable)
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range fetched {
+		cache.set(missing[i], val.value, val.ts, now)
+		results[missingIdx[i]] = indexedValue{index: missingIdx[i], value: val.value, ts: val.ts}
+	}
+	return results, nil
+}
+
+// InvalidateValueCache drops every cached value of a feature variant.
+func (serv *FeatureServer) InvalidateValueCache(name, variant string) {
+	if cache, has := serv.ValueCaches.Load(serv.getNVCacheKey(name, variant)); has {
+		cache.(*valueCache).purge()
+	}
+}
+
+// updateEventPrefix is the prefix of the keys that workers write a coordinator.ResourceUpdatedEvent to
+// when a scheduled materialization completes.
+const updateEventPrefix = "UPDATE_EVENT_"
+
+// WatchResourceUpdates invalidates a feature variant's value cache whenever a materialization
+// of it completes. It blocks until ctx is cancelled.
+func (serv *FeatureServer) WatchResourceUpdates(ctx context.Context, client *clientv3.Client) error {
+	for resp := range client.Watch(ctx, updateEventPrefix, clientv3.WithPrefix()) {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, event := range resp.Events {
+			if event.Type != clientv3.EventTypePut {
+				continue
+			}
+			updated := &coordinator.ResourceUpdatedEvent{}
+			if err := updated.Deserialize(coordinator.Config(event.Kv.Value)); err != nil {
+				serv.Logger.Errorw("Failed to parse resource update event", "key", string(event.Kv.Key), "error", err)
+				continue
+			}
+			serv.handleResourceUpdate(updated)
+		}
+	}
+	return ctx.Err()
+}
+
+func (serv *FeatureServer) handleResourceUpdate(event *coordinator.ResourceUpdatedEvent) {
+	id := event.ResourceID
+	if id.Type != metadata.FEATURE_VARIANT {
+		return
+	}
+	serv.Logger.Infow("Invalidating value cache", "name", id.Name, "variant", id.Variant, "completed", event.Completed)
+	serv.InvalidateValueCache(id.Name, id.Variant)
+}
@@ -0,0 +1,105 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/featureform/coordinator"
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	"github.com/featureform/provider/types"
+)
+
+func TestValueCache(t *testing.T) {
+	now := time.Now()
+	cache := newValueCache(metadata.ValueCacheConfig{MaxEntries: 2, TTL: time.Minute})
+	cache.set("a", 1, time.Time{}, now)
+	cache.set("b", 2, time.Time{}, now)
+	// Reading a makes b the least recently used entry.
+	if val, has := cache.get("a", now); !has || val.value != 1 {
+		t.Fatalf("Expected cached value 1, got %v %v", val.value, has)
+	}
+	cache.set("c", 3, time.Time{}, now)
+	if _, has := cache.get("b", now); has {
+		t.Fatalf("Least recently used entry should be evicted")
+	}
+	if _, has := cache.get("a", now); !has {
+		t.Fatalf("Recently used entry should not be evicted")
+	}
+	if _, has := cache.get("c", now.Add(2*time.Minute)); has {
+		t.Fatalf("Expired entry should not be served")
+	}
+	cache.purge()
+	if _, has := cache.get("a", now); has {
+		t.Fatalf("Purged entry should not be served")
+	}
+}
+
+func TestFeatureServeValueCache(t *testing.T) {
+	defsFn := func(providerType string) []metadata.ResourceDef {
+		defs := simpleResourceDefsFn(providerType)
+		for i, def := range defs {
+			if feat, ok := def.(metadata.FeatureDef); ok && feat.Variant == "variant" {
+				feat.ValueCache = metadata.ValueCacheConfig{MaxEntries: 10}
+				defs[i] = feat
+			}
+		}
+		return defs
+	}
+	var table provider.OnlineStoreTable
+	factory := func(cfg pc.SerializedConfig) (provider.Provider, error) {
+		store := provider.NewLocalOnlineStore()
+		var err error
+		if table, err = store.CreateTable("feature", "variant", types.Float64); err != nil {
+			panic(err)
+		}
+		if err := table.Set("a", 1.5); err != nil {
+			panic(err)
+		}
+		return store, nil
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: defsFn,
+		FactoryFn:      factory,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{Name: "feature", Version: "variant"},
+		},
+		Entities: []*pb.Entity{
+			{Name: "mockEntity", Values: []string{"a"}},
+		},
+	}
+	serve := func() interface{} {
+		resp, err := serv.FeatureServe(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Failed to serve feature: %s", err)
+		}
+		return unwrapVal(resp.ValueLists[0].Values[0])
+	}
+	assert.Equal(t, 1.5, serve())
+	if err := table.Set("a", 2.5); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	assert.Equal(t, 1.5, serve(), "value should be served from the cache")
+	serv.handleResourceUpdate(&coordinator.ResourceUpdatedEvent{
+		ResourceID: metadata.ResourceID{Name: "label", Variant: "variant", Type: metadata.LABEL_VARIANT},
+	})
+	assert.Equal(t, 1.5, serve(), "updates to other resources should not invalidate the cache")
+	serv.handleResourceUpdate(&coordinator.ResourceUpdatedEvent{
+		ResourceID: metadata.ResourceID{Name: "feature", Variant: "variant", Type: metadata.FEATURE_VARIANT},
+		Completed:  time.Now(),
+	})
+	assert.Equal(t, 2.5, serve(), "value should be read again after the feature is updated")
+}
@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celtypes "github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+)
+
+func (serv *FeatureServer) getOrCacheExpression(meta *metadata.FeatureVariant, expr metadata.Expression) (cel.Program, error) {
+	key := serv.getNVCacheKey(meta.Name(), meta.Variant())
+	if program, has := serv.Expressions.Load(key); has {
+		return program.(cel.Program), nil
+	}
+	program, err := expr.Compile()
+	if err != nil {
+		return nil, err
+	}
+	serv.Expressions.Store(key, program)
+	return program, nil
+}
+
+// getExpressionValues evaluates a SERVER_COMPUTED feature once per entity. The input features are
+// fetched first and bound to their variable names, so row i of the result is computed from row i
+// of every input.
+func (serv *FeatureServer) getExpressionValues(ctx context.Context, entityMap map[string][]string, requestInputs map[string]interface{}, meta *metadata.FeatureVariant) ([]interface{}, error) {
+	expr, ok := meta.LocationExpression().(metadata.Expression)
+	if !ok {
+		return nil, fferr.NewInternalError(fmt.Errorf("feature %s:%s has no expression", meta.Name(), meta.Variant()))
+	}
+	program, err := serv.getOrCacheExpression(meta, expr)
+	if err != nil {
+		return nil, err
+	}
+	numRows := 1
+	if entities, has := entityMap[meta.Entity()]; has {
+		numRows = len(entities)
+	}
+	inputs := make(map[string][]interface{}, len(expr.Inputs))
+	for name, input := range expr.Inputs {
+		inputMeta, err := serv.getOrCacheFeatureMetadata(ctx, input.Name, input.Variant)
+		if err != nil {
+			return nil, err
+		}
+		if inputMeta.Mode() == metadata.CLIENT_COMPUTED {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("expression input %s:%s is computed by the client", input.Name, input.Variant))
+		}
+		values, _, err := serv.getRawFeatureValues(ctx, entityMap, requestInputs, inputMeta)
+		if err != nil {
+			return nil, err
+		}
+		if len(inputs) == 0 {
+			numRows = len(values)
+		} else if len(values) != numRows {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("expression inputs of %s:%s return different numbers of rows", meta.Name(), meta.Variant()))
+		}
+		inputs[name] = values
+	}
+	if requestInputs == nil {
+		requestInputs = make(map[string]interface{})
+	}
+	now := time.Now().UTC()
+	results := make([]interface{}, numRows)
+	for i := range results {
+		activation := map[string]interface{}{
+			metadata.ExpressionRequestVariable: requestInputs,
+			metadata.ExpressionNowVariable:     now,
+		}
+		for name, values := range inputs {
+			activation[name] = values[i]
+		}
+		out, _, err := program.Eval(activation)
+		if err != nil {
+			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("failed to evaluate %s:%s: %w", meta.Name(), meta.Variant(), err))
+		}
+		results[i], err = expressionResult(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+var float32ListType = reflect.TypeOf([]float32{})
+
+// expressionResult converts a CEL value into a Go value that wrapValue can serialize.
+func expressionResult(val ref.Val) (interface{}, error) {
+	switch val.Type() {
+	case celtypes.NullType:
+		return nil, nil
+	case celtypes.ListType:
+		return val.ConvertToNative(float32ListType)
+	case celtypes.DurationType:
+		return val.Value().(time.Duration).Seconds(), nil
+	default:
+		return val.Value(), nil
+	}
+}
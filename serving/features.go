@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/featureform/fferr"
 	"github.com/featureform/metadata"
@@ -11,11 +12,15 @@ import (
 	pb "github.com/featureform/proto"
 	"github.com/featureform/provider"
 	pt "github.com/featureform/provider/provider_type"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type indexedValue struct {
 	index int
 	value interface{}
+	// ts is the event time of the value. It's zero if the table doesn't track event times
+	// or the value was written without one.
+	ts time.Time
 }
 
 type indexedFeatureRow struct {
@@ -23,7 +28,7 @@ type indexedFeatureRow struct {
 	values *pb.ValueList
 }
 
-func (serv *FeatureServer) getFeatureRows(ctx context.Context, features []*pb.FeatureID, entityMap map[string][]string) ([]*pb.ValueList, error) {
+func (serv *FeatureServer) getFeatureRows(ctx context.Context, features []*pb.FeatureID, entityMap map[string][]string, requestInputs map[string]interface{}) ([]*pb.ValueList, error) {
 	vals := make(chan indexedFeatureRow, len(features))
 	errc := make(chan error, len(features))
 
@@ -31,7 +36,7 @@ func (serv *FeatureServer) getFeatureRows(ctx context.Context, features []*pb.Fe
 
 	// This function creates async requests to fetch feature values
 	// so that everything can be done in parallel.
-	serv.sendFeatureRequests(ctx, features, entityMap, vals, errc)
+	serv.sendFeatureRequests(ctx, features, entityMap, requestInputs, vals, errc)
 
 	// This function collects the results of the async requests
 	// from the channels from the previous function.
@@ -47,14 +52,14 @@ func (serv *FeatureServer) getFeatureRows(ctx context.Context, features []*pb.Fe
 	return results, nil
 }
 
-func (serv *FeatureServer) sendFeatureRequests(ctx context.Context, features []*pb.FeatureID, entityMap map[string][]string, vals chan indexedFeatureRow, errc chan error) {
+func (serv *FeatureServer) sendFeatureRequests(ctx context.Context, features []*pb.FeatureID, entityMap map[string][]string, requestInputs map[string]interface{}, vals chan indexedFeatureRow, errc chan error) {
 	// We asynchronously start fetches for each feature in the request
 	for i, feature := range features {
 		go func(i int, feature *pb.FeatureID) {
 			name, variant := feature.GetName(), feature.GetVersion()
 
 			// Features can have multiple values (one per entity)
-			valueList, err := serv.getFeatureValues(ctx, name, variant, entityMap)
+			valueList, err := serv.getFeatureValues(ctx, name, variant, entityMap, requestInputs)
 			if err != nil {
 				errc <- err
 				serv.Logger.Errorw("Could not get feature value", "Name", name, "Variant", variant, "Error", err.Error())
@@ -89,7 +94,7 @@ func (serv *FeatureServer) collectFeatures(features []*pb.FeatureID, vals chan i
 
 }
 
-func (serv *FeatureServer) getFeatureValues(ctx context.Context, name, variant string, entityMap map[string][]string) (*pb.ValueList, error) {
+func (serv *FeatureServer) getFeatureValues(ctx context.Context, name, variant string, entityMap map[string][]string, requestInputs map[string]interface{}) (*pb.ValueList, error) {
 
 	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
 	ctx = context.WithValue(ctx, observer{}, obs)
@@ -100,27 +105,67 @@ func (serv *FeatureServer) getFeatureValues(ctx context.Context, name, variant s
 		return nil, err
 	}
 
+	values, timestamps, err := serv.getRawFeatureValues(ctx, entityMap, requestInputs, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	valueList, err := serv.castValues(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+	valueList.EventTimestamps = timestamps
+	return valueList, nil
+}
+
+// getRawFeatureValues returns the feature's values for each entity before they're serialized, along
+// with their event times if the online store tracks them.
+func (serv *FeatureServer) getRawFeatureValues(ctx context.Context, entityMap map[string][]string, requestInputs map[string]interface{}, meta *metadata.FeatureVariant) ([]interface{}, []*tspb.Timestamp, error) {
 	var values []interface{}
+	var timestamps []*tspb.Timestamp
 	switch meta.Mode() {
 	case metadata.PRECOMPUTED:
 		if meta.Provider() == "" {
-			return nil, fferr.NewInvalidArgumentError(fmt.Errorf("feature %s:%s is not saved in an inference store", name, variant))
+			return nil, nil, fferr.NewInvalidArgumentError(fmt.Errorf("feature %s:%s is not saved in an inference store", meta.Name(), meta.Variant()))
 		}
 
-		precomputedValues, err := serv.getPrecomputedValues(ctx, entityMap, meta)
+		precomputedValues, tracksTimestamps, err := serv.getPrecomputedValues(ctx, entityMap, meta)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		ttl := meta.TTL()
+		now := time.Now()
 		for _, val := range precomputedValues {
-			values = append(values, val.value)
+			if isExpired(val.ts, ttl, now) {
+				values = append(values, nil)
+			} else {
+				values = append(values, val.value)
+			}
+			if tracksTimestamps {
+				timestamps = append(timestamps, tspb.New(val.ts))
+			}
 		}
 	case metadata.CLIENT_COMPUTED:
 		values = append(values, meta.LocationFunction())
+	case metadata.SERVER_COMPUTED:
+		computed, err := serv.getExpressionValues(ctx, entityMap, requestInputs, meta)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = computed
 	default:
-		return nil, fferr.NewInternalError(fmt.Errorf("unknown computation mode %v", meta.Mode()))
+		return nil, nil, fferr.NewInternalError(fmt.Errorf("unknown computation mode %v", meta.Mode()))
 	}
+	return values, timestamps, nil
+}
 
-	return serv.castValues(ctx, values)
+// isExpired returns true if a value with event time ts is older than ttl. Values without
+// an event time and features without a TTL never expire.
+func isExpired(ts time.Time, ttl time.Duration, now time.Time) bool {
+	if ttl <= 0 || ts.IsZero() {
+		return false
+	}
+	return now.Sub(ts) > ttl
 }
 
 func (serv *FeatureServer) getOrCacheFeatureMetadata(ctx context.Context, name, variant string) (*metadata.FeatureVariant, error) {
@@ -145,33 +190,41 @@ func (serv *FeatureServer) getOrCacheFeatureMetadata(ctx context.Context, name,
 	}
 }
 
-func (serv *FeatureServer) getPrecomputedValues(ctx context.Context, entityMap map[string][]string, meta *metadata.FeatureVariant) ([]indexedValue, error) {
+// getPrecomputedValues fetches the feature's values for each entity from its online store. The returned
+// bool is true if the store tracks event times, in which case each value's ts is set.
+func (serv *FeatureServer) getPrecomputedValues(ctx context.Context, entityMap map[string][]string, meta *metadata.FeatureVariant) ([]indexedValue, bool, error) {
 	logger := serv.Logger
 	obs := ctx.Value(observer{}).(metrics.FeatureObserver)
 	entities, has := entityMap[meta.Entity()]
 	if !has {
 		logger.Errorw("Entity not found", "Entity", meta.Entity())
 		obs.SetError()
-		return nil, fferr.NewEntityNotFoundError(meta.Name(), meta.Variant(), meta.Entity(), nil)
+		return nil, false, fferr.NewEntityNotFoundError(meta.Name(), meta.Variant(), meta.Entity(), nil)
 	}
 
 	store, err := serv.getOrCacheFeatureProvider(ctx, meta)
 	if err != nil {
 		logger.Errorw("Could not fetch provider", "Entity", meta.Entity())
 		obs.SetError()
-		return nil, err
+		return nil, false, err
 	}
 
 	featureTable, err := serv.cacheFeatureTable(ctx, store, meta.Name(), meta.Variant())
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	featureValues, err := serv.getEntityValues(ctx, entities, featureTable)
+	var featureValues []indexedValue
+	if meta.ValueCache().MaxEntries > 0 {
+		featureValues, err = serv.getCachedEntityValues(ctx, entities, featureTable, meta)
+	} else {
+		featureValues, err = serv.getEntityValues(ctx, entities, featureTable)
+	}
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return featureValues, nil
+	_, tracksTimestamps := featureTable.(provider.TimestampedOnlineStoreTable)
+	return featureValues, tracksTimestamps, nil
 
 }
 
@@ -236,20 +289,42 @@ func (serv *FeatureServer) cacheFeatureTable(ctx context.Context, store provider
 func (serv *FeatureServer) getEntityValues(ctx context.Context, entities []string, featureTable provider.OnlineStoreTable) ([]indexedValue, error) {
 	obs := ctx.Value(observer{}).(metrics.FeatureObserver)
 
+	// Tables that support it fetch every entity at once rather than making a round trip per entity.
+	if batchTable, ok := featureTable.(provider.BatchGetOnlineTable); ok {
+		items, err := batchTable.BatchGet(entities)
+		if err != nil {
+			serv.Logger.Errorw("entity not found", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		results := make([]indexedValue, len(items))
+		for i, item := range items {
+			results[i] = indexedValue{index: i, value: item.Value, ts: item.TS}
+		}
+		return results, nil
+	}
+
 	valCh := make(chan indexedValue, len(entities))
 	errCh := make(chan error, len(entities))
 
 	for i, entityVal := range entities {
 		// Start a goroutine for each entity
 		go func(index int, ev string) {
-			val, err := featureTable.(provider.OnlineStoreTable).Get(ev)
+			var val interface{}
+			var ts time.Time
+			var err error
+			if tsTable, ok := featureTable.(provider.TimestampedOnlineStoreTable); ok {
+				val, ts, err = tsTable.GetWithTimestamp(ev)
+			} else {
+				val, err = featureTable.Get(ev)
+			}
 			if err != nil {
 				// Push error into the error channel
 				errCh <- err
 				return
 			}
 			// If no error, push value into the value channel
-			valCh <- indexedValue{index: index, value: val}
+			valCh <- indexedValue{index: index, value: val, ts: ts}
 		}(i, entityVal)
 	}
 
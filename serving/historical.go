@@ -0,0 +1,281 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/featureform/fferr"
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/provider/types"
+	"github.com/google/uuid"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type entityTimestamp struct {
+	Entity string
+	TS     time.Time
+}
+
+// HistoricalFeatures returns point-in-time correct feature values for each requested (entity, timestamp) row.
+// The rows are written to a temporary label table in the features' offline store so that the same join used
+// by CreateTrainingSet computes the values. Each row's position is stored as the label value, which lets us
+// pair the results back up with the requested rows regardless of the order the store returns them in.
+func (serv *FeatureServer) HistoricalFeatures(req *pb.HistoricalFeaturesRequest, stream pb.Feature_HistoricalFeaturesServer) error {
+	ctx := stream.Context()
+	features := req.GetFeatures()
+	logger := serv.Logger.With("Features", features)
+	logger.Info("Serving historical features")
+	if len(features) == 0 {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("at least one feature is required"))
+	}
+	ids := make([]provider.ResourceID, len(features))
+	for i, feature := range features {
+		ids[i] = provider.ResourceID{Name: feature.GetName(), Variant: feature.GetVersion(), Type: provider.Feature}
+	}
+	rows, err := serv.getEntityTimestampRows(ctx, req)
+	if err != nil {
+		logger.Errorw("Failed to get entity timestamp rows", "Error", err)
+		return err
+	}
+	store, err := serv.getFeaturesOfflineStore(ctx, ids)
+	if err != nil {
+		logger.Errorw("Failed to get offline store for features", "Error", err)
+		return err
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Errorw("Failed to close offline store", "Error", err)
+		}
+	}()
+	name := fmt.Sprintf("historical-%s", uuid.NewString())
+	defer serv.dropHistoricalTrainingSet(store, name)
+	iter, err := serv.createHistoricalTrainingSet(store, name, ids, rows)
+	if err != nil {
+		logger.Errorw("Failed to create historical training set", "Error", err)
+		return err
+	}
+	if closer, ok := iter.(io.Closer); ok {
+		// The training set can't be dropped while its rows are still being read.
+		defer closer.Close()
+	}
+	for iter.Next() {
+		idx, err := rowIndex(iter.Label())
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= len(rows) {
+			return fferr.NewInternalError(fmt.Errorf("row index %d out of range", idx))
+		}
+		sRow, err := serializedHistoricalRow(rows[idx], iter.Features())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(sRow); err != nil {
+			logger.Errorw("Failed to write to stream", "Error", err)
+			return fferr.NewInternalError(err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		logger.Errorw("Dataset error", "Error", err)
+		return err
+	}
+	return nil
+}
+
+func (serv *FeatureServer) getEntityTimestampRows(ctx context.Context, req *pb.HistoricalFeaturesRequest) ([]entityTimestamp, error) {
+	switch rows := req.GetRows().(type) {
+	case *pb.HistoricalFeaturesRequest_Inline:
+		inline := rows.Inline.GetRows()
+		entityRows := make([]entityTimestamp, len(inline))
+		for i, row := range inline {
+			if row.GetTimestamp() == nil {
+				return nil, fferr.NewInvalidArgumentError(fmt.Errorf("row %d is missing a timestamp", i))
+			}
+			entityRows[i] = entityTimestamp{Entity: row.GetEntity(), TS: row.GetTimestamp().AsTime()}
+		}
+		return entityRows, nil
+	case *pb.HistoricalFeaturesRequest_Source:
+		return serv.getSourceEntityTimestampRows(rows.Source)
+	default:
+		return nil, fferr.NewInvalidArgumentError(fmt.Errorf("historical features request must set inline rows or a source"))
+	}
+}
+
+func (serv *FeatureServer) getSourceEntityTimestampRows(source *pb.EntityTimestampSource) ([]entityTimestamp, error) {
+	name, variant := source.GetId().GetName(), source.GetId().GetVersion()
+	iter, err := serv.getSourceDataIterator(name, variant, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	entityIdx, tsIdx := -1, -1
+	for i, col := range iter.Columns() {
+		switch strings.Trim(col, "\"`") {
+		case source.GetEntityColumn():
+			entityIdx = i
+		case source.GetTimestampColumn():
+			tsIdx = i
+		}
+	}
+	if entityIdx == -1 || tsIdx == -1 {
+		wrapped := fferr.NewInvalidArgumentError(fmt.Errorf("source is missing entity or timestamp column"))
+		wrapped.AddDetail("entity_column", source.GetEntityColumn())
+		wrapped.AddDetail("timestamp_column", source.GetTimestampColumn())
+		return nil, wrapped
+	}
+	rows := make([]entityTimestamp, 0)
+	for iter.Next() {
+		values := iter.Values()
+		ts, err := parseTimestamp(values[tsIdx])
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, entityTimestamp{Entity: fmt.Sprintf("%v", values[entityIdx]), TS: ts})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func parseTimestamp(val interface{}) (time.Time, error) {
+	switch casted := val.(type) {
+	case time.Time:
+		return casted, nil
+	case string:
+		ts, err := time.Parse(time.RFC3339, casted)
+		if err != nil {
+			return time.Time{}, fferr.NewDataTypeNotFoundErrorf(casted, "could not parse timestamp: %v", err)
+		}
+		return ts, nil
+	default:
+		return time.Time{}, fferr.NewDataTypeNotFoundErrorf(val, "unsupported timestamp type %T", val)
+	}
+}
+
+// createHistoricalTrainingSet writes rows to a new label table and joins the features onto it.
+func (serv *FeatureServer) createHistoricalTrainingSet(store provider.OfflineStore, name string, features []provider.ResourceID, rows []entityTimestamp) (provider.TrainingSetIterator, error) {
+	labelID := provider.ResourceID{Name: name, Type: provider.Label}
+	schema := provider.TableSchema{
+		Columns: []provider.TableColumn{
+			{Name: "entity", ValueType: types.String},
+			{Name: "value", ValueType: types.Int},
+			{Name: "ts", ValueType: types.Timestamp},
+		},
+	}
+	serv.Logger.Debugw("Creating historical label table", "name", name, "rows", len(rows))
+	table, err := store.CreateResourceTable(labelID, schema)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		if err := table.Write(provider.ResourceRecord{Entity: row.Entity, Value: i, TS: row.TS}); err != nil {
+			return nil, err
+		}
+	}
+	tsID := provider.ResourceID{Name: name, Type: provider.TrainingSet}
+	def := provider.TrainingSetDef{
+		ID:       tsID,
+		Label:    labelID,
+		Features: features,
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		return nil, err
+	}
+	return store.GetTrainingSet(tsID)
+}
+
+// dropHistoricalTrainingSet removes the training set and label table created for a request, including any
+// that were left part way through when creating them failed. Stores that can't drop tables keep them.
+func (serv *FeatureServer) dropHistoricalTrainingSet(store provider.OfflineStore, name string) {
+	tableStore, ok := store.(provider.ResourceTableOfflineStore)
+	if !ok {
+		serv.Logger.Warnw("Offline store can't delete historical tables, keeping them", "name", name, "provider", store.Type())
+		return
+	}
+	for _, id := range []provider.ResourceID{{Name: name, Type: provider.TrainingSet}, {Name: name, Type: provider.Label}} {
+		err := tableStore.DeleteResourceTable(id)
+		if _, isNotFound := err.(*fferr.DatasetNotFoundError); err != nil && !isNotFound {
+			serv.Logger.Errorw("Failed to delete historical table", "id", id, "Error", err)
+		}
+	}
+}
+
+func rowIndex(label interface{}) (int, error) {
+	switch casted := label.(type) {
+	case int:
+		return casted, nil
+	case int32:
+		return int(casted), nil
+	case int64:
+		return int(casted), nil
+	case float64:
+		return int(casted), nil
+	default:
+		return 0, fferr.NewInternalError(fmt.Errorf("unexpected row index type %T", label))
+	}
+}
+
+// getFeaturesOfflineStore returns the offline store that the features were materialized from.
+// All of the features must share an entity and an offline provider.
+func (serv *FeatureServer) getFeaturesOfflineStore(ctx context.Context, ids []provider.ResourceID) (provider.OfflineStore, error) {
+	_, err := serv.checkEntityOfFeature(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assuming that all the features have the same offline provider
+	feat, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: ids[0].Name, Variant: ids[0].Variant})
+	if err != nil {
+		return nil, err
+	}
+	serv.Logger.Debugw("Fetching Feature Provider from ", "name", ids[0].Name, "variant", ids[0].Variant)
+	featureSource, err := feat.FetchSource(serv.Metadata, ctx)
+	if err != nil {
+		return nil, err
+	}
+	providerEntry, err := featureSource.FetchProvider(serv.Metadata, ctx)
+	if err != nil {
+		return nil, err
+	}
+	providerName := providerEntry.Name()
+	err = serv.checkFeatureSources(providerName, ids, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// An error here means that the provider of the feature isn't an offline store.
+	// That shouldn't be possible.
+	return p.AsOfflineStore()
+}
+
+func serializedHistoricalRow(row entityTimestamp, features []interface{}) (*pb.HistoricalFeatureRow, error) {
+	serialized := &pb.HistoricalFeatureRow{
+		Entity:    row.Entity,
+		Timestamp: tspb.New(row.TS),
+		Features:  make([]*pb.Value, len(features)),
+	}
+	for i, feature := range features {
+		val, err := newValue(feature)
+		if err != nil {
+			return nil, err
+		}
+		serialized.Features[i] = val.Serialized()
+	}
+	return serialized, nil
+}
@@ -5,9 +5,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	_ "net/http/pprof"
+	"time"
 
 	help "github.com/featureform/helpers"
 	"github.com/featureform/logging"
@@ -15,6 +17,7 @@ import (
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
 	"github.com/featureform/serving"
+	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
 )
 
@@ -45,7 +48,44 @@ func main() {
 	if err != nil {
 		logger.Panicw("Failed to create training server", "Err", err)
 	}
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(help.UnaryServerErrorInterceptor), grpc.StreamInterceptor(help.StreamServerErrorInterceptor))
+	// Value caches are invalidated when a materialization completes, which requires watching etcd.
+	if etcdHost := help.GetEnv("ETCD_HOST", ""); etcdHost != "" {
+		etcdPort := help.GetEnv("ETCD_PORT", "2379")
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)},
+			Username:    help.GetEnv("ETCD_USERNAME", "root"),
+			Password:    help.GetEnv("ETCD_PASSWORD", "secretpassword"),
+			DialTimeout: time.Second * 5,
+		})
+		if err != nil {
+			logger.Panicw("Failed to connect to etcd", "Err", err)
+		}
+		go func() {
+			if err := serv.WatchResourceUpdates(context.Background(), client); err != nil {
+				logger.Errorw("Stopped watching resource updates", "Err", err)
+			}
+		}()
+	}
+	unary := []grpc.UnaryServerInterceptor{help.UnaryServerErrorInterceptor}
+	stream := []grpc.StreamServerInterceptor{help.StreamServerErrorInterceptor}
+	rbac, err := metadata.RBACConfigFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to configure access control", "Err", err)
+	}
+	if rbac != nil {
+		authorizer := metadata.NewRBACAuthorizer(meta, *rbac)
+		unary = append(unary, help.UnaryServerAuthInterceptor(authorizer))
+		stream = append(stream, help.StreamServerAuthInterceptor(authorizer))
+	}
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+	credentials, err := help.ServerTLSFromEnv()
+	if err != nil {
+		logger.Panicw("Failed to load TLS credentials", "Err", err)
+	}
+	if credentials != nil {
+		opts = append(opts, grpc.Creds(credentials))
+	}
+	grpcServer := grpc.NewServer(opts...)
 
 	pb.RegisterFeatureServer(grpcServer, serv)
 	logger.Infow("Serving metrics", "Port", metricsPort)
@@ -224,6 +224,36 @@ func wrapValue(value interface{}) (proto *pb.Value, err error) {
 	return
 }
 
+// unwrapValue is the inverse of wrapValue. It's used for values sent by clients.
+func unwrapValue(val *pb.Value) interface{} {
+	switch typed := val.GetValue().(type) {
+	case *pb.Value_StrValue:
+		return typed.StrValue
+	case *pb.Value_IntValue:
+		return typed.IntValue
+	case *pb.Value_FloatValue:
+		return typed.FloatValue
+	case *pb.Value_DoubleValue:
+		return typed.DoubleValue
+	case *pb.Value_Int64Value:
+		return typed.Int64Value
+	case *pb.Value_Int32Value:
+		return typed.Int32Value
+	case *pb.Value_BoolValue:
+		return typed.BoolValue
+	case *pb.Value_OnDemandFunction:
+		return typed.OnDemandFunction
+	case *pb.Value_Vector32Value:
+		return typed.Vector32Value.GetValue()
+	case *pb.Value_Uint32Value:
+		return typed.Uint32Value
+	case *pb.Value_Uint64Value:
+		return typed.Uint64Value
+	default:
+		return nil
+	}
+}
+
 func wrapFloat(val float32) *pb.Value {
 	return &pb.Value{
 		Value: &pb.Value_FloatValue{FloatValue: val},
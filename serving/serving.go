@@ -27,17 +27,23 @@ type FeatureServer struct {
 	Providers *sync.Map
 	Tables    *sync.Map
 	Features  *sync.Map
+	// Compiled expressions of SERVER_COMPUTED features.
+	Expressions *sync.Map
+	// Value caches of feature variants that have one configured.
+	ValueCaches *sync.Map
 }
 
 func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler, logger *zap.SugaredLogger) (*FeatureServer, error) {
 	logger.Debug("Creating new training data server")
 	return &FeatureServer{
-		Metadata:  meta,
-		Metrics:   promMetrics,
-		Logger:    logger,
-		Providers: &sync.Map{},
-		Tables:    &sync.Map{},
-		Features:  &sync.Map{},
+		Metadata:    meta,
+		Metrics:     promMetrics,
+		Logger:      logger,
+		Providers:   &sync.Map{},
+		Tables:      &sync.Map{},
+		Features:    &sync.Map{},
+		Expressions: &sync.Map{},
+		ValueCaches: &sync.Map{},
 	}, nil
 }
 
@@ -85,25 +91,40 @@ func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.F
 }
 
 type splitContext struct {
-	stream          pb.Feature_TrainTestSplitServer
-	req             *pb.TrainTestSplitRequest
-	trainIterator   *provider.TrainingSetIterator
-	testIterator    *provider.TrainingSetIterator
+	stream             pb.Feature_TrainTestSplitServer
+	req                *pb.TrainTestSplitRequest
+	trainIterator      *provider.TrainingSetIterator
+	testIterator       *provider.TrainingSetIterator
+	validationIterator *provider.TrainingSetIterator
+	// dropSplit is set on initialization and called once the stream is closed.
+	dropSplit       *func() error
 	isTestFinished  *bool
 	isTrainFinished *bool
-	logger          *zap.SugaredLogger
+	// isValidationFinished starts out true when the split has no validation rows.
+	isValidationFinished *bool
+	logger               *zap.SugaredLogger
 }
 
 func (serv *FeatureServer) TrainTestSplit(stream pb.Feature_TrainTestSplitServer) error {
 	var (
-		trainIter, testIter provider.TrainingSetIterator
-		isTrainFinished     bool
-		isTestFinished      bool
+		trainIter, testIter, validationIter provider.TrainingSetIterator
+		isTrainFinished                     bool
+		isTestFinished                      bool
+		isValidationFinished                = true
+		dropSplit                           func() error
 	)
+	defer func() {
+		if dropSplit == nil {
+			return
+		}
+		if err := dropSplit(); err != nil {
+			serv.Logger.Errorw("Failed to drop train test split", "Error", err)
+		}
+	}()
 
 	for {
-		if isTrainFinished && isTestFinished {
-			serv.Logger.Infow("Both iterators are finished, closing stream")
+		if isTrainFinished && isTestFinished && isValidationFinished {
+			serv.Logger.Infow("All iterators are finished, closing stream")
 			// returning nil will close the stream
 			return nil
 		}
@@ -127,13 +148,16 @@ func (serv *FeatureServer) TrainTestSplit(stream pb.Feature_TrainTestSplitServer
 		defer featureObserver.Finish()
 
 		splitContext := splitContext{
-			stream:          stream,
-			req:             req,
-			trainIterator:   &trainIter,
-			testIterator:    &testIter,
-			isTestFinished:  &isTestFinished,
-			isTrainFinished: &isTrainFinished,
-			logger:          logger,
+			stream:               stream,
+			req:                  req,
+			trainIterator:        &trainIter,
+			testIterator:         &testIter,
+			validationIterator:   &validationIter,
+			dropSplit:            &dropSplit,
+			isTestFinished:       &isTestFinished,
+			isTrainFinished:      &isTrainFinished,
+			isValidationFinished: &isValidationFinished,
+			logger:               logger,
 		}
 
 		switch req.GetRequestType() {
@@ -152,22 +176,24 @@ func (serv *FeatureServer) TrainTestSplit(stream pb.Feature_TrainTestSplitServer
 }
 
 func (serv *FeatureServer) handleSplitInitializeRequest(splitContext *splitContext) error {
-	splitContext.logger.Infow("Initializing dataset", "id", splitContext.req.Id, "shuffle", splitContext.req.Shuffle, "testSize", splitContext.req.TestSize)
+	splitContext.logger.Infow("Initializing dataset", "id", splitContext.req.Id, "shuffle", splitContext.req.Shuffle, "testSize", splitContext.req.TestSize, "strategy", splitContext.req.Strategy, "validationSize", splitContext.req.ValidationSize)
 
-	trainTestSplitDef := provider.TrainTestSplitDef{
-		TrainingSetName:    splitContext.req.Id.GetName(),
-		TrainingSetVariant: splitContext.req.Id.GetVersion(),
-		TestSize:           splitContext.req.TestSize,
-		Shuffle:            splitContext.req.Shuffle,
-		RandomState:        int(splitContext.req.RandomState),
+	trainTestSplitDef, err := trainTestSplitDefFromRequest(splitContext.req)
+	if err != nil {
+		return err
 	}
-
-	cleanupFunc, err := serv.createTrainTestSplit(trainTestSplitDef)
-	defer cleanupFunc()
+	store, err := serv.getTrainingSetOfflineStore(trainTestSplitDef.TrainingSetName, trainTestSplitDef.TrainingSetVariant)
 	if err != nil {
-		return fferr.NewInternalError(err)
+		return err
+	}
+	serv.Logger.Infow("Creating Train Test Split", "TrainTestSplitDef", fmt.Sprintf("%+v", trainTestSplitDef))
+	cleanupFunc, err := store.CreateTrainTestSplit(trainTestSplitDef)
+	if err != nil {
+		splitContext.logger.Errorw("Failed to create train test split", "Error", err)
+		return err
 	}
-	train, test, err := serv.getTrainTestSplitIterators(trainTestSplitDef)
+	*splitContext.dropSplit = cleanupFunc
+	train, test, err := store.GetTrainTestSplit(trainTestSplitDef)
 	if err != nil {
 		splitContext.logger.Errorw("Failed to get training set iterator", "Error", err)
 		return err
@@ -175,6 +201,15 @@ func (serv *FeatureServer) handleSplitInitializeRequest(splitContext *splitConte
 
 	*splitContext.trainIterator = train
 	*splitContext.testIterator = test
+	if hasValidationSplit(trainTestSplitDef) {
+		validation, err := store.GetValidationSplit(trainTestSplitDef)
+		if err != nil {
+			splitContext.logger.Errorw("Failed to get validation set iterator", "Error", err)
+			return err
+		}
+		*splitContext.validationIterator = validation
+		*splitContext.isValidationFinished = false
+	}
 
 	initResponse := &pb.BatchTrainTestSplitResponse{
 		RequestType: pb.RequestType_INITIALIZE,
@@ -196,9 +231,14 @@ func (serv *FeatureServer) handleSplitDataRequest(splitContext *splitContext) er
 		thisIter = *splitContext.trainIterator
 	case pb.RequestType_TEST:
 		thisIter = *splitContext.testIterator
+	case pb.RequestType_VALIDATION:
+		thisIter = *splitContext.validationIterator
 	default:
 		return fmt.Errorf("invalid request type")
 	}
+	if thisIter == nil {
+		return fferr.NewInvalidArgumentError(fmt.Errorf("%s split was not initialized", splitContext.req.GetRequestType()))
+	}
 
 	rows := 0
 	trainingDataRows := make([]*pb.TrainingDataRow, 0)
@@ -212,8 +252,13 @@ func (serv *FeatureServer) handleSplitDataRequest(splitContext *splitContext) er
 			trainingDataRows = append(trainingDataRows, sRow)
 			rows++
 		} else {
+			if err := thisIter.Err(); err != nil {
+				splitContext.logger.Errorw("Dataset error", "Error", err)
+				return err
+			}
 			// if we reach the end of the iterator mid-batch, we'll send the processed rows so far and end the iteration
 			serv.handleFinishedIterator(trainingDataRows, splitContext)
+			return nil
 		}
 	}
 
@@ -237,25 +282,25 @@ func (serv *FeatureServer) handleSplitDataRequest(splitContext *splitContext) er
 }
 
 func (serv *FeatureServer) handleFinishedIterator(trainingDataRows []*pb.TrainingDataRow, splitContext *splitContext) {
-	if splitContext.req.GetRequestType() == pb.RequestType_TEST {
+	switch splitContext.req.GetRequestType() {
+	case pb.RequestType_TEST:
 		*splitContext.isTestFinished = true
-	} else if splitContext.req.GetRequestType() == pb.RequestType_TRAINING {
+	case pb.RequestType_TRAINING:
 		*splitContext.isTrainFinished = true
+	case pb.RequestType_VALIDATION:
+		*splitContext.isValidationFinished = true
 	}
 
-	if *splitContext.isTestFinished && *splitContext.isTrainFinished {
-		return // return so that we can close the stream
-	} else {
-		response := &pb.BatchTrainTestSplitResponse{
-			Result: &pb.BatchTrainTestSplitResponse_Data{
-				Data: &pb.TrainingDataRows{Rows: trainingDataRows},
-			},
-			IteratorDone: true,
-		}
+	// The last batch is sent even when every iterator is finished, the stream is closed afterwards.
+	response := &pb.BatchTrainTestSplitResponse{
+		Result: &pb.BatchTrainTestSplitResponse_Data{
+			Data: &pb.TrainingDataRows{Rows: trainingDataRows},
+		},
+		IteratorDone: true,
+	}
 
-		if err := splitContext.stream.Send(response); err != nil {
-			splitContext.logger.Errorw("Failed to write to stream", "Error", err)
-		}
+	if err := splitContext.stream.Send(response); err != nil {
+		splitContext.logger.Errorw("Failed to write to stream", "Error", err)
 	}
 }
 
@@ -333,13 +378,14 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 	return store.GetTrainingSet(provider.ResourceID{Name: name, Variant: variant})
 }
 
-func (serv *FeatureServer) createTrainTestSplit(def provider.TrainTestSplitDef) (func() error, error) {
+// getTrainingSetOfflineStore returns the offline store that a training set was created in.
+func (serv *FeatureServer) getTrainingSetOfflineStore(name, variant string) (provider.OfflineStore, error) {
 	ctx := context.TODO()
-	serv.Logger.Infow("Creating Train Test Split", "TrainTestSplitDef", fmt.Sprintf("%+v", def))
-	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: def.TrainingSetName, Variant: def.TrainingSetVariant})
+	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
 	if err != nil {
 		return nil, err
 	}
+	serv.Logger.Debugw("Fetching Training Set Provider", "name", name, "variant", variant)
 	providerEntry, err := ts.FetchProvider(serv.Metadata, ctx)
 	if err != nil {
 		return nil, err
@@ -350,72 +396,53 @@ func (serv *FeatureServer) createTrainTestSplit(def provider.TrainTestSplitDef)
 	}
 	store, err := p.AsOfflineStore()
 	if err != nil {
+		serv.Logger.Errorw("Training set provider is not an offline store", "Error", err)
 		return nil, err
 	}
-
-	return store.CreateTrainTestSplit(def)
+	return store, nil
 }
 
-func (serv *FeatureServer) getTrainTestSplitIterators(def provider.TrainTestSplitDef) (provider.TrainingSetIterator, provider.TrainingSetIterator, error) {
-	ctx := context.TODO()
-	serv.Logger.Infow("Getting Training Set Iterator", "name", def.TrainingSetName, "variant", def.TrainingSetVariant)
-	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{def.TrainingSetName, def.TrainingSetVariant})
-	if err != nil {
-		return nil, nil, err
-	}
-	serv.Logger.Debugw("Fetching Training Set Provider", "name", def.TrainingSetName, "variant", def.TrainingSetVariant)
-	providerEntry, err := ts.FetchProvider(serv.Metadata, ctx)
-	if err != nil {
-		return nil, nil, err
-	}
-	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
-	if err != nil {
-		return nil, nil, err
-	}
-	store, err := p.AsOfflineStore()
-	if err != nil {
-		serv.Logger.Errorw("Training set provider is not an offline store", "Error", err)
-		return nil, nil, err
-	}
-	return store.GetTrainTestSplit(def)
+var splitStrategies = map[pb.SplitStrategy]provider.TrainTestSplitStrategy{
+	pb.SplitStrategy_SPLIT_RANDOM:      provider.RandomSplit,
+	pb.SplitStrategy_SPLIT_TIME:        provider.TimeSplit,
+	pb.SplitStrategy_SPLIT_STRATIFIED:  provider.StratifiedSplit,
+	pb.SplitStrategy_SPLIT_ENTITY_HASH: provider.EntityHashSplit,
 }
 
-func (serv *FeatureServer) getBatchFeatureIterator(ids []provider.ResourceID) (provider.BatchFeatureIterator, error) {
-	ctx := context.TODO()
-	_, err := serv.checkEntityOfFeature(ids)
-	if err != nil {
-		return nil, err
+func trainTestSplitDefFromRequest(req *pb.TrainTestSplitRequest) (provider.TrainTestSplitDef, error) {
+	strategy, has := splitStrategies[req.GetStrategy()]
+	if !has {
+		return provider.TrainTestSplitDef{}, fferr.NewInvalidArgumentError(fmt.Errorf("unknown split strategy %s", req.GetStrategy()))
 	}
-
-	// Assuming that all the features have the same offline provider
-	feat, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{ids[0].Name, ids[0].Variant})
-	if err != nil {
-		return nil, err
+	def := provider.TrainTestSplitDef{
+		TrainingSetName:    req.GetId().GetName(),
+		TrainingSetVariant: req.GetId().GetVersion(),
+		TestSize:           req.GetTestSize(),
+		Shuffle:            req.GetShuffle(),
+		RandomState:        int(req.GetRandomState()),
+		Strategy:           strategy,
+		ValidationSize:     req.GetValidationSize(),
 	}
-	serv.Logger.Debugw("Fetching Feature Provider from ", "name", ids[0].Name, "variant", ids[0].Variant)
-	featureSource, err := feat.FetchSource(serv.Metadata, ctx)
-	if err != nil {
-		return nil, err
+	if req.GetTestCutoff() != nil {
+		def.TestCutoff = req.GetTestCutoff().AsTime()
 	}
-	providerEntry, err := featureSource.FetchProvider(serv.Metadata, ctx)
-	if err != nil {
-		return nil, err
-	}
-	providerName := providerEntry.Name()
-	err = serv.checkFeatureSources(providerName, ids, ctx)
-	if err != nil {
-		return nil, err
+	if req.GetValidationCutoff() != nil {
+		def.ValidationCutoff = req.GetValidationCutoff().AsTime()
 	}
+	return def, nil
+}
 
-	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
-	if err != nil {
-		return nil, err
+// hasValidationSplit returns true if the split holds out any rows for validation.
+func hasValidationSplit(def provider.TrainTestSplitDef) bool {
+	if def.Strategy == provider.TimeSplit {
+		return !def.ValidationCutoff.IsZero()
 	}
+	return def.ValidationSize > 0
+}
 
-	store, err := p.AsOfflineStore()
+func (serv *FeatureServer) getBatchFeatureIterator(ids []provider.ResourceID) (provider.BatchFeatureIterator, error) {
+	store, err := serv.getFeaturesOfflineStore(context.TODO(), ids)
 	if err != nil {
-		// This means that the provider of the feature isn't an offline store.
-		// That shouldn't be possible.
 		return nil, err
 	}
 	return store.GetBatchFeatures(ids)
@@ -541,7 +568,12 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 		entityMap[entity.GetName()] = entity.GetValues()
 	}
 
-	rows, err := serv.getFeatureRows(ctx, features, entityMap)
+	requestInputs := make(map[string]interface{}, len(req.GetRequestInputs()))
+	for name, val := range req.GetRequestInputs() {
+		requestInputs[name] = unwrapValue(val)
+	}
+
+	rows, err := serv.getFeatureRows(ctx, features, entityMap, requestInputs)
 	if err != nil {
 		return nil, err
 	}
@@ -13,6 +13,7 @@ import (
 	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,6 +23,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 	grpcmeta "google.golang.org/grpc/metadata"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
@@ -771,6 +773,234 @@ func (stream *mockBatchServingStream) RecvMsg(interface{}) error {
 // 	}
 // }
 
+type mockHistoricalStream struct {
+	Rows []*pb.HistoricalFeatureRow
+}
+
+func (stream *mockHistoricalStream) Send(row *pb.HistoricalFeatureRow) error {
+	stream.Rows = append(stream.Rows, row)
+	return nil
+}
+
+func (stream *mockHistoricalStream) Context() context.Context {
+	return context.Background()
+}
+
+func (stream *mockHistoricalStream) SetHeader(grpcmeta.MD) error {
+	return nil
+}
+
+func (stream *mockHistoricalStream) SendHeader(grpcmeta.MD) error {
+	return nil
+}
+
+func (stream *mockHistoricalStream) SetTrailer(grpcmeta.MD) {
+}
+
+func (stream *mockHistoricalStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func (stream *mockHistoricalStream) RecvMsg(interface{}) error {
+	return nil
+}
+
+func TestFeatureServeTTL(t *testing.T) {
+	now := time.Now()
+	defsFn := func(providerType string) []metadata.ResourceDef {
+		defs := simpleResourceDefsFn(providerType)
+		for i, def := range defs {
+			if feat, ok := def.(metadata.FeatureDef); ok && feat.Variant == "variant" {
+				feat.TTL = time.Hour
+				defs[i] = feat
+			}
+		}
+		return defs
+	}
+	factory := func(cfg pc.SerializedConfig) (provider.Provider, error) {
+		store := provider.NewLocalOnlineStore()
+		table, err := store.CreateTable("feature", "variant", types.Float64)
+		if err != nil {
+			panic(err)
+		}
+		tsTable := table.(provider.TimestampedOnlineStoreTable)
+		if err := tsTable.SetWithTimestamp("fresh", 1.5, now.Add(-time.Minute)); err != nil {
+			panic(err)
+		}
+		if err := tsTable.SetWithTimestamp("stale", 2.5, now.Add(-2*time.Hour)); err != nil {
+			panic(err)
+		}
+		if err := table.Set("unknown", 3.5); err != nil {
+			panic(err)
+		}
+		return store, nil
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: defsFn,
+		FactoryFn:      factory,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{Name: "feature", Version: "variant"},
+		},
+		Entities: []*pb.Entity{
+			{Name: "mockEntity", Values: []string{"fresh", "stale", "unknown"}},
+		},
+	}
+	resp, err := serv.FeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve feature: %s", err)
+	}
+	vals := resp.ValueLists[0]
+	assert.Equal(t, 1.5, unwrapVal(vals.Values[0]))
+	assert.Equal(t, "", unwrapVal(vals.Values[1]), "expired value should be null")
+	assert.Equal(t, 3.5, unwrapVal(vals.Values[2]), "value without a timestamp should never expire")
+	if len(vals.EventTimestamps) != 3 {
+		t.Fatalf("Wrong number of event timestamps: %d\nExpected: %d", len(vals.EventTimestamps), 3)
+	}
+	assert.True(t, now.Add(-time.Minute).Equal(vals.EventTimestamps[0].AsTime()))
+	assert.True(t, now.Add(-2*time.Hour).Equal(vals.EventTimestamps[1].AsTime()))
+	assert.True(t, vals.EventTimestamps[2].AsTime().IsZero())
+}
+
+func TestHistoricalFeatures(t *testing.T) {
+	t1 := time.UnixMilli(1000).UTC()
+	t2 := time.UnixMilli(2000).UTC()
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	recs := map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: {
+			{Entity: "a", Value: 1, TS: t1},
+			{Entity: "a", Value: 2, TS: t2},
+			{Entity: "b", Value: 3, TS: t2},
+		},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(recs, nil),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.HistoricalFeaturesRequest{
+		Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+		Rows: &pb.HistoricalFeaturesRequest_Inline{
+			Inline: &pb.EntityTimestampRows{
+				Rows: []*pb.EntityTimestamp{
+					{Entity: "a", Timestamp: tspb.New(t1)},
+					{Entity: "a", Timestamp: tspb.New(t2.Add(time.Second))},
+					{Entity: "b", Timestamp: tspb.New(t2)},
+				},
+			},
+		},
+	}
+	stream := &mockHistoricalStream{}
+	if err := serv.HistoricalFeatures(req, stream); err != nil {
+		t.Fatalf("Failed to get historical features: %s", err)
+	}
+	type Row struct {
+		Entity  string
+		TS      time.Time
+		Feature interface{}
+	}
+	expected := []Row{
+		{"a", t1, 1},
+		{"a", t2.Add(time.Second), 2},
+		{"b", t2, 3},
+	}
+	actual := make([]Row, len(stream.Rows))
+	for i, row := range stream.Rows {
+		actual[i] = Row{row.Entity, row.Timestamp.AsTime(), unwrapVal(row.Features[0])}
+	}
+	assert.ElementsMatch(t, expected, actual)
+}
+
+// trackingOfflineStore records the tables that are still registered so tests can check that temporary ones
+// are dropped.
+type trackingOfflineStore struct {
+	provider.OfflineStore
+	tables           map[provider.ResourceID]bool
+	failTrainingSets bool
+}
+
+func (store *trackingOfflineStore) AsOfflineStore() (provider.OfflineStore, error) {
+	return store, nil
+}
+
+func (store *trackingOfflineStore) CreateResourceTable(id provider.ResourceID, schema provider.TableSchema) (provider.OfflineTable, error) {
+	store.tables[id] = true
+	return store.OfflineStore.CreateResourceTable(id, schema)
+}
+
+func (store *trackingOfflineStore) CreateTrainingSet(def provider.TrainingSetDef) error {
+	if store.failTrainingSets {
+		return fmt.Errorf("training set failed")
+	}
+	store.tables[def.ID] = true
+	return store.OfflineStore.CreateTrainingSet(def)
+}
+
+func (store *trackingOfflineStore) DeleteResourceTable(id provider.ResourceID) error {
+	delete(store.tables, id)
+	return store.OfflineStore.(provider.ResourceTableOfflineStore).DeleteResourceTable(id)
+}
+
+func TestHistoricalFeaturesDropsTables(t *testing.T) {
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	store := &trackingOfflineStore{OfflineStore: provider.NewMemoryOfflineStore(), tables: make(map[provider.ResourceID]bool)}
+	table, err := store.OfflineStore.CreateResourceTable(featureId, provider.TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %s", err)
+	}
+	if err := table.Write(provider.ResourceRecord{Entity: "a", Value: 1, TS: time.UnixMilli(1000).UTC()}); err != nil {
+		t.Fatalf("Failed to write feature: %s", err)
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn: func(cfg pc.SerializedConfig) (provider.Provider, error) {
+			return store, nil
+		},
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.HistoricalFeaturesRequest{
+		Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+		Rows: &pb.HistoricalFeaturesRequest_Inline{
+			Inline: &pb.EntityTimestampRows{
+				Rows: []*pb.EntityTimestamp{{Entity: "a", Timestamp: tspb.New(time.UnixMilli(2000))}},
+			},
+		},
+	}
+	if err := serv.HistoricalFeatures(req, &mockHistoricalStream{}); err != nil {
+		t.Fatalf("Failed to get historical features: %s", err)
+	}
+	if len(store.tables) != 0 {
+		t.Fatalf("Expected historical tables to be dropped, still have %v", store.tables)
+	}
+	store.failTrainingSets = true
+	if err := serv.HistoricalFeatures(req, &mockHistoricalStream{}); err == nil {
+		t.Fatalf("Expected a failed training set to fail the request")
+	}
+	if len(store.tables) != 0 {
+		t.Fatalf("Expected historical tables to be dropped after a failure, still have %v", store.tables)
+	}
+}
+
+func TestHistoricalFeaturesNoRows(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), nil),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.HistoricalFeaturesRequest{
+		Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+	}
+	if err := serv.HistoricalFeatures(req, &mockHistoricalStream{}); err == nil {
+		t.Fatalf("Succeeded without entity timestamp rows")
+	}
+}
+
 func TestFeatureNotFound(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1568,3 +1798,168 @@ func TestTrainTestSplit_DataRequest(t *testing.T) {
 	assert.NotEmpty(t, mockTrainTestSplitServer.Responses)
 	assert.Equal(t, pb.RequestType_INITIALIZE, mockTrainTestSplitServer.Responses[0].RequestType)
 }
+
+func TestTrainTestSplit_ValidationRequest(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+
+	// Both rows are written at the epoch, so they fall between the cutoffs and into the validation split.
+	initRequest := &pb.TrainTestSplitRequest{
+		Id:               &pb.TrainingDataID{Name: "training-set", Version: "variant"},
+		Strategy:         pb.SplitStrategy_SPLIT_TIME,
+		ValidationCutoff: tspb.New(time.UnixMilli(0).Add(-time.Hour)),
+		TestCutoff:       tspb.New(time.UnixMilli(0).Add(time.Hour)),
+		RequestType:      pb.RequestType_INITIALIZE,
+		BatchSize:        3,
+	}
+	requests := []*pb.TrainTestSplitRequest{initRequest}
+	for _, requestType := range []pb.RequestType{pb.RequestType_TRAINING, pb.RequestType_TEST, pb.RequestType_VALIDATION} {
+		req := proto.Clone(initRequest).(*pb.TrainTestSplitRequest)
+		req.RequestType = requestType
+		requests = append(requests, req)
+	}
+
+	mockTrainTestSplitServer := new(MockFeature_TrainTestSplitServer)
+	for _, req := range requests {
+		mockTrainTestSplitServer.On("Recv").Return(req, nil).Once()
+	}
+	mockTrainTestSplitServer.On("Recv").Return(nil, io.EOF).Maybe()
+	mockTrainTestSplitServer.On("Send", mock.Anything).Return(nil)
+
+	if err := serv.TrainTestSplit(mockTrainTestSplitServer); err != nil {
+		t.Fatalf("Failed to split training set: %s", err)
+	}
+	responses := mockTrainTestSplitServer.Responses
+	if len(responses) != 4 {
+		t.Fatalf("Expected 4 responses, got %d", len(responses))
+	}
+	for i, expectedRows := range []int{0, 0, 2} {
+		resp := responses[i+1]
+		if !resp.IteratorDone {
+			t.Fatalf("Expected %s iterator to be done", requests[i+1].RequestType)
+		}
+		if rows := len(resp.GetData().GetRows()); rows != expectedRows {
+			t.Fatalf("Expected %d %s rows, got %d", expectedRows, requests[i+1].RequestType, rows)
+		}
+	}
+}
+
+func TestTrainTestSplitDefFromRequest(t *testing.T) {
+	testCutoff := time.UnixMilli(2000).UTC()
+	validationCutoff := time.UnixMilli(1000).UTC()
+	req := &pb.TrainTestSplitRequest{
+		Id:               &pb.TrainingDataID{Name: "training-set", Version: "variant"},
+		TestSize:         .2,
+		ValidationSize:   .1,
+		Shuffle:          true,
+		RandomState:      3,
+		Strategy:         pb.SplitStrategy_SPLIT_ENTITY_HASH,
+		TestCutoff:       tspb.New(testCutoff),
+		ValidationCutoff: tspb.New(validationCutoff),
+	}
+	def, err := trainTestSplitDefFromRequest(req)
+	if err != nil {
+		t.Fatalf("Failed to build split def: %s", err)
+	}
+	expected := provider.TrainTestSplitDef{
+		TrainingSetName:    "training-set",
+		TrainingSetVariant: "variant",
+		TestSize:           .2,
+		ValidationSize:     .1,
+		Shuffle:            true,
+		RandomState:        3,
+		Strategy:           provider.EntityHashSplit,
+		TestCutoff:         testCutoff,
+		ValidationCutoff:   validationCutoff,
+	}
+	if !reflect.DeepEqual(expected, def) {
+		t.Fatalf("Wrong split def\nExpected: %+v\nFound: %+v", expected, def)
+	}
+	if !hasValidationSplit(def) {
+		t.Fatalf("Expected split to have validation rows")
+	}
+
+	req.Strategy = pb.SplitStrategy(100)
+	if _, err := trainTestSplitDefFromRequest(req); err == nil {
+		t.Fatalf("Expected unknown split strategy to fail")
+	}
+}
+
+func serverComputedResourceDefsFn(providerType string) []metadata.ResourceDef {
+	inputs := map[string]metadata.NameVariant{"x": {Name: "feature", Variant: "variant"}}
+	return append(simpleResourceDefsFn(providerType),
+		metadata.FeatureDef{
+			Name:    "feature-expr",
+			Variant: "scaled",
+			Entity:  "mockEntity",
+			Owner:   "Featureform",
+			Location: metadata.Expression{
+				Expression: "x * double(request.scale)",
+				Inputs:     inputs,
+			},
+			Mode:       metadata.SERVER_COMPUTED,
+			IsOnDemand: true,
+		},
+		metadata.FeatureDef{
+			Name:    "feature-expr",
+			Variant: "bucket",
+			Entity:  "mockEntity",
+			Owner:   "Featureform",
+			Location: metadata.Expression{
+				Expression: `x > 10.0 ? "high" : "low"`,
+				Inputs:     inputs,
+			},
+			Mode:       metadata.SERVER_COMPUTED,
+			IsOnDemand: true,
+		},
+	)
+}
+
+func TestServerComputedFeatureServe(t *testing.T) {
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	recs := map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: {{Entity: "a", Value: 12.5}, {Entity: "b", Value: 2.0}},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: serverComputedResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(recs),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{Name: "feature-expr", Version: "scaled"},
+			{Name: "feature-expr", Version: "bucket"},
+		},
+		Entities: []*pb.Entity{
+			{Name: "mockEntity", Values: []string{"a", "b"}},
+		},
+		RequestInputs: map[string]*pb.Value{
+			"scale": {Value: &pb.Value_IntValue{IntValue: 2}},
+		},
+	}
+	resp, err := serv.FeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve feature: %s", err)
+	}
+	expected := [][]interface{}{{25.0, 4.0}, {"high", "low"}}
+	for i, valueList := range resp.ValueLists {
+		actual := make([]interface{}, len(valueList.Values))
+		for j, val := range valueList.Values {
+			actual[j] = unwrapVal(val)
+		}
+		if !reflect.DeepEqual(expected[i], actual) {
+			t.Fatalf("Wrong values for %s\nExpected: %v\nFound: %v", req.Features[i].Version, expected[i], actual)
+		}
+	}
+
+	req.Features = []*pb.FeatureID{{Name: "feature-expr", Version: "scaled"}}
+	req.RequestInputs = nil
+	if _, err := serv.FeatureServe(context.Background(), req); err == nil {
+		t.Fatalf("Expected error evaluating an expression without its request input")
+	}
+}